@@ -2,114 +2,1541 @@ package main
 
 import (
 	"context"
+	"crypto/subtle"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"dog-view/internal/backup"
+	apperrors "dog-view/internal/errors"
+	"dog-view/internal/ledger"
 	"dog-view/internal/model"
 	"dog-view/internal/repository"
 	"dog-view/internal/service"
+	"dog-view/internal/vault"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 // App struct
 type App struct {
-	ctx             context.Context
-	repo            *repository.SQLiteRepository
-	categoryService *service.CategoryService
-	recordService   *service.RecordService
-	exportService   *service.ExportService
+	ctx              context.Context
+	ledgers          *ledger.Registry
+	repo             *repository.SQLiteRepository
+	categoryService  *service.CategoryService
+	recordService    *service.RecordService
+	tagService       *service.TagService
+	accountService   *service.AccountService
+	settingsService  *service.SettingsService
+	rateService      *service.RateService
+	budgetService    *service.BudgetService
+	recurringService *service.RecurringService
+	trashService     *service.TrashService
+	duplicateService *service.DuplicateService
+	profileService   *service.CSVProfileService
+	historyService   *service.HistoryService
+	backupService    *service.BackupService
+	lockService      *service.LockService
+	exportService    *service.ExportService
+
+	// stateMu 保护 repo、各服务以及 startupErr、ledgerLocked、passphrase：返回数据的绑定方法
+	// 通过 ready 在整个调用期间持有读锁；打开、切换或关闭账本时同时持有 ledgerMu 和写锁，
+	// 等进行中的调用结束后才替换服务并关闭原仓库。持有 ledgerMu 时可以直接读取这些字段
+	stateMu sync.RWMutex
+	// startupErr 启动阶段（数据库打开或迁移）的错误，非空时所有绑定方法直接返回该错误
+	startupErr error
+	// ledgerLocked 当前账本已加密且尚未解锁，此时所有绑定方法返回 ErrLedgerLocked
+	ledgerLocked bool
+	// passphrase 当前加密账本的密码，替换账本文件后用于重新打开
+	passphrase string
+	// appLocked 应用已锁定，此时返回数据的绑定方法（包括账本管理）都返回 ErrAppLocked
+	appLocked atomic.Bool
+	// lastActivity 最近一次用户操作的时间（UnixNano），用于空闲自动锁定
+	lastActivity atomic.Int64
+	// failedUnlocks 连续输错 PIN 的次数，达到上限后在 unlockRetryAt 之前拒绝解锁，受 ledgerMu 保护
+	failedUnlocks int
+	unlockRetryAt time.Time
+	// ledgerMu 串行化账本的打开与切换以及后台任务，需要时先于 stateMu 获取
+	ledgerMu sync.Mutex
+	// stopScheduler 停止周期记账、回收站清理、自动备份与自动锁定的后台定时任务
+	stopScheduler context.CancelFunc
+}
+
+// NewApp creates a new App application struct
+func NewApp() *App {
+	return &App{}
+}
+
+// emit 向前端发送事件，startup 之前（如测试中）没有运行时上下文时忽略
+func (a *App) emit(event string, data ...interface{}) {
+	if a.ctx == nil {
+		return
+	}
+	runtime.EventsEmit(a.ctx, event, data...)
+}
+
+// logError 记录错误日志，startup 之前没有运行时上下文时忽略
+func (a *App) logError(message string) {
+	if a.ctx == nil {
+		return
+	}
+	runtime.LogError(a.ctx, message)
+}
+
+// startup is called when the app starts
+func (a *App) startup(ctx context.Context) {
+	a.ctx = ctx
+
+	// 加载账本登记表
+	dataDir, err := repository.DataDir()
+	if err != nil {
+		err = fmt.Errorf("获取数据目录失败: %w", err)
+		a.setStartupErr(err)
+		runtime.LogError(ctx, err.Error())
+		return
+	}
+	ledgers, err := ledger.Load(dataDir, repository.DefaultDBFile)
+	if err != nil {
+		err = fmt.Errorf("加载账本失败: %w", err)
+		a.setStartupErr(err)
+		runtime.LogError(ctx, err.Error())
+		return
+	}
+	a.ledgers = ledgers
+
+	// 初始化数据库，加密的账本等待解锁
+	a.ledgerMu.Lock()
+	err = a.openLedger(ledgers.Current(), "")
+	a.ledgerMu.Unlock()
+	if err != nil {
+		err = fmt.Errorf("数据库初始化失败: %w", err)
+		a.setStartupErr(err)
+		runtime.LogError(ctx, err.Error())
+		return
+	}
+
+	// 补记周期记账、清理过期的回收站内容、备份账本，并在应用运行期间定时检查
+	schedulerCtx, cancel := context.WithCancel(ctx)
+	a.stopScheduler = cancel
+	go a.runScheduler(schedulerCtx)
+
+	// 设置了 PIN 时以锁定状态启动
+	a.touch()
+	a.ledgerMu.Lock()
+	a.lockIfEnabled()
+	a.ledgerMu.Unlock()
+	go a.runLockWatcher(schedulerCtx)
+}
+
+// openLedger 打开账本数据库并将所有服务切换到新的仓库，失败时保持原账本不变。
+// 加密的账本未提供密码时关闭原账本并进入锁定状态，等待 UnlockLedger。调用方需持有 ledgerMu
+func (a *App) openLedger(l ledger.Ledger, passphrase string) error {
+	repo, err := repository.OpenRepository(l.Path, passphrase)
+	if err == apperrors.ErrLedgerLocked {
+		a.stateMu.Lock()
+		defer a.stateMu.Unlock()
+		if a.repo != nil {
+			a.repo.Close()
+			a.repo = nil
+		}
+		a.ledgerLocked = true
+		a.passphrase = ""
+		a.startupErr = nil
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	history, err := service.NewHistoryService(repo)
+	if err != nil {
+		repo.Close()
+		return err
+	}
+
+	// 等进行中的调用结束后再替换服务，之后关闭原仓库
+	a.stateMu.Lock()
+	defer a.stateMu.Unlock()
+	old := a.repo
+	a.repo = repo
+
+	// 初始化服务
+	a.categoryService = service.NewCategoryService(repo)
+	a.settingsService = service.NewSettingsService(repo)
+	a.rateService = service.NewRateService(repo, a.settingsService)
+	a.recordService = service.NewRecordService(repo, a.settingsService, a.rateService)
+	a.tagService = service.NewTagService(repo, a.recordService)
+	a.budgetService = service.NewBudgetService(repo, a.settingsService, a.recordService)
+	a.recurringService = service.NewRecurringService(repo, a.recordService)
+	a.trashService = service.NewTrashService(repo, a.settingsService)
+	a.duplicateService = service.NewDuplicateService(repo)
+	a.profileService = service.NewCSVProfileService(repo)
+	a.historyService = history
+	a.backupService = service.NewBackupService(repo, backup.NewStore(a.ledgers.BackupDir(l.ID)), a.settingsService)
+	a.lockService = service.NewLockService(repo)
+	a.accountService = service.NewAccountService(repo)
+	a.exportService = service.NewExportService(repo, a.settingsService)
+	a.startupErr = nil
+	a.ledgerLocked = false
+	a.passphrase = ""
+	if repo.Encrypted() {
+		a.passphrase = passphrase
+	}
+
+	if old != nil {
+		old.Close()
+	}
+	return nil
+}
+
+// shutdown is called when the app closes
+func (a *App) shutdown(ctx context.Context) {
+	if a.stopScheduler != nil {
+		a.stopScheduler()
+	}
+
+	a.ledgerMu.Lock()
+	defer a.ledgerMu.Unlock()
+	a.closeLedger()
+}
+
+// closeLedger 等进行中的调用结束后关闭当前账本，替换账本文件前调用；调用方需持有 ledgerMu
+func (a *App) closeLedger() {
+	a.stateMu.Lock()
+	defer a.stateMu.Unlock()
+	if a.repo != nil {
+		a.repo.Close()
+		a.repo = nil
+	}
+}
+
+// setStartupErr 记录账本无法打开的原因，之后所有绑定方法返回该错误
+func (a *App) setStartupErr(err error) {
+	a.stateMu.Lock()
+	defer a.stateMu.Unlock()
+	a.startupErr = err
+}
+
+// ready 检查应用是否已完成初始化且未锁定，返回数据的绑定方法都先调用它。
+// 检查通过时持有 stateMu 的读锁，保证调用期间账本不被切换或关闭，调用方需 defer a.release()
+func (a *App) ready() error {
+	if a.appLocked.Load() {
+		return apperrors.ErrAppLocked
+	}
+	a.stateMu.RLock()
+	if err := a.ledgerOpen(); err != nil {
+		a.stateMu.RUnlock()
+		return err
+	}
+	return nil
+}
+
+// release 释放 ready 获取的读锁
+func (a *App) release() {
+	a.stateMu.RUnlock()
+}
+
+// readyLocked 同 ready，但不加读锁，供持有 ledgerMu 的绑定方法使用
+func (a *App) readyLocked() error {
+	if a.appLocked.Load() {
+		return apperrors.ErrAppLocked
+	}
+	return a.ledgerOpen()
+}
+
+// ledgerOpen 检查当前账本是否已打开，不检查应用锁，供后台任务使用；调用方需持有 ledgerMu 或 stateMu
+func (a *App) ledgerOpen() error {
+	if a.startupErr != nil {
+		return a.startupErr
+	}
+	if a.ledgerLocked {
+		return apperrors.ErrLedgerLocked
+	}
+	if a.repo == nil {
+		return fmt.Errorf("应用尚未完成初始化")
+	}
+	return nil
+}
+
+// reopenLedger 替换账本文件后重新打开账本，失败时所有绑定方法返回该错误，直到切换到其他账本。
+// 调用方需持有 ledgerMu
+func (a *App) reopenLedger(l ledger.Ledger, passphrase string) error {
+	if err := a.openLedger(l, passphrase); err != nil {
+		err = fmt.Errorf("重新打开账本失败: %w", err)
+		a.setStartupErr(err)
+		return err
+	}
+	return nil
+}
+
+// GetStartupError 返回启动阶段的错误信息，供前端展示
+func (a *App) GetStartupError() string {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+	if a.startupErr == nil {
+		return ""
+	}
+	return a.startupErr.Error()
+}
+
+// ============ 账本管理 ============
+
+// ledgersReady 账本管理不依赖当前账本是否打开成功，便于从损坏的账本切换出去
+func (a *App) ledgersReady() error {
+	if a.appLocked.Load() {
+		return apperrors.ErrAppLocked
+	}
+	if a.ledgers == nil {
+		a.stateMu.RLock()
+		defer a.stateMu.RUnlock()
+		if a.startupErr != nil {
+			return a.startupErr
+		}
+		return fmt.Errorf("应用尚未完成初始化")
+	}
+	return nil
+}
+
+func (a *App) GetLedgers() ([]ledger.Ledger, error) {
+	if err := a.ledgersReady(); err != nil {
+		return nil, err
+	}
+	return a.ledgers.List(), nil
+}
+
+func (a *App) GetCurrentLedger() (*ledger.Ledger, error) {
+	if err := a.ledgersReady(); err != nil {
+		return nil, err
+	}
+	current := a.ledgers.Current()
+	return &current, nil
+}
+
+func (a *App) CreateLedger(name string) (*ledger.Ledger, error) {
+	if err := a.ledgersReady(); err != nil {
+		return nil, err
+	}
+	l, err := a.ledgers.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (a *App) RenameLedger(id int64, name string) error {
+	if err := a.ledgersReady(); err != nil {
+		return err
+	}
+	return a.ledgers.Rename(id, name)
+}
+
+// DeleteLedger 删除账本的数据库文件、迁移前的备份与未完成恢复留下的暂存文件，当前账本需先切换走才能删除；
+// 备份目录中的备份保留
+func (a *App) DeleteLedger(id int64) error {
+	if err := a.ledgersReady(); err != nil {
+		return err
+	}
+
+	a.ledgerMu.Lock()
+	defer a.ledgerMu.Unlock()
+
+	removed, err := a.ledgers.Remove(id)
+	if err != nil {
+		return err
+	}
+	files, err := repository.MigrationBackups(removed.Path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	files = append(files, removed.Path, removed.Path+".restore")
+	for _, f := range files {
+		if err := os.Remove(f); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// SwitchLedger 切换到指定账本，无需重启应用；加密的账本切换后需先解锁
+func (a *App) SwitchLedger(id int64) error {
+	if err := a.ledgersReady(); err != nil {
+		return err
+	}
+
+	a.ledgerMu.Lock()
+	defer a.ledgerMu.Unlock()
+
+	l, err := a.ledgers.Get(id)
+	if err != nil {
+		return err
+	}
+	prev, passphrase := a.ledgers.Current(), a.passphrase
+	if err := a.openLedger(l, ""); err != nil {
+		return err
+	}
+	if err := a.ledgers.SetCurrent(id); err != nil {
+		// 登记表仍指向原账本，重新打开原账本保持一致
+		if reopenErr := a.reopenLedger(prev, passphrase); reopenErr != nil {
+			return reopenErr
+		}
+		return err
+	}
+
+	a.postDueRecurring()
+	a.purgeExpiredTrash()
+	a.autoBackup(false)
+	// 切换到设置了 PIN 的账本时需要输入该账本的 PIN
+	a.lockIfEnabled()
+	a.emit("ledger:switched", l)
+	return nil
+}
+
+// ============ 应用锁 ============
+
+// maxUnlockAttempts 连续输错 PIN 的次数上限，达到后暂停解锁 unlockCooldown
+const (
+	maxUnlockAttempts = 5
+	unlockCooldown    = 30 * time.Second
+)
+
+// lockCheckInterval 检查空闲时间与窗口最小化状态的间隔
+const lockCheckInterval = 5 * time.Second
+
+// IsAppLocked 应用是否已锁定
+func (a *App) IsAppLocked() bool {
+	return a.appLocked.Load()
+}
+
+// LockApp 立即锁定应用，需要已设置 PIN
+func (a *App) LockApp() error {
+	a.ledgerMu.Lock()
+	defer a.ledgerMu.Unlock()
+	if err := a.readyLocked(); err != nil {
+		return err
+	}
+
+	settings, err := a.lockService.Settings()
+	if err != nil {
+		return err
+	}
+	if !settings.Enabled {
+		return apperrors.ErrNoPIN
+	}
+	a.lock()
+	return nil
+}
+
+// UnlockApp 用 PIN 解锁应用，连续输错 maxUnlockAttempts 次后需等待一段时间
+func (a *App) UnlockApp(pin string) error {
+	a.ledgerMu.Lock()
+	defer a.ledgerMu.Unlock()
+	if err := a.ledgerOpen(); err != nil {
+		return err
+	}
+
+	if !a.appLocked.Load() {
+		return nil
+	}
+	if time.Now().Before(a.unlockRetryAt) {
+		return apperrors.ErrTooManyAttempts
+	}
+
+	ok, err := a.lockService.Verify(pin)
+	if err == apperrors.ErrNoPIN {
+		// PIN 已被清除（如恢复了未设置 PIN 的备份），无需校验
+		ok, err = true, nil
+	}
+	if err != nil {
+		return err
+	}
+	if !ok {
+		a.failedUnlocks++
+		if a.failedUnlocks >= maxUnlockAttempts {
+			a.failedUnlocks = 0
+			a.unlockRetryAt = time.Now().Add(unlockCooldown)
+		}
+		return apperrors.ErrWrongPIN
+	}
+
+	a.failedUnlocks = 0
+	a.touch()
+	a.appLocked.Store(false)
+	a.emit("app:unlocked")
+	return nil
+}
+
+// GetLockSettings 获取应用锁设置
+func (a *App) GetLockSettings() (model.LockSettings, error) {
+	if err := a.ready(); err != nil {
+		return model.LockSettings{}, err
+	}
+	defer a.release()
+	return a.lockService.Settings()
+}
+
+// SetLockPIN 设置或修改 PIN，已设置 PIN 时需提供当前 PIN
+func (a *App) SetLockPIN(currentPIN, newPIN string) error {
+	if err := a.ready(); err != nil {
+		return err
+	}
+	defer a.release()
+	return a.lockService.SetPIN(currentPIN, newPIN)
+}
+
+// RemoveLockPIN 清除 PIN，关闭应用锁
+func (a *App) RemoveLockPIN(currentPIN string) error {
+	if err := a.ready(); err != nil {
+		return err
+	}
+	defer a.release()
+	return a.lockService.RemovePIN(currentPIN)
+}
+
+// SetLockOptions 设置空闲自动锁定的分钟数（0 表示不自动锁定）与最小化时是否锁定
+func (a *App) SetLockOptions(idleMinutes int, lockOnMinimize bool) error {
+	if err := a.ready(); err != nil {
+		return err
+	}
+	defer a.release()
+	return a.lockService.SetOptions(idleMinutes, lockOnMinimize)
+}
+
+// ReportActivity 前端在用户操作时调用，重置空闲计时；锁定期间的操作不计入
+func (a *App) ReportActivity() {
+	if !a.appLocked.Load() {
+		a.touch()
+	}
+}
+
+// touch 记录一次用户操作
+func (a *App) touch() {
+	a.lastActivity.Store(time.Now().UnixNano())
+}
+
+// lock 锁定应用并通知前端
+func (a *App) lock() {
+	a.appLocked.Store(true)
+	a.emit("app:locked")
+}
+
+// lockIfEnabled 当前账本设置了 PIN 时锁定应用，调用方需持有 ledgerMu
+func (a *App) lockIfEnabled() {
+	if a.ledgerOpen() != nil {
+		return
+	}
+	settings, err := a.lockService.Settings()
+	if err != nil {
+		a.logError(fmt.Sprintf("读取应用锁设置失败: %v", err))
+		return
+	}
+	if settings.Enabled {
+		a.lock()
+	}
+}
+
+// runLockWatcher 定时检查空闲时间与窗口状态，直到应用退出
+func (a *App) runLockWatcher(ctx context.Context) {
+	ticker := time.NewTicker(lockCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		a.ledgerMu.Lock()
+		a.checkAutoLock(time.Now())
+		a.ledgerMu.Unlock()
+	}
+}
+
+// checkAutoLock 空闲超过设定时间或窗口最小化时锁定应用，调用方需持有 ledgerMu
+func (a *App) checkAutoLock(now time.Time) {
+	if a.appLocked.Load() || a.ledgerOpen() != nil {
+		return
+	}
+	settings, err := a.lockService.Settings()
+	if err != nil || !settings.Enabled {
+		return
+	}
+
+	idle := now.Sub(time.Unix(0, a.lastActivity.Load()))
+	if settings.IdleMinutes > 0 && idle >= time.Duration(settings.IdleMinutes)*time.Minute ||
+		settings.LockOnMinimize && runtime.WindowIsMinimised(a.ctx) {
+		a.lock()
+	}
+}
+
+// ============ 账本加密 ============
+
+// IsLedgerLocked 当前账本是否已加密且尚未解锁
+func (a *App) IsLedgerLocked() bool {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+	return a.ledgerLocked
+}
+
+// IsLedgerEncrypted 当前账本是否已加密
+func (a *App) IsLedgerEncrypted() (bool, error) {
+	if err := a.ready(); err != nil {
+		return false, err
+	}
+	defer a.release()
+	return a.repo.Encrypted(), nil
+}
+
+// UnlockLedger 用密码解锁当前账本，密码错误时返回 ErrWrongPassphrase
+func (a *App) UnlockLedger(passphrase string) error {
+	if err := a.ledgersReady(); err != nil {
+		return err
+	}
+
+	a.ledgerMu.Lock()
+	defer a.ledgerMu.Unlock()
+
+	if !a.ledgerLocked {
+		return nil
+	}
+	l := a.ledgers.Current()
+	if err := a.openLedger(l, passphrase); err != nil {
+		return err
+	}
+
+	// 锁定期间跳过的定时任务在解锁后补做
+	a.postDueRecurring()
+	a.purgeExpiredTrash()
+	a.autoBackup(true)
+	// 账本设置了 PIN 时解锁账本后仍需输入 PIN
+	a.lockIfEnabled()
+	a.emit("ledger:unlocked", l)
+	return nil
+}
+
+// EnableEncryption 用密码加密当前账本及其全部备份，之后每次打开账本都需要输入密码
+func (a *App) EnableEncryption(passphrase string) error {
+	a.ledgerMu.Lock()
+	defer a.ledgerMu.Unlock()
+	if err := a.readyLocked(); err != nil {
+		return err
+	}
+
+	if a.repo.Encrypted() {
+		return apperrors.ErrAlreadyEncrypted
+	}
+	return a.rewriteLedger("", passphrase)
+}
+
+// ChangePassphrase 修改当前账本的密码，账本及其全部备份改用新密码重新加密
+func (a *App) ChangePassphrase(oldPassphrase, newPassphrase string) error {
+	a.ledgerMu.Lock()
+	defer a.ledgerMu.Unlock()
+	if err := a.readyLocked(); err != nil {
+		return err
+	}
+
+	if err := a.checkPassphrase(oldPassphrase); err != nil {
+		return err
+	}
+	if err := vault.ValidatePassphrase(newPassphrase); err != nil {
+		return err
+	}
+	return a.rewriteLedger(oldPassphrase, newPassphrase)
+}
+
+// DisableEncryption 解密当前账本及其全部备份，需要输入当前密码
+func (a *App) DisableEncryption(passphrase string) error {
+	a.ledgerMu.Lock()
+	defer a.ledgerMu.Unlock()
+	if err := a.readyLocked(); err != nil {
+		return err
+	}
+
+	if err := a.checkPassphrase(passphrase); err != nil {
+		return err
+	}
+	return a.rewriteLedger(passphrase, "")
+}
+
+// checkPassphrase 校验当前加密账本的密码，调用方需持有 ledgerMu
+func (a *App) checkPassphrase(passphrase string) error {
+	if !a.repo.Encrypted() {
+		return apperrors.ErrNotEncrypted
+	}
+	if subtle.ConstantTimeCompare([]byte(passphrase), []byte(a.passphrase)) != 1 {
+		return apperrors.ErrWrongPassphrase
+	}
+	return nil
+}
+
+// rewriteLedger 关闭当前账本，将账本文件及其全部备份改写为用 newPassphrase 加密（为空时解密），
+// 再重新打开。每个文件都是整体替换，账本文件改写失败时保持原状；
+// 备份改写失败时账本已使用新密码，未改写的备份仍可用原密码恢复。调用方需持有 ledgerMu
+func (a *App) rewriteLedger(oldPassphrase, newPassphrase string) error {
+	var key *vault.Key
+	if newPassphrase != "" {
+		var err error
+		if key, err = vault.NewKey(newPassphrase); err != nil {
+			return err
+		}
+	}
+
+	l := a.ledgers.Current()
+	backups := a.backupService
+	a.closeLedger()
+	if err := vault.Rewrite(l.Path, oldPassphrase, key); err != nil {
+		if reopenErr := a.reopenLedger(l, oldPassphrase); reopenErr != nil {
+			return reopenErr
+		}
+		return err
+	}
+	if err := a.reopenLedger(l, newPassphrase); err != nil {
+		return err
+	}
+
+	if err := backups.Rewrite(oldPassphrase, key); err != nil {
+		return fmt.Errorf("账本已更新，但部分备份改写失败: %w", err)
+	}
+	return nil
+}
+
+// ============ 分类管理 ============
+
+// GetCategories 获取分类列表，includeArchived 为 false 时不含已归档的分类（用于记账时选择）
+func (a *App) GetCategories(recordType string, includeArchived bool) ([]model.Category, error) {
+	if err := a.ready(); err != nil {
+		return nil, err
+	}
+	defer a.release()
+	return a.categoryService.List(recordType, includeArchived)
+}
+
+// GetCategoryTree 获取分类树
+func (a *App) GetCategoryTree(recordType string, includeArchived bool) ([]model.Category, error) {
+	if err := a.ready(); err != nil {
+		return nil, err
+	}
+	defer a.release()
+	return a.categoryService.Tree(recordType, includeArchived)
+}
+
+// CreateCategory 创建分类，parentID 为 0 时创建一级分类
+func (a *App) CreateCategory(name, icon, recordType string, parentID int64) error {
+	if err := a.ready(); err != nil {
+		return err
+	}
+	defer a.release()
+	return a.categoryService.Create(name, icon, recordType, parentID)
+}
+
+func (a *App) UpdateCategory(id int64, name, icon string) error {
+	if err := a.ready(); err != nil {
+		return err
+	}
+	defer a.release()
+	return a.categoryService.Update(id, name, icon)
+}
+
+func (a *App) DeleteCategory(id int64) error {
+	if err := a.ready(); err != nil {
+		return err
+	}
+	defer a.release()
+	return a.categoryService.Delete(id)
+}
+
+// ArchiveCategory 归档分类
+func (a *App) ArchiveCategory(id int64) error {
+	if err := a.ready(); err != nil {
+		return err
+	}
+	defer a.release()
+	return a.categoryService.SetArchived(id, true)
+}
+
+// UnarchiveCategory 取消归档分类
+func (a *App) UnarchiveCategory(id int64) error {
+	if err := a.ready(); err != nil {
+		return err
+	}
+	defer a.release()
+	return a.categoryService.SetArchived(id, false)
+}
+
+// MergeCategories 将 sourceIDs 合并到 targetID，返回转移的记录数
+func (a *App) MergeCategories(sourceIDs []int64, targetID int64) (int, error) {
+	if err := a.ready(); err != nil {
+		return 0, err
+	}
+	defer a.release()
+	return a.categoryService.Merge(sourceIDs, targetID)
+}
+
+// DeleteCategoryAndReassign 删除分类并将其记录转到 targetID，返回转移的记录数
+func (a *App) DeleteCategoryAndReassign(id, targetID int64) (int, error) {
+	if err := a.ready(); err != nil {
+		return 0, err
+	}
+	defer a.release()
+	return a.categoryService.DeleteAndReassign(id, targetID)
+}
+
+// MoveCategory 调整分类的上级分类，parentID 为 0 时提升为一级分类
+func (a *App) MoveCategory(id, parentID int64) error {
+	if err := a.ready(); err != nil {
+		return err
+	}
+	defer a.release()
+	return a.categoryService.Move(id, parentID)
+}
+
+// ReorderCategories 按 ids 顺序重排同一上级分类下的分类
+func (a *App) ReorderCategories(parentID int64, ids []int64) error {
+	if err := a.ready(); err != nil {
+		return err
+	}
+	defer a.release()
+	return a.categoryService.Reorder(parentID, ids)
+}
+
+// ============ 记录管理 ============
+
+func (a *App) CreateRecord(amount model.Money, recordType string, categoryID, accountID int64, note, date string, tagIDs []int64) error {
+	if err := a.ready(); err != nil {
+		return err
+	}
+	defer a.release()
+
+	// 预算统计失败（如缺少汇率）不影响记账，只是不发出提醒
+	var before *model.BudgetReport
+	if recordType == model.TypeExpense {
+		before, _ = a.budgetService.StatusOn(date)
+	}
+
+	if err := a.recordService.Create(amount, recordType, categoryID, accountID, note, date, tagIDs); err != nil {
+		return err
+	}
+
+	if before != nil {
+		after, _ := a.budgetService.StatusOn(date)
+		a.emitBudgetAlerts(service.Alerts(before, after))
+	}
+	return nil
+}
+
+func (a *App) UpdateRecord(id int64, amount model.Money, categoryID, accountID int64, note, date string, tagIDs []int64) error {
+	if err := a.ready(); err != nil {
+		return err
+	}
+	defer a.release()
+	return a.recordService.Update(id, amount, categoryID, accountID, note, date, tagIDs)
+}
+
+func (a *App) CreateTransfer(amount model.Money, fromAccountID, toAccountID int64, note, date string) error {
+	if err := a.ready(); err != nil {
+		return err
+	}
+	defer a.release()
+	return a.recordService.CreateTransfer(amount, fromAccountID, toAccountID, note, date)
+}
+
+func (a *App) UpdateTransfer(id int64, amount model.Money, fromAccountID, toAccountID int64, note, date string) error {
+	if err := a.ready(); err != nil {
+		return err
+	}
+	defer a.release()
+	return a.recordService.UpdateTransfer(id, amount, fromAccountID, toAccountID, note, date)
+}
+
+func (a *App) DeleteRecord(id int64) error {
+	if err := a.ready(); err != nil {
+		return err
+	}
+	defer a.release()
+	return a.recordService.Delete(id)
+}
+
+func (a *App) GetRecordsByMonth(year, month int) ([]model.Record, error) {
+	if err := a.ready(); err != nil {
+		return nil, err
+	}
+	defer a.release()
+	return a.recordService.ListByMonth(year, month)
+}
+
+func (a *App) GetRecentRecords(limit int) ([]model.Record, error) {
+	if err := a.ready(); err != nil {
+		return nil, err
+	}
+	defer a.release()
+	return a.recordService.GetRecentRecords(limit)
+}
+
+// SearchRecords 按备注全文搜索记录，结果按相关度排序并附带高亮片段
+func (a *App) SearchRecords(query string, limit int) ([]model.SearchResult, error) {
+	if err := a.ready(); err != nil {
+		return nil, err
+	}
+	defer a.release()
+	return a.recordService.Search(query, limit)
+}
+
+// QueryRecords 按筛选条件、排序和游标分页查询记录
+func (a *App) QueryRecords(q model.RecordQuery) (*model.RecordPage, error) {
+	if err := a.ready(); err != nil {
+		return nil, err
+	}
+	defer a.release()
+	return a.recordService.Query(q)
+}
+
+// ============ 重复记录 ============
+
+// FindDuplicates 扫描账本，返回疑似重复的记录组
+func (a *App) FindDuplicates() ([]model.DuplicateGroup, error) {
+	if err := a.ready(); err != nil {
+		return nil, err
+	}
+	defer a.release()
+	return a.duplicateService.Find()
+}
+
+// FindSimilarRecords 返回与待录入记录指纹相同的已有记录，录入前用于提醒可能重复
+func (a *App) FindSimilarRecords(amount model.Money, recordType string, categoryID, accountID int64, note, date string) ([]model.Record, error) {
+	if err := a.ready(); err != nil {
+		return nil, err
+	}
+	defer a.release()
+	return a.duplicateService.Similar(model.Record{
+		Amount:     amount,
+		Type:       recordType,
+		CategoryID: categoryID,
+		AccountID:  accountID,
+		Note:       note,
+		Date:       date,
+	})
+}
+
+// ResolveDuplicates 将选中的重复记录一并移入回收站，可作为一次操作撤销
+func (a *App) ResolveDuplicates(ids []int64) (int, error) {
+	if err := a.ready(); err != nil {
+		return 0, err
+	}
+	defer a.release()
+	return a.duplicateService.Resolve(ids)
+}
+
+// ============ 标签 ============
+
+func (a *App) GetTags() ([]model.Tag, error) {
+	if err := a.ready(); err != nil {
+		return nil, err
+	}
+	defer a.release()
+	return a.tagService.List()
+}
+
+func (a *App) CreateTag(name, color string) (*model.Tag, error) {
+	if err := a.ready(); err != nil {
+		return nil, err
+	}
+	defer a.release()
+	return a.tagService.Create(name, color)
+}
+
+func (a *App) UpdateTag(id int64, name, color string) error {
+	if err := a.ready(); err != nil {
+		return err
+	}
+	defer a.release()
+	return a.tagService.Update(id, name, color)
+}
+
+func (a *App) DeleteTag(id int64) error {
+	if err := a.ready(); err != nil {
+		return err
+	}
+	defer a.release()
+	return a.tagService.Delete(id)
+}
+
+func (a *App) SetRecordTags(recordID int64, tagIDs []int64) error {
+	if err := a.ready(); err != nil {
+		return err
+	}
+	defer a.release()
+	return a.tagService.SetRecordTags(recordID, tagIDs)
+}
+
+// GetRecordsByTags 获取记账周期内带有指定标签的记录，matchAll 为 true 时要求包含全部标签
+func (a *App) GetRecordsByTags(year, month int, tagIDs []int64, matchAll bool) ([]model.Record, error) {
+	if err := a.ready(); err != nil {
+		return nil, err
+	}
+	defer a.release()
+	return a.tagService.ListByTags(year, month, tagIDs, matchAll)
+}
+
+func (a *App) GetTagStats(year, month int) (*model.TagStatsResponse, error) {
+	if err := a.ready(); err != nil {
+		return nil, err
+	}
+	defer a.release()
+	return a.tagService.GetTagStats(year, month)
 }
 
-// NewApp creates a new App application struct
-func NewApp() *App {
-	return &App{}
+// ============ 预算 ============
+
+// GetBudgets 获取记账周期生效的预算（含沿用之前月份的循环预算）
+func (a *App) GetBudgets(year, month int) ([]model.Budget, error) {
+	if err := a.ready(); err != nil {
+		return nil, err
+	}
+	defer a.release()
+	return a.budgetService.Effective(year, month)
 }
 
-// startup is called when the app starts
-func (a *App) startup(ctx context.Context) {
-	a.ctx = ctx
+// SetBudget 设置预算，categoryID 为 0 表示总预算，recurring 为 true 时之后的月份沿用
+func (a *App) SetBudget(year, month int, categoryID int64, amount model.Money, recurring bool) error {
+	if err := a.ready(); err != nil {
+		return err
+	}
+	defer a.release()
+	return a.budgetService.Set(year, month, categoryID, amount, recurring)
+}
+
+// DeleteBudget 删除在该记账周期设置的预算，沿用之前月份的循环预算需在设置它的月份删除
+func (a *App) DeleteBudget(year, month int, id int64) error {
+	if err := a.ready(); err != nil {
+		return err
+	}
+	defer a.release()
+	return a.budgetService.Delete(year, month, id)
+}
+
+func (a *App) GetBudgetStatus(year, month int) (*model.BudgetReport, error) {
+	if err := a.ready(); err != nil {
+		return nil, err
+	}
+	defer a.release()
+	return a.budgetService.Status(year, month)
+}
+
+// emitBudgetAlerts 通知前端预算使用率越过 80% 或 100%
+func (a *App) emitBudgetAlerts(alerts []model.BudgetAlert) {
+	for _, alert := range alerts {
+		event := "budget:warning"
+		if alert.Threshold >= service.BudgetExceededThreshold {
+			event = "budget:exceeded"
+		}
+		a.emit(event, alert)
+	}
+}
+
+// ============ 回收站 ============
+
+// GetTrash 获取回收站中的记录和分类
+func (a *App) GetTrash() (*model.Trash, error) {
+	if err := a.ready(); err != nil {
+		return nil, err
+	}
+	defer a.release()
+	return a.trashService.List()
+}
+
+// RestoreRecord 从回收站恢复记录，其分类在回收站中时一并恢复
+func (a *App) RestoreRecord(id int64) error {
+	if err := a.ready(); err != nil {
+		return err
+	}
+	defer a.release()
+	return a.trashService.RestoreRecord(id)
+}
+
+// RestoreCategory 从回收站恢复分类，其上级分类在回收站中时一并恢复
+func (a *App) RestoreCategory(id int64) error {
+	if err := a.ready(); err != nil {
+		return err
+	}
+	defer a.release()
+	return a.trashService.RestoreCategory(id)
+}
+
+// PurgeRecord 彻底删除回收站中的记录
+func (a *App) PurgeRecord(id int64) error {
+	if err := a.ready(); err != nil {
+		return err
+	}
+	defer a.release()
+	return a.trashService.PurgeRecord(id)
+}
+
+// PurgeCategory 彻底删除回收站中的分类
+func (a *App) PurgeCategory(id int64) error {
+	if err := a.ready(); err != nil {
+		return err
+	}
+	defer a.release()
+	return a.trashService.PurgeCategory(id)
+}
+
+// EmptyTrash 清空回收站，返回彻底删除的记录数和分类数
+func (a *App) EmptyTrash() (model.PurgeResult, error) {
+	if err := a.ready(); err != nil {
+		return model.PurgeResult{}, err
+	}
+	defer a.release()
+	return a.trashService.Empty()
+}
+
+// GetTrashRetentionDays 获取回收站保留天数，0 表示不自动清理
+func (a *App) GetTrashRetentionDays() (int, error) {
+	if err := a.ready(); err != nil {
+		return 0, err
+	}
+	defer a.release()
+	return a.settingsService.TrashRetentionDays()
+}
+
+// SetTrashRetentionDays 设置回收站保留天数，下次定时检查时按新设置清理
+func (a *App) SetTrashRetentionDays(days int) error {
+	if err := a.ready(); err != nil {
+		return err
+	}
+	defer a.release()
+	return a.settingsService.SetTrashRetentionDays(days)
+}
+
+// purgeExpiredTrash 彻底删除当前账本回收站中超过保留天数的内容，调用方需持有 ledgerMu
+func (a *App) purgeExpiredTrash() {
+	if a.ledgerOpen() != nil {
+		return
+	}
+
+	purged, err := a.trashService.PurgeExpired(time.Now())
+	if err != nil {
+		a.logError(fmt.Sprintf("清理回收站失败: %v", err))
+	}
+	if purged.Records > 0 || purged.Categories > 0 {
+		a.emit("trash:purged", purged)
+	}
+}
+
+// ============ 变更历史 ============
+
+// GetRecordHistory 获取记录的全部变更（包括撤销和重做），按先后顺序排列
+func (a *App) GetRecordHistory(id int64) ([]model.RecordChange, error) {
+	if err := a.ready(); err != nil {
+		return nil, err
+	}
+	defer a.release()
+	return a.historyService.RecordHistory(id)
+}
+
+// Undo 撤销本次打开账本以来的最近一次操作，返回被撤销的变更集。
+// 相关数据已被其他操作修改时返回错误，该操作不再能撤销。设置、汇率和 CSV 列映射方案的修改不能撤销
+func (a *App) Undo() (*model.ChangeSet, error) {
+	if err := a.ready(); err != nil {
+		return nil, err
+	}
+	defer a.release()
+	return a.historyService.Undo()
+}
+
+// Redo 重做最近一次撤销的操作，返回被重做的变更集；撤销之后有新的操作时无法重做
+func (a *App) Redo() (*model.ChangeSet, error) {
+	if err := a.ready(); err != nil {
+		return nil, err
+	}
+	defer a.release()
+	return a.historyService.Redo()
+}
+
+// ============ 备份 ============
 
-	// 初始化数据库
-	repo, err := repository.NewSQLiteRepository()
+// ListBackups 获取当前账本的全部备份，最新的在前
+func (a *App) ListBackups() ([]model.Backup, error) {
+	if err := a.ready(); err != nil {
+		return nil, err
+	}
+	defer a.release()
+	return a.backupService.List()
+}
+
+// CreateBackup 立即备份当前账本，之后按保留策略删除多余的备份
+func (a *App) CreateBackup() (*model.Backup, error) {
+	a.ledgerMu.Lock()
+	defer a.ledgerMu.Unlock()
+	if err := a.readyLocked(); err != nil {
+		return nil, err
+	}
+	return a.backupService.Create(time.Now())
+}
+
+// RestoreBackup 用备份替换当前账本的数据。备份校验通过后先备份当前数据再替换，
+// 恢复之前的数据仍可从备份列表中找回
+func (a *App) RestoreBackup(name string) error {
+	a.ledgerMu.Lock()
+	defer a.ledgerMu.Unlock()
+	if err := a.readyLocked(); err != nil {
+		return err
+	}
+
+	// 先复制再备份当前数据：新备份触发的轮换可能删除要恢复的备份
+	l := a.ledgers.Current()
+	staged := l.Path + ".restore"
+	defer os.Remove(staged)
+	if err := a.backupService.CopyTo(name, staged); err != nil {
+		return err
+	}
+	// 恢复不改变账本的加密状态，避免用未加密的备份悄悄关闭加密
+	encrypted, err := vault.IsEncrypted(staged)
 	if err != nil {
-		runtime.LogFatal(ctx, "数据库初始化失败: "+err.Error())
+		return err
+	}
+	if encrypted != a.repo.Encrypted() {
+		return apperrors.ErrEncryptionMismatch
+	}
+	if err := repository.CheckDatabase(staged, a.passphrase); err != nil {
+		return fmt.Errorf("备份文件无法使用: %w", err)
+	}
+	if _, err := a.backupService.Create(time.Now()); err != nil {
+		return fmt.Errorf("备份当前数据失败: %w", err)
+	}
+
+	// 替换文件前必须关闭数据库，之后无论成败都重新打开账本
+	passphrase := a.passphrase
+	a.closeLedger()
+	replaceErr := os.Rename(staged, l.Path)
+	if err := a.reopenLedger(l, passphrase); err != nil {
+		return err
+	}
+	if replaceErr != nil {
+		return replaceErr
+	}
+
+	a.emit("backup:restored", l)
+	return nil
+}
+
+// GetBackupPolicy 获取自动备份的间隔与保留份数
+func (a *App) GetBackupPolicy() (model.BackupPolicy, error) {
+	if err := a.ready(); err != nil {
+		return model.BackupPolicy{}, err
+	}
+	defer a.release()
+	return a.settingsService.BackupPolicy()
+}
+
+// SetBackupPolicy 设置自动备份的间隔与保留份数，下次备份时按新的保留份数轮换
+func (a *App) SetBackupPolicy(policy model.BackupPolicy) error {
+	if err := a.ready(); err != nil {
+		return err
+	}
+	defer a.release()
+	return a.settingsService.SetBackupPolicy(policy)
+}
+
+// autoBackup 按设定的间隔备份当前账本，startup 为 true 时总是备份；调用方需持有 ledgerMu
+func (a *App) autoBackup(startup bool) {
+	if a.ledgerOpen() != nil {
 		return
 	}
-	a.repo = repo
+	if _, err := a.backupService.AutoBackup(time.Now(), startup); err != nil {
+		a.logError(fmt.Sprintf("自动备份失败: %v", err))
+	}
+}
 
-	// 初始化服务
-	a.categoryService = service.NewCategoryService(repo)
-	a.recordService = service.NewRecordService(repo)
-	a.exportService = service.NewExportService(repo)
+// ============ 周期记账 ============
+
+// schedulerInterval 后台定时任务的检查间隔，跨天后的第一次检查会补记当天的发生
+const schedulerInterval = time.Hour
+
+// runScheduler 启动时立即补记到期的周期记账、清理过期的回收站内容并备份账本，之后定时检查直到应用退出
+func (a *App) runScheduler(ctx context.Context) {
+	ticker := time.NewTicker(schedulerInterval)
+	defer ticker.Stop()
+
+	for startup := true; ; startup = false {
+		a.ledgerMu.Lock()
+		a.postDueRecurring()
+		a.purgeExpiredTrash()
+		a.autoBackup(startup)
+		a.ledgerMu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
 }
 
-// shutdown is called when the app closes
-func (a *App) shutdown(ctx context.Context) {
-	if a.repo != nil {
-		a.repo.Close()
+// postDueRecurring 补记当前账本到期的周期记账，调用方需持有 ledgerMu
+func (a *App) postDueRecurring() {
+	if a.ledgerOpen() != nil {
+		return
+	}
+
+	posted, err := a.recurringService.PostDue(time.Now().Format(service.DateLayout))
+	if err != nil {
+		a.logError(fmt.Sprintf("周期记账入账失败: %v", err))
+	}
+	if posted > 0 {
+		a.emit("recurring:posted", posted)
 	}
 }
 
-// ============ 分类管理 ============
+func (a *App) GetRecurringRules() ([]model.RecurringRule, error) {
+	if err := a.ready(); err != nil {
+		return nil, err
+	}
+	defer a.release()
+	return a.recurringService.List()
+}
+
+// CreateRecurringRule 创建周期规则，开始日期已过去的发生会立即补记
+func (a *App) CreateRecurringRule(rule model.RecurringRule) (*model.RecurringRule, error) {
+	a.ledgerMu.Lock()
+	defer a.ledgerMu.Unlock()
+	if err := a.readyLocked(); err != nil {
+		return nil, err
+	}
 
-func (a *App) GetCategories(recordType string) ([]model.Category, error) {
-	return a.categoryService.List(recordType)
+	created, err := a.recurringService.Create(rule)
+	if err != nil {
+		return nil, err
+	}
+	a.postDueRecurring()
+	return created, nil
 }
 
-func (a *App) CreateCategory(name, icon, recordType string) error {
-	return a.categoryService.Create(name, icon, recordType)
+func (a *App) UpdateRecurringRule(rule model.RecurringRule) error {
+	if err := a.ready(); err != nil {
+		return err
+	}
+	defer a.release()
+	return a.recurringService.Update(rule)
 }
 
-func (a *App) UpdateCategory(id int64, name, icon string) error {
-	return a.categoryService.Update(id, name, icon)
+func (a *App) DeleteRecurringRule(id int64) error {
+	if err := a.ready(); err != nil {
+		return err
+	}
+	defer a.release()
+	return a.recurringService.Delete(id)
 }
 
-func (a *App) DeleteCategory(id int64) error {
-	return a.categoryService.Delete(id)
+// GetUpcomingOccurrences 获取今天起 days 天内尚未入账的周期记账
+func (a *App) GetUpcomingOccurrences(days int) ([]model.UpcomingOccurrence, error) {
+	if err := a.ready(); err != nil {
+		return nil, err
+	}
+	defer a.release()
+	return a.recurringService.Upcoming(time.Now().Format(service.DateLayout), days)
 }
 
-func (a *App) ReorderCategories(ids []int64) error {
-	return a.categoryService.Reorder(ids)
+// SkipOccurrence 跳过周期规则的某一次发生
+func (a *App) SkipOccurrence(ruleID int64, date string) error {
+	if err := a.ready(); err != nil {
+		return err
+	}
+	defer a.release()
+	return a.recurringService.Skip(ruleID, date)
 }
 
-// ============ 记录管理 ============
+// ModifyOccurrence 单独修改周期规则某一次发生的金额和备注
+func (a *App) ModifyOccurrence(ruleID int64, date string, amount model.Money, note string) error {
+	if err := a.ready(); err != nil {
+		return err
+	}
+	defer a.release()
+	return a.recurringService.Modify(ruleID, date, amount, note)
+}
+
+// ============ 账户管理 ============
+
+func (a *App) GetAccounts() ([]model.Account, error) {
+	if err := a.ready(); err != nil {
+		return nil, err
+	}
+	defer a.release()
+	return a.accountService.List()
+}
 
-func (a *App) CreateRecord(amount float64, recordType string, categoryID int64, note, date string) error {
-	return a.recordService.Create(amount, recordType, categoryID, note, date)
+func (a *App) CreateAccount(name, accountType, icon string, openingBalance model.Money) error {
+	if err := a.ready(); err != nil {
+		return err
+	}
+	defer a.release()
+	return a.accountService.Create(name, accountType, icon, openingBalance)
 }
 
-func (a *App) UpdateRecord(id int64, amount float64, categoryID int64, note, date string) error {
-	return a.recordService.Update(id, amount, categoryID, note, date)
+func (a *App) UpdateAccount(id int64, name, accountType, icon string, openingBalance model.Money) error {
+	if err := a.ready(); err != nil {
+		return err
+	}
+	defer a.release()
+	return a.accountService.Update(id, name, accountType, icon, openingBalance)
 }
 
-func (a *App) DeleteRecord(id int64) error {
-	return a.recordService.Delete(id)
+func (a *App) DeleteAccount(id int64) error {
+	if err := a.ready(); err != nil {
+		return err
+	}
+	defer a.release()
+	return a.accountService.Delete(id)
 }
 
-func (a *App) GetRecordsByMonth(year, month int) ([]model.Record, error) {
-	return a.recordService.ListByMonth(year, month)
+// GetAccountBalances 获取所有账户截至 date（含当天）的余额
+func (a *App) GetAccountBalances(date string) ([]model.AccountBalance, error) {
+	if err := a.ready(); err != nil {
+		return nil, err
+	}
+	defer a.release()
+	return a.accountService.Balances(date)
 }
 
-func (a *App) GetRecentRecords(limit int) ([]model.Record, error) {
-	return a.recordService.GetRecentRecords(limit)
+func (a *App) GetAccountBalance(accountID int64, date string) (*model.AccountBalance, error) {
+	if err := a.ready(); err != nil {
+		return nil, err
+	}
+	defer a.release()
+	return a.accountService.Balance(accountID, date)
 }
 
 // ============ 统计分析 ============
 
 func (a *App) GetMonthSummary(year, month int) (*model.MonthSummary, error) {
+	if err := a.ready(); err != nil {
+		return nil, err
+	}
+	defer a.release()
 	return a.recordService.GetMonthSummary(year, month)
 }
 
-func (a *App) GetCategoryStats(year, month int) (*model.CategoryStatsResponse, error) {
-	return a.recordService.GetCategoryStats(year, month)
+// GetCategoryStats 获取分类统计，rollUp 为 true 时子分类金额计入一级分类
+func (a *App) GetCategoryStats(year, month int, rollUp bool) (*model.CategoryStatsResponse, error) {
+	if err := a.ready(); err != nil {
+		return nil, err
+	}
+	defer a.release()
+	return a.recordService.GetCategoryStats(year, month, rollUp)
 }
 
 func (a *App) GetTrendStats(year int) ([]model.MonthTrend, error) {
+	if err := a.ready(); err != nil {
+		return nil, err
+	}
+	defer a.release()
 	return a.recordService.GetTrendStats(year)
 }
 
+// GetCurrentPeriod 获取今天所属的记账周期
+func (a *App) GetCurrentPeriod() (*service.Period, error) {
+	if err := a.ready(); err != nil {
+		return nil, err
+	}
+	defer a.release()
+	period, err := a.recordService.CurrentPeriod()
+	if err != nil {
+		return nil, err
+	}
+	return &period, nil
+}
+
+// ============ 设置 ============
+
+func (a *App) GetMonthStartDay() (int, error) {
+	if err := a.ready(); err != nil {
+		return 0, err
+	}
+	defer a.release()
+	return a.settingsService.MonthStartDay()
+}
+
+func (a *App) SetMonthStartDay(day int) error {
+	if err := a.ready(); err != nil {
+		return err
+	}
+	defer a.release()
+	return a.settingsService.SetMonthStartDay(day)
+}
+
+func (a *App) GetBaseCurrency() (string, error) {
+	if err := a.ready(); err != nil {
+		return "", err
+	}
+	defer a.release()
+	return a.settingsService.BaseCurrency()
+}
+
+// SetBaseCurrency 设置本位币，统计金额统一换算为该币种
+func (a *App) SetBaseCurrency(currency string) error {
+	if err := a.ready(); err != nil {
+		return err
+	}
+	defer a.release()
+	return a.settingsService.SetBaseCurrency(currency)
+}
+
+// ============ 汇率 ============
+
+func (a *App) GetExchangeRates() ([]model.ExchangeRate, error) {
+	if err := a.ready(); err != nil {
+		return nil, err
+	}
+	defer a.release()
+	return a.rateService.List()
+}
+
+// SaveExchangeRate 录入某日汇率：1 单位 from 兑换 rate 单位 to
+func (a *App) SaveExchangeRate(from, to, date, rate string) error {
+	if err := a.ready(); err != nil {
+		return err
+	}
+	defer a.release()
+	return a.rateService.Save(from, to, date, rate)
+}
+
+func (a *App) DeleteExchangeRate(id int64) error {
+	if err := a.ready(); err != nil {
+		return err
+	}
+	defer a.release()
+	return a.rateService.Delete(id)
+}
+
+// ImportExchangeRates 从 CSV（date, from, to, rate）导入汇率
+func (a *App) ImportExchangeRates() (int, error) {
+	if err := a.ready(); err != nil {
+		return 0, err
+	}
+	defer a.release()
+
+	filePath, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "导入汇率",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "CSV 文件", Pattern: "*.csv"},
+		},
+	})
+	if err != nil || filePath == "" {
+		return 0, err
+	}
+
+	return a.rateService.ImportCSV(filePath)
+}
+
 // ============ 导入导出 ============
 
 func (a *App) ExportToCSV() (string, error) {
+	if err := a.ready(); err != nil {
+		return "", err
+	}
+	defer a.release()
+
 	filePath, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
 		Title:           "导出 CSV",
 		DefaultFilename: "dog-view-export.csv",
@@ -129,6 +1556,11 @@ func (a *App) ExportToCSV() (string, error) {
 }
 
 func (a *App) ExportToJSON() (string, error) {
+	if err := a.ready(); err != nil {
+		return "", err
+	}
+	defer a.release()
+
 	filePath, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
 		Title:           "导出 JSON",
 		DefaultFilename: "dog-view-export.json",
@@ -147,7 +1579,13 @@ func (a *App) ExportToJSON() (string, error) {
 	return filePath, nil
 }
 
-func (a *App) ImportFromCSV() (int, error) {
+// PreviewImportCSV 选择 CSV 文件并试运行导入，返回逐行校验结果；取消选择时返回 nil
+func (a *App) PreviewImportCSV() (*model.ImportPreview, error) {
+	if err := a.ready(); err != nil {
+		return nil, err
+	}
+	defer a.release()
+
 	filePath, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
 		Title: "导入 CSV",
 		Filters: []runtime.FileFilter{
@@ -155,13 +1593,117 @@ func (a *App) ImportFromCSV() (int, error) {
 		},
 	})
 	if err != nil || filePath == "" {
-		return 0, err
+		return nil, err
+	}
+
+	return a.exportService.PreviewCSV(filePath)
+}
+
+// PreviewImportBill 选择支付宝或微信支付导出的账单并试运行导入；取消选择时返回 nil
+func (a *App) PreviewImportBill() (*model.ImportPreview, error) {
+	if err := a.ready(); err != nil {
+		return nil, err
+	}
+	defer a.release()
+
+	filePath, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "导入支付宝/微信账单",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "账单文件", Pattern: "*.csv;*.xlsx"},
+		},
+	})
+	if err != nil || filePath == "" {
+		return nil, err
+	}
+
+	return a.exportService.PreviewBill(filePath)
+}
+
+// PreviewImportMappedCSV 选择银行流水等 CSV 文件，按保存的列映射方案试运行导入；取消选择时返回 nil
+func (a *App) PreviewImportMappedCSV(profileID int64) (*model.ImportPreview, error) {
+	if err := a.ready(); err != nil {
+		return nil, err
+	}
+	defer a.release()
+	profile, err := a.profileService.Get(profileID)
+	if err != nil {
+		return nil, err
+	}
+
+	filePath, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "导入 " + profile.Name + " 流水",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "CSV 文件", Pattern: "*.csv;*.txt"},
+		},
+	})
+	if err != nil || filePath == "" {
+		return nil, err
+	}
+
+	return a.exportService.PreviewMappedCSV(filePath, profile.Mapping)
+}
+
+// InspectCSV 选择 CSV 文件并返回前几行，用于配置列映射；encoding 为空时自动识别。取消选择时返回 nil
+func (a *App) InspectCSV(encoding, delimiter string) (*model.CSVSample, error) {
+	if err := a.ready(); err != nil {
+		return nil, err
+	}
+	defer a.release()
+
+	filePath, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "选择 CSV 文件",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "CSV 文件", Pattern: "*.csv;*.txt"},
+		},
+	})
+	if err != nil || filePath == "" {
+		return nil, err
+	}
+
+	return a.exportService.InspectCSV(filePath, encoding, delimiter)
+}
+
+// ListCSVProfiles 获取保存的列映射方案
+func (a *App) ListCSVProfiles() ([]model.CSVProfile, error) {
+	if err := a.ready(); err != nil {
+		return nil, err
+	}
+	defer a.release()
+	return a.profileService.List()
+}
+
+// CreateCSVProfile 保存一家银行的列映射方案
+func (a *App) CreateCSVProfile(name string, mapping model.CSVMapping) (*model.CSVProfile, error) {
+	if err := a.ready(); err != nil {
+		return nil, err
+	}
+	defer a.release()
+	return a.profileService.Create(name, mapping)
+}
+
+func (a *App) UpdateCSVProfile(id int64, name string, mapping model.CSVMapping) error {
+	if err := a.ready(); err != nil {
+		return err
 	}
+	defer a.release()
+	return a.profileService.Update(id, name, mapping)
+}
 
-	return a.exportService.ImportFromCSV(filePath)
+func (a *App) DeleteCSVProfile(id int64) error {
+	if err := a.ready(); err != nil {
+		return err
+	}
+	defer a.release()
+	return a.profileService.Delete(id)
 }
 
-func (a *App) ImportFromJSON() (int, error) {
+// PreviewImportJSON 选择 JSON 文件并试运行导入，返回逐行校验结果；取消选择时返回 nil
+func (a *App) PreviewImportJSON() (*model.ImportPreview, error) {
+	if err := a.ready(); err != nil {
+		return nil, err
+	}
+	defer a.release()
+
 	filePath, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
 		Title: "导入 JSON",
 		Filters: []runtime.FileFilter{
@@ -169,8 +1711,35 @@ func (a *App) ImportFromJSON() (int, error) {
 		},
 	})
 	if err != nil || filePath == "" {
-		return 0, err
+		return nil, err
+	}
+
+	return a.exportService.PreviewJSON(filePath)
+}
+
+// CommitImport 按预览确认后的计划在一个事务中导入，提交前重新校验
+func (a *App) CommitImport(plan model.ImportPreview) (*model.ImportResult, error) {
+	if err := a.ready(); err != nil {
+		return nil, err
+	}
+	defer a.release()
+	return a.exportService.CommitImport(&plan)
+}
+
+// ListImportBatches 返回全部导入批次，最新的在前
+func (a *App) ListImportBatches() ([]model.ImportBatch, error) {
+	if err := a.ready(); err != nil {
+		return nil, err
 	}
+	defer a.release()
+	return a.exportService.ImportBatches()
+}
 
-	return a.exportService.ImportFromJSON(filePath)
+// UndoImport 将一次导入的记录和分类整批移入回收站
+func (a *App) UndoImport(batchID int64) (model.ImportUndoResult, error) {
+	if err := a.ready(); err != nil {
+		return model.ImportUndoResult{}, err
+	}
+	defer a.release()
+	return a.exportService.UndoImport(batchID)
 }
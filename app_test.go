@@ -0,0 +1,246 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	apperrors "dog-view/internal/errors"
+	"dog-view/internal/ledger"
+	"dog-view/internal/model"
+	"dog-view/internal/repository"
+	"dog-view/internal/vault"
+)
+
+// 切换账本时进行中的调用应使用完整的旧服务完成，之后的调用使用新服务，不会用到已关闭的仓库
+func TestOpenLedgerDuringCalls(t *testing.T) {
+	ledgers, err := ledger.Load(t.TempDir(), repository.DefaultDBFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &App{ledgers: ledgers}
+	if err := a.openLedger(ledgers.Current(), ""); err != nil {
+		t.Fatal(err)
+	}
+	defer a.closeLedger()
+
+	if err := a.CreateCategory("餐饮", "🍚", model.TypeExpense, 0); err != nil {
+		t.Fatal(err)
+	}
+	cats, err := a.GetCategories(model.TypeExpense, false)
+	if err != nil || len(cats) == 0 {
+		t.Fatalf("GetCategories: %v, %v", cats, err)
+	}
+
+	stop := make(chan struct{})
+	errs := make(chan error, 1)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				_, err := a.GetCategories(model.TypeExpense, false)
+				if err == nil {
+					err = a.CreateRecord(model.Money{Minor: 100}, model.TypeExpense, cats[0].ID, 0, "", "2024-01-05", nil)
+				}
+				if err != nil {
+					select {
+					case errs <- err:
+					default:
+					}
+					return
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 20; i++ {
+		a.ledgerMu.Lock()
+		err := a.openLedger(ledgers.Current(), "")
+		a.ledgerMu.Unlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(stop)
+	wg.Wait()
+
+	select {
+	case err := <-errs:
+		t.Fatalf("切换账本期间的调用失败: %v", err)
+	default:
+	}
+}
+
+// 解锁设置了 PIN 的加密账本后应用仍处于锁定状态，需再输入 PIN
+func TestUnlockLedgerRequiresPIN(t *testing.T) {
+	ledgers, err := ledger.Load(t.TempDir(), repository.DefaultDBFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &App{ledgers: ledgers}
+	if err := a.openLedger(ledgers.Current(), ""); err != nil {
+		t.Fatal(err)
+	}
+	defer a.closeLedger()
+
+	if err := a.SetLockPIN("", "1234"); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.EnableEncryption("passphrase"); err != nil {
+		t.Fatal(err)
+	}
+
+	// 重新打开时加密账本等待解锁
+	if err := a.openLedger(ledgers.Current(), ""); err != nil {
+		t.Fatal(err)
+	}
+	if !a.IsLedgerLocked() {
+		t.Fatal("encrypted ledger should be locked after reopening")
+	}
+	if err := a.UnlockLedger("passphrase"); err != nil {
+		t.Fatal(err)
+	}
+	if a.IsLedgerLocked() {
+		t.Error("ledger still locked after UnlockLedger")
+	}
+	if !a.IsAppLocked() {
+		t.Error("app should be locked until the PIN is entered")
+	}
+	if err := a.UnlockApp("1234"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.GetCategories(model.TypeExpense, false); err != nil {
+		t.Errorf("GetCategories after unlock: %v", err)
+	}
+}
+
+// 删除账本时一并删除迁移前的备份与恢复暂存文件，其他文件保留
+func TestDeleteLedgerRemovesFiles(t *testing.T) {
+	ledgers, err := ledger.Load(t.TempDir(), repository.DefaultDBFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &App{ledgers: ledgers}
+	if err := a.openLedger(ledgers.Current(), ""); err != nil {
+		t.Fatal(err)
+	}
+	defer a.closeLedger()
+
+	l, err := ledgers.Create("旅行")
+	if err != nil {
+		t.Fatal(err)
+	}
+	removed := []string{l.Path, l.Path + ".pre-v3-20240101-120000.bak", l.Path + ".restore"}
+	kept := l.Path + ".notes"
+	for _, f := range append(removed, kept) {
+		if err := os.WriteFile(f, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := a.DeleteLedger(l.ID); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range removed {
+		if _, err := os.Stat(f); !os.IsNotExist(err) {
+			t.Errorf("%s not removed: %v", filepath.Base(f), err)
+		}
+	}
+	if _, err := os.Stat(kept); err != nil {
+		t.Errorf("%s should be kept: %v", filepath.Base(kept), err)
+	}
+}
+
+// 登记表保存失败时切换账本回退到原账本
+func TestSwitchLedgerRollback(t *testing.T) {
+	dir := t.TempDir()
+	ledgers, err := ledger.Load(dir, repository.DefaultDBFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &App{ledgers: ledgers}
+	if err := a.openLedger(ledgers.Current(), ""); err != nil {
+		t.Fatal(err)
+	}
+	defer a.closeLedger()
+	if err := a.CreateCategory("餐饮", "🍚", model.TypeExpense, 0); err != nil {
+		t.Fatal(err)
+	}
+	l, err := ledgers.Create("旅行")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 用非空目录占住登记表路径，使保存失败
+	path := filepath.Join(dir, ledger.RegistryFile)
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(path, "x"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := a.SwitchLedger(l.ID); err == nil {
+		t.Fatal("SwitchLedger should fail when the registry cannot be saved")
+	}
+	if cur := ledgers.Current(); cur.ID == l.ID {
+		t.Errorf("registry switched to %d", cur.ID)
+	}
+	cats, err := a.GetCategories(model.TypeExpense, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, c := range cats {
+		found = found || c.Name == "餐饮"
+	}
+	if !found {
+		t.Error("app is not on the previous ledger after a failed switch")
+	}
+}
+
+// 加密账本不能用未加密的备份恢复，否则会悄悄关闭加密
+func TestRestoreBackupEncryptionMismatch(t *testing.T) {
+	ledgers, err := ledger.Load(t.TempDir(), repository.DefaultDBFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &App{ledgers: ledgers}
+	l := ledgers.Current()
+	if err := a.openLedger(l, ""); err != nil {
+		t.Fatal(err)
+	}
+	defer a.closeLedger()
+
+	b, err := a.CreateBackup()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(ledgers.BackupDir(l.ID), b.Name)
+	plain, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.EnableEncryption("passphrase"); err != nil {
+		t.Fatal(err)
+	}
+	// 模拟加密时未能改写的备份
+	if err := os.WriteFile(path, plain, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := a.RestoreBackup(b.Name); err != apperrors.ErrEncryptionMismatch {
+		t.Fatalf("RestoreBackup = %v, want ErrEncryptionMismatch", err)
+	}
+	if encrypted, err := vault.IsEncrypted(l.Path); err != nil || !encrypted {
+		t.Errorf("ledger encrypted = %v, %v after rejected restore", encrypted, err)
+	}
+}
@@ -6,9 +6,37 @@ import { Records } from './pages/Records';
 import { Analysis } from './pages/Analysis';
 import { Settings } from './pages/Settings';
 import { useStore } from './stores/useStore';
+import {
+  GetStartupError,
+  IsAppLocked,
+  IsLedgerLocked,
+  ReportActivity,
+  UnlockApp,
+  UnlockLedger,
+} from '../wailsjs/go/main/App';
+import { EventsOn } from '../wailsjs/runtime/runtime';
+import { formatMoney } from './utils/money';
+import type { BudgetAlert } from './types';
+
+// 用户操作时通知后端重置空闲计时的最小间隔
+const ACTIVITY_REPORT_INTERVAL = 30 * 1000;
+
+// promptUnlock 反复要求输入密码直到解锁成功或用户取消，解锁成功后由事件触发重新加载
+async function promptUnlock(message: string, unlock: (secret: string) => Promise<void>) {
+  for (;;) {
+    const secret = prompt(message);
+    if (secret === null) return;
+    try {
+      await unlock(secret);
+      return;
+    } catch (e) {
+      alert(String(e));
+    }
+  }
+}
 
 function App() {
-  const { theme, setTheme } = useStore();
+  const { theme, setTheme, initCurrentPeriod } = useStore();
 
   useEffect(() => {
     // 初始化主题
@@ -17,6 +45,63 @@ function App() {
       setTheme(savedTheme);
     }
     document.documentElement.setAttribute('data-theme', savedTheme || 'light');
+
+    // 数据库初始化或迁移失败时提示用户；加密的账本先输入密码解锁，解锁后重新加载
+    GetStartupError().then(async (message) => {
+      if (message) {
+        alert(message);
+        return;
+      }
+      if (await IsLedgerLocked()) {
+        await promptUnlock('账本已加密，请输入密码', UnlockLedger);
+        return;
+      }
+      if (await IsAppLocked()) {
+        await promptUnlock('应用已锁定，请输入 PIN', UnlockApp);
+        return;
+      }
+      initCurrentPeriod();
+    });
+
+    // 空闲或最小化时后端锁定应用，解锁后重新加载
+    const offLocked = EventsOn('app:locked', () => promptUnlock('应用已锁定，请输入 PIN', UnlockApp));
+    const offAppUnlocked = EventsOn('app:unlocked', () => window.location.reload());
+
+    let lastReport = 0;
+    const onActivity = () => {
+      const now = Date.now();
+      if (now - lastReport >= ACTIVITY_REPORT_INTERVAL) {
+        lastReport = now;
+        ReportActivity();
+      }
+    };
+    const activityEvents = ['mousedown', 'keydown', 'wheel', 'touchstart'];
+    activityEvents.forEach((name) => window.addEventListener(name, onActivity, { passive: true }));
+
+    // 切换账本、解锁账本或恢复备份后重新加载所有页面数据
+    const offLedger = EventsOn('ledger:switched', () => window.location.reload());
+    const offUnlocked = EventsOn('ledger:unlocked', () => window.location.reload());
+    const offRestored = EventsOn('backup:restored', () => window.location.reload());
+
+    // 新增支出使预算使用率越过阈值时提醒
+    const offWarning = EventsOn('budget:warning', ({ status }: BudgetAlert) => {
+      alert(`${status.categoryName}已使用预算的 ${status.percentage.toFixed(0)}%`);
+    });
+    const offExceeded = EventsOn('budget:exceeded', ({ status }: BudgetAlert) => {
+      const over = { ...status.remaining, minor: -status.remaining.minor };
+      alert(`${status.categoryName}已超出预算 ${formatMoney(over)}`);
+    });
+
+    return () => {
+      offLocked();
+      offAppUnlocked();
+      activityEvents.forEach((name) => window.removeEventListener(name, onActivity));
+      offLedger();
+      offUnlocked();
+      offRestored();
+      offWarning();
+      offExceeded();
+    };
   }, []);
 
   return (
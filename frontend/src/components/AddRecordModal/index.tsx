@@ -5,8 +5,9 @@ import { useStore } from '../../stores/useStore';
 import { CategoryCard } from '../CategoryCard';
 import { AmountInput } from '../AmountInput';
 import { CreateCategoryModal } from '../CreateCategoryModal';
-import { CreateRecord } from '../../../wailsjs/go/main/App';
+import { CreateRecord, FindSimilarRecords } from '../../../wailsjs/go/main/App';
 import type { RecordType, Category } from '../../types';
+import { toMoney } from '../../utils/money';
 import styles from './AddRecordModal.module.css';
 
 interface AddRecordModalProps {
@@ -48,12 +49,18 @@ export function AddRecordModal({ onClose, onSuccess }: AddRecordModalProps) {
 
     setLoading(true);
     try {
+      const similar = await FindSimilarRecords(toMoney(amount), recordType, selectedCategory.id, 0, note, date);
+      if (similar.length > 0 && !confirm(`${date} 已有 ${similar.length} 条相同的记录，仍要添加吗？`)) {
+        return;
+      }
       await CreateRecord(
-        parseFloat(amount),
+        toMoney(amount),
         recordType,
         selectedCategory.id,
+        0,
         note,
-        date
+        date,
+        []
       );
       onSuccess();
       onClose();
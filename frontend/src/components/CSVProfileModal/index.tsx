@@ -0,0 +1,310 @@
+import { useState } from 'react';
+import { X } from 'lucide-react';
+import { CreateCSVProfile, InspectCSV, UpdateCSVProfile } from '../../../wailsjs/go/main/App';
+import { model } from '../../../wailsjs/go/models';
+import type { CSVColumn, CSVMapping, CSVProfile, CSVSample, SignConvention } from '../../types';
+import styles from './CSVProfileModal.module.css';
+
+const EMPTY_COLUMN: CSVColumn = { index: 0, header: '' };
+
+const DEFAULT_MAPPING: CSVMapping = {
+  encoding: 'auto',
+  delimiter: ',',
+  skipRows: 0,
+  hasHeader: true,
+  date: EMPTY_COLUMN,
+  amount: EMPTY_COLUMN,
+  income: EMPTY_COLUMN,
+  expense: EMPTY_COLUMN,
+  type: EMPTY_COLUMN,
+  category: EMPTY_COLUMN,
+  currency: EMPTY_COLUMN,
+  notes: [],
+  dateLayout: 'YYYY-MM-DD',
+  signConvention: 'negative_expense',
+  decimalSeparator: '.',
+  incomeLabels: [],
+  expenseLabels: [],
+};
+
+// 列可以填表头名称或列号（从 1 开始）
+const parseColumn = (text: string): CSVColumn => {
+  const value = text.trim();
+  if (/^\d+$/.test(value)) {
+    return { index: Number(value), header: '' };
+  }
+  return { index: 0, header: value };
+};
+
+const formatColumn = (c: CSVColumn) => c.header || (c.index > 0 ? String(c.index) : '');
+
+// 多个值以逗号或顿号分隔
+const splitList = (text: string) =>
+  text
+    .split(/[,，、]/)
+    .map((s) => s.trim())
+    .filter(Boolean);
+
+interface CSVProfileModalProps {
+  profile?: CSVProfile; // 为空时新建
+  onClose: () => void;
+  onSuccess: () => void;
+}
+
+export function CSVProfileModal({ profile, onClose, onSuccess }: CSVProfileModalProps) {
+  const [name, setName] = useState(profile?.name ?? '');
+  const [mapping, setMapping] = useState<CSVMapping>(profile?.mapping ?? DEFAULT_MAPPING);
+  // 多值字段按原文编辑，保存时再拆分，避免输入分隔符时被立即吞掉
+  const [notes, setNotes] = useState((profile?.mapping.notes ?? []).map(formatColumn).join('、'));
+  const [incomeLabels, setIncomeLabels] = useState((profile?.mapping.incomeLabels ?? []).join('、'));
+  const [expenseLabels, setExpenseLabels] = useState((profile?.mapping.expenseLabels ?? []).join('、'));
+  const [sample, setSample] = useState<CSVSample | null>(null);
+  const [loading, setLoading] = useState(false);
+  const [error, setError] = useState('');
+
+  const update = (patch: Partial<CSVMapping>) => setMapping({ ...mapping, ...patch });
+
+  const columnInput = (label: string, key: 'date' | 'amount' | 'income' | 'expense' | 'type' | 'category' | 'currency') => (
+    <div className={styles.formGroup}>
+      <label>{label}</label>
+      <input
+        className={styles.input}
+        value={formatColumn(mapping[key])}
+        onChange={(e) => update({ [key]: parseColumn(e.target.value) } as Partial<CSVMapping>)}
+        placeholder="表头名称或列号"
+      />
+    </div>
+  );
+
+  const handleInspect = async () => {
+    try {
+      const result = await InspectCSV(mapping.encoding, mapping.delimiter);
+      if (result) {
+        setSample(result);
+      }
+    } catch (err: any) {
+      setError(err.message || '读取文件失败');
+    }
+  };
+
+  const handleSubmit = async () => {
+    if (!name.trim()) {
+      setError('请输入方案名称，如银行名称');
+      return;
+    }
+
+    setLoading(true);
+    setError('');
+
+    try {
+      const data = model.CSVMapping.createFrom({
+        ...mapping,
+        notes: splitList(notes).map(parseColumn),
+        incomeLabels: splitList(incomeLabels),
+        expenseLabels: splitList(expenseLabels),
+      });
+      if (profile) {
+        await UpdateCSVProfile(profile.id, name.trim(), data);
+      } else {
+        await CreateCSVProfile(name.trim(), data);
+      }
+      onSuccess();
+      onClose();
+    } catch (err: any) {
+      setError(err.message || '保存失败');
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  const sign = mapping.signConvention;
+
+  return (
+    <div className={styles.overlay} onClick={onClose}>
+      <div className={styles.modal} onClick={(e) => e.stopPropagation()}>
+        <header className={styles.header}>
+          <button className={styles.closeBtn} onClick={onClose}>
+            <X size={20} />
+          </button>
+          <h2>{profile ? '编辑列映射' : '新建列映射'}</h2>
+          <div style={{ width: 36 }} />
+        </header>
+
+        <div className={styles.content}>
+          <div className={styles.formGroup}>
+            <label>方案名称</label>
+            <input
+              className={styles.input}
+              value={name}
+              onChange={(e) => setName(e.target.value)}
+              placeholder="例如：工商银行"
+              autoFocus
+            />
+          </div>
+
+          <div className={styles.row}>
+            <div className={styles.formGroup}>
+              <label>编码</label>
+              <select
+                className={styles.select}
+                value={mapping.encoding}
+                onChange={(e) => update({ encoding: e.target.value as CSVMapping['encoding'] })}
+              >
+                <option value="auto">自动识别</option>
+                <option value="utf-8">UTF-8</option>
+                <option value="gbk">GBK</option>
+              </select>
+            </div>
+            <div className={styles.formGroup}>
+              <label>分隔符</label>
+              <input
+                className={styles.input}
+                value={mapping.delimiter}
+                onChange={(e) => update({ delimiter: e.target.value })}
+                placeholder="默认逗号，制表符填 \t"
+              />
+            </div>
+          </div>
+
+          <button className={styles.sampleBtn} onClick={handleInspect}>
+            {sample ? `${sample.source}（${sample.encoding}）` : '选择样例文件对照列'}
+          </button>
+          {sample && (
+            <div className={styles.sample}>
+              <table>
+                <tbody>
+                  {sample.rows.map((row, i) => (
+                    <tr key={i}>
+                      <td>{i + 1}</td>
+                      {row.map((cell, j) => (
+                        <td key={j}>{cell}</td>
+                      ))}
+                    </tr>
+                  ))}
+                </tbody>
+              </table>
+            </div>
+          )}
+
+          <div className={styles.row}>
+            <div className={styles.formGroup}>
+              <label>跳过开头的行数</label>
+              <input
+                className={styles.input}
+                type="number"
+                min={0}
+                value={mapping.skipRows}
+                onChange={(e) => update({ skipRows: Number(e.target.value) || 0 })}
+              />
+            </div>
+            <label className={styles.checkbox}>
+              <input
+                type="checkbox"
+                checked={mapping.hasHeader}
+                onChange={(e) => update({ hasHeader: e.target.checked })}
+              />
+              跳过的行之后是表头
+            </label>
+          </div>
+
+          <div className={styles.row}>
+            {columnInput('日期列', 'date')}
+            <div className={styles.formGroup}>
+              <label>日期格式</label>
+              <input
+                className={styles.input}
+                value={mapping.dateLayout}
+                onChange={(e) => update({ dateLayout: e.target.value })}
+                placeholder="YYYY-MM-DD"
+              />
+            </div>
+          </div>
+
+          <div className={styles.row}>
+            <div className={styles.formGroup}>
+              <label>收支判断</label>
+              <select
+                className={styles.select}
+                value={sign}
+                onChange={(e) => update({ signConvention: e.target.value as SignConvention })}
+              >
+                <option value="negative_expense">负数为支出</option>
+                <option value="negative_income">负数为收入（信用卡）</option>
+                <option value="type_column">按类型列</option>
+                <option value="split_columns">收入、支出分两列</option>
+              </select>
+            </div>
+            <div className={styles.formGroup}>
+              <label>小数点</label>
+              <select
+                className={styles.select}
+                value={mapping.decimalSeparator}
+                onChange={(e) => update({ decimalSeparator: e.target.value })}
+              >
+                <option value=".">点（1,234.56）</option>
+                <option value=",">逗号（1.234,56）</option>
+              </select>
+            </div>
+          </div>
+
+          {sign === 'split_columns' ? (
+            <div className={styles.row}>
+              {columnInput('收入金额列', 'income')}
+              {columnInput('支出金额列', 'expense')}
+            </div>
+          ) : (
+            <div className={styles.row}>
+              {columnInput('金额列', 'amount')}
+              {sign === 'type_column' && columnInput('类型列', 'type')}
+            </div>
+          )}
+
+          {sign === 'type_column' && (
+            <div className={styles.row}>
+              <div className={styles.formGroup}>
+                <label>表示收入的值</label>
+                <input
+                  className={styles.input}
+                  value={incomeLabels}
+                  onChange={(e) => setIncomeLabels(e.target.value)}
+                  placeholder="例如：收入、贷"
+                />
+              </div>
+              <div className={styles.formGroup}>
+                <label>表示支出的值</label>
+                <input
+                  className={styles.input}
+                  value={expenseLabels}
+                  onChange={(e) => setExpenseLabels(e.target.value)}
+                  placeholder="例如：支出、借"
+                />
+              </div>
+            </div>
+          )}
+
+          <div className={styles.row}>
+            {columnInput('分类列（可选）', 'category')}
+            {columnInput('币种列（可选）', 'currency')}
+          </div>
+
+          <div className={styles.formGroup}>
+            <label>备注列（可多列，依次拼接）</label>
+            <input
+              className={styles.input}
+              value={notes}
+              onChange={(e) => setNotes(e.target.value)}
+              placeholder="例如：摘要、对方户名"
+            />
+          </div>
+          <span className={styles.hint}>没有分类列时，记录归入「其他收入」「其他支出」</span>
+
+          {error && <div className={styles.error}>{error}</div>}
+
+          <button className={styles.submitBtn} onClick={handleSubmit} disabled={!name.trim() || loading}>
+            {loading ? '保存中...' : '保存方案'}
+          </button>
+        </div>
+      </div>
+    </div>
+  );
+}
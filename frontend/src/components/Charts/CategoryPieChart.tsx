@@ -1,5 +1,6 @@
 import { PieChart, Pie, Cell, ResponsiveContainer, Legend, Tooltip } from 'recharts';
 import type { CategoryStat } from '../../types';
+import { moneyToNumber } from '../../utils/money';
 
 interface CategoryPieChartProps {
   data: CategoryStat[];
@@ -20,13 +21,14 @@ export function CategoryPieChart({ data }: CategoryPieChartProps) {
     );
   }
 
-  const total = data.reduce((sum, item) => sum + item.amount, 0);
+  const chartData = data.map((item) => ({ ...item, amount: moneyToNumber(item.amount) }));
+  const total = chartData.reduce((sum, item) => sum + item.amount, 0);
 
   return (
     <ResponsiveContainer width="100%" height={300}>
       <PieChart>
         <Pie
-          data={data}
+          data={chartData}
           dataKey="amount"
           nameKey="categoryName"
           cx="50%"
@@ -9,6 +9,7 @@ import {
   ResponsiveContainer,
 } from 'recharts';
 import type { MonthTrend } from '../../types';
+import { moneyToNumber } from '../../utils/money';
 
 interface TrendLineChartProps {
   data: MonthTrend[];
@@ -26,6 +27,8 @@ export function TrendLineChart({ data }: TrendLineChartProps) {
   const formattedData = data.map((item) => ({
     ...item,
     month: item.month.slice(5), // "2024-01" -> "01"
+    income: moneyToNumber(item.income),
+    expense: moneyToNumber(item.expense),
   }));
 
   return (
@@ -38,7 +38,7 @@ export function CreateCategoryModal({ type, onClose, onSuccess }: CreateCategory
     setError('');
 
     try {
-      await CreateCategory(name.trim(), selectedEmoji, type);
+      await CreateCategory(name.trim(), selectedEmoji, type, 0);
       onSuccess();
       onClose();
     } catch (err: any) {
@@ -1,5 +1,6 @@
 import { Trash2 } from 'lucide-react';
 import type { Record } from '../../types';
+import { formatMoney } from '../../utils/money';
 import styles from './RecordList.module.css';
 
 interface RecordListProps {
@@ -55,7 +56,7 @@ export function RecordList({ records, onEdit, onDelete, showDate = true }: Recor
                     record.type === 'income' ? styles.income : styles.expense
                   }`}
                 >
-                  {record.type === 'income' ? '+' : '-'}¥{record.amount.toFixed(2)}
+                  {record.type === 'income' ? '+' : '-'}¥{formatMoney(record.amount)}
                 </span>
                 {onDelete && (
                   <button
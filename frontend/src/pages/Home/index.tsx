@@ -4,6 +4,7 @@ import { useStore } from '../../stores/useStore';
 import { RecordList } from '../../components/RecordList';
 import { CategoryPieChart } from '../../components/Charts';
 import { AddRecordModal } from '../../components/AddRecordModal';
+import { formatMoney } from '../../utils/money';
 import styles from './Home.module.css';
 
 export function Home() {
@@ -45,15 +46,15 @@ export function Home() {
       <div className={styles.summaryCards}>
         <div className={`${styles.summaryCard} ${styles.income}`}>
           <span className={styles.label}>收入</span>
-          <span className={styles.value}>¥{monthSummary?.totalIncome.toFixed(2) || '0.00'}</span>
+          <span className={styles.value}>¥{formatMoney(monthSummary?.totalIncome)}</span>
         </div>
         <div className={`${styles.summaryCard} ${styles.expense}`}>
           <span className={styles.label}>支出</span>
-          <span className={styles.value}>¥{monthSummary?.totalExpense.toFixed(2) || '0.00'}</span>
+          <span className={styles.value}>¥{formatMoney(monthSummary?.totalExpense)}</span>
         </div>
         <div className={`${styles.summaryCard} ${styles.balance}`}>
           <span className={styles.label}>结余</span>
-          <span className={styles.value}>¥{monthSummary?.balance.toFixed(2) || '0.00'}</span>
+          <span className={styles.value}>¥{formatMoney(monthSummary?.balance)}</span>
         </div>
       </div>
 
@@ -1,10 +1,28 @@
-import { Download, Upload } from 'lucide-react';
+import { useState } from 'react';
+import { Copy, Download, Plus, Undo2, Upload } from 'lucide-react';
 import { useStore } from '../../stores/useStore';
-import { ExportToCSV, ExportToJSON, ImportFromCSV, ImportFromJSON } from '../../../wailsjs/go/main/App';
+import { CSVProfileModal } from '../../components/CSVProfileModal';
+import {
+  CommitImport,
+  ExportToCSV,
+  ExportToJSON,
+  FindDuplicates,
+  ListCSVProfiles,
+  ListImportBatches,
+  PreviewImportBill,
+  PreviewImportCSV,
+  PreviewImportJSON,
+  PreviewImportMappedCSV,
+  ResolveDuplicates,
+  UndoImport,
+} from '../../../wailsjs/go/main/App';
+import { model } from '../../../wailsjs/go/models';
+import type { ImportPreview } from '../../types';
 import styles from './Settings.module.css';
 
 export function Settings() {
   const { theme, toggleTheme } = useStore();
+  const [showProfileModal, setShowProfileModal] = useState(false);
 
   const handleExportCSV = async () => {
     try {
@@ -30,20 +48,136 @@ export function Settings() {
     }
   };
 
-  const handleImportCSV = async () => {
+  // 先试运行导入，展示校验结果，确认后再按计划提交
+  const runImport = async (preview: () => Promise<ImportPreview | null>) => {
     try {
-      const count = await ImportFromCSV();
-      alert(`成功导入 ${count} 条记录`);
+      const plan = await preview();
+      if (!plan) {
+        return;
+      }
+
+      const lines = [`共 ${plan.rows.length} 行，可导入 ${plan.valid} 行，${plan.invalid} 行有错误将被跳过`];
+      if (plan.ignored > 0) {
+        lines.push(`另有 ${plan.ignored} 笔交易未成功、已退款或不计收支，不导入`);
+      }
+      if (plan.newCategories.length > 0) {
+        lines.push(`将新建分类：${plan.newCategories.map((c) => c.name).join('、')}`);
+      }
+      if (plan.duplicates > 0) {
+        lines.push(`其中 ${plan.duplicates} 行可能与已有记录重复`);
+      }
+      if (plan.newTags.length > 0) {
+        lines.push(`将新建标签：${plan.newTags.map((t) => t.name).join('、')}`);
+      }
+      const issues = plan.rows.flatMap((row) =>
+        [...row.errors, ...row.warnings].map((issue) => `第 ${row.line} 行：${issue.message}`)
+      );
+      if (issues.length > 0) {
+        lines.push('', ...issues.slice(0, 10));
+        if (issues.length > 10) {
+          lines.push(`……另有 ${issues.length - 10} 条问题`);
+        }
+      }
+      if (plan.valid === 0) {
+        alert(lines.join('\n'));
+        return;
+      }
+      if (!confirm([...lines, '', '确定导入吗？'].join('\n'))) {
+        return;
+      }
+      if (plan.duplicates > 0) {
+        plan.duplicatePolicy = confirm(`导入疑似重复的 ${plan.duplicates} 行并标记为「疑似重复」吗？取消则跳过这些行`)
+          ? 'flag'
+          : 'skip';
+      }
+
+      const result = await CommitImport(model.ImportPreview.createFrom(plan));
+      const skipped = result.skipped + (plan.duplicatePolicy === 'skip' ? result.duplicates : 0);
+      alert(`成功导入 ${result.imported} 条记录` + (skipped > 0 ? `，跳过 ${skipped} 条` : ''));
     } catch (error) {
       console.error('导入失败:', error);
       alert('导入失败');
     }
   };
 
-  const handleImportJSON = async () => {
+  // 撤销最近一次导入，记录和导入时新建的分类移入回收站
+  const handleUndoImport = async () => {
+    try {
+      const batches = await ListImportBatches();
+      if (batches.length === 0) {
+        alert('没有可撤销的导入');
+        return;
+      }
+      const last = batches[0];
+      if (!confirm(`撤销 ${last.source} 的导入（${last.records} 条记录）？记录将移入回收站`)) {
+        return;
+      }
+      const result = await UndoImport(last.id);
+      alert(`已将 ${result.records} 条记录、${result.categories} 个分类移入回收站`);
+    } catch (error) {
+      console.error('撤销导入失败:', error);
+      alert('撤销导入失败');
+    }
+  };
+
+  // 查找账本中的重复记录，每组保留最早录入的一条，其余移入回收站
+  const handleFindDuplicates = async () => {
     try {
-      const count = await ImportFromJSON();
-      alert(`成功导入 ${count} 条记录`);
+      const groups = await FindDuplicates();
+      if (groups.length === 0) {
+        alert('没有发现重复记录');
+        return;
+      }
+      const extra = groups.flatMap((g) => g.records.slice(1).map((r) => r.id));
+      const lines = groups.slice(0, 10).map((g) => {
+        const first = g.records[0];
+        return `${first.date} ${first.category?.name ?? ''} ${first.note}：${g.records.length} 条`;
+      });
+      if (groups.length > 10) {
+        lines.push(`……另有 ${groups.length - 10} 组`);
+      }
+      if (!confirm([`发现 ${groups.length} 组重复记录`, ...lines, '', `保留每组最早的一条，将其余 ${extra.length} 条移入回收站？`].join('\n'))) {
+        return;
+      }
+      const removed = await ResolveDuplicates(extra);
+      alert(`已将 ${removed} 条重复记录移入回收站`);
+    } catch (error) {
+      console.error('查找重复记录失败:', error);
+      alert('查找重复记录失败');
+    }
+  };
+
+  const handleImportCSV = () => runImport(PreviewImportCSV);
+
+  const handleImportJSON = () => runImport(PreviewImportJSON);
+
+  const handleImportBill = () => runImport(PreviewImportBill);
+
+  // 按保存的列映射方案导入银行流水，有多个方案时先选择
+  const handleImportBank = async () => {
+    try {
+      const profiles = await ListCSVProfiles();
+      if (profiles.length === 0) {
+        alert('请先新建一个列映射方案');
+        setShowProfileModal(true);
+        return;
+      }
+      let profile = profiles[0];
+      if (profiles.length > 1) {
+        const answer = prompt(
+          ['选择列映射方案：', ...profiles.map((p, i) => `${i + 1}. ${p.name}`)].join('\n'),
+          '1'
+        );
+        if (!answer) {
+          return;
+        }
+        profile = profiles[Number(answer) - 1];
+        if (!profile) {
+          alert('方案编号无效');
+          return;
+        }
+      }
+      await runImport(() => PreviewImportMappedCSV(profile.id));
     } catch (error) {
       console.error('导入失败:', error);
       alert('导入失败');
@@ -92,7 +226,7 @@ export function Settings() {
           <div className={styles.settingRow}>
             <div>
               <span className={styles.settingLabel}>导入数据</span>
-              <span className={styles.settingDesc}>从文件导入记录</span>
+              <span className={styles.settingDesc}>从文件或支付宝、微信支付账单导入记录</span>
             </div>
             <div className={styles.btnGroup}>
               <button className={styles.actionBtn} onClick={handleImportCSV}>
@@ -103,8 +237,44 @@ export function Settings() {
                 <Upload size={16} />
                 JSON
               </button>
+              <button className={styles.actionBtn} onClick={handleImportBill}>
+                <Upload size={16} />
+                账单
+              </button>
+              <button className={styles.actionBtn} onClick={handleUndoImport}>
+                <Undo2 size={16} />
+                撤销
+              </button>
             </div>
           </div>
+
+          <div className={styles.settingRow}>
+            <div>
+              <span className={styles.settingLabel}>银行流水</span>
+              <span className={styles.settingDesc}>按保存的列映射方案导入银行导出的 CSV</span>
+            </div>
+            <div className={styles.btnGroup}>
+              <button className={styles.actionBtn} onClick={handleImportBank}>
+                <Upload size={16} />
+                导入
+              </button>
+              <button className={styles.actionBtn} onClick={() => setShowProfileModal(true)}>
+                <Plus size={16} />
+                新建方案
+              </button>
+            </div>
+          </div>
+
+          <div className={styles.settingRow}>
+            <div>
+              <span className={styles.settingLabel}>重复记录</span>
+              <span className={styles.settingDesc}>查找日期、分类、金额和备注都相同的记录</span>
+            </div>
+            <button className={styles.actionBtn} onClick={handleFindDuplicates}>
+              <Copy size={16} />
+              查找
+            </button>
+          </div>
         </div>
       </section>
 
@@ -118,6 +288,10 @@ export function Settings() {
           </div>
         </div>
       </section>
+
+      {showProfileModal && (
+        <CSVProfileModal onClose={() => setShowProfileModal(false)} onSuccess={() => alert('列映射方案已保存')} />
+      )}
     </div>
   );
 }
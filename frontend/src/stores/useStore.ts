@@ -1,6 +1,6 @@
 import { create } from 'zustand';
 import type { Category, Record, MonthSummary, CategoryStatsResponse, MonthTrend, Theme, RecordType } from '../types';
-import { GetCategories, GetRecordsByMonth, GetMonthSummary, GetCategoryStats, GetTrendStats, GetRecentRecords } from '../../wailsjs/go/main/App';
+import { GetCategories, GetRecordsByMonth, GetMonthSummary, GetCategoryStats, GetTrendStats, GetRecentRecords, GetCurrentPeriod } from '../../wailsjs/go/main/App';
 
 interface AppState {
   // 主题
@@ -12,6 +12,7 @@ interface AppState {
   currentYear: number;
   currentMonth: number;
   setCurrentDate: (year: number, month: number) => void;
+  initCurrentPeriod: () => Promise<void>;
 
   // 分类
   categories: Category[];
@@ -57,12 +58,21 @@ export const useStore = create<AppState>((set, get) => ({
   setCurrentDate: (year, month) => {
     set({ currentYear: year, currentMonth: month });
   },
+  // 记账月起始日不是 1 号时，今天可能属于上一个记账月
+  initCurrentPeriod: async () => {
+    try {
+      const period = await GetCurrentPeriod();
+      set({ currentYear: period.year, currentMonth: period.month });
+    } catch (error) {
+      console.error('获取当前记账周期失败:', error);
+    }
+  },
 
   // 分类
   categories: [],
   fetchCategories: async (type) => {
     try {
-      const categories = await GetCategories(type || '');
+      const categories = await GetCategories(type || '', false);
       set({ categories: (categories || []) as unknown as Category[] });
     } catch (error) {
       console.error('获取分类失败:', error);
@@ -106,7 +116,7 @@ export const useStore = create<AppState>((set, get) => ({
   fetchCategoryStats: async () => {
     const { currentYear, currentMonth } = get();
     try {
-      const stats = await GetCategoryStats(currentYear, currentMonth);
+      const stats = await GetCategoryStats(currentYear, currentMonth, true);
       set({ categoryStats: stats as unknown as CategoryStatsResponse });
     } catch (error) {
       console.error('获取分类统计失败:', error);
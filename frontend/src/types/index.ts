@@ -3,32 +3,261 @@ export interface Category {
   name: string;
   icon: string;
   type: 'income' | 'expense';
+  parentId: number; // 0 表示一级分类
   sortOrder: number;
+  archived: boolean;
+  importBatch: number; // 导入时自动创建的分类为导入批次 ID，否则为 0
   createdAt: string;
+  deletedAt?: string; // 仅回收站中的分类
+  children?: Category[];
+}
+
+// 金额，以最小货币单位（如 分）存储
+export interface Money {
+  minor: number;
+  currency: string;
+}
+
+export interface Tag {
+  id: number;
+  name: string;
+  color: string;
 }
 
 export interface Record {
   id: number;
-  amount: number;
+  amount: Money;
   type: 'income' | 'expense';
   categoryId: number;
   category?: Category;
+  tags?: Tag[];
   note: string;
   date: string;
+  importBatch: number; // 导入批次 ID，0 表示手动录入
   createdAt: string;
+  deletedAt?: string; // 仅回收站中的记录
 }
 
 export interface MonthSummary {
-  totalIncome: number;
-  totalExpense: number;
-  balance: number;
+  totalIncome: Money;
+  totalExpense: Money;
+  balance: Money;
+}
+
+// 记录筛选条件，零值字段表示不限；金额按记录自身币种的最小单位比较
+export interface RecordFilter {
+  startDate: string;
+  endDate: string;
+  types: Array<'income' | 'expense' | 'transfer'>;
+  categoryIds: number[];
+  tagIds: number[];
+  matchAllTags: boolean;
+  minAmount: number;
+  maxAmount: number;
+  note: string;
+}
+
+export interface RecordQuery {
+  filter: RecordFilter;
+  sortBy: 'date' | 'amount';
+  desc: boolean;
+  cursor: string; // 上一页的 nextCursor，首页为空
+  limit: number;
+}
+
+export interface RecordPage {
+  records: Record[];
+  nextCursor: string; // 为空表示没有更多
+  total: number;
+  summary: MonthSummary;
+}
+
+// 备注搜索结果，snippet 中命中文本以 \u0002 与 \u0003 包围
+export interface SearchResult {
+  record: Record;
+  snippet: string;
+}
+
+// 回收站内容，均按删除时间倒序
+export interface Trash {
+  records: Record[];
+  categories: Category[];
+}
+
+export interface PurgeResult {
+  records: number;
+  categories: number;
+}
+
+// 账本数据库的一份备份，name 为备份目录中的文件名
+export interface Backup {
+  name: string;
+  size: number;
+  createdAt: string;
+}
+
+// 自动备份设置，intervalHours 为 0 时不自动备份
+export interface BackupPolicy {
+  intervalHours: number;
+  keepDaily: number;
+  keepWeekly: number;
+  keepMonthly: number;
+}
+
+// 应用锁设置，enabled 表示已设置 PIN；idleMinutes 为 0 时不自动锁定
+export interface LockSettings {
+  enabled: boolean;
+  idleMinutes: number;
+  lockOnMinimize: boolean;
+}
+
+// 一次操作产生的全部变更，撤销和重做时 targetId 为对应的变更集
+export interface ChangeSet {
+  id: number;
+  action: string;
+  targetId: number;
+  createdAt: string;
+}
+
+// 记录的一次变更，before 为空表示新建，after 为空表示彻底删除
+export interface RecordChange {
+  setId: number;
+  action: string;
+  before: Record | null;
+  after: Record | null;
+  createdAt: string;
+}
+
+// 导入行的一条错误或警告，field 为出错的列
+export interface ImportIssue {
+  field: string;
+  message: string;
+}
+
+// 导入预览中的一行，errors 非空时该行不会导入
+export interface ImportRow {
+  line: number;
+  date: string;
+  type: string;
+  category: string;
+  amount: string;
+  currency: string;
+  note: string;
+  tags: string[];
+  duplicate: boolean; // 与账本中已有的记录指纹相同
+  errors: ImportIssue[];
+  warnings: ImportIssue[];
+}
+
+export interface ImportCategory {
+  name: string;
+  icon: string;
+  type: string;
+  parent?: string;
+  archived?: boolean;
+  fromFile: boolean;
+}
+
+export interface ImportTag {
+  name: string;
+  color: string;
+  fromFile: boolean;
+}
+
+// 导入预览，也是提交导入时的计划
+export interface ImportPreview {
+  source: string;
+  rows: ImportRow[];
+  newCategories: ImportCategory[];
+  newTags: ImportTag[];
+  valid: number;
+  invalid: number;
+  duplicates: number;
+  duplicatePolicy: DuplicatePolicy;
+  ignored: number; // 账单中未成功、已退款或不计收支的交易笔数
+}
+
+export interface ImportResult {
+  batchId: number;
+  imported: number;
+  skipped: number;
+  duplicates: number;
+  categoriesCreated: number;
+  tagsCreated: number;
+}
+
+// 通用 CSV 的一列：header 非空时按表头名称查找，否则按 index（从 1 开始）；两者都为空表示没有该列
+export interface CSVColumn {
+  index: number;
+  header: string;
+}
+
+export type CSVEncoding = 'auto' | 'utf-8' | 'gbk';
+
+// 金额正负号约定：负数为支出 / 负数为收入 / 收支由类型列决定 / 收入和支出分两列
+export type SignConvention = 'negative_expense' | 'negative_income' | 'type_column' | 'split_columns';
+
+// 通用 CSV 的列映射，描述一种银行流水的格式
+export interface CSVMapping {
+  encoding: CSVEncoding;
+  delimiter: string;
+  skipRows: number;
+  hasHeader: boolean;
+  date: CSVColumn;
+  amount: CSVColumn;
+  income: CSVColumn;
+  expense: CSVColumn;
+  type: CSVColumn;
+  category: CSVColumn;
+  currency: CSVColumn;
+  notes: CSVColumn[];
+  dateLayout: string; // 如 YYYY-MM-DD、YYYYMMDD
+  signConvention: SignConvention;
+  decimalSeparator: string;
+  incomeLabels: string[];
+  expenseLabels: string[];
+}
+
+// 保存的列映射方案，按银行命名
+export interface CSVProfile {
+  id: number;
+  name: string;
+  mapping: CSVMapping;
+  createdAt: string;
+}
+
+// CSV 文件的前几行，供配置列映射时对照
+export interface CSVSample {
+  source: string;
+  encoding: CSVEncoding;
+  rows: string[][];
+}
+
+// 疑似重复行的处理方式：跳过、照常导入、导入并打上「疑似重复」标签
+export type DuplicatePolicy = 'skip' | 'keep' | 'flag';
+
+// 指纹相同的一组记录，按录入时间排序
+export interface DuplicateGroup {
+  fingerprint: string;
+  records: Record[];
+}
+
+// 一次提交的导入，可整批撤销
+export interface ImportBatch {
+  id: number;
+  source: string;
+  records: number;
+  categories: number;
+  tags: number;
+  createdAt: string;
 }
 
 export interface CategoryStat {
   categoryId: number;
   categoryName: string;
   categoryIcon: string;
-  amount: number;
+  parentId: number;
+  amount: Money;
   percentage: number;
 }
 
@@ -39,8 +268,27 @@ export interface CategoryStatsResponse {
 
 export interface MonthTrend {
   month: string;
-  income: number;
-  expense: number;
+  income: Money;
+  expense: Money;
+}
+
+export interface BudgetStatus {
+  budgetId: number;
+  categoryId: number;
+  categoryName: string;
+  categoryIcon: string;
+  inheritedFrom: string;
+  budget: Money;
+  spent: Money;
+  remaining: Money;
+  percentage: number;
+  projected: Money;
+  projectedOverrun: boolean;
+}
+
+export interface BudgetAlert {
+  threshold: number;
+  status: BudgetStatus;
 }
 
 export type RecordType = 'income' | 'expense';
@@ -0,0 +1,38 @@
+import type { Money } from '../types';
+
+// 与后端 model.CurrencyExponent 保持一致
+const CURRENCY_EXPONENTS: { [currency: string]: number } = {
+  JPY: 0,
+  KRW: 0,
+  VND: 0,
+};
+
+export const DEFAULT_CURRENCY = 'CNY';
+
+export function currencyExponent(currency: string): number {
+  return CURRENCY_EXPONENTS[currency] ?? 2;
+}
+
+// 将输入的十进制字符串转换为最小货币单位，避免 parseFloat 带来的精度问题
+export function toMoney(value: string, currency: string = DEFAULT_CURRENCY): Money {
+  const exp = currencyExponent(currency);
+  const [intPart = '0', fracPart = ''] = value.trim().split('.');
+  const digits = (intPart || '0') + fracPart.padEnd(exp, '0').slice(0, exp);
+  return { minor: parseInt(digits, 10) || 0, currency };
+}
+
+// 最小货币单位转换为数值，仅用于图表等展示场景
+export function moneyToNumber(money?: Money): number {
+  if (!money) return 0;
+  return money.minor / Math.pow(10, currencyExponent(money.currency));
+}
+
+// 格式化金额，如 1234 CNY -> "12.34"
+export function formatMoney(money?: Money): string {
+  if (!money) return '0.00';
+  const exp = currencyExponent(money.currency);
+  const sign = money.minor < 0 ? '-' : '';
+  const digits = Math.abs(money.minor).toString().padStart(exp + 1, '0');
+  if (exp === 0) return sign + digits;
+  return `${sign}${digits.slice(0, -exp)}.${digits.slice(-exp)}`;
+}
@@ -1,37 +1,225 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 import {model} from '../models';
+import {ledger} from '../models';
+import {service} from '../models';
 
-export function CreateCategory(arg1:string,arg2:string,arg3:string):Promise<void>;
+export function ArchiveCategory(arg1:number):Promise<void>;
 
-export function CreateRecord(arg1:number,arg2:string,arg3:number,arg4:string,arg5:string):Promise<void>;
+export function ChangePassphrase(arg1:string,arg2:string):Promise<void>;
+
+export function CommitImport(arg1:model.ImportPreview):Promise<model.ImportResult>;
+
+export function CreateAccount(arg1:string,arg2:string,arg3:string,arg4:model.Money):Promise<void>;
+
+export function CreateBackup():Promise<model.Backup>;
+
+export function CreateCSVProfile(arg1:string,arg2:model.CSVMapping):Promise<model.CSVProfile>;
+
+export function CreateCategory(arg1:string,arg2:string,arg3:string,arg4:number):Promise<void>;
+
+export function CreateLedger(arg1:string):Promise<ledger.Ledger>;
+
+export function CreateRecord(arg1:model.Money,arg2:string,arg3:number,arg4:number,arg5:string,arg6:string,arg7:Array<number>):Promise<void>;
+
+export function CreateRecurringRule(arg1:model.RecurringRule):Promise<model.RecurringRule>;
+
+export function CreateTag(arg1:string,arg2:string):Promise<model.Tag>;
+
+export function CreateTransfer(arg1:model.Money,arg2:number,arg3:number,arg4:string,arg5:string):Promise<void>;
+
+export function DeleteAccount(arg1:number):Promise<void>;
+
+export function DeleteBudget(arg1:number,arg2:number,arg3:number):Promise<void>;
+
+export function DeleteCSVProfile(arg1:number):Promise<void>;
 
 export function DeleteCategory(arg1:number):Promise<void>;
 
+export function DeleteCategoryAndReassign(arg1:number,arg2:number):Promise<number>;
+
+export function DeleteExchangeRate(arg1:number):Promise<void>;
+
+export function DeleteLedger(arg1:number):Promise<void>;
+
 export function DeleteRecord(arg1:number):Promise<void>;
 
+export function DeleteRecurringRule(arg1:number):Promise<void>;
+
+export function DeleteTag(arg1:number):Promise<void>;
+
+export function DisableEncryption(arg1:string):Promise<void>;
+
+export function EmptyTrash():Promise<model.PurgeResult>;
+
+export function EnableEncryption(arg1:string):Promise<void>;
+
 export function ExportToCSV():Promise<string>;
 
 export function ExportToJSON():Promise<string>;
 
-export function GetCategories(arg1:string):Promise<Array<model.Category>>;
+export function FindDuplicates():Promise<Array<model.DuplicateGroup>>;
+
+export function FindSimilarRecords(arg1:model.Money,arg2:string,arg3:number,arg4:number,arg5:string,arg6:string):Promise<Array<model.Record>>;
+
+export function GetAccountBalance(arg1:number,arg2:string):Promise<model.AccountBalance>;
+
+export function GetAccountBalances(arg1:string):Promise<Array<model.AccountBalance>>;
 
-export function GetCategoryStats(arg1:number,arg2:number):Promise<model.CategoryStatsResponse>;
+export function GetAccounts():Promise<Array<model.Account>>;
+
+export function GetBackupPolicy():Promise<model.BackupPolicy>;
+
+export function GetBaseCurrency():Promise<string>;
+
+export function GetBudgetStatus(arg1:number,arg2:number):Promise<model.BudgetReport>;
+
+export function GetBudgets(arg1:number,arg2:number):Promise<Array<model.Budget>>;
+
+export function GetCategories(arg1:string,arg2:boolean):Promise<Array<model.Category>>;
+
+export function GetCategoryStats(arg1:number,arg2:number,arg3:boolean):Promise<model.CategoryStatsResponse>;
+
+export function GetCategoryTree(arg1:string,arg2:boolean):Promise<Array<model.Category>>;
+
+export function GetCurrentLedger():Promise<ledger.Ledger>;
+
+export function GetCurrentPeriod():Promise<service.Period>;
+
+export function GetExchangeRates():Promise<Array<model.ExchangeRate>>;
+
+export function GetLedgers():Promise<Array<ledger.Ledger>>;
+
+export function GetLockSettings():Promise<model.LockSettings>;
+
+export function GetMonthStartDay():Promise<number>;
 
 export function GetMonthSummary(arg1:number,arg2:number):Promise<model.MonthSummary>;
 
 export function GetRecentRecords(arg1:number):Promise<Array<model.Record>>;
 
+export function GetRecordHistory(arg1:number):Promise<Array<model.RecordChange>>;
+
 export function GetRecordsByMonth(arg1:number,arg2:number):Promise<Array<model.Record>>;
 
+export function GetRecordsByTags(arg1:number,arg2:number,arg3:Array<number>,arg4:boolean):Promise<Array<model.Record>>;
+
+export function GetRecurringRules():Promise<Array<model.RecurringRule>>;
+
+export function GetStartupError():Promise<string>;
+
+export function GetTagStats(arg1:number,arg2:number):Promise<model.TagStatsResponse>;
+
+export function GetTags():Promise<Array<model.Tag>>;
+
+export function GetTrash():Promise<model.Trash>;
+
+export function GetTrashRetentionDays():Promise<number>;
+
 export function GetTrendStats(arg1:number):Promise<Array<model.MonthTrend>>;
 
-export function ImportFromCSV():Promise<number>;
+export function GetUpcomingOccurrences(arg1:number):Promise<Array<model.UpcomingOccurrence>>;
+
+export function ImportExchangeRates():Promise<number>;
+
+export function InspectCSV(arg1:string,arg2:string):Promise<model.CSVSample>;
 
-export function ImportFromJSON():Promise<number>;
+export function IsAppLocked():Promise<boolean>;
 
-export function ReorderCategories(arg1:Array<number>):Promise<void>;
+export function IsLedgerEncrypted():Promise<boolean>;
+
+export function IsLedgerLocked():Promise<boolean>;
+
+export function ListBackups():Promise<Array<model.Backup>>;
+
+export function ListCSVProfiles():Promise<Array<model.CSVProfile>>;
+
+export function ListImportBatches():Promise<Array<model.ImportBatch>>;
+
+export function LockApp():Promise<void>;
+
+export function MergeCategories(arg1:Array<number>,arg2:number):Promise<number>;
+
+export function ModifyOccurrence(arg1:number,arg2:string,arg3:model.Money,arg4:string):Promise<void>;
+
+export function MoveCategory(arg1:number,arg2:number):Promise<void>;
+
+export function PreviewImportBill():Promise<model.ImportPreview>;
+
+export function PreviewImportCSV():Promise<model.ImportPreview>;
+
+export function PreviewImportJSON():Promise<model.ImportPreview>;
+
+export function PreviewImportMappedCSV(arg1:number):Promise<model.ImportPreview>;
+
+export function PurgeCategory(arg1:number):Promise<void>;
+
+export function PurgeRecord(arg1:number):Promise<void>;
+
+export function QueryRecords(arg1:model.RecordQuery):Promise<model.RecordPage>;
+
+export function Redo():Promise<model.ChangeSet>;
+
+export function RemoveLockPIN(arg1:string):Promise<void>;
+
+export function RenameLedger(arg1:number,arg2:string):Promise<void>;
+
+export function ReorderCategories(arg1:number,arg2:Array<number>):Promise<void>;
+
+export function ReportActivity():Promise<void>;
+
+export function ResolveDuplicates(arg1:Array<number>):Promise<number>;
+
+export function RestoreBackup(arg1:string):Promise<void>;
+
+export function RestoreCategory(arg1:number):Promise<void>;
+
+export function RestoreRecord(arg1:number):Promise<void>;
+
+export function SaveExchangeRate(arg1:string,arg2:string,arg3:string,arg4:string):Promise<void>;
+
+export function SearchRecords(arg1:string,arg2:number):Promise<Array<model.SearchResult>>;
+
+export function SetBackupPolicy(arg1:model.BackupPolicy):Promise<void>;
+
+export function SetBaseCurrency(arg1:string):Promise<void>;
+
+export function SetBudget(arg1:number,arg2:number,arg3:number,arg4:model.Money,arg5:boolean):Promise<void>;
+
+export function SetLockOptions(arg1:number,arg2:boolean):Promise<void>;
+
+export function SetLockPIN(arg1:string,arg2:string):Promise<void>;
+
+export function SetMonthStartDay(arg1:number):Promise<void>;
+
+export function SetRecordTags(arg1:number,arg2:Array<number>):Promise<void>;
+
+export function SetTrashRetentionDays(arg1:number):Promise<void>;
+
+export function SkipOccurrence(arg1:number,arg2:string):Promise<void>;
+
+export function SwitchLedger(arg1:number):Promise<void>;
+
+export function UnarchiveCategory(arg1:number):Promise<void>;
+
+export function Undo():Promise<model.ChangeSet>;
+
+export function UndoImport(arg1:number):Promise<model.ImportUndoResult>;
+
+export function UnlockApp(arg1:string):Promise<void>;
+
+export function UnlockLedger(arg1:string):Promise<void>;
+
+export function UpdateAccount(arg1:number,arg2:string,arg3:string,arg4:string,arg5:model.Money):Promise<void>;
+
+export function UpdateCSVProfile(arg1:number,arg2:string,arg3:model.CSVMapping):Promise<void>;
 
 export function UpdateCategory(arg1:number,arg2:string,arg3:string):Promise<void>;
 
-export function UpdateRecord(arg1:number,arg2:number,arg3:number,arg4:string,arg5:string):Promise<void>;
+export function UpdateRecord(arg1:number,arg2:model.Money,arg3:number,arg4:number,arg5:string,arg6:string,arg7:Array<number>):Promise<void>;
+
+export function UpdateRecurringRule(arg1:model.RecurringRule):Promise<void>;
+
+export function UpdateTag(arg1:number,arg2:string,arg3:string):Promise<void>;
+
+export function UpdateTransfer(arg1:number,arg2:model.Money,arg3:number,arg4:number,arg5:string,arg6:string):Promise<void>;
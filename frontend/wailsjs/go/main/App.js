@@ -2,22 +2,106 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
-export function CreateCategory(arg1, arg2, arg3) {
-  return window['go']['main']['App']['CreateCategory'](arg1, arg2, arg3);
+export function ArchiveCategory(arg1) {
+  return window['go']['main']['App']['ArchiveCategory'](arg1);
 }
 
-export function CreateRecord(arg1, arg2, arg3, arg4, arg5) {
-  return window['go']['main']['App']['CreateRecord'](arg1, arg2, arg3, arg4, arg5);
+export function ChangePassphrase(arg1, arg2) {
+  return window['go']['main']['App']['ChangePassphrase'](arg1, arg2);
+}
+
+export function CommitImport(arg1) {
+  return window['go']['main']['App']['CommitImport'](arg1);
+}
+
+export function CreateAccount(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['CreateAccount'](arg1, arg2, arg3, arg4);
+}
+
+export function CreateBackup() {
+  return window['go']['main']['App']['CreateBackup']();
+}
+
+export function CreateCSVProfile(arg1, arg2) {
+  return window['go']['main']['App']['CreateCSVProfile'](arg1, arg2);
+}
+
+export function CreateCategory(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['CreateCategory'](arg1, arg2, arg3, arg4);
+}
+
+export function CreateLedger(arg1) {
+  return window['go']['main']['App']['CreateLedger'](arg1);
+}
+
+export function CreateRecord(arg1, arg2, arg3, arg4, arg5, arg6, arg7) {
+  return window['go']['main']['App']['CreateRecord'](arg1, arg2, arg3, arg4, arg5, arg6, arg7);
+}
+
+export function CreateRecurringRule(arg1) {
+  return window['go']['main']['App']['CreateRecurringRule'](arg1);
+}
+
+export function CreateTag(arg1, arg2) {
+  return window['go']['main']['App']['CreateTag'](arg1, arg2);
+}
+
+export function CreateTransfer(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['CreateTransfer'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function DeleteAccount(arg1) {
+  return window['go']['main']['App']['DeleteAccount'](arg1);
+}
+
+export function DeleteBudget(arg1, arg2, arg3) {
+  return window['go']['main']['App']['DeleteBudget'](arg1, arg2, arg3);
+}
+
+export function DeleteCSVProfile(arg1) {
+  return window['go']['main']['App']['DeleteCSVProfile'](arg1);
 }
 
 export function DeleteCategory(arg1) {
   return window['go']['main']['App']['DeleteCategory'](arg1);
 }
 
+export function DeleteCategoryAndReassign(arg1, arg2) {
+  return window['go']['main']['App']['DeleteCategoryAndReassign'](arg1, arg2);
+}
+
+export function DeleteExchangeRate(arg1) {
+  return window['go']['main']['App']['DeleteExchangeRate'](arg1);
+}
+
+export function DeleteLedger(arg1) {
+  return window['go']['main']['App']['DeleteLedger'](arg1);
+}
+
 export function DeleteRecord(arg1) {
   return window['go']['main']['App']['DeleteRecord'](arg1);
 }
 
+export function DeleteRecurringRule(arg1) {
+  return window['go']['main']['App']['DeleteRecurringRule'](arg1);
+}
+
+export function DeleteTag(arg1) {
+  return window['go']['main']['App']['DeleteTag'](arg1);
+}
+
+export function DisableEncryption(arg1) {
+  return window['go']['main']['App']['DisableEncryption'](arg1);
+}
+
+export function EmptyTrash() {
+  return window['go']['main']['App']['EmptyTrash']();
+}
+
+export function EnableEncryption(arg1) {
+  return window['go']['main']['App']['EnableEncryption'](arg1);
+}
+
 export function ExportToCSV() {
   return window['go']['main']['App']['ExportToCSV']();
 }
@@ -26,12 +110,76 @@ export function ExportToJSON() {
   return window['go']['main']['App']['ExportToJSON']();
 }
 
-export function GetCategories(arg1) {
-  return window['go']['main']['App']['GetCategories'](arg1);
+export function FindDuplicates() {
+  return window['go']['main']['App']['FindDuplicates']();
 }
 
-export function GetCategoryStats(arg1, arg2) {
-  return window['go']['main']['App']['GetCategoryStats'](arg1, arg2);
+export function FindSimilarRecords(arg1, arg2, arg3, arg4, arg5, arg6) {
+  return window['go']['main']['App']['FindSimilarRecords'](arg1, arg2, arg3, arg4, arg5, arg6);
+}
+
+export function GetAccountBalance(arg1, arg2) {
+  return window['go']['main']['App']['GetAccountBalance'](arg1, arg2);
+}
+
+export function GetAccountBalances(arg1) {
+  return window['go']['main']['App']['GetAccountBalances'](arg1);
+}
+
+export function GetAccounts() {
+  return window['go']['main']['App']['GetAccounts']();
+}
+
+export function GetBackupPolicy() {
+  return window['go']['main']['App']['GetBackupPolicy']();
+}
+
+export function GetBaseCurrency() {
+  return window['go']['main']['App']['GetBaseCurrency']();
+}
+
+export function GetBudgetStatus(arg1, arg2) {
+  return window['go']['main']['App']['GetBudgetStatus'](arg1, arg2);
+}
+
+export function GetBudgets(arg1, arg2) {
+  return window['go']['main']['App']['GetBudgets'](arg1, arg2);
+}
+
+export function GetCategories(arg1, arg2) {
+  return window['go']['main']['App']['GetCategories'](arg1, arg2);
+}
+
+export function GetCategoryStats(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetCategoryStats'](arg1, arg2, arg3);
+}
+
+export function GetCategoryTree(arg1, arg2) {
+  return window['go']['main']['App']['GetCategoryTree'](arg1, arg2);
+}
+
+export function GetCurrentLedger() {
+  return window['go']['main']['App']['GetCurrentLedger']();
+}
+
+export function GetCurrentPeriod() {
+  return window['go']['main']['App']['GetCurrentPeriod']();
+}
+
+export function GetExchangeRates() {
+  return window['go']['main']['App']['GetExchangeRates']();
+}
+
+export function GetLedgers() {
+  return window['go']['main']['App']['GetLedgers']();
+}
+
+export function GetLockSettings() {
+  return window['go']['main']['App']['GetLockSettings']();
+}
+
+export function GetMonthStartDay() {
+  return window['go']['main']['App']['GetMonthStartDay']();
 }
 
 export function GetMonthSummary(arg1, arg2) {
@@ -42,30 +190,254 @@ export function GetRecentRecords(arg1) {
   return window['go']['main']['App']['GetRecentRecords'](arg1);
 }
 
+export function GetRecordHistory(arg1) {
+  return window['go']['main']['App']['GetRecordHistory'](arg1);
+}
+
 export function GetRecordsByMonth(arg1, arg2) {
   return window['go']['main']['App']['GetRecordsByMonth'](arg1, arg2);
 }
 
+export function GetRecordsByTags(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['GetRecordsByTags'](arg1, arg2, arg3, arg4);
+}
+
+export function GetRecurringRules() {
+  return window['go']['main']['App']['GetRecurringRules']();
+}
+
+export function GetStartupError() {
+  return window['go']['main']['App']['GetStartupError']();
+}
+
+export function GetTagStats(arg1, arg2) {
+  return window['go']['main']['App']['GetTagStats'](arg1, arg2);
+}
+
+export function GetTags() {
+  return window['go']['main']['App']['GetTags']();
+}
+
+export function GetTrash() {
+  return window['go']['main']['App']['GetTrash']();
+}
+
+export function GetTrashRetentionDays() {
+  return window['go']['main']['App']['GetTrashRetentionDays']();
+}
+
 export function GetTrendStats(arg1) {
   return window['go']['main']['App']['GetTrendStats'](arg1);
 }
 
-export function ImportFromCSV() {
-  return window['go']['main']['App']['ImportFromCSV']();
+export function GetUpcomingOccurrences(arg1) {
+  return window['go']['main']['App']['GetUpcomingOccurrences'](arg1);
+}
+
+export function ImportExchangeRates() {
+  return window['go']['main']['App']['ImportExchangeRates']();
+}
+
+export function InspectCSV(arg1, arg2) {
+  return window['go']['main']['App']['InspectCSV'](arg1, arg2);
+}
+
+export function IsAppLocked() {
+  return window['go']['main']['App']['IsAppLocked']();
+}
+
+export function IsLedgerEncrypted() {
+  return window['go']['main']['App']['IsLedgerEncrypted']();
+}
+
+export function IsLedgerLocked() {
+  return window['go']['main']['App']['IsLedgerLocked']();
+}
+
+export function ListBackups() {
+  return window['go']['main']['App']['ListBackups']();
+}
+
+export function ListCSVProfiles() {
+  return window['go']['main']['App']['ListCSVProfiles']();
+}
+
+export function ListImportBatches() {
+  return window['go']['main']['App']['ListImportBatches']();
+}
+
+export function LockApp() {
+  return window['go']['main']['App']['LockApp']();
+}
+
+export function MergeCategories(arg1, arg2) {
+  return window['go']['main']['App']['MergeCategories'](arg1, arg2);
+}
+
+export function ModifyOccurrence(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['ModifyOccurrence'](arg1, arg2, arg3, arg4);
+}
+
+export function MoveCategory(arg1, arg2) {
+  return window['go']['main']['App']['MoveCategory'](arg1, arg2);
+}
+
+export function PreviewImportBill() {
+  return window['go']['main']['App']['PreviewImportBill']();
+}
+
+export function PreviewImportCSV() {
+  return window['go']['main']['App']['PreviewImportCSV']();
+}
+
+export function PreviewImportJSON() {
+  return window['go']['main']['App']['PreviewImportJSON']();
+}
+
+export function PreviewImportMappedCSV(arg1) {
+  return window['go']['main']['App']['PreviewImportMappedCSV'](arg1);
+}
+
+export function PurgeCategory(arg1) {
+  return window['go']['main']['App']['PurgeCategory'](arg1);
+}
+
+export function PurgeRecord(arg1) {
+  return window['go']['main']['App']['PurgeRecord'](arg1);
+}
+
+export function QueryRecords(arg1) {
+  return window['go']['main']['App']['QueryRecords'](arg1);
+}
+
+export function Redo() {
+  return window['go']['main']['App']['Redo']();
+}
+
+export function RemoveLockPIN(arg1) {
+  return window['go']['main']['App']['RemoveLockPIN'](arg1);
+}
+
+export function RenameLedger(arg1, arg2) {
+  return window['go']['main']['App']['RenameLedger'](arg1, arg2);
+}
+
+export function ReorderCategories(arg1, arg2) {
+  return window['go']['main']['App']['ReorderCategories'](arg1, arg2);
+}
+
+export function ReportActivity() {
+  return window['go']['main']['App']['ReportActivity']();
+}
+
+export function ResolveDuplicates(arg1) {
+  return window['go']['main']['App']['ResolveDuplicates'](arg1);
 }
 
-export function ImportFromJSON() {
-  return window['go']['main']['App']['ImportFromJSON']();
+export function RestoreBackup(arg1) {
+  return window['go']['main']['App']['RestoreBackup'](arg1);
 }
 
-export function ReorderCategories(arg1) {
-  return window['go']['main']['App']['ReorderCategories'](arg1);
+export function RestoreCategory(arg1) {
+  return window['go']['main']['App']['RestoreCategory'](arg1);
+}
+
+export function RestoreRecord(arg1) {
+  return window['go']['main']['App']['RestoreRecord'](arg1);
+}
+
+export function SaveExchangeRate(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['SaveExchangeRate'](arg1, arg2, arg3, arg4);
+}
+
+export function SearchRecords(arg1, arg2) {
+  return window['go']['main']['App']['SearchRecords'](arg1, arg2);
+}
+
+export function SetBackupPolicy(arg1) {
+  return window['go']['main']['App']['SetBackupPolicy'](arg1);
+}
+
+export function SetBaseCurrency(arg1) {
+  return window['go']['main']['App']['SetBaseCurrency'](arg1);
+}
+
+export function SetBudget(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['SetBudget'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function SetLockOptions(arg1, arg2) {
+  return window['go']['main']['App']['SetLockOptions'](arg1, arg2);
+}
+
+export function SetLockPIN(arg1, arg2) {
+  return window['go']['main']['App']['SetLockPIN'](arg1, arg2);
+}
+
+export function SetMonthStartDay(arg1) {
+  return window['go']['main']['App']['SetMonthStartDay'](arg1);
+}
+
+export function SetRecordTags(arg1, arg2) {
+  return window['go']['main']['App']['SetRecordTags'](arg1, arg2);
+}
+
+export function SetTrashRetentionDays(arg1) {
+  return window['go']['main']['App']['SetTrashRetentionDays'](arg1);
+}
+
+export function SkipOccurrence(arg1, arg2) {
+  return window['go']['main']['App']['SkipOccurrence'](arg1, arg2);
+}
+
+export function SwitchLedger(arg1) {
+  return window['go']['main']['App']['SwitchLedger'](arg1);
+}
+
+export function UnarchiveCategory(arg1) {
+  return window['go']['main']['App']['UnarchiveCategory'](arg1);
+}
+
+export function Undo() {
+  return window['go']['main']['App']['Undo']();
+}
+
+export function UndoImport(arg1) {
+  return window['go']['main']['App']['UndoImport'](arg1);
+}
+
+export function UnlockApp(arg1) {
+  return window['go']['main']['App']['UnlockApp'](arg1);
+}
+
+export function UnlockLedger(arg1) {
+  return window['go']['main']['App']['UnlockLedger'](arg1);
+}
+
+export function UpdateAccount(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['UpdateAccount'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function UpdateCSVProfile(arg1, arg2, arg3) {
+  return window['go']['main']['App']['UpdateCSVProfile'](arg1, arg2, arg3);
 }
 
 export function UpdateCategory(arg1, arg2, arg3) {
   return window['go']['main']['App']['UpdateCategory'](arg1, arg2, arg3);
 }
 
-export function UpdateRecord(arg1, arg2, arg3, arg4, arg5) {
-  return window['go']['main']['App']['UpdateRecord'](arg1, arg2, arg3, arg4, arg5);
+export function UpdateRecord(arg1, arg2, arg3, arg4, arg5, arg6, arg7) {
+  return window['go']['main']['App']['UpdateRecord'](arg1, arg2, arg3, arg4, arg5, arg6, arg7);
+}
+
+export function UpdateRecurringRule(arg1) {
+  return window['go']['main']['App']['UpdateRecurringRule'](arg1);
+}
+
+export function UpdateTag(arg1, arg2, arg3) {
+  return window['go']['main']['App']['UpdateTag'](arg1, arg2, arg3);
+}
+
+export function UpdateTransfer(arg1, arg2, arg3, arg4, arg5, arg6) {
+  return window['go']['main']['App']['UpdateTransfer'](arg1, arg2, arg3, arg4, arg5, arg6);
 }
@@ -1,24 +1,82 @@
+export namespace ledger {
+	
+	export class Ledger {
+	    id: number;
+	    name: string;
+	    path: string;
+	    // Go type: time
+	    createdAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Ledger(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.path = source["path"];
+	        this.createdAt = this.convertValues(source["createdAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
 export namespace model {
 	
-	export class Category {
+	export class Money {
+	    minor: number;
+	    currency: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Money(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.minor = source["minor"];
+	        this.currency = source["currency"];
+	    }
+	}
+	export class Account {
 	    id: number;
 	    name: string;
-	    icon: string;
 	    type: string;
+	    icon: string;
+	    openingBalance: Money;
 	    sortOrder: number;
 	    // Go type: time
 	    createdAt: any;
 	
 	    static createFrom(source: any = {}) {
-	        return new Category(source);
+	        return new Account(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.id = source["id"];
 	        this.name = source["name"];
-	        this.icon = source["icon"];
 	        this.type = source["type"];
+	        this.icon = source["icon"];
+	        this.openingBalance = this.convertValues(source["openingBalance"], Money);
 	        this.sortOrder = source["sortOrder"];
 	        this.createdAt = this.convertValues(source["createdAt"], null);
 	    }
@@ -41,38 +99,198 @@ export namespace model {
 		    return a;
 		}
 	}
-	export class CategoryStat {
+	export class AccountBalance {
+	    account: Account;
+	    date: string;
+	    balance: Money;
+	
+	    static createFrom(source: any = {}) {
+	        return new AccountBalance(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.account = this.convertValues(source["account"], Account);
+	        this.date = source["date"];
+	        this.balance = this.convertValues(source["balance"], Money);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Backup {
+	    name: string;
+	    size: number;
+	    // Go type: time
+	    createdAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Backup(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.size = source["size"];
+	        this.createdAt = this.convertValues(source["createdAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class BackupPolicy {
+	    intervalHours: number;
+	    keepDaily: number;
+	    keepWeekly: number;
+	    keepMonthly: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new BackupPolicy(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.intervalHours = source["intervalHours"];
+	        this.keepDaily = source["keepDaily"];
+	        this.keepWeekly = source["keepWeekly"];
+	        this.keepMonthly = source["keepMonthly"];
+	    }
+	}
+	export class Budget {
+	    id: number;
+	    month: string;
+	    categoryId: number;
+	    amount: Money;
+	    recurring: boolean;
+	    // Go type: time
+	    createdAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Budget(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.month = source["month"];
+	        this.categoryId = source["categoryId"];
+	        this.amount = this.convertValues(source["amount"], Money);
+	        this.recurring = source["recurring"];
+	        this.createdAt = this.convertValues(source["createdAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class BudgetStatus {
+	    budgetId: number;
 	    categoryId: number;
 	    categoryName: string;
 	    categoryIcon: string;
-	    amount: number;
+	    inheritedFrom: string;
+	    budget: Money;
+	    spent: Money;
+	    remaining: Money;
 	    percentage: number;
+	    projected: Money;
+	    projectedOverrun: boolean;
 	
 	    static createFrom(source: any = {}) {
-	        return new CategoryStat(source);
+	        return new BudgetStatus(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.budgetId = source["budgetId"];
 	        this.categoryId = source["categoryId"];
 	        this.categoryName = source["categoryName"];
 	        this.categoryIcon = source["categoryIcon"];
-	        this.amount = source["amount"];
+	        this.inheritedFrom = source["inheritedFrom"];
+	        this.budget = this.convertValues(source["budget"], Money);
+	        this.spent = this.convertValues(source["spent"], Money);
+	        this.remaining = this.convertValues(source["remaining"], Money);
 	        this.percentage = source["percentage"];
+	        this.projected = this.convertValues(source["projected"], Money);
+	        this.projectedOverrun = source["projectedOverrun"];
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
-	export class CategoryStatsResponse {
-	    incomeStats: CategoryStat[];
-	    expenseStats: CategoryStat[];
+	export class BudgetReport {
+	    month: string;
+	    overall?: BudgetStatus;
+	    categories: BudgetStatus[];
 	
 	    static createFrom(source: any = {}) {
-	        return new CategoryStatsResponse(source);
+	        return new BudgetReport(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.incomeStats = this.convertValues(source["incomeStats"], CategoryStat);
-	        this.expenseStats = this.convertValues(source["expenseStats"], CategoryStat);
+	        this.month = source["month"];
+	        this.overall = this.convertValues(source["overall"], BudgetStatus);
+	        this.categories = this.convertValues(source["categories"], BudgetStatus);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -93,62 +311,99 @@ export namespace model {
 		    return a;
 		}
 	}
-	export class MonthSummary {
-	    totalIncome: number;
-	    totalExpense: number;
-	    balance: number;
+	
+	export class CSVColumn {
+	    index: number;
+	    header: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new MonthSummary(source);
+	        return new CSVColumn(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.totalIncome = source["totalIncome"];
-	        this.totalExpense = source["totalExpense"];
-	        this.balance = source["balance"];
+	        this.index = source["index"];
+	        this.header = source["header"];
 	    }
 	}
-	export class MonthTrend {
-	    month: string;
-	    income: number;
-	    expense: number;
+	export class CSVMapping {
+	    encoding: string;
+	    delimiter: string;
+	    skipRows: number;
+	    hasHeader: boolean;
+	    date: CSVColumn;
+	    amount: CSVColumn;
+	    income: CSVColumn;
+	    expense: CSVColumn;
+	    type: CSVColumn;
+	    category: CSVColumn;
+	    currency: CSVColumn;
+	    notes: CSVColumn[];
+	    dateLayout: string;
+	    signConvention: string;
+	    decimalSeparator: string;
+	    incomeLabels: string[];
+	    expenseLabels: string[];
 	
 	    static createFrom(source: any = {}) {
-	        return new MonthTrend(source);
+	        return new CSVMapping(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.month = source["month"];
-	        this.income = source["income"];
-	        this.expense = source["expense"];
+	        this.encoding = source["encoding"];
+	        this.delimiter = source["delimiter"];
+	        this.skipRows = source["skipRows"];
+	        this.hasHeader = source["hasHeader"];
+	        this.date = this.convertValues(source["date"], CSVColumn);
+	        this.amount = this.convertValues(source["amount"], CSVColumn);
+	        this.income = this.convertValues(source["income"], CSVColumn);
+	        this.expense = this.convertValues(source["expense"], CSVColumn);
+	        this.type = this.convertValues(source["type"], CSVColumn);
+	        this.category = this.convertValues(source["category"], CSVColumn);
+	        this.currency = this.convertValues(source["currency"], CSVColumn);
+	        this.notes = this.convertValues(source["notes"], CSVColumn);
+	        this.dateLayout = source["dateLayout"];
+	        this.signConvention = source["signConvention"];
+	        this.decimalSeparator = source["decimalSeparator"];
+	        this.incomeLabels = source["incomeLabels"];
+	        this.expenseLabels = source["expenseLabels"];
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
-	export class Record {
+	export class CSVProfile {
 	    id: number;
-	    amount: number;
-	    type: string;
-	    categoryId: number;
-	    category?: Category;
-	    note: string;
-	    date: string;
+	    name: string;
+	    mapping: CSVMapping;
 	    // Go type: time
 	    createdAt: any;
 	
 	    static createFrom(source: any = {}) {
-	        return new Record(source);
+	        return new CSVProfile(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.id = source["id"];
-	        this.amount = source["amount"];
-	        this.type = source["type"];
-	        this.categoryId = source["categoryId"];
-	        this.category = this.convertValues(source["category"], Category);
-	        this.note = source["note"];
-	        this.date = source["date"];
+	        this.name = source["name"];
+	        this.mapping = this.convertValues(source["mapping"], CSVMapping);
 	        this.createdAt = this.convertValues(source["createdAt"], null);
 	    }
 	
@@ -170,6 +425,1129 @@ export namespace model {
 		    return a;
 		}
 	}
+	export class CSVSample {
+	    source: string;
+	    encoding: string;
+	    rows: string[][];
+	
+	    static createFrom(source: any = {}) {
+	        return new CSVSample(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.source = source["source"];
+	        this.encoding = source["encoding"];
+	        this.rows = source["rows"];
+	    }
+	}
+	export class Category {
+	    id: number;
+	    name: string;
+	    icon: string;
+	    type: string;
+	    parentId: number;
+	    sortOrder: number;
+	    archived: boolean;
+	    importBatch: number;
+	    // Go type: time
+	    createdAt: any;
+	    // Go type: time
+	    deletedAt?: any;
+	    children?: Category[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Category(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.icon = source["icon"];
+	        this.type = source["type"];
+	        this.parentId = source["parentId"];
+	        this.sortOrder = source["sortOrder"];
+	        this.archived = source["archived"];
+	        this.importBatch = source["importBatch"];
+	        this.createdAt = this.convertValues(source["createdAt"], null);
+	        this.deletedAt = this.convertValues(source["deletedAt"], null);
+	        this.children = this.convertValues(source["children"], Category);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CategoryStat {
+	    categoryId: number;
+	    categoryName: string;
+	    categoryIcon: string;
+	    parentId: number;
+	    amount: Money;
+	    percentage: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CategoryStat(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.categoryId = source["categoryId"];
+	        this.categoryName = source["categoryName"];
+	        this.categoryIcon = source["categoryIcon"];
+	        this.parentId = source["parentId"];
+	        this.amount = this.convertValues(source["amount"], Money);
+	        this.percentage = source["percentage"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CategoryStatsResponse {
+	    incomeStats: CategoryStat[];
+	    expenseStats: CategoryStat[];
+	
+	    static createFrom(source: any = {}) {
+	        return new CategoryStatsResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.incomeStats = this.convertValues(source["incomeStats"], CategoryStat);
+	        this.expenseStats = this.convertValues(source["expenseStats"], CategoryStat);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Change {
+	    id: number;
+	    setId: number;
+	    action: string;
+	    entity: string;
+	    entityId: number;
+	    before: number[];
+	    after: number[];
+	    // Go type: time
+	    createdAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Change(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.setId = source["setId"];
+	        this.action = source["action"];
+	        this.entity = source["entity"];
+	        this.entityId = source["entityId"];
+	        this.before = source["before"];
+	        this.after = source["after"];
+	        this.createdAt = this.convertValues(source["createdAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ChangeSet {
+	    id: number;
+	    action: string;
+	    targetId: number;
+	    changes?: Change[];
+	    // Go type: time
+	    createdAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new ChangeSet(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.action = source["action"];
+	        this.targetId = source["targetId"];
+	        this.changes = this.convertValues(source["changes"], Change);
+	        this.createdAt = this.convertValues(source["createdAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Tag {
+	    id: number;
+	    name: string;
+	    color: string;
+	    // Go type: time
+	    createdAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Tag(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.color = source["color"];
+	        this.createdAt = this.convertValues(source["createdAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Record {
+	    id: number;
+	    amount: Money;
+	    type: string;
+	    categoryId: number;
+	    category?: Category;
+	    accountId: number;
+	    toAccountId: number;
+	    tags: Tag[];
+	    note: string;
+	    date: string;
+	    importBatch: number;
+	    // Go type: time
+	    createdAt: any;
+	    // Go type: time
+	    deletedAt?: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Record(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.amount = this.convertValues(source["amount"], Money);
+	        this.type = source["type"];
+	        this.categoryId = source["categoryId"];
+	        this.category = this.convertValues(source["category"], Category);
+	        this.accountId = source["accountId"];
+	        this.toAccountId = source["toAccountId"];
+	        this.tags = this.convertValues(source["tags"], Tag);
+	        this.note = source["note"];
+	        this.date = source["date"];
+	        this.importBatch = source["importBatch"];
+	        this.createdAt = this.convertValues(source["createdAt"], null);
+	        this.deletedAt = this.convertValues(source["deletedAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class DuplicateGroup {
+	    fingerprint: string;
+	    records: Record[];
+	
+	    static createFrom(source: any = {}) {
+	        return new DuplicateGroup(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.fingerprint = source["fingerprint"];
+	        this.records = this.convertValues(source["records"], Record);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ExchangeRate {
+	    id: number;
+	    from: string;
+	    to: string;
+	    date: string;
+	    rate: string;
+	    // Go type: time
+	    createdAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new ExchangeRate(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.from = source["from"];
+	        this.to = source["to"];
+	        this.date = source["date"];
+	        this.rate = source["rate"];
+	        this.createdAt = this.convertValues(source["createdAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ImportBatch {
+	    id: number;
+	    source: string;
+	    records: number;
+	    categories: number;
+	    tags: number;
+	    // Go type: time
+	    createdAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportBatch(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.source = source["source"];
+	        this.records = source["records"];
+	        this.categories = source["categories"];
+	        this.tags = source["tags"];
+	        this.createdAt = this.convertValues(source["createdAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ImportCategory {
+	    name: string;
+	    icon: string;
+	    type: string;
+	    parent?: string;
+	    archived?: boolean;
+	    fromFile: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportCategory(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.icon = source["icon"];
+	        this.type = source["type"];
+	        this.parent = source["parent"];
+	        this.archived = source["archived"];
+	        this.fromFile = source["fromFile"];
+	    }
+	}
+	export class ImportIssue {
+	    field: string;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportIssue(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.field = source["field"];
+	        this.message = source["message"];
+	    }
+	}
+	export class ImportTag {
+	    name: string;
+	    color: string;
+	    fromFile: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportTag(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.color = source["color"];
+	        this.fromFile = source["fromFile"];
+	    }
+	}
+	export class ImportRow {
+	    line: number;
+	    date: string;
+	    type: string;
+	    category: string;
+	    amount: string;
+	    currency: string;
+	    note: string;
+	    tags: string[];
+	    duplicate: boolean;
+	    errors: ImportIssue[];
+	    warnings: ImportIssue[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportRow(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.line = source["line"];
+	        this.date = source["date"];
+	        this.type = source["type"];
+	        this.category = source["category"];
+	        this.amount = source["amount"];
+	        this.currency = source["currency"];
+	        this.note = source["note"];
+	        this.tags = source["tags"];
+	        this.duplicate = source["duplicate"];
+	        this.errors = this.convertValues(source["errors"], ImportIssue);
+	        this.warnings = this.convertValues(source["warnings"], ImportIssue);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ImportPreview {
+	    source: string;
+	    rows: ImportRow[];
+	    newCategories: ImportCategory[];
+	    newTags: ImportTag[];
+	    valid: number;
+	    invalid: number;
+	    duplicates: number;
+	    duplicatePolicy: string;
+	    ignored: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportPreview(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.source = source["source"];
+	        this.rows = this.convertValues(source["rows"], ImportRow);
+	        this.newCategories = this.convertValues(source["newCategories"], ImportCategory);
+	        this.newTags = this.convertValues(source["newTags"], ImportTag);
+	        this.valid = source["valid"];
+	        this.invalid = source["invalid"];
+	        this.duplicates = source["duplicates"];
+	        this.duplicatePolicy = source["duplicatePolicy"];
+	        this.ignored = source["ignored"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ImportResult {
+	    batchId: number;
+	    imported: number;
+	    skipped: number;
+	    duplicates: number;
+	    categoriesCreated: number;
+	    tagsCreated: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.batchId = source["batchId"];
+	        this.imported = source["imported"];
+	        this.skipped = source["skipped"];
+	        this.duplicates = source["duplicates"];
+	        this.categoriesCreated = source["categoriesCreated"];
+	        this.tagsCreated = source["tagsCreated"];
+	    }
+	}
+	
+	
+	export class ImportUndoResult {
+	    records: number;
+	    categories: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportUndoResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.records = source["records"];
+	        this.categories = source["categories"];
+	    }
+	}
+	export class LockSettings {
+	    enabled: boolean;
+	    idleMinutes: number;
+	    lockOnMinimize: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new LockSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.idleMinutes = source["idleMinutes"];
+	        this.lockOnMinimize = source["lockOnMinimize"];
+	    }
+	}
+	
+	export class MonthSummary {
+	    totalIncome: Money;
+	    totalExpense: Money;
+	    balance: Money;
+	
+	    static createFrom(source: any = {}) {
+	        return new MonthSummary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.totalIncome = this.convertValues(source["totalIncome"], Money);
+	        this.totalExpense = this.convertValues(source["totalExpense"], Money);
+	        this.balance = this.convertValues(source["balance"], Money);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class MonthTrend {
+	    month: string;
+	    income: Money;
+	    expense: Money;
+	
+	    static createFrom(source: any = {}) {
+	        return new MonthTrend(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.month = source["month"];
+	        this.income = this.convertValues(source["income"], Money);
+	        this.expense = this.convertValues(source["expense"], Money);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PurgeResult {
+	    records: number;
+	    categories: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new PurgeResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.records = source["records"];
+	        this.categories = source["categories"];
+	    }
+	}
+	
+	export class RecordChange {
+	    setId: number;
+	    action: string;
+	    before?: Record;
+	    after?: Record;
+	    // Go type: time
+	    createdAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new RecordChange(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.setId = source["setId"];
+	        this.action = source["action"];
+	        this.before = this.convertValues(source["before"], Record);
+	        this.after = this.convertValues(source["after"], Record);
+	        this.createdAt = this.convertValues(source["createdAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RecordFilter {
+	    startDate: string;
+	    endDate: string;
+	    types: string[];
+	    categoryIds: number[];
+	    tagIds: number[];
+	    matchAllTags: boolean;
+	    minAmount: number;
+	    maxAmount: number;
+	    note: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RecordFilter(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.startDate = source["startDate"];
+	        this.endDate = source["endDate"];
+	        this.types = source["types"];
+	        this.categoryIds = source["categoryIds"];
+	        this.tagIds = source["tagIds"];
+	        this.matchAllTags = source["matchAllTags"];
+	        this.minAmount = source["minAmount"];
+	        this.maxAmount = source["maxAmount"];
+	        this.note = source["note"];
+	    }
+	}
+	export class RecordPage {
+	    records: Record[];
+	    nextCursor: string;
+	    total: number;
+	    summary: MonthSummary;
+	
+	    static createFrom(source: any = {}) {
+	        return new RecordPage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.records = this.convertValues(source["records"], Record);
+	        this.nextCursor = source["nextCursor"];
+	        this.total = source["total"];
+	        this.summary = this.convertValues(source["summary"], MonthSummary);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RecordQuery {
+	    filter: RecordFilter;
+	    sortBy: string;
+	    desc: boolean;
+	    cursor: string;
+	    limit: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new RecordQuery(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.filter = this.convertValues(source["filter"], RecordFilter);
+	        this.sortBy = source["sortBy"];
+	        this.desc = source["desc"];
+	        this.cursor = source["cursor"];
+	        this.limit = source["limit"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RecurringRule {
+	    id: number;
+	    name: string;
+	    frequency: string;
+	    interval: number;
+	    dayOfMonth: number;
+	    startDate: string;
+	    endDate: string;
+	    maxCount: number;
+	    skipWeekends: boolean;
+	    amount: Money;
+	    type: string;
+	    categoryId: number;
+	    accountId: number;
+	    note: string;
+	    active: boolean;
+	    // Go type: time
+	    createdAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new RecurringRule(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.frequency = source["frequency"];
+	        this.interval = source["interval"];
+	        this.dayOfMonth = source["dayOfMonth"];
+	        this.startDate = source["startDate"];
+	        this.endDate = source["endDate"];
+	        this.maxCount = source["maxCount"];
+	        this.skipWeekends = source["skipWeekends"];
+	        this.amount = this.convertValues(source["amount"], Money);
+	        this.type = source["type"];
+	        this.categoryId = source["categoryId"];
+	        this.accountId = source["accountId"];
+	        this.note = source["note"];
+	        this.active = source["active"];
+	        this.createdAt = this.convertValues(source["createdAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SearchResult {
+	    record: Record;
+	    snippet: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SearchResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.record = this.convertValues(source["record"], Record);
+	        this.snippet = source["snippet"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class TagStat {
+	    tagId: number;
+	    tagName: string;
+	    tagColor: string;
+	    amount: Money;
+	    percentage: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TagStat(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.tagId = source["tagId"];
+	        this.tagName = source["tagName"];
+	        this.tagColor = source["tagColor"];
+	        this.amount = this.convertValues(source["amount"], Money);
+	        this.percentage = source["percentage"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TagStatsResponse {
+	    incomeStats: TagStat[];
+	    expenseStats: TagStat[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TagStatsResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.incomeStats = this.convertValues(source["incomeStats"], TagStat);
+	        this.expenseStats = this.convertValues(source["expenseStats"], TagStat);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Trash {
+	    records: Record[];
+	    categories: Category[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Trash(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.records = this.convertValues(source["records"], Record);
+	        this.categories = this.convertValues(source["categories"], Category);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class UpcomingOccurrence {
+	    ruleId: number;
+	    ruleName: string;
+	    date: string;
+	    status: string;
+	    amount: Money;
+	    type: string;
+	    categoryId: number;
+	    accountId: number;
+	    note: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new UpcomingOccurrence(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ruleId = source["ruleId"];
+	        this.ruleName = source["ruleName"];
+	        this.date = source["date"];
+	        this.status = source["status"];
+	        this.amount = this.convertValues(source["amount"], Money);
+	        this.type = source["type"];
+	        this.categoryId = source["categoryId"];
+	        this.accountId = source["accountId"];
+	        this.note = source["note"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace service {
+	
+	export class Period {
+	    year: number;
+	    month: number;
+	    start: string;
+	    end: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Period(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.year = source["year"];
+	        this.month = source["month"];
+	        this.start = source["start"];
+	        this.end = source["end"];
+	    }
+	}
 
 }
 
@@ -0,0 +1,192 @@
+// Package backup 管理账本的备份目录：备份文件命名、列出、按策略轮换以及恢复前的复制。
+// 备份文件本身由仓库生成（SQLite 的 VACUUM INTO），本包只处理文件
+package backup
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	apperrors "dog-view/internal/errors"
+	"dog-view/internal/model"
+)
+
+// 备份文件名为创建时间（本地时间），同一秒内的多份备份追加 -1、-2 等序号
+const (
+	nameLayout = "20060102-150405"
+	fileExt    = ".db"
+)
+
+// Store 一个账本的备份目录
+type Store struct {
+	dir string
+}
+
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// NewPath 返回一个尚未使用的备份文件路径，目录不存在时自动创建
+func (s *Store) NewPath(now time.Time) (string, error) {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return "", err
+	}
+
+	base := now.Format(nameLayout)
+	for i := 0; ; i++ {
+		name := base
+		if i > 0 {
+			name = fmt.Sprintf("%s-%d", base, i)
+		}
+		path := filepath.Join(s.dir, name+fileExt)
+		_, err := os.Stat(path)
+		if os.IsNotExist(err) {
+			return path, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+// parseName 从备份文件名解析创建时间，不是备份文件时返回 false
+func parseName(name string) (time.Time, bool) {
+	base := strings.TrimSuffix(name, fileExt)
+	if base == name || len(base) < len(nameLayout) {
+		return time.Time{}, false
+	}
+	if seq := base[len(nameLayout):]; seq != "" {
+		if n, err := strconv.Atoi(strings.TrimPrefix(seq, "-")); err != nil || n <= 0 || seq[0] != '-' {
+			return time.Time{}, false
+		}
+	}
+	t, err := time.ParseInLocation(nameLayout, base[:len(nameLayout)], time.Local)
+	return t, err == nil
+}
+
+// List 返回全部备份，最新的在前；目录不存在时返回空列表
+func (s *Store) List() ([]model.Backup, error) {
+	entries, err := os.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return []model.Backup{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	backups := []model.Backup{}
+	for _, e := range entries {
+		createdAt, ok := parseName(e.Name())
+		if !ok || !e.Type().IsRegular() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+		backups = append(backups, model.Backup{Name: e.Name(), Size: info.Size(), CreatedAt: createdAt})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		a, b := backups[i], backups[j]
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		// 同一秒内序号大的较新，序号位数不同时按长度比较
+		return len(a.Name) > len(b.Name) || len(a.Name) == len(b.Name) && a.Name > b.Name
+	})
+	return backups, nil
+}
+
+// Path 返回备份文件的路径，name 必须是 List 返回的文件名
+func (s *Store) Path(name string) (string, error) {
+	if _, ok := parseName(name); !ok || filepath.Base(name) != name {
+		return "", apperrors.ErrBackupNotFound
+	}
+	path := filepath.Join(s.dir, name)
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) || err == nil && !info.Mode().IsRegular() {
+		return "", apperrors.ErrBackupNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// Rotate 按策略删除多余的备份，返回删除的份数。
+// 按天、周（ISO 周）、月分别保留最近若干个周期中最新的一份，最新的备份始终保留
+func (s *Store) Rotate(policy model.BackupPolicy) (int, error) {
+	backups, err := s.List()
+	if err != nil || len(backups) == 0 {
+		return 0, err
+	}
+
+	keep := map[string]bool{backups[0].Name: true}
+	keepPeriods := func(n int, period func(t time.Time) string) {
+		seen := make(map[string]bool)
+		for _, b := range backups {
+			if len(seen) >= n {
+				return
+			}
+			p := period(b.CreatedAt)
+			if !seen[p] {
+				seen[p] = true
+				keep[b.Name] = true
+			}
+		}
+	}
+	keepPeriods(policy.KeepDaily, func(t time.Time) string { return t.Format("2006-01-02") })
+	keepPeriods(policy.KeepWeekly, func(t time.Time) string {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	})
+	keepPeriods(policy.KeepMonthly, func(t time.Time) string { return t.Format("2006-01") })
+
+	removed := 0
+	for _, b := range backups {
+		if keep[b.Name] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.dir, b.Name)); err != nil && !os.IsNotExist(err) {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// CopyTo 将备份复制到 dst 并同步到磁盘，用于恢复前把备份放到账本文件旁边
+func (s *Store) CopyTo(name, dst string) error {
+	src, err := s.Path(name)
+	if err != nil {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
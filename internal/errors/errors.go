@@ -3,11 +3,73 @@ package errors
 import "errors"
 
 var (
-	ErrCategoryNotFound  = errors.New("分类不存在")
-	ErrCategoryInUse     = errors.New("分类正在使用中，无法删除")
-	ErrRecordNotFound    = errors.New("记录不存在")
-	ErrInvalidAmount     = errors.New("金额无效")
-	ErrInvalidDate       = errors.New("日期格式错误")
-	ErrImportFailed      = errors.New("导入失败")
-	ErrDuplicateCategory = errors.New("分类名称已存在")
+	ErrCategoryNotFound     = errors.New("分类不存在")
+	ErrCategoryInUse        = errors.New("分类正在使用中，无法删除")
+	ErrRecordNotFound       = errors.New("记录不存在")
+	ErrInvalidAmount        = errors.New("金额无效")
+	ErrInvalidDate          = errors.New("日期格式错误")
+	ErrImportFailed         = errors.New("导入失败")
+	ErrDuplicateCategory    = errors.New("分类名称已存在")
+	ErrMigrationFailed      = errors.New("数据库迁移失败")
+	ErrInvalidMonthStartDay = errors.New("每月起始日需在 1 到 28 之间")
+	ErrLedgerNotFound       = errors.New("账本不存在")
+	ErrLedgerInUse          = errors.New("不能删除当前正在使用的账本")
+	ErrInvalidLedgerName    = errors.New("账本名称不能为空")
+	ErrDuplicateLedger      = errors.New("账本名称已存在")
+	ErrAccountNotFound      = errors.New("账户不存在")
+	ErrAccountInUse         = errors.New("账户正在使用中，无法删除")
+	ErrDuplicateAccount     = errors.New("账户名称已存在")
+	ErrInvalidTransfer      = errors.New("转出和转入账户不能相同")
+	ErrInvalidRecordType    = errors.New("记录类型无效")
+	ErrInvalidAccountName   = errors.New("账户名称不能为空")
+	ErrInvalidAccountType   = errors.New("账户类型无效")
+	ErrInvalidCurrency      = errors.New("币种代码无效")
+	ErrCurrencyMismatch     = errors.New("记录币种与账户币种不一致")
+	ErrInvalidExchangeRate  = errors.New("汇率无效")
+	ErrExchangeRateNotFound = errors.New("缺少汇率")
+	ErrBudgetNotFound       = errors.New("预算不存在")
+	ErrBudgetInherited      = errors.New("该预算沿用自之前的月份，请在设置它的月份删除，或为本月设置新的预算")
+	ErrRuleNotFound         = errors.New("周期规则不存在")
+	ErrInvalidRule          = errors.New("周期规则无效")
+	ErrNotScheduled         = errors.New("该日期不是周期规则的发生日")
+	ErrOccurrencePosted     = errors.New("该次周期记账已入账")
+	ErrTagNotFound          = errors.New("标签不存在")
+	ErrDuplicateTag         = errors.New("标签名称已存在")
+	ErrInvalidTagName       = errors.New("标签名称不能为空")
+	ErrInvalidParent        = errors.New("上级分类无效")
+	ErrCategoryHasChildren  = errors.New("分类下还有子分类，无法删除")
+	ErrInvalidMerge         = errors.New("只能将分类合并到同类型的其他分类")
+	ErrInvalidSortField     = errors.New("排序字段无效")
+	ErrInvalidCursor        = errors.New("分页游标无效")
+	ErrInvalidRetention     = errors.New("回收站保留天数需在 0 到 3650 之间")
+	ErrChangeSetNotFound    = errors.New("变更记录不存在")
+	ErrNothingToUndo        = errors.New("没有可撤销的操作")
+	ErrNothingToRedo        = errors.New("没有可重做的操作")
+	ErrUndoConflict         = errors.New("数据已被其他操作修改，无法撤销或重做")
+	ErrBackupNotFound       = errors.New("备份不存在")
+	ErrInvalidBackupPolicy  = errors.New("备份间隔需在 0 到 720 小时之间，保留份数需在 0 到 100 之间")
+	ErrLedgerLocked         = errors.New("账本已加密，请先输入密码解锁")
+	ErrWrongPassphrase      = errors.New("密码错误")
+	ErrWeakPassphrase       = errors.New("密码至少需要 8 个字符")
+	ErrNotEncrypted         = errors.New("账本未加密")
+	ErrAlreadyEncrypted     = errors.New("账本已加密")
+	ErrNotDatabase          = errors.New("不是有效的数据库文件")
+	ErrEncryptionMismatch   = errors.New("备份与当前账本的加密状态不一致，无法恢复")
+	ErrAppLocked            = errors.New("应用已锁定，请先输入 PIN 解锁")
+	ErrWrongPIN             = errors.New("PIN 错误")
+	ErrInvalidPIN           = errors.New("PIN 需为 4 到 64 个字符")
+	ErrNoPIN                = errors.New("尚未设置 PIN")
+	ErrTooManyAttempts      = errors.New("PIN 错误次数过多，请稍后再试")
+	ErrInvalidIdleMinutes   = errors.New("自动锁定时间需在 0 到 1440 分钟之间")
+	ErrCategoryRequired     = errors.New("分类不能为空")
+	ErrCategoryTypeMismatch = errors.New("分类类型与记录类型不一致")
+	ErrCategoryArchived     = errors.New("分类已归档")
+	ErrTransferImport       = errors.New("不支持导入转账记录")
+	ErrImportBatchNotFound  = errors.New("导入批次不存在")
+	ErrInvalidDupPolicy     = errors.New("重复记录的处理方式无效")
+	ErrUnknownBillFormat    = errors.New("无法识别的账单格式，目前支持支付宝和微信支付导出的账单")
+	ErrProfileNotFound      = errors.New("列映射方案不存在")
+	ErrDuplicateProfile     = errors.New("列映射方案名称已存在")
+	ErrInvalidProfileName   = errors.New("列映射方案名称不能为空")
+	ErrInvalidCSVMapping    = errors.New("列映射不完整或无效")
 )
@@ -0,0 +1,302 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	apperrors "dog-view/internal/errors"
+	"dog-view/internal/model"
+)
+
+// 支持的账单来源
+const (
+	BillAlipay = "alipay" // 支付宝
+	BillWeChat = "wechat" // 微信支付
+)
+
+// Bill 从支付平台导出的账单中读取的交易
+type Bill struct {
+	Source  string      // BillAlipay 或 BillWeChat
+	Records []CSVRecord // 待导入的收支，交给调用方逐行校验
+	Ignored int         // 未成功、已全额退款或不计收支的交易笔数，不导入
+}
+
+// 账单中用到的字段，各平台、各版本的表头不同
+const (
+	billTime = iota
+	billCategory
+	billCounterparty
+	billGoods
+	billDirection
+	billAmount
+	billMethod
+	billStatus
+	billRefund
+	billNote
+	billFieldCount
+)
+
+// billFormat 一种账单格式：每个字段可能的表头，按优先级排列，取第一个非空的值
+type billFormat struct {
+	source  string
+	headers [billFieldCount][]string
+}
+
+var billFormats = []billFormat{
+	{
+		// 新版账单：交易时间,交易分类,交易对方,对方账号,商品说明,收/支,金额,收/付款方式,交易状态,...
+		// 旧版账单：交易号,...,交易创建时间,付款时间,...,交易对方,商品名称,金额（元）,收/支,交易状态,服务费（元）,成功退款（元）,备注,...
+		source: BillAlipay,
+		headers: [billFieldCount][]string{
+			billTime:         {"交易时间", "付款时间", "交易创建时间"},
+			billCategory:     {"交易分类"},
+			billCounterparty: {"交易对方"},
+			billGoods:        {"商品说明", "商品名称"},
+			billDirection:    {"收/支"},
+			billAmount:       {"金额", "金额（元）"},
+			billMethod:       {"收/付款方式"},
+			billStatus:       {"交易状态"},
+			billRefund:       {"成功退款（元）"},
+			billNote:         {"备注"},
+		},
+	},
+	{
+		// 交易时间,交易类型,交易对方,商品,收/支,金额(元),支付方式,当前状态,交易单号,商户单号,备注
+		source: BillWeChat,
+		headers: [billFieldCount][]string{
+			billTime:         {"交易时间"},
+			billCounterparty: {"交易对方"},
+			billGoods:        {"商品"},
+			billDirection:    {"收/支"},
+			billAmount:       {"金额(元)", "金额（元）"},
+			billMethod:       {"支付方式"},
+			billStatus:       {"当前状态"},
+			billNote:         {"备注"},
+		},
+	},
+}
+
+// billRequired 识别账单格式时必须存在的字段
+var billRequired = []int{billTime, billDirection, billAmount, billStatus}
+
+// billSkipStatuses 交易状态包含这些内容时不导入：交易未完成、失败，或已全额退款
+var billSkipStatuses = []string{"关闭", "失败", "全额退款", "退款成功", "退还", "等待付款", "未支付"}
+
+// billDateLayouts 账单中的交易时间格式
+var billDateLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006/1/2 15:04:05",
+	"2006/1/2 15:04",
+	"2006-01-02",
+	"2006/1/2",
+}
+
+// billRow 账单中的一行及其在文件中的行号
+type billRow struct {
+	line  int
+	cells []string
+}
+
+// ImportBill 读取支付宝或微信支付导出的账单（CSV 或 XLSX，CSV 可为 GBK 编码），
+// 按表头自动识别格式。交易对方、商品和收付款方式写入备注，无法识别时返回 ErrUnknownBillFormat
+func ImportBill(filePath string) (*Bill, error) {
+	rows, err := readBillRows(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, row := range rows {
+		for _, format := range billFormats {
+			columns, ok := format.match(row.cells)
+			if !ok {
+				continue
+			}
+			bill := &Bill{Source: format.source}
+			for _, r := range rows[i+1:] {
+				if len(r.cells) > 0 && strings.HasPrefix(strings.TrimSpace(r.cells[0]), "---") {
+					break // 旧版支付宝账单末尾的汇总信息
+				}
+				rec, ok := parseBillRow(r, columns)
+				switch {
+				case rec != nil:
+					bill.Records = append(bill.Records, *rec)
+				case ok:
+					bill.Ignored++
+				}
+			}
+			return bill, nil
+		}
+	}
+	return nil, apperrors.ErrUnknownBillFormat
+}
+
+// readBillRows 读取账单的全部行，XLSX 按扩展名识别
+func readBillRows(filePath string) ([]billRow, error) {
+	if strings.EqualFold(filepath.Ext(filePath), ".xlsx") {
+		cells, err := readXLSX(filePath)
+		if err != nil {
+			return nil, err
+		}
+		rows := make([]billRow, len(cells))
+		for i, c := range cells {
+			rows[i] = billRow{line: i + 1, cells: c}
+		}
+		return rows, nil
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	data, err = decodeText(data, model.EncodingAuto)
+	if err != nil {
+		return nil, err
+	}
+
+	// 表头之前的说明文字列数不定，引号也不总是成对
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	var rows []billRow
+	for {
+		cells, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, billRow{line: line, cells: cells})
+	}
+	return rows, nil
+}
+
+// match 判断该行是否为本格式的表头，返回各字段对应的列号（按优先级排列）
+func (f billFormat) match(header []string) ([billFieldCount][]int, bool) {
+	index := make(map[string]int, len(header))
+	for i, h := range header {
+		if _, ok := index[strings.TrimSpace(h)]; !ok {
+			index[strings.TrimSpace(h)] = i
+		}
+	}
+
+	var columns [billFieldCount][]int
+	for field, names := range f.headers {
+		for _, name := range names {
+			if i, ok := index[name]; ok {
+				columns[field] = append(columns[field], i)
+			}
+		}
+	}
+	for _, field := range billRequired {
+		if len(columns[field]) == 0 {
+			return columns, false
+		}
+	}
+	return columns, true
+}
+
+// parseBillRow 将账单中的一行转换为导入记录。空行返回 nil, false；
+// 按状态或收支方向不导入的交易返回 nil, true。金额和日期无法解析时保留原文，由调用方报告
+func parseBillRow(row billRow, columns [billFieldCount][]int) (*CSVRecord, bool) {
+	value := func(field int) string {
+		for _, i := range columns[field] {
+			if i < len(row.cells) {
+				// 旧版支付宝账单的值带有补齐用的空格和制表符，微信账单用 "/" 表示空值
+				if v := strings.TrimSpace(row.cells[i]); v != "" && v != "/" {
+					return v
+				}
+			}
+		}
+		return ""
+	}
+
+	amount, status := value(billAmount), value(billStatus)
+	if amount == "" && status == "" {
+		return nil, false
+	}
+	for _, s := range billSkipStatuses {
+		if strings.Contains(status, s) {
+			return nil, true
+		}
+	}
+
+	rec := &CSVRecord{
+		Line:     row.line,
+		Date:     billDate(value(billTime)),
+		Amount:   cleanBillAmount(amount),
+		Currency: model.DefaultCurrency,
+	}
+	switch value(billDirection) {
+	case "支出":
+		rec.Type = model.TypeExpense
+		rec.Category = value(billCategory)
+		if rec.Category == "" {
+			rec.Category = DefaultExpenseCategory
+		}
+	case "收入":
+		// 支付宝的交易分类按消费划分，收入统一归入默认分类
+		rec.Type = model.TypeIncome
+		rec.Category = DefaultIncomeCategory
+	default: // 不计收支：转账到自己的账户、理财申购赎回等
+		return nil, true
+	}
+
+	// 部分退款：旧版支付宝账单有退款金额列，微信账单写在状态中，如 "已退款(￥5.00)"
+	refund := value(billRefund)
+	if refund == "" {
+		if _, after, ok := strings.Cut(status, "已退款"); ok {
+			refund = strings.Trim(after, "()（）")
+		}
+	}
+	if refund != "" {
+		paid, err1 := model.ParseMoney(rec.Amount, rec.Currency)
+		back, err2 := model.ParseMoney(cleanBillAmount(refund), rec.Currency)
+		if err1 == nil && err2 == nil {
+			left := paid.Sub(back)
+			if left.Minor <= 0 {
+				return nil, true
+			}
+			rec.Amount = left.String()
+		}
+	}
+
+	var parts []string
+	for _, field := range []int{billCounterparty, billGoods, billMethod, billNote} {
+		v := value(field)
+		if v == "" || len(parts) > 0 && parts[len(parts)-1] == v {
+			continue
+		}
+		parts = append(parts, v)
+	}
+	rec.Note = strings.Join(parts, " · ")
+	return rec, true
+}
+
+// cleanBillAmount 去掉金额中的货币符号
+func cleanBillAmount(s string) string {
+	s = strings.NewReplacer("¥", "", "￥", "", "元", "").Replace(s)
+	return strings.TrimSpace(s)
+}
+
+// billDate 将交易时间转换为日期。XLSX 中的日期单元格可能是 Excel 序列号；无法解析时返回原文
+func billDate(s string) string {
+	for _, layout := range billDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.Format("2006-01-02")
+		}
+	}
+	if days, err := strconv.ParseFloat(s, 64); err == nil && days > 0 {
+		return time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC).AddDate(0, 0, int(days)).Format("2006-01-02")
+	}
+	return s
+}
@@ -0,0 +1,138 @@
+package export
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+func writeBillFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// writeXLSX 生成只有一个工作表的最小 XLSX 文件，sheetData 为工作表的行
+func writeXLSX(t *testing.T, sharedStrings []string, sheetData string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "bill.xlsx")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var sst strings.Builder
+	for _, s := range sharedStrings {
+		sst.WriteString("<si><t>" + s + "</t></si>")
+	}
+	zw := zip.NewWriter(f)
+	for name, body := range map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+			`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="s" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="x" Target="worksheets/sheet1.xml"/></Relationships>`,
+		"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` + sst.String() + `</sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+			`<sheetData>` + sheetData + `</sheetData></worksheet>`,
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestImportBillAlipay(t *testing.T) {
+	content := "导出信息：\n姓名：张三\n" +
+		"----------------------支付宝（中国）网络技术有限公司  电子客户回单------------------------\n" +
+		"交易时间,交易分类,交易对方,对方账号,商品说明,收/支,金额,收/付款方式,交易状态,交易订单号,商家订单号,备注,\n" +
+		"2024-01-02 12:00:00,餐饮美食,某某餐厅,xxx,午餐,支出,12.50,余额宝,交易成功,1,2,,\n" +
+		"2024-01-03 12:00:00,日用百货,超市,xxx,纸巾,支出,5.00,花呗,交易关闭,1,2,,\n" +
+		"2024-01-04 12:00:00,转账红包,李四,xxx,转账,收入,100.00,,交易成功,1,2,,\n" +
+		"2024-01-05 12:00:00,投资理财,余额宝,xxx,转入,不计收支,100.00,,交易成功,1,2,,\n"
+	gbk, err := simplifiedchinese.GBK.NewEncoder().String(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bill, err := ImportBill(writeBillFile(t, "alipay.csv", []byte(gbk)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 交易关闭和不计收支的交易不导入
+	if bill.Source != BillAlipay || len(bill.Records) != 2 || bill.Ignored != 2 {
+		t.Fatalf("ImportBill() = %+v", bill)
+	}
+	r := bill.Records[0]
+	if r.Line != 5 || r.Date != "2024-01-02" || r.Category != "餐饮美食" || r.Amount != "12.50" || r.Note != "某某餐厅 · 午餐 · 余额宝" {
+		t.Fatalf("第一笔交易为 %+v", r)
+	}
+}
+
+func TestImportBillWeChatRefunds(t *testing.T) {
+	content := "\ufeff微信支付账单明细,,,,,,,,\n微信昵称：[x],,,,,,,,\n" +
+		"----------------------微信支付账单明细列表--------------------,,,,,,,,\n" +
+		"交易时间,交易类型,交易对方,商品,收/支,金额(元),支付方式,当前状态,交易单号,商户单号,备注\n" +
+		"2024-01-02 12:00:00,商户消费,某店,咖啡,支出,¥30.00,零钱,已退款(￥10.00),1,2,/\n" +
+		"2024-01-03 12:00:00,商户消费,某店,奶茶,支出,¥15.00,零钱,已全额退款,1,2,/\n" +
+		"2024-01-03 13:00:00,商户消费-退款,某店,/,收入,¥15.00,零钱,已全额退款,1,2,/\n" +
+		"2024-01-04 12:00:00,微信红包,朋友,/,收入,¥8.88,/,已存入零钱,1,2,/\n"
+
+	bill, err := ImportBill(writeBillFile(t, "wechat.csv", []byte(content)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 部分退款按实付金额导入，全额退款的消费及其退款都不导入
+	if bill.Source != BillWeChat || len(bill.Records) != 2 || bill.Ignored != 2 {
+		t.Fatalf("ImportBill() = %+v", bill)
+	}
+	if bill.Records[0].Amount != "20.00" || bill.Records[1].Amount != "8.88" || bill.Records[1].Note != "朋友" {
+		t.Fatalf("导入的交易为 %+v", bill.Records)
+	}
+}
+
+func TestImportBillXLSX(t *testing.T) {
+	shared := []string{"交易时间", "收/支", "金额(元)", "当前状态", "支出", "支付成功"}
+	path := writeXLSX(t, shared,
+		`<row r="1"><c r="A1" t="inlineStr"><is><t>微信支付账单明细</t></is></c></row>`+
+			`<row r="2"><c r="A2" t="s"><v>0</v></c><c r="C2" t="s"><v>1</v></c><c r="D2" t="s"><v>2</v></c><c r="E2" t="s"><v>3</v></c></row>`+
+			`<row r="3"><c r="A3"><v>45293.5</v></c><c r="C3" t="s"><v>4</v></c><c r="D3"><v>12.3</v></c><c r="E3" t="s"><v>5</v></c></row>`)
+
+	bill, err := ImportBill(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 日期为 Excel 序列号，跳过的 B 列为空
+	if len(bill.Records) != 1 || bill.Records[0].Date != "2024-01-02" || bill.Records[0].Amount != "12.3" || bill.Records[0].Line != 3 {
+		t.Fatalf("ImportBill() = %+v", bill)
+	}
+
+	for _, ref := range []string{"a1", "1", "A", "A1B", "ZZZZ1"} {
+		path := writeXLSX(t, nil, `<row r="1"><c r="`+ref+`" t="inlineStr"><is><t>x</t></is></c></row>`)
+		if _, err := ImportBill(path); err == nil || !strings.Contains(err.Error(), ref) {
+			t.Fatalf("单元格引用 %q: ImportBill() = %v，期望引用无效", ref, err)
+		}
+	}
+}
+
+func TestImportBillUnknownFormat(t *testing.T) {
+	if _, err := ImportBill(writeBillFile(t, "other.csv", []byte("a,b,c\n1,2,3\n"))); err == nil {
+		t.Fatal("ImportBill() 未识别的格式应返回错误")
+	}
+}
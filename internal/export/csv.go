@@ -1,10 +1,12 @@
 package export
 
 import (
+	"bytes"
 	"encoding/csv"
 	"fmt"
+	"io"
 	"os"
-	"strconv"
+	"strings"
 
 	"dog-view/internal/model"
 )
@@ -24,7 +26,7 @@ func ExportCSV(records []model.Record, filePath string) error {
 	defer writer.Flush()
 
 	// 写入表头
-	header := []string{"date", "type", "category", "amount", "note"}
+	header := []string{"date", "type", "category", "amount", "note", "currency", "tags"}
 	if err := writer.Write(header); err != nil {
 		return err
 	}
@@ -39,8 +41,10 @@ func ExportCSV(records []model.Record, filePath string) error {
 			r.Date,
 			r.Type,
 			categoryName,
-			fmt.Sprintf("%.2f", r.Amount),
+			r.Amount.String(),
 			r.Note,
+			r.Amount.Currency,
+			strings.Join(TagNames(r.Tags), TagSeparator),
 		}
 		if err := writer.Write(row); err != nil {
 			return err
@@ -50,57 +54,97 @@ func ExportCSV(records []model.Record, filePath string) error {
 	return nil
 }
 
-// CSVRecord CSV 导入记录结构
+// CSVRecord CSV 导入记录结构，保留原始值，由调用方逐行校验
 type CSVRecord struct {
+	Line     int // 文件中的行号
 	Date     string
 	Type     string
 	Category string
-	Amount   float64
+	Amount   string
+	Currency string
 	Note     string
+	Tags     []string
 }
 
-// ImportCSV 从 CSV 导入记录
+// 导入的数据没有分类时，按收支类型归入的分类
+const (
+	DefaultExpenseCategory = "其他支出"
+	DefaultIncomeCategory  = "其他收入"
+)
+
+// TagSeparator CSV 中多个标签之间的分隔符
+const TagSeparator = "|"
+
+// TagNames 提取标签名称
+func TagNames(tags []model.Tag) []string {
+	names := make([]string, 0, len(tags))
+	for _, t := range tags {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+// splitTags 拆分 CSV 中的标签列，忽略空白项
+func splitTags(s string) []string {
+	var tags []string
+	for _, name := range strings.Split(s, TagSeparator) {
+		if name = strings.TrimSpace(name); name != "" {
+			tags = append(tags, name)
+		}
+	}
+	return tags
+}
+
+// ImportCSV 从 CSV 读取记录，列数不足的行缺少的字段为空，交由调用方报告。
+// 文件可以是 UTF-8（带或不带 BOM）或 GBK 编码
 func ImportCSV(filePath string) ([]CSVRecord, error) {
-	file, err := os.Open(filePath)
+	data, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
-
-	reader := csv.NewReader(file)
-	rows, err := reader.ReadAll()
+	data, err = decodeText(data, model.EncodingAuto)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(rows) < 2 {
-		return nil, fmt.Errorf("CSV 文件为空或只有表头")
-	}
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
 
 	var records []CSVRecord
-	for i, row := range rows[1:] { // 跳过表头
-		if len(row) < 4 {
-			return nil, fmt.Errorf("第 %d 行数据不完整", i+2)
+	for header := true; ; header = false {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
 		}
-
-		amount, err := strconv.ParseFloat(row[3], 64)
 		if err != nil {
-			return nil, fmt.Errorf("第 %d 行金额格式错误", i+2)
+			return nil, err
 		}
-
-		note := ""
-		if len(row) >= 5 {
-			note = row[4]
+		if header { // 跳过表头
+			continue
 		}
+		line, _ := reader.FieldPos(0)
 
+		field := func(i int) string {
+			if i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+		// 第 6 列为币种、第 7 列为标签，旧版导出文件没有这两列
 		records = append(records, CSVRecord{
-			Date:     row[0],
-			Type:     row[1],
-			Category: row[2],
-			Amount:   amount,
-			Note:     note,
+			Line:     line,
+			Date:     field(0),
+			Type:     field(1),
+			Category: field(2),
+			Amount:   field(3),
+			Currency: field(5),
+			Note:     field(4),
+			Tags:     splitTags(field(6)),
 		})
 	}
 
+	if len(records) == 0 {
+		return nil, fmt.Errorf("CSV 文件为空或只有表头")
+	}
 	return records, nil
 }
@@ -0,0 +1,38 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+
+	"dog-view/internal/model"
+)
+
+// utf8BOM Excel 等软件在 UTF-8 文本开头写入的字节序标记
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// DetectEncoding 识别文本编码：带 BOM 或本身是合法 UTF-8 时为 UTF-8，否则视为 GBK。
+// 国内银行和支付平台导出的账单多为 GBK
+func DetectEncoding(data []byte) string {
+	if bytes.HasPrefix(data, utf8BOM) || utf8.Valid(data) {
+		return model.EncodingUTF8
+	}
+	return model.EncodingGBK
+}
+
+// decodeText 按 encoding 将文本转换为 UTF-8 并去掉 BOM，encoding 为空或 EncodingAuto 时自动识别
+func decodeText(data []byte, encoding string) ([]byte, error) {
+	if encoding == "" || encoding == model.EncodingAuto {
+		encoding = DetectEncoding(data)
+	}
+	switch encoding {
+	case model.EncodingUTF8:
+		return bytes.TrimPrefix(data, utf8BOM), nil
+	case model.EncodingGBK:
+		return simplifiedchinese.GBK.NewDecoder().Bytes(data)
+	default:
+		return nil, fmt.Errorf("不支持的编码: %q", encoding)
+	}
+}
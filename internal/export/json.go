@@ -13,28 +13,39 @@ type ExportData struct {
 	ExportDate string           `json:"exportDate"`
 	Records    []ExportRecord   `json:"records"`
 	Categories []ExportCategory `json:"categories"`
+	Tags       []ExportTag      `json:"tags,omitempty"`
 }
 
 type ExportRecord struct {
-	Date     string  `json:"date"`
-	Type     string  `json:"type"`
-	Category string  `json:"category"`
-	Amount   float64 `json:"amount"`
-	Note     string  `json:"note"`
+	Date     string      `json:"date"`
+	Type     string      `json:"type"`
+	Category string      `json:"category"`
+	Amount   json.Number `json:"amount"`             // 十进制金额原文，避免浮点误差
+	Currency string      `json:"currency,omitempty"` // 旧版文件没有币种，导入时使用本位币
+	Note     string      `json:"note"`
+	Tags     []string    `json:"tags,omitempty"`
 }
 
 type ExportCategory struct {
-	Name string `json:"name"`
-	Icon string `json:"icon"`
-	Type string `json:"type"`
+	Name     string `json:"name"`
+	Icon     string `json:"icon"`
+	Type     string `json:"type"`
+	Parent   string `json:"parent,omitempty"` // 上级分类名称
+	Archived bool   `json:"archived,omitempty"`
+}
+
+type ExportTag struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
 }
 
 // ExportJSON 导出记录到 JSON
-func ExportJSON(records []model.Record, categories []model.Category, filePath string) error {
+func ExportJSON(records []model.Record, categories []model.Category, tags []model.Tag, filePath string) error {
 	data := ExportData{
 		ExportDate: time.Now().Format(time.RFC3339),
 		Records:    make([]ExportRecord, 0, len(records)),
 		Categories: make([]ExportCategory, 0, len(categories)),
+		Tags:       make([]ExportTag, 0, len(tags)),
 	}
 
 	for _, r := range records {
@@ -46,19 +57,30 @@ func ExportJSON(records []model.Record, categories []model.Category, filePath st
 			Date:     r.Date,
 			Type:     r.Type,
 			Category: categoryName,
-			Amount:   r.Amount,
+			Amount:   json.Number(r.Amount.String()),
+			Currency: r.Amount.Currency,
 			Note:     r.Note,
+			Tags:     TagNames(r.Tags),
 		})
 	}
 
+	// categories 按先序排列，上级分类总在子分类之前
+	names := make(map[int64]string, len(categories))
 	for _, c := range categories {
+		names[c.ID] = c.Name
 		data.Categories = append(data.Categories, ExportCategory{
-			Name: c.Name,
-			Icon: c.Icon,
-			Type: c.Type,
+			Name:     c.Name,
+			Icon:     c.Icon,
+			Type:     c.Type,
+			Parent:   names[c.ParentID],
+			Archived: c.Archived,
 		})
 	}
 
+	for _, t := range tags {
+		data.Tags = append(data.Tags, ExportTag{Name: t.Name, Color: t.Color})
+	}
+
 	file, err := os.Create(filePath)
 	if err != nil {
 		return err
@@ -0,0 +1,282 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"dog-view/internal/model"
+)
+
+// dateTokens 列映射中的日期格式占位符与 Go 时间格式的对应，长的占位符在前
+var dateTokens = strings.NewReplacer(
+	"YYYY", "2006",
+	"YY", "06",
+	"MM", "01",
+	"M", "1",
+	"DD", "02",
+	"D", "2",
+	"HH", "15",
+	"mm", "04",
+	"ss", "05",
+)
+
+// GoDateLayout 将列映射中的日期格式（如 "YYYY/MM/DD HH:mm"）转换为 Go 时间格式
+func GoDateLayout(pattern string) string {
+	return dateTokens.Replace(pattern)
+}
+
+// readCSVRows 按编码和分隔符读取 CSV 的全部行及其行号
+func readCSVRows(filePath, encoding, delimiter string) ([][]string, []int, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err = decodeText(data, encoding)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	if delimiter != "" {
+		reader.Comma, err = parseDelimiter(delimiter)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var rows [][]string
+	var lines []int
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, row)
+		lines = append(lines, line)
+	}
+	return rows, lines, nil
+}
+
+// parseDelimiter 分隔符为单个字符，制表符可写作 `\t`
+func parseDelimiter(s string) (rune, error) {
+	if s == `\t` {
+		return '\t', nil
+	}
+	r, size := utf8.DecodeRuneInString(s)
+	if size != len(s) || r == '"' || r == '\r' || r == '\n' || r == utf8.RuneError {
+		return 0, fmt.Errorf("分隔符无效: %q", s)
+	}
+	return r, nil
+}
+
+// PeekCSV 读取 CSV 的前 n 行，用于配置列映射；encoding 为空时自动识别，返回实际使用的编码
+func PeekCSV(filePath, encoding, delimiter string, n int) (string, [][]string, error) {
+	if encoding == "" || encoding == model.EncodingAuto {
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return "", nil, err
+		}
+		encoding = DetectEncoding(data)
+	}
+	rows, _, err := readCSVRows(filePath, encoding, delimiter)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(rows) > n {
+		rows = rows[:n]
+	}
+	return encoding, rows, nil
+}
+
+// ImportMappedCSV 按列映射读取银行流水等通用 CSV。金额按正负号约定拆分为收支和绝对值，
+// 日期按 DateLayout 转换；无法解析的值保留原文，由调用方逐行报告。映射本身的校验由调用方负责
+func ImportMappedCSV(filePath string, m model.CSVMapping) ([]CSVRecord, error) {
+	rows, lines, err := readCSVRows(filePath, m.Encoding, m.Delimiter)
+	if err != nil {
+		return nil, err
+	}
+	if m.SkipRows >= len(rows) {
+		return nil, fmt.Errorf("CSV 文件为空或只有表头")
+	}
+	rows, lines = rows[m.SkipRows:], lines[m.SkipRows:]
+
+	var header []string
+	if m.HasHeader {
+		header, rows, lines = rows[0], rows[1:], lines[1:]
+	}
+	index := func(c model.CSVColumn) (int, error) {
+		if c.Header == "" {
+			return c.Index - 1, nil // 未指定时为 -1
+		}
+		for i, h := range header {
+			if strings.TrimSpace(h) == c.Header {
+				return i, nil
+			}
+		}
+		return -1, fmt.Errorf("CSV 文件中找不到列 %q", c.Header)
+	}
+	var cols struct{ date, amount, income, expense, typ, category, currency int }
+	for _, c := range []struct {
+		col *int
+		def model.CSVColumn
+	}{
+		{&cols.date, m.Date},
+		{&cols.amount, m.Amount},
+		{&cols.income, m.Income},
+		{&cols.expense, m.Expense},
+		{&cols.typ, m.Type},
+		{&cols.category, m.Category},
+		{&cols.currency, m.Currency},
+	} {
+		if *c.col, err = index(c.def); err != nil {
+			return nil, err
+		}
+	}
+	notes := make([]int, 0, len(m.Notes))
+	for _, c := range m.Notes {
+		i, err := index(c)
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, i)
+	}
+
+	layout := GoDateLayout(m.DateLayout)
+	var records []CSVRecord
+	for n, row := range rows {
+		field := func(i int) string {
+			if i >= 0 && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+		if strings.TrimSpace(strings.Join(row, "")) == "" {
+			continue
+		}
+
+		rec := CSVRecord{
+			Line:     lines[n],
+			Date:     mappedDate(field(cols.date), layout),
+			Currency: field(cols.currency),
+			Category: field(cols.category),
+		}
+		switch m.SignConvention {
+		case model.SignSplitColumns:
+			income, expense := field(cols.income), field(cols.expense)
+			if amount, negative, ok := mappedAmount(income, m.DecimalSeparator); ok && amount != "0" && !negative {
+				rec.Type, rec.Amount = model.TypeIncome, amount
+			} else {
+				rec.Type = model.TypeExpense
+				rec.Amount, _, _ = mappedAmount(expense, m.DecimalSeparator)
+			}
+		case model.SignTypeColumn:
+			rec.Amount, _, _ = mappedAmount(field(cols.amount), m.DecimalSeparator)
+			rec.Type = mappedType(field(cols.typ), m.IncomeLabels, m.ExpenseLabels)
+		default:
+			amount, negative, _ := mappedAmount(field(cols.amount), m.DecimalSeparator)
+			rec.Amount = amount
+			if negative == (m.SignConvention == model.SignNegativeIncome) {
+				rec.Type = model.TypeIncome
+			} else {
+				rec.Type = model.TypeExpense
+			}
+		}
+		if rec.Category == "" {
+			switch rec.Type {
+			case model.TypeIncome:
+				rec.Category = DefaultIncomeCategory
+			case model.TypeExpense:
+				rec.Category = DefaultExpenseCategory
+			}
+		}
+
+		var parts []string
+		for _, i := range notes {
+			if v := field(i); v != "" {
+				parts = append(parts, v)
+			}
+		}
+		rec.Note = strings.Join(parts, " · ")
+		records = append(records, rec)
+	}
+
+	if len(records) == 0 {
+		return nil, fmt.Errorf("CSV 文件为空或只有表头")
+	}
+	return records, nil
+}
+
+// mappedDate 按格式解析日期，格式只含日期而值带有时间时忽略时间部分；无法解析时返回原文
+func mappedDate(s, layout string) string {
+	if layout == "" {
+		return billDate(s)
+	}
+	if t, err := time.Parse(layout, s); err == nil {
+		return t.Format("2006-01-02")
+	}
+	if date, _, ok := strings.Cut(s, " "); ok {
+		if t, err := time.Parse(layout, date); err == nil {
+			return t.Format("2006-01-02")
+		}
+	}
+	return s
+}
+
+// mappedAmount 将金额转换为以 "." 为小数点、不带正负号的十进制数，返回是否为负数。
+// 去掉货币符号和千位分隔符，负数可以写作 "-12.00"、"12.00-" 或 "(12.00)"；无法识别时返回原文
+func mappedAmount(s, decimalSeparator string) (string, bool, bool) {
+	raw := s
+	s = strings.NewReplacer("¥", "", "￥", "", "$", "", "元", "", " ", "", "\u00a0", "").Replace(s)
+	if decimalSeparator == "," {
+		s = strings.NewReplacer(".", "", "'", "").Replace(s)
+		s = strings.Replace(s, ",", ".", 1)
+	} else {
+		s = strings.NewReplacer(",", "", "'", "").Replace(s)
+	}
+
+	negative := false
+	switch {
+	case strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")"):
+		negative, s = true, s[1:len(s)-1]
+	case strings.HasSuffix(s, "-"):
+		negative, s = true, strings.TrimSuffix(s, "-")
+	case strings.HasPrefix(s, "-"):
+		negative, s = true, strings.TrimPrefix(s, "-")
+	case strings.HasPrefix(s, "+"):
+		s = strings.TrimPrefix(s, "+")
+	}
+	if _, err := model.ParseMoney(s, ""); err != nil {
+		return raw, false, false
+	}
+	if strings.Trim(s, "0.") == "" {
+		return "0", negative, true
+	}
+	return s, negative, true
+}
+
+// mappedType 按类型列的值判断收支，未列出的值原样返回，由调用方报告
+func mappedType(s string, incomeLabels, expenseLabels []string) string {
+	for _, label := range incomeLabels {
+		if s == label {
+			return model.TypeIncome
+		}
+	}
+	for _, label := range expenseLabels {
+		if s == label {
+			return model.TypeExpense
+		}
+	}
+	return s
+}
@@ -0,0 +1,45 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strings"
+
+	"dog-view/internal/model"
+)
+
+// ImportRatesCSV 从 CSV 读取汇率，列依次为 date, from, to, rate，首行为表头
+func ImportRatesCSV(filePath string) ([]model.ExchangeRate, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) < 2 {
+		return nil, fmt.Errorf("CSV 文件为空或只有表头")
+	}
+
+	var rates []model.ExchangeRate
+	for i, row := range rows[1:] { // 跳过表头
+		if len(row) < 4 {
+			return nil, fmt.Errorf("第 %d 行数据不完整", i+2)
+		}
+
+		rates = append(rates, model.ExchangeRate{
+			Date: strings.TrimSpace(row[0]),
+			From: strings.ToUpper(strings.TrimSpace(row[1])),
+			To:   strings.ToUpper(strings.TrimSpace(row[2])),
+			Rate: strings.TrimSpace(row[3]),
+		})
+	}
+
+	return rates, nil
+}
@@ -0,0 +1,253 @@
+package ledger
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	apperrors "dog-view/internal/errors"
+)
+
+// RegistryFile 账本登记表文件名，位于应用数据目录
+const RegistryFile = "ledgers.json"
+
+// BackupDirName 备份目录名，位于应用数据目录，每个账本的备份放在以账本 ID 命名的子目录中
+const BackupDirName = "backups"
+
+// DefaultLedgerName 首次启动时为已有 data.db 创建的账本名称
+const DefaultLedgerName = "默认账本"
+
+// Ledger 账本，每个账本对应一个独立的 SQLite 数据库文件
+type Ledger struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Path      string    `json:"path"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// registryData 登记表的持久化结构
+type registryData struct {
+	CurrentID int64    `json:"currentId"`
+	NextID    int64    `json:"nextId"`
+	Ledgers   []Ledger `json:"ledgers"`
+}
+
+// Registry 账本登记表，记录所有账本以及当前使用的账本
+type Registry struct {
+	mu   sync.Mutex
+	dir  string
+	data registryData
+}
+
+// Load 加载数据目录下的账本登记表，不存在时以 defaultDBFile 创建默认账本
+func Load(dir, defaultDBFile string) (*Registry, error) {
+	r := &Registry{dir: dir}
+
+	content, err := os.ReadFile(filepath.Join(dir, RegistryFile))
+	switch {
+	case os.IsNotExist(err):
+		data := registryData{
+			CurrentID: 1,
+			NextID:    2,
+			Ledgers: []Ledger{{
+				ID:        1,
+				Name:      DefaultLedgerName,
+				Path:      filepath.Join(dir, defaultDBFile),
+				CreatedAt: time.Now(),
+			}},
+		}
+		if err := r.save(data); err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	default:
+		if err := json.Unmarshal(content, &r.data); err != nil {
+			return nil, fmt.Errorf("账本登记表格式错误: %w", err)
+		}
+	}
+
+	if len(r.data.Ledgers) == 0 {
+		return nil, fmt.Errorf("账本登记表为空")
+	}
+	if _, ok := r.find(r.data.CurrentID); !ok {
+		r.data.CurrentID = r.data.Ledgers[0].ID
+	}
+
+	return r, nil
+}
+
+// save 先写临时文件再重命名，避免写到一半时崩溃导致登记表损坏。
+// 写入成功后才替换内存中的登记表，失败时保持原样，因此修改账本列表前需先复制，不能改动 r.data 的底层数组
+func (r *Registry) save(data registryData) error {
+	content, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(r.dir, RegistryFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	r.data = data
+	return nil
+}
+
+// cloneLedgers 复制账本列表，调用方需持有 mu
+func (r *Registry) cloneLedgers() []Ledger {
+	ledgers := make([]Ledger, len(r.data.Ledgers))
+	copy(ledgers, r.data.Ledgers)
+	return ledgers
+}
+
+func (r *Registry) find(id int64) (int, bool) {
+	for i, l := range r.data.Ledgers {
+		if l.ID == id {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// validateName 校验账本名称非空且不重复
+func (r *Registry) validateName(name string, exceptID int64) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", apperrors.ErrInvalidLedgerName
+	}
+	for _, l := range r.data.Ledgers {
+		if l.Name == name && l.ID != exceptID {
+			return "", apperrors.ErrDuplicateLedger
+		}
+	}
+	return name, nil
+}
+
+// List 返回全部账本
+func (r *Registry) List() []Ledger {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cloneLedgers()
+}
+
+// Current 返回当前账本
+func (r *Registry) Current() Ledger {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, _ := r.find(r.data.CurrentID)
+	return r.data.Ledgers[i]
+}
+
+// Get 根据 ID 获取账本
+func (r *Registry) Get(id int64) (Ledger, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, ok := r.find(id)
+	if !ok {
+		return Ledger{}, apperrors.ErrLedgerNotFound
+	}
+	return r.data.Ledgers[i], nil
+}
+
+// BackupDir 返回账本的备份目录，目录在首次备份时创建；删除账本时保留其备份
+func (r *Registry) BackupDir(id int64) string {
+	return filepath.Join(r.dir, BackupDirName, fmt.Sprintf("ledger-%d", id))
+}
+
+// Create 登记新账本，数据库文件放在数据目录的 ledgers 子目录下
+func (r *Registry) Create(name string) (Ledger, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	name, err := r.validateName(name, 0)
+	if err != nil {
+		return Ledger{}, err
+	}
+
+	ledgerDir := filepath.Join(r.dir, "ledgers")
+	if err := os.MkdirAll(ledgerDir, 0755); err != nil {
+		return Ledger{}, err
+	}
+
+	l := Ledger{
+		ID:        r.data.NextID,
+		Name:      name,
+		Path:      filepath.Join(ledgerDir, fmt.Sprintf("ledger-%d.db", r.data.NextID)),
+		CreatedAt: time.Now(),
+	}
+	data := r.data
+	data.NextID++
+	data.Ledgers = append(r.cloneLedgers(), l)
+
+	if err := r.save(data); err != nil {
+		return Ledger{}, err
+	}
+	return l, nil
+}
+
+// Rename 重命名账本
+func (r *Registry) Rename(id int64, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, ok := r.find(id)
+	if !ok {
+		return apperrors.ErrLedgerNotFound
+	}
+	name, err := r.validateName(name, id)
+	if err != nil {
+		return err
+	}
+
+	data := r.data
+	data.Ledgers = r.cloneLedgers()
+	data.Ledgers[i].Name = name
+	return r.save(data)
+}
+
+// Remove 从登记表中移除账本并返回被移除的账本，当前账本不能移除
+func (r *Registry) Remove(id int64) (Ledger, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, ok := r.find(id)
+	if !ok {
+		return Ledger{}, apperrors.ErrLedgerNotFound
+	}
+	if id == r.data.CurrentID {
+		return Ledger{}, apperrors.ErrLedgerInUse
+	}
+
+	removed := r.data.Ledgers[i]
+	data := r.data
+	data.Ledgers = make([]Ledger, 0, len(r.data.Ledgers)-1)
+	data.Ledgers = append(data.Ledgers, r.data.Ledgers[:i]...)
+	data.Ledgers = append(data.Ledgers, r.data.Ledgers[i+1:]...)
+	if err := r.save(data); err != nil {
+		return Ledger{}, err
+	}
+	return removed, nil
+}
+
+// SetCurrent 切换当前账本
+func (r *Registry) SetCurrent(id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.find(id); !ok {
+		return apperrors.ErrLedgerNotFound
+	}
+	data := r.data
+	data.CurrentID = id
+	return r.save(data)
+}
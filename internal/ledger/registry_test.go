@@ -0,0 +1,60 @@
+package ledger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// 写入登记表失败时内存中的账本列表与当前账本保持不变
+func TestRegistrySaveFailureKeepsState(t *testing.T) {
+	dir := t.TempDir()
+	r, err := Load(dir, "data.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := r.Create("A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := r.Create("B")
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := r.List()
+
+	// 用非空目录占住登记表路径，使重命名失败
+	path := filepath.Join(dir, RegistryFile)
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(path, "x"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := r.Remove(a.ID); err == nil {
+		t.Error("Remove should fail when the registry cannot be saved")
+	}
+	if err := r.Rename(b.ID, "C"); err == nil {
+		t.Error("Rename should fail when the registry cannot be saved")
+	}
+	if err := r.SetCurrent(b.ID); err == nil {
+		t.Error("SetCurrent should fail when the registry cannot be saved")
+	}
+	if _, err := r.Create("D"); err == nil {
+		t.Error("Create should fail when the registry cannot be saved")
+	}
+
+	after := r.List()
+	if len(after) != len(before) {
+		t.Fatalf("ledgers = %v, want %v", after, before)
+	}
+	for i := range before {
+		if after[i] != before[i] {
+			t.Errorf("ledger %d = %+v, want %+v", i, after[i], before[i])
+		}
+	}
+	if cur := r.Current(); cur.ID != 1 {
+		t.Errorf("current = %d, want 1", cur.ID)
+	}
+}
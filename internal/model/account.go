@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+// Account 资金账户，如现金、银行卡、支付宝
+type Account struct {
+	ID             int64     `json:"id"`
+	Name           string    `json:"name"`
+	Type           string    `json:"type"` // "cash" | "bank" | "alipay" | "wechat" | "credit" | "other"
+	Icon           string    `json:"icon"`
+	OpeningBalance Money     `json:"openingBalance"`
+	SortOrder      int       `json:"sortOrder"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+// AccountType constants
+const (
+	AccountCash   = "cash"
+	AccountBank   = "bank"
+	AccountAlipay = "alipay"
+	AccountWechat = "wechat"
+	AccountCredit = "credit"
+	AccountOther  = "other"
+)
+
+// ValidAccountType 判断是否为已知的账户类型
+func ValidAccountType(t string) bool {
+	switch t {
+	case AccountCash, AccountBank, AccountAlipay, AccountWechat, AccountCredit, AccountOther:
+		return true
+	}
+	return false
+}
+
+// AccountBalance 账户在某一日期的余额
+type AccountBalance struct {
+	Account Account `json:"account"`
+	Date    string  `json:"date"` // 截至当天（含）
+	Balance Money   `json:"balance"`
+}
@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// Backup 账本数据库的一份备份，Name 即备份目录中的文件名
+type Backup struct {
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"` // 字节数
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// BackupPolicy 自动备份的间隔与保留份数。IntervalHours 为 0 时不自动备份；
+// 按天、周、月分别保留最近若干个周期中最新的一份，最新的备份始终保留
+type BackupPolicy struct {
+	IntervalHours int `json:"intervalHours"`
+	KeepDaily     int `json:"keepDaily"`
+	KeepWeekly    int `json:"keepWeekly"`
+	KeepMonthly   int `json:"keepMonthly"`
+}
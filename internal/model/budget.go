@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+// Budget 某个记账周期的预算，CategoryID 为 0 表示当月总预算
+type Budget struct {
+	ID         int64     `json:"id"`
+	Month      string    `json:"month"` // 记账周期，如 "2024-01"
+	CategoryID int64     `json:"categoryId"`
+	Amount     Money     `json:"amount"`
+	Recurring  bool      `json:"recurring"` // 之后未单独设置预算的月份沿用该预算
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// BudgetStatus 预算执行情况，金额均为本位币
+type BudgetStatus struct {
+	BudgetID      int64   `json:"budgetId"`
+	CategoryID    int64   `json:"categoryId"` // 0 表示总预算
+	CategoryName  string  `json:"categoryName"`
+	CategoryIcon  string  `json:"categoryIcon"`
+	InheritedFrom string  `json:"inheritedFrom"` // 沿用自哪个月的预算，当月设置时为空
+	Budget        Money   `json:"budget"`
+	Spent         Money   `json:"spent"`
+	Remaining     Money   `json:"remaining"` // 超支时为负数
+	Percentage    float64 `json:"percentage"`
+	// Projected 按当前支出速度推算的周期末支出，已结束的周期等于实际支出
+	Projected        Money `json:"projected"`
+	ProjectedOverrun bool  `json:"projectedOverrun"`
+}
+
+// BudgetReport 一个记账周期的预算执行情况
+type BudgetReport struct {
+	Month      string         `json:"month"`
+	Overall    *BudgetStatus  `json:"overall,omitempty"` // 未设置总预算时为空
+	Categories []BudgetStatus `json:"categories"`
+}
+
+// BudgetAlert 新增记录使预算使用率越过阈值时发出的提醒
+type BudgetAlert struct {
+	Threshold int          `json:"threshold"` // 80 或 100
+	Status    BudgetStatus `json:"status"`
+}
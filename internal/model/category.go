@@ -3,18 +3,24 @@ package model
 import "time"
 
 type Category struct {
-	ID        int64     `json:"id"`
-	Name      string    `json:"name"`
-	Icon      string    `json:"icon"`
-	Type      string    `json:"type"` // "income" | "expense"
-	SortOrder int       `json:"sortOrder"`
-	CreatedAt time.Time `json:"createdAt"`
+	ID          int64      `json:"id"`
+	Name        string     `json:"name"`
+	Icon        string     `json:"icon"`
+	Type        string     `json:"type"`        // "income" | "expense"
+	ParentID    int64      `json:"parentId"`    // 0 表示一级分类，分类最多两级
+	SortOrder   int        `json:"sortOrder"`   // 同一上级分类下的排序
+	Archived    bool       `json:"archived"`    // 归档后不再出现在记账时的分类选择中，历史统计照常
+	ImportBatch int64      `json:"importBatch"` // 导入时自动创建的分类为导入批次 ID，否则为 0
+	CreatedAt   time.Time  `json:"createdAt"`
+	DeletedAt   *time.Time `json:"deletedAt,omitempty"` // 非空表示在回收站中
+	Children    []Category `json:"children,omitempty"`  // 仅分类树中填充
 }
 
 // RecordType constants
 const (
-	TypeIncome  = "income"
-	TypeExpense = "expense"
+	TypeIncome   = "income"
+	TypeExpense  = "expense"
+	TypeTransfer = "transfer" // 账户间转账，不计入收支统计
 )
 
 // DefaultExpenseCategories 默认支出分类（空，用户自定义）
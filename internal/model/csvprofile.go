@@ -0,0 +1,67 @@
+package model
+
+import "time"
+
+// 文本编码
+const (
+	EncodingAuto = "auto" // 按 BOM 和内容识别 UTF-8 / GBK
+	EncodingUTF8 = "utf-8"
+	EncodingGBK  = "gbk"
+)
+
+// 金额的正负号约定，决定记录是收入还是支出
+const (
+	SignNegativeExpense = "negative_expense" // 负数为支出、正数为收入，多数银行流水如此
+	SignNegativeIncome  = "negative_income"  // 负数为收入（还款、退款）、正数为支出，多见于信用卡账单
+	SignTypeColumn      = "type_column"      // 金额取绝对值，收支由类型列决定
+	SignSplitColumns    = "split_columns"    // 收入和支出金额分两列，非空的一列决定收支
+)
+
+// CSVColumn 文件中的一列：Header 非空时按表头名称查找，否则按 Index（从 1 开始）；两者都为空表示没有该列
+type CSVColumn struct {
+	Index  int    `json:"index"`
+	Header string `json:"header"`
+}
+
+// IsSet 是否指定了列
+func (c CSVColumn) IsSet() bool {
+	return c.Index > 0 || c.Header != ""
+}
+
+// CSVMapping 通用 CSV 的列映射，描述一种银行流水的格式
+type CSVMapping struct {
+	Encoding  string `json:"encoding"`  // EncodingAuto 等，为空时自动识别
+	Delimiter string `json:"delimiter"` // 列分隔符，为空时使用逗号
+	SkipRows  int    `json:"skipRows"`  // 表头（或第一行数据）之前的说明行数
+	HasHeader bool   `json:"hasHeader"` // 按表头名称查找列时必须有表头
+
+	Date     CSVColumn   `json:"date"`
+	Amount   CSVColumn   `json:"amount"`   // SignSplitColumns 时不使用
+	Income   CSVColumn   `json:"income"`   // 仅 SignSplitColumns 使用
+	Expense  CSVColumn   `json:"expense"`  // 仅 SignSplitColumns 使用
+	Type     CSVColumn   `json:"type"`     // 仅 SignTypeColumn 使用
+	Category CSVColumn   `json:"category"` // 没有该列或值为空时归入默认的收入、支出分类
+	Currency CSVColumn   `json:"currency"` // 没有该列时使用默认币种
+	Notes    []CSVColumn `json:"notes"`    // 多列依次拼接为备注，如摘要和对方户名
+
+	DateLayout       string   `json:"dateLayout"`       // 日期格式，如 "YYYY-MM-DD"、"YYYYMMDD"、"DD/MM/YYYY"，可带时间
+	SignConvention   string   `json:"signConvention"`   // SignNegativeExpense 等
+	DecimalSeparator string   `json:"decimalSeparator"` // "." 或 ","，为空时使用 "."
+	IncomeLabels     []string `json:"incomeLabels"`     // SignTypeColumn 时表示收入的值，如 "收入"、"贷"
+	ExpenseLabels    []string `json:"expenseLabels"`    // SignTypeColumn 时表示支出的值，如 "支出"、"借"
+}
+
+// CSVProfile 保存的列映射方案，按银行等来源命名，导入时复用
+type CSVProfile struct {
+	ID        int64      `json:"id"`
+	Name      string     `json:"name"`
+	Mapping   CSVMapping `json:"mapping"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// CSVSample CSV 文件的前几行，供配置列映射时对照
+type CSVSample struct {
+	Source   string     `json:"source"`   // 文件名
+	Encoding string     `json:"encoding"` // 实际使用的编码，自动识别时为识别结果
+	Rows     [][]string `json:"rows"`
+}
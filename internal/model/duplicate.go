@@ -0,0 +1,18 @@
+package model
+
+// 导入时对疑似重复记录的处理方式
+const (
+	DuplicateSkip = "skip" // 不导入
+	DuplicateKeep = "keep" // 照常导入
+	DuplicateFlag = "flag" // 导入并打上 DuplicateTag 标签，留待核对
+)
+
+// DuplicateTag 标记疑似重复记录的标签名称，不存在时自动创建
+const DuplicateTag = "疑似重复"
+
+// DuplicateGroup 指纹（日期、类型、分类、金额和规范化后的备注）相同的一组记录，
+// 按录入时间排列，第一条为最早录入的
+type DuplicateGroup struct {
+	Fingerprint string   `json:"fingerprint"`
+	Records     []Record `json:"records"`
+}
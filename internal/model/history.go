@@ -0,0 +1,86 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// 变更日志中的实体类型。设置（包括 PIN）、汇率和 CSV 列映射方案不记入变更日志，不能撤销
+const (
+	EntityRecord     = "record"
+	EntityCategory   = "category"
+	EntityTag        = "tag"
+	EntityAccount    = "account"
+	EntityBudget     = "budget"
+	EntityRule       = "recurring_rule"
+	EntityOccurrence = "occurrence" // 周期规则的单次发生
+)
+
+// 变更集对应的操作
+const (
+	ActionCreateRecord      = "create_record"
+	ActionUpdateRecord      = "update_record"
+	ActionDeleteRecord      = "delete_record"
+	ActionSetRecordTags     = "set_record_tags"
+	ActionRestoreRecord     = "restore_record"
+	ActionPurgeRecord       = "purge_record"
+	ActionPostRecurring     = "post_recurring" // 周期记账自动入账
+	ActionCreateCategory    = "create_category"
+	ActionUpdateCategory    = "update_category"
+	ActionArchiveCategory   = "archive_category" // 归档或取消归档
+	ActionMoveCategory      = "move_category"
+	ActionReorderCategories = "reorder_categories"
+	ActionDeleteCategory    = "delete_category"
+	ActionMergeCategories   = "merge_categories"
+	ActionRestoreCategory   = "restore_category"
+	ActionPurgeCategory     = "purge_category"
+	ActionCreateTag         = "create_tag"
+	ActionUpdateTag         = "update_tag"
+	ActionDeleteTag         = "delete_tag"
+	ActionCreateAccount     = "create_account"
+	ActionUpdateAccount     = "update_account"
+	ActionDeleteAccount     = "delete_account"
+	ActionSetBudget         = "set_budget"
+	ActionDeleteBudget      = "delete_budget"
+	ActionCreateRule        = "create_rule"
+	ActionUpdateRule        = "update_rule"
+	ActionDeleteRule        = "delete_rule"
+	ActionSaveOccurrence    = "save_occurrence" // 跳过或修改单次发生
+	ActionPurgeTrash        = "purge_trash"
+	ActionImport            = "import"
+	ActionUndoImport        = "undo_import" // 将导入批次移入回收站
+	ActionUndo              = "undo"
+	ActionRedo              = "redo"
+)
+
+// ChangeSet 一次操作在同一事务中产生的全部变更，撤销和重做本身也记为变更集
+type ChangeSet struct {
+	ID       int64  `json:"id"`
+	Action   string `json:"action"`
+	TargetID int64  `json:"targetId"` // 撤销或重做时为对应的变更集
+	// Changes 仅在需要时填充
+	Changes   []Change  `json:"changes,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Change 一个实体在一次操作前后的快照（JSON），Before 为空表示新建，After 为空表示彻底删除
+type Change struct {
+	ID        int64           `json:"id"`
+	SetID     int64           `json:"setId"`
+	Action    string          `json:"action"`
+	Entity    string          `json:"entity"`
+	EntityID  int64           `json:"entityId"`
+	Before    json.RawMessage `json:"before"`
+	After     json.RawMessage `json:"after"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+// RecordChange 记录的一次变更，Before 为空表示新建，After 为空表示彻底删除；
+// 快照中的分类名称和标签为变更当时的值
+type RecordChange struct {
+	SetID     int64     `json:"setId"`
+	Action    string    `json:"action"`
+	Before    *Record   `json:"before"`
+	After     *Record   `json:"after"`
+	CreatedAt time.Time `json:"createdAt"`
+}
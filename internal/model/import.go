@@ -0,0 +1,81 @@
+package model
+
+import "time"
+
+// ImportIssue 导入行的一条错误或警告，Field 为出错的列（date、type、amount 等），整行问题时为空
+type ImportIssue struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ImportRow 导入预览中的一行，保留文件中的原始值，提交时重新校验
+type ImportRow struct {
+	Line      int           `json:"line"` // CSV 为文件行号，JSON 为记录序号（从 1 开始）
+	Date      string        `json:"date"`
+	Type      string        `json:"type"`
+	Category  string        `json:"category"`
+	Amount    string        `json:"amount"`   // 十进制金额原文
+	Currency  string        `json:"currency"` // 为空时使用本位币
+	Note      string        `json:"note"`
+	Tags      []string      `json:"tags"`
+	Duplicate bool          `json:"duplicate"` // 与账本中已有的记录指纹相同
+	Errors    []ImportIssue `json:"errors"`    // 非空时该行不会导入
+	Warnings  []ImportIssue `json:"warnings"`  // 不影响导入，供用户确认
+}
+
+// ImportCategory 导入时将要创建的分类
+type ImportCategory struct {
+	Name     string `json:"name"`
+	Icon     string `json:"icon"`
+	Type     string `json:"type"`
+	Parent   string `json:"parent,omitempty"` // 上级分类名称
+	Archived bool   `json:"archived,omitempty"`
+	FromFile bool   `json:"fromFile"` // 文件中定义的分类，没有记录使用也会创建；其余分类由记录自动创建
+}
+
+// ImportTag 导入时将要创建的标签
+type ImportTag struct {
+	Name     string `json:"name"`
+	Color    string `json:"color"`
+	FromFile bool   `json:"fromFile"` // 文件中定义的标签，没有记录使用也会创建
+}
+
+// ImportPreview 导入预览（试运行）的结果，也是提交导入时的计划。
+// 用户可以在提交前删除不想导入的行
+type ImportPreview struct {
+	Source          string           `json:"source"` // 导入的文件名
+	Rows            []ImportRow      `json:"rows"`
+	NewCategories   []ImportCategory `json:"newCategories"` // 按创建顺序排列，上级分类在子分类之前
+	NewTags         []ImportTag      `json:"newTags"`
+	Valid           int              `json:"valid"`           // 可以导入的行数，含疑似重复的行
+	Invalid         int              `json:"invalid"`         // 有错误、将被跳过的行数
+	Duplicates      int              `json:"duplicates"`      // 与已有记录指纹相同的行数
+	DuplicatePolicy string           `json:"duplicatePolicy"` // 疑似重复行的处理方式（DuplicateSkip 等），预览时默认跳过
+	Ignored         int              `json:"ignored"`         // 账单中未成功、已退款或不计收支的交易笔数，不在 Rows 中
+}
+
+// ImportResult 提交导入的结果
+type ImportResult struct {
+	BatchID           int64 `json:"batchId"` // 没有创建任何数据时为 0
+	Imported          int   `json:"imported"`
+	Skipped           int   `json:"skipped"`    // 提交时校验未通过的行数
+	Duplicates        int   `json:"duplicates"` // 疑似重复的行数，按处理方式跳过或照常导入
+	CategoriesCreated int   `json:"categoriesCreated"`
+	TagsCreated       int   `json:"tagsCreated"`
+}
+
+// ImportBatch 一次提交的导入。导入的记录和分类都带有批次 ID，可以整批撤销
+type ImportBatch struct {
+	ID         int64     `json:"id"`
+	Source     string    `json:"source"`
+	Records    int       `json:"records"` // 导入时创建的数量，撤销后不变
+	Categories int       `json:"categories"`
+	Tags       int       `json:"tags"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// ImportUndoResult 撤销导入批次的结果，记录和分类移入回收站
+type ImportUndoResult struct {
+	Records    int `json:"records"`
+	Categories int `json:"categories"` // 仍被其他记录或子分类使用的分类保留
+}
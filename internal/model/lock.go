@@ -0,0 +1,8 @@
+package model
+
+// LockSettings 应用锁设置，Enabled 表示已设置 PIN；IdleMinutes 为 0 时不自动锁定
+type LockSettings struct {
+	Enabled        bool `json:"enabled"`
+	IdleMinutes    int  `json:"idleMinutes"`
+	LockOnMinimize bool `json:"lockOnMinimize"`
+}
@@ -0,0 +1,158 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency 默认币种
+const DefaultCurrency = "CNY"
+
+// currencyExponents 各币种最小单位的小数位数，未列出的币种按 2 位处理
+var currencyExponents = map[string]int{
+	"JPY": 0,
+	"KRW": 0,
+	"VND": 0,
+}
+
+// CurrencyExponent 返回币种最小货币单位对应的小数位数
+func CurrencyExponent(currency string) int {
+	if exp, ok := currencyExponents[currency]; ok {
+		return exp
+	}
+	return 2
+}
+
+// ValidCurrency 判断是否为 3 位大写字母的 ISO 4217 币种代码
+func ValidCurrency(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, c := range code {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// Money 金额，以最小货币单位（如 分）的整数存储，避免浮点误差
+type Money struct {
+	Minor    int64  `json:"minor"`    // 最小货币单位数量，如 1234 表示 12.34 元
+	Currency string `json:"currency"` // ISO 4217 币种代码，如 "CNY"
+}
+
+// NewMoney 创建金额，币种为空时使用默认币种
+func NewMoney(minor int64, currency string) Money {
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	return Money{Minor: minor, Currency: currency}
+}
+
+// ParseMoney 解析十进制金额字符串，如 "12.34"、"-5"、"1,234.5"
+// 超出币种精度的小数位四舍五入，兼容旧版浮点导出的数据
+func ParseMoney(s, currency string) (Money, error) {
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	exp := CurrencyExponent(currency)
+
+	str := strings.ReplaceAll(strings.TrimSpace(s), ",", "")
+	negative := false
+	switch {
+	case strings.HasPrefix(str, "-"):
+		negative = true
+		str = str[1:]
+	case strings.HasPrefix(str, "+"):
+		str = str[1:]
+	}
+
+	intPart, fracPart, _ := strings.Cut(str, ".")
+	if intPart == "" && fracPart == "" {
+		return Money{}, fmt.Errorf("金额格式错误: %q", s)
+	}
+	if intPart == "" {
+		intPart = "0"
+	}
+	if !isDigits(intPart) || (fracPart != "" && !isDigits(fracPart)) {
+		return Money{}, fmt.Errorf("金额格式错误: %q", s)
+	}
+
+	// 补齐或截断到币种精度，截断部分四舍五入
+	roundUp := false
+	if len(fracPart) > exp {
+		roundUp = fracPart[exp] >= '5'
+		fracPart = fracPart[:exp]
+	}
+	fracPart += strings.Repeat("0", exp-len(fracPart))
+
+	minor, err := strconv.ParseInt(intPart+fracPart, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("金额超出范围: %q", s)
+	}
+	if roundUp {
+		minor++
+	}
+	if negative {
+		minor = -minor
+	}
+
+	return Money{Minor: minor, Currency: currency}, nil
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// String 返回不带币种符号的十进制金额，如 "12.34"
+func (m Money) String() string {
+	exp := CurrencyExponent(m.Currency)
+	minor := m.Minor
+	sign := ""
+	if minor < 0 {
+		sign = "-"
+		minor = -minor
+	}
+
+	digits := strconv.FormatInt(minor, 10)
+	if exp == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exp {
+		digits = strings.Repeat("0", exp-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exp] + "." + digits[len(digits)-exp:]
+}
+
+// Add 相加，调用方需保证币种一致
+func (m Money) Add(o Money) Money {
+	return Money{Minor: m.Minor + o.Minor, Currency: m.Currency}
+}
+
+// Sub 相减，调用方需保证币种一致
+func (m Money) Sub(o Money) Money {
+	return Money{Minor: m.Minor - o.Minor, Currency: m.Currency}
+}
+
+// IsZero 是否为零
+func (m Money) IsZero() bool {
+	return m.Minor == 0
+}
+
+// Percentage 计算 m 占 total 的百分比
+func (m Money) Percentage(total Money) float64 {
+	if total.Minor == 0 {
+		return 0
+	}
+	return float64(m.Minor) / float64(total.Minor) * 100
+}
@@ -0,0 +1,130 @@
+package model
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RecordFilter 记录列表的筛选条件，零值字段表示不限；日期区间左闭右开
+type RecordFilter struct {
+	StartDate   string   `json:"startDate"`
+	EndDate     string   `json:"endDate"`
+	Types       []string `json:"types"`
+	CategoryIDs []int64  `json:"categoryIds"`
+	TagIDs      []int64  `json:"tagIds"`
+	// MatchAllTags 为 true 时要求记录包含全部标签，否则包含任一标签即可
+	MatchAllTags bool `json:"matchAllTags"`
+	// MinAmount、MaxAmount 为闭区间，按记录自身币种的最小单位比较，0 表示不限
+	MinAmount int64 `json:"minAmount"`
+	MaxAmount int64 `json:"maxAmount"`
+	// Note 备注包含的文本，英文字母不区分大小写
+	Note string `json:"note"`
+}
+
+// 记录查询的排序字段，排序值相同的记录再按 ID 排序
+const (
+	SortByDate   = "date"
+	SortByAmount = "amount" // 按记录自身币种的金额排序，不做换算
+)
+
+// RecordQuery 记录分页查询
+type RecordQuery struct {
+	Filter RecordFilter `json:"filter"`
+	SortBy string       `json:"sortBy"` // 为空时按日期
+	Desc   bool         `json:"desc"`
+	// Cursor 上一页返回的 NextCursor，为空时从第一条开始
+	Cursor string `json:"cursor"`
+	Limit  int    `json:"limit"`
+}
+
+// RecordPage 一页查询结果
+type RecordPage struct {
+	Records []Record `json:"records"`
+	// NextCursor 下一页的游标，为空表示没有更多记录
+	NextCursor string `json:"nextCursor"`
+	// Total 与 Summary 针对整个筛选结果而非当前页，Summary 已换算为本位币
+	Total   int          `json:"total"`
+	Summary MonthSummary `json:"summary"`
+}
+
+// RecordCursor 分页游标，记录上一页最后一条记录的排序键
+type RecordCursor struct {
+	Date   string
+	Amount int64
+	ID     int64
+}
+
+// CursorOf 返回 rec 对应的游标
+func CursorOf(rec Record) RecordCursor {
+	return RecordCursor{Date: rec.Date, Amount: rec.Amount.Minor, ID: rec.ID}
+}
+
+// Encode 编码为不透明字符串
+func (c RecordCursor) Encode() string {
+	raw := fmt.Sprintf("%s|%d|%d", c.Date, c.Amount, c.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// After 判断 rec 在给定排序下是否位于游标之后
+func (c RecordCursor) After(rec Record, sortBy string, desc bool) bool {
+	cmp := 0
+	switch sortBy {
+	case SortByAmount:
+		cmp = compareInt(rec.Amount.Minor, c.Amount)
+	default:
+		cmp = strings.Compare(rec.Date, c.Date)
+	}
+	if cmp == 0 {
+		cmp = compareInt(rec.ID, c.ID)
+	}
+	if desc {
+		return cmp < 0
+	}
+	return cmp > 0
+}
+
+func compareInt(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// ParseRecordCursor 解析 Encode 生成的游标
+func ParseRecordCursor(s string) (RecordCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return RecordCursor{}, fmt.Errorf("游标格式错误: %w", err)
+	}
+	parts := strings.Split(string(raw), "|")
+	if len(parts) != 3 {
+		return RecordCursor{}, fmt.Errorf("游标格式错误: %q", raw)
+	}
+
+	c := RecordCursor{Date: parts[0]}
+	if c.Amount, err = strconv.ParseInt(parts[1], 10, 64); err != nil {
+		return RecordCursor{}, fmt.Errorf("游标格式错误: %w", err)
+	}
+	if c.ID, err = strconv.ParseInt(parts[2], 10, 64); err != nil {
+		return RecordCursor{}, fmt.Errorf("游标格式错误: %w", err)
+	}
+	return c, nil
+}
+
+// 搜索结果摘要中命中文本的起止标记，前端据此高亮；控制字符不会出现在正常备注中
+const (
+	HighlightStart = "\x02"
+	HighlightEnd   = "\x03"
+)
+
+// SearchResult 备注全文搜索的一条结果
+type SearchResult struct {
+	Record Record `json:"record"`
+	// Snippet 备注中命中位置附近的片段，命中文本以 HighlightStart、HighlightEnd 包围
+	Snippet string `json:"snippet"`
+}
@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// ExchangeRate 某日的汇率：1 单位 From 币种兑换 Rate 单位 To 币种
+type ExchangeRate struct {
+	ID        int64     `json:"id"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Date      string    `json:"date"` // 生效日期，之后没有新汇率的日期沿用该汇率
+	Rate      string    `json:"rate"` // 十进制字符串，如 "0.0482"，避免浮点误差
+	CreatedAt time.Time `json:"createdAt"`
+}
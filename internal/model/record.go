@@ -3,12 +3,17 @@ package model
 import "time"
 
 type Record struct {
-	ID         int64     `json:"id"`
-	Amount     float64   `json:"amount"`
-	Type       string    `json:"type"` // "income" | "expense"
-	CategoryID int64     `json:"categoryId"`
-	Category   *Category `json:"category,omitempty"`
-	Note       string    `json:"note"`
-	Date       string    `json:"date"` // "2024-01-15"
-	CreatedAt  time.Time `json:"createdAt"`
+	ID          int64      `json:"id"`
+	Amount      Money      `json:"amount"`
+	Type        string     `json:"type"`       // "income" | "expense" | "transfer"
+	CategoryID  int64      `json:"categoryId"` // 转账记录为 0
+	Category    *Category  `json:"category,omitempty"`
+	AccountID   int64      `json:"accountId"`   // 0 表示未指定账户；转账时为转出账户
+	ToAccountID int64      `json:"toAccountId"` // 仅转账记录使用，转入账户
+	Tags        []Tag      `json:"tags"`        // 按名称排序
+	Note        string     `json:"note"`
+	Date        string     `json:"date"`        // "2024-01-15"
+	ImportBatch int64      `json:"importBatch"` // 导入批次 ID，0 表示手动录入
+	CreatedAt   time.Time  `json:"createdAt"`
+	DeletedAt   *time.Time `json:"deletedAt,omitempty"` // 非空表示在回收站中
 }
@@ -0,0 +1,68 @@
+package model
+
+import "time"
+
+// 周期规则频率
+const (
+	FrequencyDaily   = "daily"
+	FrequencyWeekly  = "weekly"
+	FrequencyMonthly = "monthly"
+	FrequencyYearly  = "yearly"
+)
+
+// 单次发生的状态
+const (
+	OccurrencePending  = "pending"  // 尚未入账
+	OccurrenceModified = "modified" // 尚未入账，已单独修改金额或备注
+	OccurrenceSkipped  = "skipped"  // 已跳过，不会入账
+	OccurrencePosted   = "posted"   // 已生成记录
+)
+
+// RecurringRule 周期记账规则，如房租、订阅、工资
+type RecurringRule struct {
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	Frequency string `json:"frequency"` // "daily" | "weekly" | "monthly" | "yearly"
+	Interval  int    `json:"interval"`  // 每隔几个频率单位发生一次，至少为 1
+	// DayOfMonth 按月、按年规则的发生日，0 表示取开始日期的日；大于当月天数时取月末
+	DayOfMonth   int    `json:"dayOfMonth"`
+	StartDate    string `json:"startDate"`
+	EndDate      string `json:"endDate"`      // 为空表示不限
+	MaxCount     int    `json:"maxCount"`     // 最多发生次数，0 表示不限
+	SkipWeekends bool   `json:"skipWeekends"` // 落在周六、周日的发生日不入账
+
+	// 生成记录所用的模板
+	Amount     Money  `json:"amount"`
+	Type       string `json:"type"` // "income" | "expense"
+	CategoryID int64  `json:"categoryId"`
+	AccountID  int64  `json:"accountId"`
+	Note       string `json:"note"`
+
+	Active    bool      `json:"active"` // 暂停的规则不再入账
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Occurrence 规则某一次发生的处理结果，只有跳过、修改或已入账的发生才会保存
+type Occurrence struct {
+	ID       int64  `json:"id"`
+	RuleID   int64  `json:"ruleId"`
+	Date     string `json:"date"`
+	Status   string `json:"status"`
+	RecordID int64  `json:"recordId"` // 已入账时为生成的记录
+	// 单次修改的金额和备注，仅 modified 状态使用
+	Amount *Money `json:"amount,omitempty"`
+	Note   string `json:"note"`
+}
+
+// UpcomingOccurrence 即将发生（尚未入账）的一次周期记账
+type UpcomingOccurrence struct {
+	RuleID     int64  `json:"ruleId"`
+	RuleName   string `json:"ruleName"`
+	Date       string `json:"date"`
+	Status     string `json:"status"` // pending | modified | skipped
+	Amount     Money  `json:"amount"`
+	Type       string `json:"type"`
+	CategoryID int64  `json:"categoryId"`
+	AccountID  int64  `json:"accountId"`
+	Note       string `json:"note"`
+}
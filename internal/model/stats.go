@@ -1,10 +1,19 @@
 package model
 
+// StatTotal 某天某分类某币种的收支合计，由服务层换算为本位币后再汇总
+type StatTotal struct {
+	Date       string `json:"date"`
+	Type       string `json:"type"`
+	CategoryID int64  `json:"categoryId"`
+	TagID      int64  `json:"tagId"` // 仅按标签分组时使用
+	Amount     Money  `json:"amount"`
+}
+
 // MonthSummary 月度汇总
 type MonthSummary struct {
-	TotalIncome  float64 `json:"totalIncome"`
-	TotalExpense float64 `json:"totalExpense"`
-	Balance      float64 `json:"balance"`
+	TotalIncome  Money `json:"totalIncome"`
+	TotalExpense Money `json:"totalExpense"`
+	Balance      Money `json:"balance"`
 }
 
 // CategoryStat 分类统计（饼图数据）
@@ -12,15 +21,16 @@ type CategoryStat struct {
 	CategoryID   int64   `json:"categoryId"`
 	CategoryName string  `json:"categoryName"`
 	CategoryIcon string  `json:"categoryIcon"`
-	Amount       float64 `json:"amount"`
+	ParentID     int64   `json:"parentId"` // 下钻时按上级分类筛选
+	Amount       Money   `json:"amount"`
 	Percentage   float64 `json:"percentage"`
 }
 
 // MonthTrend 月度趋势（折线图数据）
 type MonthTrend struct {
-	Month   string  `json:"month"` // "2024-01"
-	Income  float64 `json:"income"`
-	Expense float64 `json:"expense"`
+	Month   string `json:"month"` // "2024-01"
+	Income  Money  `json:"income"`
+	Expense Money  `json:"expense"`
 }
 
 // CategoryStatsResponse 分类统计响应
@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// Tag 标签，与分类正交，一条记录可以有多个标签
+type Tag struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Color     string    `json:"color"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// TagStat 标签统计，一条记录有多个标签时会计入每个标签
+type TagStat struct {
+	TagID      int64   `json:"tagId"`
+	TagName    string  `json:"tagName"`
+	TagColor   string  `json:"tagColor"`
+	Amount     Money   `json:"amount"`
+	Percentage float64 `json:"percentage"`
+}
+
+// TagStatsResponse 标签统计响应
+type TagStatsResponse struct {
+	IncomeStats  []TagStat `json:"incomeStats"`
+	ExpenseStats []TagStat `json:"expenseStats"`
+}
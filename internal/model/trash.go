@@ -0,0 +1,13 @@
+package model
+
+// Trash 回收站内容，均按删除时间倒序
+type Trash struct {
+	Records    []Record   `json:"records"`
+	Categories []Category `json:"categories"`
+}
+
+// PurgeResult 清理回收站的结果
+type PurgeResult struct {
+	Records    int `json:"records"`
+	Categories int `json:"categories"`
+}
@@ -0,0 +1,113 @@
+package repository_test
+
+import (
+	"bytes"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	apperrors "dog-view/internal/errors"
+	"dog-view/internal/repository"
+	"dog-view/internal/vault"
+)
+
+// newCheckedDatabase 创建一个账本数据库，query 不为空时执行它修改结构版本，返回路径和当前支持的版本
+func newCheckedDatabase(t *testing.T, query string) (string, int) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "data.db.restore")
+	repo, err := repository.OpenSQLiteRepository(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	latest, err := repo.SchemaVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo.Close()
+	if query == "" {
+		return path, latest
+	}
+
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(query, latest); err != nil {
+		t.Fatal(err)
+	}
+	return path, latest
+}
+
+func TestCheckDatabaseIsReadOnly(t *testing.T) {
+	// 旧版本的备份可以恢复，检查时不迁移
+	path, _ := newCheckedDatabase(t, "DELETE FROM schema_version WHERE version = ?")
+	before, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repository.CheckDatabase(path, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	after, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Fatal("检查修改了数据库文件")
+	}
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("检查后目录中有 %d 个文件，期望只有数据库本身", len(entries))
+	}
+}
+
+func TestCheckDatabaseRejects(t *testing.T) {
+	newer, latest := newCheckedDatabase(t, "INSERT INTO schema_version (version, name) VALUES (? + 1, 'future')")
+	if err := repository.CheckDatabase(newer, ""); err == nil || !strings.Contains(err.Error(), "高于") {
+		t.Fatalf("CheckDatabase(v%d+1) = %v，期望版本过高", latest, err)
+	}
+
+	garbage := filepath.Join(t.TempDir(), "garbage.db")
+	if err := os.WriteFile(garbage, bytes.Repeat([]byte("x"), 4096), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := repository.CheckDatabase(garbage, ""); err == nil {
+		t.Fatal("CheckDatabase(非数据库文件) 应返回错误")
+	}
+}
+
+func TestCheckDatabaseEncrypted(t *testing.T) {
+	path, _ := newCheckedDatabase(t, "")
+	key, err := vault.NewKey("passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := vault.Rewrite(path, "", key); err != nil {
+		t.Fatal(err)
+	}
+	before, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repository.CheckDatabase(path, "passphrase"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repository.CheckDatabase(path, "wrong"); err != apperrors.ErrWrongPassphrase {
+		t.Fatalf("CheckDatabase(错误密码) = %v，期望 ErrWrongPassphrase", err)
+	}
+	after, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Fatal("检查修改了加密的数据库文件")
+	}
+}
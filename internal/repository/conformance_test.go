@@ -0,0 +1,50 @@
+package repository_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"dog-view/internal/repository"
+	"dog-view/internal/repository/repotest"
+	"dog-view/internal/vault"
+)
+
+func TestConformanceMemory(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repository.Repository {
+		return repository.NewMemoryRepository()
+	})
+}
+
+func TestConformanceSQLite(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repository.Repository {
+		r, err := repository.OpenSQLiteRepository(filepath.Join(t.TempDir(), "data.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	})
+}
+
+func TestConformanceEncrypted(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repository.Repository {
+		path := filepath.Join(t.TempDir(), "data.db")
+		r, err := repository.OpenSQLiteRepository(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.Close()
+
+		key, err := vault.NewKey("passphrase")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := vault.Rewrite(path, "", key); err != nil {
+			t.Fatal(err)
+		}
+		r, err = repository.OpenEncryptedRepository(path, "passphrase")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	})
+}
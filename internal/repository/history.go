@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+
+	"dog-view/internal/model"
+)
+
+// ============ 变更日志（两种实现共用） ============
+
+// entityKey 变更日志中的一个实体
+type entityKey struct {
+	entity string
+	id     int64
+}
+
+// entityOrder 回放变更集时的实体顺序：记录的标签关联依赖标签已存在，先写分类和标签
+var entityOrder = map[string]int{
+	model.EntityCategory:   0,
+	model.EntityTag:        1,
+	model.EntityAccount:    2,
+	model.EntityBudget:     3,
+	model.EntityRule:       4,
+	model.EntityOccurrence: 5,
+	model.EntityRecord:     6,
+}
+
+// sortChanges 按回放顺序排列同一变更集中的变更
+func sortChanges(changes []model.Change) {
+	sort.SliceStable(changes, func(i, j int) bool {
+		return entityOrder[changes[i].Entity] < entityOrder[changes[j].Entity]
+	})
+}
+
+// normalizeSnapshot 去掉快照中随其他实体变化的派生字段（记录的分类摘要、标签名称），
+// 并统一时区，使两个快照可以按字节比较
+func normalizeSnapshot(entity string, data json.RawMessage) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var v interface{}
+	switch entity {
+	case model.EntityRecord:
+		var rec model.Record
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return nil, err
+		}
+		rec.Category = nil
+		rec.CreatedAt = rec.CreatedAt.UTC()
+		if rec.DeletedAt != nil {
+			t := rec.DeletedAt.UTC()
+			rec.DeletedAt = &t
+		}
+		tags := make([]model.Tag, len(rec.Tags))
+		for i, t := range rec.Tags {
+			tags[i] = model.Tag{ID: t.ID}
+		}
+		sort.Slice(tags, func(i, j int) bool { return tags[i].ID < tags[j].ID })
+		rec.Tags = tags
+		v = rec
+	case model.EntityCategory:
+		var c model.Category
+		if err := json.Unmarshal(data, &c); err != nil {
+			return nil, err
+		}
+		c.Children = nil
+		c.CreatedAt = c.CreatedAt.UTC()
+		if c.DeletedAt != nil {
+			t := c.DeletedAt.UTC()
+			c.DeletedAt = &t
+		}
+		v = c
+	case model.EntityAccount:
+		var a model.Account
+		if err := json.Unmarshal(data, &a); err != nil {
+			return nil, err
+		}
+		a.CreatedAt = a.CreatedAt.UTC()
+		v = a
+	case model.EntityBudget:
+		var b model.Budget
+		if err := json.Unmarshal(data, &b); err != nil {
+			return nil, err
+		}
+		b.CreatedAt = b.CreatedAt.UTC()
+		v = b
+	case model.EntityRule:
+		var rule model.RecurringRule
+		if err := json.Unmarshal(data, &rule); err != nil {
+			return nil, err
+		}
+		rule.CreatedAt = rule.CreatedAt.UTC()
+		v = rule
+	case model.EntityOccurrence:
+		var o model.Occurrence
+		if err := json.Unmarshal(data, &o); err != nil {
+			return nil, err
+		}
+		v = o
+	default:
+		var t model.Tag
+		if err := json.Unmarshal(data, &t); err != nil {
+			return nil, err
+		}
+		t.CreatedAt = t.CreatedAt.UTC()
+		v = t
+	}
+	return json.Marshal(v)
+}
+
+// sameSnapshot 判断两个快照是否表示实体的同一状态，nil 表示实体不存在
+func sameSnapshot(entity string, a, b json.RawMessage) (bool, error) {
+	na, err := normalizeSnapshot(entity, a)
+	if err != nil {
+		return false, err
+	}
+	nb, err := normalizeSnapshot(entity, b)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(na, nb), nil
+}
+
+// marshalSnapshot 将实体编码为快照，实体不存在时返回 nil
+func marshalSnapshot(v interface{}, exists bool) (json.RawMessage, error) {
+	if !exists {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+// replaySteps 返回回放变更集时每个实体的期望当前状态和目标状态：
+// 撤销时从 After 回到 Before，重做时从 Before 回到 After
+func replaySteps(changes []model.Change, undo bool) []model.Change {
+	steps := make([]model.Change, len(changes))
+	for i, c := range changes {
+		steps[i] = c
+		if undo {
+			steps[i].Before, steps[i].After = c.After, c.Before
+		}
+	}
+	sortChanges(steps)
+	return steps
+}
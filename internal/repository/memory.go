@@ -0,0 +1,639 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	apperrors "dog-view/internal/errors"
+	"dog-view/internal/model"
+)
+
+// MemoryRepository 基于内存的仓库实现，语义与 SQLiteRepository 保持一致，
+// 适用于单元测试以及不需要持久化的场景
+type MemoryRepository struct {
+	mu sync.RWMutex
+
+	categories       map[int64]*model.Category
+	records          map[int64]*model.Record
+	trashCategories  map[int64]*model.Category // 回收站中的分类，DeletedAt 非空
+	trashRecords     map[int64]*model.Record   // 回收站中的记录，DeletedAt 非空
+	tags             map[int64]*model.Tag
+	recordTags       map[int64]map[int64]bool
+	accounts         map[int64]*model.Account
+	rates            map[int64]*model.ExchangeRate
+	budgets          map[int64]*model.Budget
+	rules            map[int64]*model.RecurringRule
+	occurrences      map[occurrenceKey]*model.Occurrence
+	settings         map[string]string
+	changeSets       []model.ChangeSet   // 按 ID 升序，Changes 已填充
+	importBatches    []model.ImportBatch // 按 ID 升序
+	csvProfiles      map[int64]*model.CSVProfile
+	nextCategoryID   int64
+	nextRecordID     int64
+	nextTagID        int64
+	nextAccountID    int64
+	nextRateID       int64
+	nextBudgetID     int64
+	nextRuleID       int64
+	nextOccurrenceID int64
+	nextChangeSetID  int64
+	nextChangeID     int64
+	nextBatchID      int64
+	nextProfileID    int64
+}
+
+// NewMemoryRepository 创建内存仓库
+func NewMemoryRepository() *MemoryRepository {
+	r := &MemoryRepository{
+		categories:      make(map[int64]*model.Category),
+		records:         make(map[int64]*model.Record),
+		trashCategories: make(map[int64]*model.Category),
+		trashRecords:    make(map[int64]*model.Record),
+		tags:            make(map[int64]*model.Tag),
+		recordTags:      make(map[int64]map[int64]bool),
+		accounts:        make(map[int64]*model.Account),
+		rates:           make(map[int64]*model.ExchangeRate),
+		budgets:         make(map[int64]*model.Budget),
+		rules:           make(map[int64]*model.RecurringRule),
+		occurrences:     make(map[occurrenceKey]*model.Occurrence),
+		settings:        make(map[string]string),
+		csvProfiles:     make(map[int64]*model.CSVProfile),
+	}
+
+	for _, c := range model.DefaultExpenseCategories {
+		r.insertCategory(c)
+	}
+	for _, c := range model.DefaultIncomeCategories {
+		r.insertCategory(c)
+	}
+
+	return r
+}
+
+// Close 内存仓库无需释放资源
+func (r *MemoryRepository) Close() error {
+	return nil
+}
+
+// now 与 SQLite CURRENT_TIMESTAMP 一致：UTC，精确到秒
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
+
+// ============ Category 操作 ============
+
+func (r *MemoryRepository) insertCategory(c model.Category) *model.Category {
+	r.nextCategoryID++
+	c.ID = r.nextCategoryID
+	c.CreatedAt = now()
+	r.categories[c.ID] = &c
+	return &c
+}
+
+// nameTaken 判断名称是否已被其他未删除的分类占用
+func (r *MemoryRepository) nameTaken(name string, exceptID int64) bool {
+	for _, c := range r.categories {
+		if c.Name == name && c.ID != exceptID {
+			return true
+		}
+	}
+	return false
+}
+
+// ListCategories 获取分类列表
+func (r *MemoryRepository) ListCategories(recordType string) ([]model.Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var categories []model.Category
+	for _, c := range r.categories {
+		if recordType == "" || c.Type == recordType {
+			categories = append(categories, *c)
+		}
+	}
+
+	sort.Slice(categories, func(i, j int) bool {
+		if categories[i].SortOrder != categories[j].SortOrder {
+			return categories[i].SortOrder < categories[j].SortOrder
+		}
+		return categories[i].ID < categories[j].ID
+	})
+	return treeOrder(categories), nil
+}
+
+// GetCategoryByName 根据名称获取分类
+func (r *MemoryRepository) GetCategoryByName(name string) (*model.Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, c := range r.categories {
+		if c.Name == name {
+			found := *c
+			return &found, nil
+		}
+	}
+	return nil, apperrors.ErrCategoryNotFound
+}
+
+// CreateCategory 创建分类
+func (r *MemoryRepository) CreateCategory(c *model.Category) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.nameTaken(c.Name, 0) {
+		return apperrors.ErrDuplicateCategory
+	}
+
+	created := r.insertCategory(*c)
+	c.ID = created.ID
+	ch := r.beginChange(model.ActionCreateCategory)
+	ch.created(model.EntityCategory, c.ID)
+	return ch.commit()
+}
+
+// UpdateCategory 更新分类
+func (r *MemoryRepository) UpdateCategory(c *model.Category) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.categories[c.ID]
+	if !ok {
+		return apperrors.ErrCategoryNotFound
+	}
+	if r.nameTaken(c.Name, c.ID) {
+		return apperrors.ErrDuplicateCategory
+	}
+
+	ch := r.beginChange(model.ActionUpdateCategory)
+	if err := ch.track(model.EntityCategory, c.ID); err != nil {
+		return err
+	}
+	existing.Name = c.Name
+	existing.Icon = c.Icon
+	return ch.commit()
+}
+
+// SetCategoryArchived 归档或取消归档分类
+func (r *MemoryRepository) SetCategoryArchived(id int64, archived bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.categories[id]
+	if !ok {
+		return apperrors.ErrCategoryNotFound
+	}
+	ch := r.beginChange(model.ActionArchiveCategory)
+	if err := ch.track(model.EntityCategory, id); err != nil {
+		return err
+	}
+	existing.Archived = archived
+	return ch.commit()
+}
+
+// MoveCategory 修改分类的上级分类和排序值
+func (r *MemoryRepository) MoveCategory(id, parentID int64, sortOrder int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.categories[id]
+	if !ok {
+		return apperrors.ErrCategoryNotFound
+	}
+	ch := r.beginChange(model.ActionMoveCategory)
+	if err := ch.track(model.EntityCategory, id); err != nil {
+		return err
+	}
+	existing.ParentID = parentID
+	existing.SortOrder = sortOrder
+	return ch.commit()
+}
+
+// DeleteCategory 将分类移入回收站
+func (r *MemoryRepository) DeleteCategory(id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, rec := range r.records {
+		if rec.CategoryID == id {
+			return apperrors.ErrCategoryInUse
+		}
+	}
+	for _, c := range r.categories {
+		if c.ParentID == id {
+			return apperrors.ErrCategoryHasChildren
+		}
+	}
+	c, ok := r.categories[id]
+	if !ok {
+		return apperrors.ErrCategoryNotFound
+	}
+
+	ch := r.beginChange(model.ActionDeleteCategory)
+	if err := ch.track(model.EntityCategory, id); err != nil {
+		return err
+	}
+	deletedAt := now()
+	c.DeletedAt = &deletedAt
+	r.trashCategories[id] = c
+	delete(r.categories, id)
+	return ch.commit()
+}
+
+// MergeCategories 合并分类，先整体校验再修改，与 SQLite 的事务语义一致
+func (r *MemoryRepository) MergeCategories(sourceIDs []int64, targetID int64) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	target, ok := r.categories[targetID]
+	if !ok {
+		return 0, apperrors.ErrCategoryNotFound
+	}
+	sources := make(map[int64]bool, len(sourceIDs))
+	for _, id := range sourceIDs {
+		if _, ok := r.categories[id]; !ok || sources[id] {
+			return 0, apperrors.ErrCategoryNotFound
+		}
+		sources[id] = true
+	}
+
+	ch := r.beginChange(model.ActionMergeCategories)
+	tracked := []entityKey{{model.EntityCategory, targetID}}
+	for _, id := range sourceIDs {
+		tracked = append(tracked, entityKey{model.EntityCategory, id})
+	}
+	for _, categories := range []map[int64]*model.Category{r.categories, r.trashCategories} {
+		for _, c := range categories {
+			if sources[c.ParentID] {
+				tracked = append(tracked, entityKey{model.EntityCategory, c.ID})
+			}
+		}
+	}
+	for _, records := range []map[int64]*model.Record{r.records, r.trashRecords} {
+		for _, rec := range records {
+			if sources[rec.CategoryID] {
+				tracked = append(tracked, entityKey{model.EntityRecord, rec.ID})
+			}
+		}
+	}
+	for _, b := range r.budgets {
+		if sources[b.CategoryID] {
+			tracked = append(tracked, entityKey{model.EntityBudget, b.ID})
+		}
+	}
+	for _, rule := range r.rules {
+		if sources[rule.CategoryID] {
+			tracked = append(tracked, entityKey{model.EntityRule, rule.ID})
+		}
+	}
+	for _, k := range tracked {
+		if err := ch.track(k.entity, k.id); err != nil {
+			return 0, err
+		}
+	}
+
+	moved := 0
+	for _, rec := range r.records {
+		if sources[rec.CategoryID] {
+			rec.CategoryID = targetID
+			moved++
+		}
+	}
+	// 回收站中的记录和子分类也一并转移，源分类删除后不会留下悬空引用
+	for _, rec := range r.trashRecords {
+		if sources[rec.CategoryID] {
+			rec.CategoryID = targetID
+			moved++
+		}
+	}
+	for _, c := range r.trashCategories {
+		if sources[c.ParentID] {
+			c.ParentID = targetID
+		}
+	}
+	for _, rule := range r.rules {
+		if sources[rule.CategoryID] {
+			rule.CategoryID = targetID
+		}
+	}
+
+	// 按源分类顺序、预算 ID 顺序转移预算，目标分类当月已有预算时丢弃
+	budgetIDs := make([]int64, 0, len(r.budgets))
+	for id := range r.budgets {
+		budgetIDs = append(budgetIDs, id)
+	}
+	sort.Slice(budgetIDs, func(i, j int) bool { return budgetIDs[i] < budgetIDs[j] })
+	months := make(map[string]bool)
+	for _, id := range budgetIDs {
+		if b := r.budgets[id]; b.CategoryID == targetID {
+			months[b.Month] = true
+		}
+	}
+	for _, source := range sourceIDs {
+		for _, id := range budgetIDs {
+			b, ok := r.budgets[id]
+			if !ok || b.CategoryID != source {
+				continue
+			}
+			if months[b.Month] {
+				delete(r.budgets, id)
+				continue
+			}
+			b.CategoryID = targetID
+			months[b.Month] = true
+		}
+	}
+
+	for _, c := range r.categories {
+		if sources[c.ParentID] {
+			c.ParentID = targetID
+		}
+	}
+	if target.ParentID == targetID {
+		target.ParentID = 0
+	}
+	for id := range sources {
+		delete(r.categories, id)
+	}
+	return moved, ch.commit()
+}
+
+// UpdateCategoryOrder 更新同一上级分类下的分类排序
+func (r *MemoryRepository) UpdateCategoryOrder(parentID int64, ids []int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// 先整体校验，与 SQLite 的事务回滚语义一致
+	for _, id := range ids {
+		if c, ok := r.categories[id]; !ok || c.ParentID != parentID {
+			return apperrors.ErrInvalidParent
+		}
+	}
+	ch := r.beginChange(model.ActionReorderCategories)
+	for _, id := range ids {
+		if err := ch.track(model.EntityCategory, id); err != nil {
+			return err
+		}
+	}
+	for i, id := range ids {
+		r.categories[id].SortOrder = i + 1
+	}
+	return ch.commit()
+}
+
+// ============ Record 操作 ============
+
+// withCategory 返回附带分类摘要和标签的记录副本，字段与 SQLite 的查询结果一致
+func (r *MemoryRepository) withCategory(rec *model.Record) model.Record {
+	out := *rec
+	out.Category = nil
+	c, ok := r.categories[rec.CategoryID]
+	if !ok {
+		c, ok = r.trashCategories[rec.CategoryID]
+	}
+	if ok {
+		out.Category = &model.Category{ID: c.ID, Name: c.Name, Icon: c.Icon, Type: c.Type}
+	}
+
+	out.Tags = []model.Tag{}
+	for id := range r.recordTags[rec.ID] {
+		if t, ok := r.tags[id]; ok {
+			out.Tags = append(out.Tags, *t)
+		}
+	}
+	sortTags(out.Tags)
+	return out
+}
+
+// sortedRecords 返回满足条件的记录，按日期、创建时间、ID 倒序
+func (r *MemoryRepository) sortedRecords(match func(rec *model.Record) bool) []model.Record {
+	var records []model.Record
+	for _, rec := range r.records {
+		if match(rec) {
+			records = append(records, r.withCategory(rec))
+		}
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		a, b := records[i], records[j]
+		if a.Date != b.Date {
+			return a.Date > b.Date
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.ID > b.ID
+	})
+	return records
+}
+
+// insertRecord 保存记录及其标签关联并回填 ID，调用方需持有写锁
+func (r *MemoryRepository) insertRecord(rec *model.Record) {
+	r.nextRecordID++
+	stored := *rec
+	stored.ID = r.nextRecordID
+	stored.Category = nil
+	stored.Tags = nil
+	stored.CreatedAt = now()
+	r.records[stored.ID] = &stored
+
+	if len(rec.Tags) > 0 {
+		r.recordTags[stored.ID] = make(map[int64]bool)
+		for _, t := range rec.Tags {
+			r.recordTags[stored.ID][t.ID] = true
+		}
+	}
+	rec.ID = stored.ID
+}
+
+// CreateRecord 创建记录
+func (r *MemoryRepository) CreateRecord(rec *model.Record) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.insertRecord(rec)
+	ch := r.beginChange(model.ActionCreateRecord)
+	ch.created(model.EntityRecord, rec.ID)
+	return ch.commit()
+}
+
+// UpdateRecord 更新记录及其标签
+func (r *MemoryRepository) UpdateRecord(rec *model.Record) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.records[rec.ID]
+	if !ok {
+		return apperrors.ErrRecordNotFound
+	}
+
+	ch := r.beginChange(model.ActionUpdateRecord)
+	if err := ch.track(model.EntityRecord, rec.ID); err != nil {
+		return err
+	}
+	existing.Amount = rec.Amount
+	existing.CategoryID = rec.CategoryID
+	existing.AccountID = rec.AccountID
+	existing.ToAccountID = rec.ToAccountID
+	existing.Note = rec.Note
+	existing.Date = rec.Date
+	tags := make(map[int64]bool, len(rec.Tags))
+	for _, tag := range rec.Tags {
+		tags[tag.ID] = true
+	}
+	r.recordTags[rec.ID] = tags
+	return ch.commit()
+}
+
+// DeleteRecord 将记录移入回收站，标签关联保留以便恢复
+func (r *MemoryRepository) DeleteRecord(id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rec, ok := r.records[id]
+	if !ok {
+		return apperrors.ErrRecordNotFound
+	}
+	ch := r.beginChange(model.ActionDeleteRecord)
+	if err := ch.track(model.EntityRecord, id); err != nil {
+		return err
+	}
+	deletedAt := now()
+	rec.DeletedAt = &deletedAt
+	r.trashRecords[id] = rec
+	delete(r.records, id)
+	return ch.commit()
+}
+
+// DeleteRecords 将多条记录移入回收站
+func (r *MemoryRepository) DeleteRecords(ids []int64) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ch := r.beginChange(model.ActionDeleteRecord)
+	deletedAt := now()
+	count := 0
+	for _, id := range ids {
+		rec, ok := r.records[id]
+		if !ok {
+			continue
+		}
+		if err := ch.track(model.EntityRecord, id); err != nil {
+			return 0, err
+		}
+		rec.DeletedAt = &deletedAt
+		r.trashRecords[id] = rec
+		delete(r.records, id)
+		count++
+	}
+	return count, ch.commit()
+}
+
+// GetRecordByID 根据 ID 获取记录
+func (r *MemoryRepository) GetRecordByID(id int64) (*model.Record, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rec, ok := r.records[id]
+	if !ok {
+		return nil, apperrors.ErrRecordNotFound
+	}
+	out := r.withCategory(rec)
+	return &out, nil
+}
+
+// ListRecordsBetween 获取日期区间 [startDate, endDate) 内的记录
+func (r *MemoryRepository) ListRecordsBetween(startDate, endDate string) ([]model.Record, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.sortedRecords(func(rec *model.Record) bool {
+		return rec.Date >= startDate && rec.Date < endDate
+	}), nil
+}
+
+// GetRecentRecords 获取最近 N 条记录
+func (r *MemoryRepository) GetRecentRecords(limit int) ([]model.Record, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	records := r.sortedRecords(func(rec *model.Record) bool { return true })
+	if limit >= 0 && len(records) > limit {
+		records = records[:limit]
+	}
+	return records, nil
+}
+
+// GetAllRecords 获取所有记录
+func (r *MemoryRepository) GetAllRecords() ([]model.Record, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.sortedRecords(func(rec *model.Record) bool { return true }), nil
+}
+
+// ============ 统计查询 ============
+
+// GetStatTotals 获取日期区间内按日期、类型、分类和币种分组的收支合计
+func (r *MemoryRepository) GetStatTotals(startDate, endDate string) ([]model.StatTotal, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	type key struct {
+		date, recordType, currency string
+		categoryID                 int64
+	}
+	sums := make(map[key]int64)
+	for _, rec := range r.records {
+		if rec.Type != model.TypeIncome && rec.Type != model.TypeExpense {
+			continue
+		}
+		if rec.Date >= startDate && rec.Date < endDate {
+			sums[key{rec.Date, rec.Type, rec.Amount.Currency, rec.CategoryID}] += rec.Amount.Minor
+		}
+	}
+
+	totals := make([]model.StatTotal, 0, len(sums))
+	for k, minor := range sums {
+		totals = append(totals, model.StatTotal{
+			Date:       k.date,
+			Type:       k.recordType,
+			CategoryID: k.categoryID,
+			Amount:     model.Money{Minor: minor, Currency: k.currency},
+		})
+	}
+
+	sort.Slice(totals, func(i, j int) bool {
+		a, b := totals[i], totals[j]
+		if a.Date != b.Date {
+			return a.Date < b.Date
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		if a.CategoryID != b.CategoryID {
+			return a.CategoryID < b.CategoryID
+		}
+		return a.Amount.Currency < b.Amount.Currency
+	})
+	return totals, nil
+}
+
+// ============ 设置 ============
+
+// GetSetting 读取设置项，不存在时返回空字符串
+func (r *MemoryRepository) GetSetting(key string) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.settings[key], nil
+}
+
+// SetSetting 写入设置项
+func (r *MemoryRepository) SetSetting(key, value string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.settings[key] = value
+	return nil
+}
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"sort"
+
+	apperrors "dog-view/internal/errors"
+	"dog-view/internal/model"
+)
+
+// ============ Account 操作 ============
+
+// accountNameTaken 判断账户名称是否已被其他账户占用
+func (r *MemoryRepository) accountNameTaken(name string, exceptID int64) bool {
+	for _, a := range r.accounts {
+		if a.Name == name && a.ID != exceptID {
+			return true
+		}
+	}
+	return false
+}
+
+// ListAccounts 获取账户列表
+func (r *MemoryRepository) ListAccounts() ([]model.Account, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.sortedAccounts(), nil
+}
+
+func (r *MemoryRepository) sortedAccounts() []model.Account {
+	var accounts []model.Account
+	for _, a := range r.accounts {
+		accounts = append(accounts, *a)
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		if accounts[i].SortOrder != accounts[j].SortOrder {
+			return accounts[i].SortOrder < accounts[j].SortOrder
+		}
+		return accounts[i].ID < accounts[j].ID
+	})
+	return accounts
+}
+
+// GetAccountByID 根据 ID 获取账户
+func (r *MemoryRepository) GetAccountByID(id int64) (*model.Account, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	a, ok := r.accounts[id]
+	if !ok {
+		return nil, apperrors.ErrAccountNotFound
+	}
+	found := *a
+	return &found, nil
+}
+
+// CreateAccount 创建账户
+func (r *MemoryRepository) CreateAccount(a *model.Account) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.accountNameTaken(a.Name, 0) {
+		return apperrors.ErrDuplicateAccount
+	}
+
+	r.nextAccountID++
+	stored := *a
+	stored.ID = r.nextAccountID
+	stored.CreatedAt = now()
+	r.accounts[stored.ID] = &stored
+
+	a.ID = stored.ID
+	ch := r.beginChange(model.ActionCreateAccount)
+	ch.created(model.EntityAccount, stored.ID)
+	return ch.commit()
+}
+
+// UpdateAccount 更新账户，仍有记录引用时不能修改币种
+func (r *MemoryRepository) UpdateAccount(a *model.Account) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.accounts[a.ID]
+	if !ok {
+		return apperrors.ErrAccountNotFound
+	}
+	if r.accountNameTaken(a.Name, a.ID) {
+		return apperrors.ErrDuplicateAccount
+	}
+	if existing.OpeningBalance.Currency != a.OpeningBalance.Currency && r.accountReferenced(a.ID) {
+		return apperrors.ErrCurrencyMismatch
+	}
+
+	ch := r.beginChange(model.ActionUpdateAccount)
+	if err := ch.track(model.EntityAccount, a.ID); err != nil {
+		return err
+	}
+	existing.Name = a.Name
+	existing.Type = a.Type
+	existing.Icon = a.Icon
+	existing.OpeningBalance = a.OpeningBalance
+	return ch.commit()
+}
+
+// accountReferenced 判断账户是否仍被记录引用，回收站中的记录同样算作引用
+func (r *MemoryRepository) accountReferenced(id int64) bool {
+	for _, records := range []map[int64]*model.Record{r.records, r.trashRecords} {
+		for _, rec := range records {
+			if rec.AccountID == id || rec.ToAccountID == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// DeleteAccount 删除账户，回收站中的记录同样算作引用
+func (r *MemoryRepository) DeleteAccount(id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.accountReferenced(id) {
+		return apperrors.ErrAccountInUse
+	}
+	if _, ok := r.accounts[id]; !ok {
+		return apperrors.ErrAccountNotFound
+	}
+
+	ch := r.beginChange(model.ActionDeleteAccount)
+	if err := ch.track(model.EntityAccount, id); err != nil {
+		return err
+	}
+	delete(r.accounts, id)
+	return ch.commit()
+}
+
+// GetAccountBalances 计算所有账户在 endDate 之前（不含）的余额
+func (r *MemoryRepository) GetAccountBalances(endDate string) ([]model.AccountBalance, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	flows := make(map[int64]int64)
+	for _, rec := range r.records {
+		if rec.Date >= endDate {
+			continue
+		}
+		if rec.AccountID != 0 {
+			if rec.Type == model.TypeIncome {
+				flows[rec.AccountID] += rec.Amount.Minor
+			} else {
+				flows[rec.AccountID] -= rec.Amount.Minor
+			}
+		}
+		if rec.Type == model.TypeTransfer && rec.ToAccountID != 0 {
+			flows[rec.ToAccountID] += rec.Amount.Minor
+		}
+	}
+
+	accounts := r.sortedAccounts()
+	balances := make([]model.AccountBalance, 0, len(accounts))
+	for _, a := range accounts {
+		balances = append(balances, model.AccountBalance{
+			Account: a,
+			Balance: model.NewMoney(a.OpeningBalance.Minor+flows[a.ID], a.OpeningBalance.Currency),
+		})
+	}
+	return balances, nil
+}
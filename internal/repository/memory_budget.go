@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"sort"
+
+	apperrors "dog-view/internal/errors"
+	"dog-view/internal/model"
+)
+
+// ============ 预算 ============
+
+// ListBudgets 获取 month 及之前各月设置的预算
+func (r *MemoryRepository) ListBudgets(month string) ([]model.Budget, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var budgets []model.Budget
+	for _, b := range r.budgets {
+		if month == "" || b.Month <= month {
+			budgets = append(budgets, *b)
+		}
+	}
+
+	sort.Slice(budgets, func(i, j int) bool {
+		if budgets[i].Month != budgets[j].Month {
+			return budgets[i].Month < budgets[j].Month
+		}
+		return budgets[i].CategoryID < budgets[j].CategoryID
+	})
+	return budgets, nil
+}
+
+// SaveBudget 保存预算，同一月份同一分类已有预算时覆盖
+func (r *MemoryRepository) SaveBudget(b *model.Budget) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ch := r.beginChange(model.ActionSetBudget)
+	for _, existing := range r.budgets {
+		if existing.Month == b.Month && existing.CategoryID == b.CategoryID {
+			if err := ch.track(model.EntityBudget, existing.ID); err != nil {
+				return err
+			}
+			existing.Amount = b.Amount
+			existing.Recurring = b.Recurring
+			b.ID = existing.ID
+			return ch.commit()
+		}
+	}
+
+	r.nextBudgetID++
+	stored := *b
+	stored.ID = r.nextBudgetID
+	stored.CreatedAt = now()
+	r.budgets[stored.ID] = &stored
+
+	b.ID = stored.ID
+	ch.created(model.EntityBudget, stored.ID)
+	return ch.commit()
+}
+
+// DeleteBudget 删除预算
+func (r *MemoryRepository) DeleteBudget(id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.budgets[id]; !ok {
+		return apperrors.ErrBudgetNotFound
+	}
+	ch := r.beginChange(model.ActionDeleteBudget)
+	if err := ch.track(model.EntityBudget, id); err != nil {
+		return err
+	}
+	delete(r.budgets, id)
+	return ch.commit()
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"sort"
+
+	apperrors "dog-view/internal/errors"
+	"dog-view/internal/model"
+)
+
+// ============ CSV 列映射方案 ============
+
+// cloneCSVProfile 复制方案，避免与调用方共享映射中的切片
+func cloneCSVProfile(p model.CSVProfile) *model.CSVProfile {
+	p.Mapping.Notes = append([]model.CSVColumn(nil), p.Mapping.Notes...)
+	p.Mapping.IncomeLabels = append([]string(nil), p.Mapping.IncomeLabels...)
+	p.Mapping.ExpenseLabels = append([]string(nil), p.Mapping.ExpenseLabels...)
+	return &p
+}
+
+// profileNameTaken 判断方案名称是否已被其他方案占用
+func (r *MemoryRepository) profileNameTaken(name string, exceptID int64) bool {
+	for _, p := range r.csvProfiles {
+		if p.Name == name && p.ID != exceptID {
+			return true
+		}
+	}
+	return false
+}
+
+// ListCSVProfiles 获取全部列映射方案
+func (r *MemoryRepository) ListCSVProfiles() ([]model.CSVProfile, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var profiles []model.CSVProfile
+	for _, p := range r.csvProfiles {
+		profiles = append(profiles, *cloneCSVProfile(*p))
+	}
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].Name < profiles[j].Name
+	})
+	return profiles, nil
+}
+
+// GetCSVProfile 根据 ID 获取列映射方案
+func (r *MemoryRepository) GetCSVProfile(id int64) (*model.CSVProfile, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	p, ok := r.csvProfiles[id]
+	if !ok {
+		return nil, apperrors.ErrProfileNotFound
+	}
+	return cloneCSVProfile(*p), nil
+}
+
+// CreateCSVProfile 创建列映射方案
+func (r *MemoryRepository) CreateCSVProfile(p *model.CSVProfile) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.profileNameTaken(p.Name, 0) {
+		return apperrors.ErrDuplicateProfile
+	}
+
+	r.nextProfileID++
+	stored := cloneCSVProfile(*p)
+	stored.ID = r.nextProfileID
+	stored.CreatedAt = now()
+	r.csvProfiles[stored.ID] = stored
+
+	p.ID = stored.ID
+	return nil
+}
+
+// UpdateCSVProfile 更新列映射方案的名称和映射
+func (r *MemoryRepository) UpdateCSVProfile(p *model.CSVProfile) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.csvProfiles[p.ID]
+	if !ok {
+		return apperrors.ErrProfileNotFound
+	}
+	if r.profileNameTaken(p.Name, p.ID) {
+		return apperrors.ErrDuplicateProfile
+	}
+
+	existing.Name = p.Name
+	existing.Mapping = cloneCSVProfile(*p).Mapping
+	return nil
+}
+
+// DeleteCSVProfile 删除列映射方案
+func (r *MemoryRepository) DeleteCSVProfile(id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.csvProfiles[id]; !ok {
+		return apperrors.ErrProfileNotFound
+	}
+	delete(r.csvProfiles, id)
+	return nil
+}
@@ -0,0 +1,387 @@
+package repository
+
+import (
+	"encoding/json"
+
+	apperrors "dog-view/internal/errors"
+	"dog-view/internal/model"
+)
+
+// ============ 变更日志 ============
+
+// memChangeRecorder 与 changeRecorder 语义一致：修改前记录快照，
+// commit 时读取修改后的快照并追加变更集。调用方需持有写锁
+type memChangeRecorder struct {
+	r        *MemoryRepository
+	action   string
+	targetID int64
+	changes  []model.Change
+	tracked  map[entityKey]bool
+}
+
+func (r *MemoryRepository) beginChange(action string) *memChangeRecorder {
+	return &memChangeRecorder{r: r, action: action, tracked: make(map[entityKey]bool)}
+}
+
+// track 在修改前记录实体的快照，同一实体只记录第一次
+func (ch *memChangeRecorder) track(entity string, id int64) error {
+	key := entityKey{entity, id}
+	if ch.tracked[key] {
+		return nil
+	}
+	before, err := ch.r.snapshot(entity, id)
+	if err != nil {
+		return err
+	}
+	ch.tracked[key] = true
+	ch.changes = append(ch.changes, model.Change{Entity: entity, EntityID: id, Before: before})
+	return nil
+}
+
+// created 记录新建的实体，其修改前的快照为空
+func (ch *memChangeRecorder) created(entity string, id int64) {
+	ch.tracked[entityKey{entity, id}] = true
+	ch.changes = append(ch.changes, model.Change{Entity: entity, EntityID: id})
+}
+
+// commit 追加变更集，没有任何变化时不追加
+func (ch *memChangeRecorder) commit() error {
+	r := ch.r
+	var changes []model.Change
+	for _, c := range ch.changes {
+		after, err := r.snapshot(c.Entity, c.EntityID)
+		if err != nil {
+			return err
+		}
+		same, err := sameSnapshot(c.Entity, c.Before, after)
+		if err != nil {
+			return err
+		}
+		if !same {
+			c.After = after
+			changes = append(changes, c)
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	r.nextChangeSetID++
+	set := model.ChangeSet{ID: r.nextChangeSetID, Action: ch.action, TargetID: ch.targetID, CreatedAt: now()}
+	for _, c := range changes {
+		r.nextChangeID++
+		c.ID = r.nextChangeID
+		c.SetID = set.ID
+		c.Action = set.Action
+		c.CreatedAt = set.CreatedAt
+		set.Changes = append(set.Changes, c)
+	}
+	r.changeSets = append(r.changeSets, set)
+	return nil
+}
+
+// snapshot 返回实体当前的快照（包括回收站中的），不存在时返回 nil
+func (r *MemoryRepository) snapshot(entity string, id int64) (json.RawMessage, error) {
+	switch entity {
+	case model.EntityRecord:
+		rec, ok := r.records[id]
+		if !ok {
+			rec, ok = r.trashRecords[id]
+		}
+		if !ok {
+			return nil, nil
+		}
+		return marshalSnapshot(r.withCategory(rec), true)
+	case model.EntityCategory:
+		c, ok := r.categories[id]
+		if !ok {
+			c, ok = r.trashCategories[id]
+		}
+		if !ok {
+			return nil, nil
+		}
+		out := *c
+		out.Children = nil
+		return marshalSnapshot(out, true)
+	case model.EntityAccount:
+		a, ok := r.accounts[id]
+		if !ok {
+			return nil, nil
+		}
+		return marshalSnapshot(*a, true)
+	case model.EntityBudget:
+		b, ok := r.budgets[id]
+		if !ok {
+			return nil, nil
+		}
+		return marshalSnapshot(*b, true)
+	case model.EntityRule:
+		rule, ok := r.rules[id]
+		if !ok {
+			return nil, nil
+		}
+		return marshalSnapshot(*rule, true)
+	case model.EntityOccurrence:
+		_, o, ok := r.occurrenceByID(id)
+		if !ok {
+			return nil, nil
+		}
+		return marshalSnapshot(*o, true)
+	default:
+		t, ok := r.tags[id]
+		if !ok {
+			return nil, nil
+		}
+		return marshalSnapshot(*t, true)
+	}
+}
+
+// put 将实体写成快照中的状态，快照为 nil 时彻底删除实体；名称冲突时不做任何修改
+func (r *MemoryRepository) put(entity string, id int64, data json.RawMessage) error {
+	switch entity {
+	case model.EntityRecord:
+		var rec *model.Record
+		if data != nil {
+			rec = &model.Record{}
+			if err := json.Unmarshal(data, rec); err != nil {
+				return err
+			}
+		}
+		delete(r.records, id)
+		delete(r.trashRecords, id)
+		delete(r.recordTags, id)
+		if rec == nil {
+			return nil
+		}
+
+		// 快照之后被彻底删除的标签不再关联
+		for _, t := range rec.Tags {
+			if _, ok := r.tags[t.ID]; !ok {
+				continue
+			}
+			if r.recordTags[id] == nil {
+				r.recordTags[id] = make(map[int64]bool)
+			}
+			r.recordTags[id][t.ID] = true
+		}
+		rec.ID = id
+		rec.Category = nil
+		rec.Tags = nil
+		if rec.DeletedAt != nil {
+			r.trashRecords[id] = rec
+		} else {
+			r.records[id] = rec
+		}
+	case model.EntityCategory:
+		var c *model.Category
+		if data != nil {
+			c = &model.Category{}
+			if err := json.Unmarshal(data, c); err != nil {
+				return err
+			}
+			if c.DeletedAt == nil && r.nameTaken(c.Name, id) {
+				return apperrors.ErrDuplicateCategory
+			}
+		}
+		delete(r.categories, id)
+		delete(r.trashCategories, id)
+		if c == nil {
+			return nil
+		}
+		c.ID = id
+		c.Children = nil
+		if c.DeletedAt != nil {
+			r.trashCategories[id] = c
+		} else {
+			r.categories[id] = c
+		}
+	case model.EntityAccount:
+		if data == nil {
+			// 仍有记录引用的账户不能删除
+			if r.accountReferenced(id) {
+				return apperrors.ErrUndoConflict
+			}
+			delete(r.accounts, id)
+			return nil
+		}
+		a := &model.Account{}
+		if err := json.Unmarshal(data, a); err != nil {
+			return err
+		}
+		if r.accountNameTaken(a.Name, id) {
+			return apperrors.ErrDuplicateAccount
+		}
+		a.ID = id
+		r.accounts[id] = a
+	case model.EntityBudget:
+		if data == nil {
+			delete(r.budgets, id)
+			return nil
+		}
+		b := &model.Budget{}
+		if err := json.Unmarshal(data, b); err != nil {
+			return err
+		}
+		// 同一月份同一分类已有其他预算时不能写回
+		for _, existing := range r.budgets {
+			if existing.ID != id && existing.Month == b.Month && existing.CategoryID == b.CategoryID {
+				return apperrors.ErrUndoConflict
+			}
+		}
+		b.ID = id
+		r.budgets[id] = b
+	case model.EntityRule:
+		if data == nil {
+			delete(r.rules, id)
+			return nil
+		}
+		rule := &model.RecurringRule{}
+		if err := json.Unmarshal(data, rule); err != nil {
+			return err
+		}
+		rule.ID = id
+		r.rules[id] = rule
+	case model.EntityOccurrence:
+		var o *model.Occurrence
+		if data != nil {
+			o = &model.Occurrence{}
+			if err := json.Unmarshal(data, o); err != nil {
+				return err
+			}
+			// 同一次发生已有其他记录（如已自动入账）时不能写回
+			if existing, ok := r.occurrences[occurrenceKey{o.RuleID, o.Date}]; ok && existing.ID != id {
+				return apperrors.ErrUndoConflict
+			}
+		}
+		if key, _, ok := r.occurrenceByID(id); ok {
+			delete(r.occurrences, key)
+		}
+		if o == nil {
+			return nil
+		}
+		o.ID = id
+		r.occurrences[occurrenceKey{o.RuleID, o.Date}] = o
+	default:
+		if data == nil {
+			delete(r.tags, id)
+			for _, tagIDs := range r.recordTags {
+				delete(tagIDs, id)
+			}
+			return nil
+		}
+		t := &model.Tag{}
+		if err := json.Unmarshal(data, t); err != nil {
+			return err
+		}
+		if r.tagNameTaken(t.Name, id) {
+			return apperrors.ErrDuplicateTag
+		}
+		t.ID = id
+		r.tags[id] = t
+	}
+	return nil
+}
+
+// ListChanges 获取实体的全部变更，按先后顺序排列
+func (r *MemoryRepository) ListChanges(entity string, entityID int64) ([]model.Change, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	changes := []model.Change{}
+	for _, set := range r.changeSets {
+		for _, c := range set.Changes {
+			if c.Entity == entity && c.EntityID == entityID {
+				changes = append(changes, c)
+			}
+		}
+	}
+	return changes, nil
+}
+
+// ListChangeSets 获取 ID 大于 afterID 的变更集，不含具体变更
+func (r *MemoryRepository) ListChangeSets(afterID int64) ([]model.ChangeSet, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sets := []model.ChangeSet{}
+	for _, set := range r.changeSets {
+		if set.ID > afterID {
+			set.Changes = nil
+			sets = append(sets, set)
+		}
+	}
+	return sets, nil
+}
+
+// LatestChangeSetID 获取最近一个变更集的 ID，没有变更时返回 0
+func (r *MemoryRepository) LatestChangeSetID() (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.nextChangeSetID, nil
+}
+
+// RevertChangeSet 撤销变更集
+func (r *MemoryRepository) RevertChangeSet(id int64) error {
+	return r.replayChangeSet(id, true)
+}
+
+// ReapplyChangeSet 重做变更集
+func (r *MemoryRepository) ReapplyChangeSet(id int64) error {
+	return r.replayChangeSet(id, false)
+}
+
+// replayChangeSet 先整体校验再修改；写入中途失败时按相反顺序写回原状态，
+// 与 SQLite 的事务回滚语义一致
+func (r *MemoryRepository) replayChangeSet(id int64, undo bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var changes []model.Change
+	for _, set := range r.changeSets {
+		if set.ID == id {
+			changes = set.Changes
+			break
+		}
+	}
+	if len(changes) == 0 {
+		return apperrors.ErrChangeSetNotFound
+	}
+
+	action := model.ActionRedo
+	if undo {
+		action = model.ActionUndo
+	}
+	ch := r.beginChange(action)
+	ch.targetID = id
+
+	steps := replaySteps(changes, undo)
+	originals := make([]json.RawMessage, len(steps))
+	for i, s := range steps {
+		current, err := r.snapshot(s.Entity, s.EntityID)
+		if err != nil {
+			return err
+		}
+		same, err := sameSnapshot(s.Entity, current, s.Before)
+		if err != nil {
+			return err
+		}
+		if !same {
+			return apperrors.ErrUndoConflict
+		}
+		originals[i] = current
+		if err := ch.track(s.Entity, s.EntityID); err != nil {
+			return err
+		}
+	}
+	for i, s := range steps {
+		if err := r.put(s.Entity, s.EntityID, s.After); err != nil {
+			for j := i - 1; j >= 0; j-- {
+				r.put(steps[j].Entity, steps[j].EntityID, originals[j])
+			}
+			return err
+		}
+	}
+
+	return ch.commit()
+}
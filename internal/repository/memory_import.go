@@ -0,0 +1,178 @@
+package repository
+
+import (
+	apperrors "dog-view/internal/errors"
+	"dog-view/internal/model"
+)
+
+// ============ 导入批次 ============
+
+// CreateImportBatch 创建导入批次，先整体校验再修改，与 SQLite 的事务语义一致
+func (r *MemoryRepository) CreateImportBatch(batch *model.ImportBatch, categories []model.Category, tags []model.Tag, records []model.Record) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make(map[string]bool)
+	for i, c := range categories {
+		if r.nameTaken(c.Name, 0) || names[c.Name] {
+			return apperrors.ErrDuplicateCategory
+		}
+		names[c.Name] = true
+		if -c.ParentID > int64(i) {
+			return apperrors.ErrImportFailed
+		}
+	}
+	names = make(map[string]bool)
+	for _, t := range tags {
+		if r.tagNameTaken(t.Name, 0) || names[t.Name] {
+			return apperrors.ErrDuplicateTag
+		}
+		names[t.Name] = true
+	}
+	for _, rec := range records {
+		if -rec.CategoryID > int64(len(categories)) {
+			return apperrors.ErrImportFailed
+		}
+		for _, t := range rec.Tags {
+			if -t.ID > int64(len(tags)) {
+				return apperrors.ErrImportFailed
+			}
+		}
+	}
+
+	r.nextBatchID++
+	batchID := r.nextBatchID
+	ch := r.beginChange(model.ActionImport)
+	ch.targetID = batchID
+
+	categoryIDs := make([]int64, 0, len(categories))
+	for i := range categories {
+		c := &categories[i]
+		c.ParentID, _ = importRef(c.ParentID, categoryIDs)
+		c.ImportBatch = batchID
+		c.ID = r.insertCategory(*c).ID
+		ch.created(model.EntityCategory, c.ID)
+		categoryIDs = append(categoryIDs, c.ID)
+	}
+
+	tagIDs := make([]int64, 0, len(tags))
+	for i := range tags {
+		r.nextTagID++
+		stored := tags[i]
+		stored.ID = r.nextTagID
+		stored.CreatedAt = now()
+		r.tags[stored.ID] = &stored
+		tags[i].ID = stored.ID
+		ch.created(model.EntityTag, stored.ID)
+		tagIDs = append(tagIDs, stored.ID)
+	}
+
+	for i := range records {
+		rec := &records[i]
+		rec.CategoryID, _ = importRef(rec.CategoryID, categoryIDs)
+		for j := range rec.Tags {
+			rec.Tags[j].ID, _ = importRef(rec.Tags[j].ID, tagIDs)
+		}
+		rec.ImportBatch = batchID
+		r.insertRecord(rec)
+		ch.created(model.EntityRecord, rec.ID)
+	}
+
+	*batch = model.ImportBatch{
+		ID:         batchID,
+		Source:     batch.Source,
+		Records:    len(records),
+		Categories: len(categories),
+		Tags:       len(tags),
+		CreatedAt:  now(),
+	}
+	r.importBatches = append(r.importBatches, *batch)
+	return ch.commit()
+}
+
+// ListImportBatches 获取全部导入批次，最新的在前
+func (r *MemoryRepository) ListImportBatches() ([]model.ImportBatch, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	batches := make([]model.ImportBatch, 0, len(r.importBatches))
+	for i := len(r.importBatches) - 1; i >= 0; i-- {
+		batches = append(batches, r.importBatches[i])
+	}
+	return batches, nil
+}
+
+// DeleteImportBatch 将导入批次的记录和分类移入回收站
+func (r *MemoryRepository) DeleteImportBatch(id int64) (model.ImportUndoResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var result model.ImportUndoResult
+	found := false
+	for _, b := range r.importBatches {
+		if b.ID == id {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return result, apperrors.ErrImportBatchNotFound
+	}
+
+	ch := r.beginChange(model.ActionUndoImport)
+	ch.targetID = id
+	deletedAt := now()
+
+	for rid, rec := range r.records {
+		if rec.ImportBatch != id {
+			continue
+		}
+		if err := ch.track(model.EntityRecord, rid); err != nil {
+			return result, err
+		}
+		rec.DeletedAt = &deletedAt
+		r.trashRecords[rid] = rec
+		delete(r.records, rid)
+		result.Records++
+	}
+
+	// 先删除子分类，上级分类在下一轮才不再有子分类
+	for {
+		var ids []int64
+		for cid, c := range r.categories {
+			if c.ImportBatch == id && !r.categoryUsed(cid) {
+				ids = append(ids, cid)
+			}
+		}
+		if len(ids) == 0 {
+			break
+		}
+		for _, cid := range ids {
+			if err := ch.track(model.EntityCategory, cid); err != nil {
+				return result, err
+			}
+			c := r.categories[cid]
+			c.DeletedAt = &deletedAt
+			r.trashCategories[cid] = c
+			delete(r.categories, cid)
+		}
+		result.Categories += len(ids)
+	}
+
+	return result, ch.commit()
+}
+
+// categoryUsed 判断分类是否仍被未删除的记录或子分类使用
+func (r *MemoryRepository) categoryUsed(id int64) bool {
+	for _, rec := range r.records {
+		if rec.CategoryID == id {
+			return true
+		}
+	}
+	for _, c := range r.categories {
+		if c.ParentID == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"sort"
+	"strings"
+
+	"dog-view/internal/model"
+)
+
+// ============ 记录查询 ============
+
+// containsID 判断 ids 中是否包含 id
+func containsID(ids []int64, id int64) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// matchFilter 判断记录是否满足筛选条件，调用方需持有读锁
+func (r *MemoryRepository) matchFilter(rec *model.Record, filter model.RecordFilter) bool {
+	if filter.StartDate != "" && rec.Date < filter.StartDate {
+		return false
+	}
+	if filter.EndDate != "" && rec.Date >= filter.EndDate {
+		return false
+	}
+	if len(filter.Types) > 0 {
+		matched := false
+		for _, t := range filter.Types {
+			matched = matched || rec.Type == t
+		}
+		if !matched {
+			return false
+		}
+	}
+	if len(filter.CategoryIDs) > 0 && !containsID(filter.CategoryIDs, rec.CategoryID) {
+		return false
+	}
+	if len(filter.TagIDs) > 0 && !r.hasTags(rec.ID, filter.TagIDs, filter.MatchAllTags) {
+		return false
+	}
+	if filter.MinAmount != 0 && rec.Amount.Minor < filter.MinAmount {
+		return false
+	}
+	if filter.MaxAmount != 0 && rec.Amount.Minor > filter.MaxAmount {
+		return false
+	}
+	// 与 SQLite 的 LIKE 一致，英文字母不区分大小写
+	if filter.Note != "" && !strings.Contains(strings.ToLower(rec.Note), strings.ToLower(filter.Note)) {
+		return false
+	}
+	return true
+}
+
+// ListRecords 按筛选条件获取记录
+func (r *MemoryRepository) ListRecords(filter model.RecordFilter) ([]model.Record, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.sortedRecords(func(rec *model.Record) bool {
+		return r.matchFilter(rec, filter)
+	}), nil
+}
+
+// QueryRecords 按筛选条件、排序和游标获取一页记录
+func (r *MemoryRepository) QueryRecords(q model.RecordQuery) ([]model.Record, error) {
+	var cursor *model.RecordCursor
+	if q.Cursor != "" {
+		c, err := model.ParseRecordCursor(q.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		cursor = &c
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var records []model.Record
+	for _, rec := range r.records {
+		if !r.matchFilter(rec, q.Filter) {
+			continue
+		}
+		if cursor != nil && !cursor.After(*rec, q.SortBy, q.Desc) {
+			continue
+		}
+		records = append(records, r.withCategory(rec))
+	}
+
+	// 以 records[i] 为游标时 records[j] 位于其后，即 i 排在 j 之前
+	sort.Slice(records, func(i, j int) bool {
+		return model.CursorOf(records[i]).After(records[j], q.SortBy, q.Desc)
+	})
+	if q.Limit > 0 && len(records) > q.Limit {
+		records = records[:q.Limit]
+	}
+	return records, nil
+}
+
+// GetRecordTotals 获取筛选结果的记录数，以及按日期、类型和币种分组的收支合计
+func (r *MemoryRepository) GetRecordTotals(filter model.RecordFilter) ([]model.StatTotal, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	type key struct {
+		date, recordType, currency string
+	}
+	count := 0
+	sums := make(map[key]int64)
+	for _, rec := range r.records {
+		if !r.matchFilter(rec, filter) {
+			continue
+		}
+		count++
+		if rec.Type == model.TypeIncome || rec.Type == model.TypeExpense {
+			sums[key{rec.Date, rec.Type, rec.Amount.Currency}] += rec.Amount.Minor
+		}
+	}
+
+	totals := make([]model.StatTotal, 0, len(sums))
+	for k, minor := range sums {
+		totals = append(totals, model.StatTotal{
+			Date:   k.date,
+			Type:   k.recordType,
+			Amount: model.Money{Minor: minor, Currency: k.currency},
+		})
+	}
+
+	sort.Slice(totals, func(i, j int) bool {
+		a, b := totals[i], totals[j]
+		if a.Date != b.Date {
+			return a.Date < b.Date
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.Amount.Currency < b.Amount.Currency
+	})
+	return totals, count, nil
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"sort"
+
+	apperrors "dog-view/internal/errors"
+	"dog-view/internal/model"
+)
+
+// ============ 汇率 ============
+
+// ListExchangeRates 获取全部汇率
+func (r *MemoryRepository) ListExchangeRates() ([]model.ExchangeRate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rates := make([]model.ExchangeRate, 0, len(r.rates))
+	for _, rate := range r.rates {
+		rates = append(rates, *rate)
+	}
+
+	sort.Slice(rates, func(i, j int) bool {
+		a, b := rates[i], rates[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		return a.Date > b.Date
+	})
+	return rates, nil
+}
+
+// SaveExchangeRate 保存汇率，同一币种对同一天已有汇率时覆盖
+func (r *MemoryRepository) SaveExchangeRate(rate *model.ExchangeRate) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.saveExchangeRate(rate)
+	return nil
+}
+
+// SaveExchangeRates 保存多条汇率，持有锁期间完成，不会只保存一部分
+func (r *MemoryRepository) SaveExchangeRates(rates []model.ExchangeRate) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range rates {
+		r.saveExchangeRate(&rates[i])
+	}
+	return nil
+}
+
+// saveExchangeRate 插入或覆盖汇率并回填 ID，调用方需持有 r.mu
+func (r *MemoryRepository) saveExchangeRate(rate *model.ExchangeRate) {
+	for _, existing := range r.rates {
+		if existing.From == rate.From && existing.To == rate.To && existing.Date == rate.Date {
+			existing.Rate = rate.Rate
+			rate.ID = existing.ID
+			return
+		}
+	}
+
+	r.nextRateID++
+	stored := *rate
+	stored.ID = r.nextRateID
+	stored.CreatedAt = now()
+	r.rates[stored.ID] = &stored
+
+	rate.ID = stored.ID
+}
+
+// DeleteExchangeRate 删除汇率
+func (r *MemoryRepository) DeleteExchangeRate(id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.rates[id]; !ok {
+		return apperrors.ErrExchangeRateNotFound
+	}
+	delete(r.rates, id)
+	return nil
+}
+
+// FindExchangeRate 查找 date 当天或之前最近一天的汇率
+func (r *MemoryRepository) FindExchangeRate(from, to, date string) (*model.ExchangeRate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var found *model.ExchangeRate
+	for _, rate := range r.rates {
+		if rate.From != from || rate.To != to || rate.Date > date {
+			continue
+		}
+		if found == nil || rate.Date > found.Date {
+			found = rate
+		}
+	}
+	if found == nil {
+		return nil, apperrors.ErrExchangeRateNotFound
+	}
+	out := *found
+	return &out, nil
+}
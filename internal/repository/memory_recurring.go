@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"sort"
+
+	apperrors "dog-view/internal/errors"
+	"dog-view/internal/model"
+)
+
+// ============ 周期记账 ============
+
+// occurrenceKey 发生记录的唯一键
+type occurrenceKey struct {
+	ruleID int64
+	date   string
+}
+
+// ListRecurringRules 获取全部周期规则
+func (r *MemoryRepository) ListRecurringRules() ([]model.RecurringRule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rules := make([]model.RecurringRule, 0, len(r.rules))
+	for _, rule := range r.rules {
+		rules = append(rules, *rule)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+	return rules, nil
+}
+
+// GetRecurringRule 根据 ID 获取周期规则
+func (r *MemoryRepository) GetRecurringRule(id int64) (*model.RecurringRule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rule, ok := r.rules[id]
+	if !ok {
+		return nil, apperrors.ErrRuleNotFound
+	}
+	out := *rule
+	return &out, nil
+}
+
+// CreateRecurringRule 创建周期规则
+func (r *MemoryRepository) CreateRecurringRule(rule *model.RecurringRule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextRuleID++
+	stored := *rule
+	stored.ID = r.nextRuleID
+	stored.CreatedAt = now()
+	r.rules[stored.ID] = &stored
+
+	rule.ID = stored.ID
+	ch := r.beginChange(model.ActionCreateRule)
+	ch.created(model.EntityRule, stored.ID)
+	return ch.commit()
+}
+
+// UpdateRecurringRule 更新周期规则
+func (r *MemoryRepository) UpdateRecurringRule(rule *model.RecurringRule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.rules[rule.ID]
+	if !ok {
+		return apperrors.ErrRuleNotFound
+	}
+	ch := r.beginChange(model.ActionUpdateRule)
+	if err := ch.track(model.EntityRule, rule.ID); err != nil {
+		return err
+	}
+	updated := *rule
+	updated.CreatedAt = existing.CreatedAt
+	r.rules[rule.ID] = &updated
+	return ch.commit()
+}
+
+// DeleteRecurringRule 删除周期规则及其发生记录，撤销时一并恢复，已入账的发生不会重复入账
+func (r *MemoryRepository) DeleteRecurringRule(id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.rules[id]; !ok {
+		return apperrors.ErrRuleNotFound
+	}
+	ch := r.beginChange(model.ActionDeleteRule)
+	if err := ch.track(model.EntityRule, id); err != nil {
+		return err
+	}
+	for key, o := range r.occurrences {
+		if key.ruleID == id {
+			if err := ch.track(model.EntityOccurrence, o.ID); err != nil {
+				return err
+			}
+		}
+	}
+	delete(r.rules, id)
+	for key := range r.occurrences {
+		if key.ruleID == id {
+			delete(r.occurrences, key)
+		}
+	}
+	return ch.commit()
+}
+
+// occurrenceByID 按 ID 查找发生记录。调用方需持有锁
+func (r *MemoryRepository) occurrenceByID(id int64) (occurrenceKey, *model.Occurrence, bool) {
+	for key, o := range r.occurrences {
+		if o.ID == id {
+			return key, o, true
+		}
+	}
+	return occurrenceKey{}, nil, false
+}
+
+// ListOccurrences 获取规则已保存的发生记录
+func (r *MemoryRepository) ListOccurrences(ruleID int64) ([]model.Occurrence, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var occurrences []model.Occurrence
+	for key, o := range r.occurrences {
+		if key.ruleID == ruleID {
+			occurrences = append(occurrences, *o)
+		}
+	}
+	sort.Slice(occurrences, func(i, j int) bool { return occurrences[i].Date < occurrences[j].Date })
+	return occurrences, nil
+}
+
+// storeOccurrence 新增或覆盖发生记录，沿用已有 ID
+func (r *MemoryRepository) storeOccurrence(o *model.Occurrence) {
+	key := occurrenceKey{o.RuleID, o.Date}
+	if existing, ok := r.occurrences[key]; ok {
+		o.ID = existing.ID
+	} else {
+		r.nextOccurrenceID++
+		o.ID = r.nextOccurrenceID
+	}
+	stored := *o
+	r.occurrences[key] = &stored
+}
+
+// SaveOccurrence 保存单次发生的跳过或修改
+func (r *MemoryRepository) SaveOccurrence(o *model.Occurrence) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ch := r.beginChange(model.ActionSaveOccurrence)
+	existing, ok := r.occurrences[occurrenceKey{o.RuleID, o.Date}]
+	if ok {
+		if existing.Status == model.OccurrencePosted {
+			return apperrors.ErrOccurrencePosted
+		}
+		if err := ch.track(model.EntityOccurrence, existing.ID); err != nil {
+			return err
+		}
+	}
+	r.storeOccurrence(o)
+	if !ok {
+		ch.created(model.EntityOccurrence, o.ID)
+	}
+	return ch.commit()
+}
+
+// PostOccurrence 创建记录并将发生标记为已入账
+func (r *MemoryRepository) PostOccurrence(o *model.Occurrence, rec *model.Record) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.occurrences[occurrenceKey{o.RuleID, o.Date}]; ok {
+		if existing.Status == model.OccurrencePosted || existing.Status == model.OccurrenceSkipped {
+			return false, nil
+		}
+	}
+
+	r.insertRecord(rec)
+
+	o.Status = model.OccurrencePosted
+	o.RecordID = rec.ID
+	r.storeOccurrence(o)
+	// 发生的状态不记入变更日志：撤销自动入账只删除记录，该次发生不会再次入账
+	ch := r.beginChange(model.ActionPostRecurring)
+	ch.created(model.EntityRecord, rec.ID)
+	return true, ch.commit()
+}
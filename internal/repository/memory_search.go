@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"strings"
+
+	"dog-view/internal/model"
+)
+
+// ============ 备注搜索 ============
+
+// SearchRecords 搜索备注同时包含全部 terms 的记录，按日期倒序，与 SQLite 的 LIKE 退路一致
+func (r *MemoryRepository) SearchRecords(terms []string, limit int) ([]model.SearchResult, error) {
+	if len(terms) == 0 {
+		return []model.SearchResult{}, nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	records := r.sortedRecords(func(rec *model.Record) bool {
+		note := strings.ToLower(rec.Note)
+		for _, term := range terms {
+			if !strings.Contains(note, strings.ToLower(term)) {
+				return false
+			}
+		}
+		return true
+	})
+	if limit > 0 && len(records) > limit {
+		records = records[:limit]
+	}
+
+	results := make([]model.SearchResult, 0, len(records))
+	for _, rec := range records {
+		results = append(results, model.SearchResult{Record: rec, Snippet: snippet(rec.Note, terms)})
+	}
+	return results, nil
+}
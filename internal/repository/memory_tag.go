@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"sort"
+
+	apperrors "dog-view/internal/errors"
+	"dog-view/internal/model"
+)
+
+// ============ 标签 ============
+
+// ListTags 获取全部标签
+func (r *MemoryRepository) ListTags() ([]model.Tag, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tags := make([]model.Tag, 0, len(r.tags))
+	for _, t := range r.tags {
+		tags = append(tags, *t)
+	}
+	sortTags(tags)
+	return tags, nil
+}
+
+// tagNameTaken 判断名称是否已被其他标签占用
+func (r *MemoryRepository) tagNameTaken(name string, exceptID int64) bool {
+	for _, t := range r.tags {
+		if t.Name == name && t.ID != exceptID {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateTag 创建标签
+func (r *MemoryRepository) CreateTag(tag *model.Tag) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.tagNameTaken(tag.Name, 0) {
+		return apperrors.ErrDuplicateTag
+	}
+
+	r.nextTagID++
+	stored := *tag
+	stored.ID = r.nextTagID
+	stored.CreatedAt = now()
+	r.tags[stored.ID] = &stored
+
+	tag.ID = stored.ID
+	ch := r.beginChange(model.ActionCreateTag)
+	ch.created(model.EntityTag, tag.ID)
+	return ch.commit()
+}
+
+// UpdateTag 更新标签名称和颜色
+func (r *MemoryRepository) UpdateTag(tag *model.Tag) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.tags[tag.ID]
+	if !ok {
+		return apperrors.ErrTagNotFound
+	}
+	if r.tagNameTaken(tag.Name, tag.ID) {
+		return apperrors.ErrDuplicateTag
+	}
+
+	ch := r.beginChange(model.ActionUpdateTag)
+	if err := ch.track(model.EntityTag, tag.ID); err != nil {
+		return err
+	}
+	existing.Name = tag.Name
+	existing.Color = tag.Color
+	return ch.commit()
+}
+
+// DeleteTag 删除标签并解除与记录的关联
+func (r *MemoryRepository) DeleteTag(id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.tags[id]; !ok {
+		return apperrors.ErrTagNotFound
+	}
+
+	ch := r.beginChange(model.ActionDeleteTag)
+	if err := ch.track(model.EntityTag, id); err != nil {
+		return err
+	}
+	for recordID, tagIDs := range r.recordTags {
+		if tagIDs[id] {
+			if err := ch.track(model.EntityRecord, recordID); err != nil {
+				return err
+			}
+		}
+	}
+	delete(r.tags, id)
+	for _, tagIDs := range r.recordTags {
+		delete(tagIDs, id)
+	}
+	return ch.commit()
+}
+
+// SetRecordTags 替换记录的标签
+func (r *MemoryRepository) SetRecordTags(recordID int64, tagIDs []int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.records[recordID]; !ok {
+		return apperrors.ErrRecordNotFound
+	}
+
+	ch := r.beginChange(model.ActionSetRecordTags)
+	if err := ch.track(model.EntityRecord, recordID); err != nil {
+		return err
+	}
+	set := make(map[int64]bool, len(tagIDs))
+	for _, id := range tagIDs {
+		set[id] = true
+	}
+	r.recordTags[recordID] = set
+	return ch.commit()
+}
+
+// hasTags 判断记录是否包含任一（或全部）标签
+func (r *MemoryRepository) hasTags(recordID int64, tagIDs []int64, all bool) bool {
+	tags := r.recordTags[recordID]
+	for _, id := range tagIDs {
+		if tags[id] && !all {
+			return true
+		}
+		if !tags[id] && all {
+			return false
+		}
+	}
+	return all
+}
+
+// GetTagTotals 获取日期区间内按日期、类型、标签和币种分组的收支合计
+func (r *MemoryRepository) GetTagTotals(startDate, endDate string) ([]model.StatTotal, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	type key struct {
+		date, recordType, currency string
+		tagID                      int64
+	}
+	sums := make(map[key]int64)
+	for _, rec := range r.records {
+		if rec.Type != model.TypeIncome && rec.Type != model.TypeExpense {
+			continue
+		}
+		if rec.Date < startDate || rec.Date >= endDate {
+			continue
+		}
+		for tagID := range r.recordTags[rec.ID] {
+			sums[key{rec.Date, rec.Type, rec.Amount.Currency, tagID}] += rec.Amount.Minor
+		}
+	}
+
+	totals := make([]model.StatTotal, 0, len(sums))
+	for k, minor := range sums {
+		totals = append(totals, model.StatTotal{
+			Date:   k.date,
+			Type:   k.recordType,
+			TagID:  k.tagID,
+			Amount: model.Money{Minor: minor, Currency: k.currency},
+		})
+	}
+
+	sort.Slice(totals, func(i, j int) bool {
+		a, b := totals[i], totals[j]
+		if a.Date != b.Date {
+			return a.Date < b.Date
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		if a.TagID != b.TagID {
+			return a.TagID < b.TagID
+		}
+		return a.Amount.Currency < b.Amount.Currency
+	})
+	return totals, nil
+}
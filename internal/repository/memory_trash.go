@@ -0,0 +1,235 @@
+package repository
+
+import (
+	"sort"
+	"time"
+
+	apperrors "dog-view/internal/errors"
+	"dog-view/internal/model"
+)
+
+// ============ 回收站 ============
+
+// ListDeletedRecords 获取回收站中的记录，最近删除的在前
+func (r *MemoryRepository) ListDeletedRecords() ([]model.Record, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	records := []model.Record{}
+	for _, rec := range r.trashRecords {
+		records = append(records, r.withCategory(rec))
+	}
+	sort.Slice(records, func(i, j int) bool {
+		a, b := records[i], records[j]
+		if !a.DeletedAt.Equal(*b.DeletedAt) {
+			return a.DeletedAt.After(*b.DeletedAt)
+		}
+		return a.ID > b.ID
+	})
+	return records, nil
+}
+
+// ListDeletedCategories 获取回收站中的分类，最近删除的在前
+func (r *MemoryRepository) ListDeletedCategories() ([]model.Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	categories := []model.Category{}
+	for _, c := range r.trashCategories {
+		categories = append(categories, *c)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		a, b := categories[i], categories[j]
+		if !a.DeletedAt.Equal(*b.DeletedAt) {
+			return a.DeletedAt.After(*b.DeletedAt)
+		}
+		return a.ID > b.ID
+	})
+	return categories, nil
+}
+
+// restoreCategory 恢复分类及其上级分类，先整体校验重名再修改，调用方需持有写锁
+func (r *MemoryRepository) restoreCategory(ch *memChangeRecorder, id int64) error {
+	c, ok := r.trashCategories[id]
+	if !ok {
+		if _, ok := r.categories[id]; ok {
+			return nil
+		}
+		return apperrors.ErrCategoryNotFound
+	}
+
+	restore := []*model.Category{c}
+	if parent, ok := r.trashCategories[c.ParentID]; ok {
+		restore = append(restore, parent)
+	}
+	for _, rc := range restore {
+		if r.nameTaken(rc.Name, rc.ID) {
+			return apperrors.ErrDuplicateCategory
+		}
+	}
+	if len(restore) == 2 && restore[0].Name == restore[1].Name {
+		return apperrors.ErrDuplicateCategory
+	}
+
+	for _, rc := range restore {
+		if err := ch.track(model.EntityCategory, rc.ID); err != nil {
+			return err
+		}
+	}
+	for _, rc := range restore {
+		rc.DeletedAt = nil
+		r.categories[rc.ID] = rc
+		delete(r.trashCategories, rc.ID)
+	}
+	return nil
+}
+
+// RestoreRecord 从回收站恢复记录，其分类也在回收站中时一并恢复
+func (r *MemoryRepository) RestoreRecord(id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rec, ok := r.trashRecords[id]
+	if !ok {
+		return apperrors.ErrRecordNotFound
+	}
+	ch := r.beginChange(model.ActionRestoreRecord)
+	if rec.CategoryID != 0 {
+		if err := r.restoreCategory(ch, rec.CategoryID); err != nil && err != apperrors.ErrCategoryNotFound {
+			return err
+		}
+	}
+	if err := ch.track(model.EntityRecord, id); err != nil {
+		return err
+	}
+
+	rec.DeletedAt = nil
+	r.records[id] = rec
+	delete(r.trashRecords, id)
+	return ch.commit()
+}
+
+// RestoreCategory 从回收站恢复分类，其上级分类也在回收站中时一并恢复
+func (r *MemoryRepository) RestoreCategory(id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.trashCategories[id]; !ok {
+		return apperrors.ErrCategoryNotFound
+	}
+	ch := r.beginChange(model.ActionRestoreCategory)
+	if err := r.restoreCategory(ch, id); err != nil {
+		return err
+	}
+	return ch.commit()
+}
+
+// purgeCategory 彻底删除分类及其预算。调用方需持有写锁
+func (r *MemoryRepository) purgeCategory(ch *memChangeRecorder, id int64) error {
+	if err := ch.track(model.EntityCategory, id); err != nil {
+		return err
+	}
+	for budgetID, b := range r.budgets {
+		if b.CategoryID == id {
+			if err := ch.track(model.EntityBudget, budgetID); err != nil {
+				return err
+			}
+			delete(r.budgets, budgetID)
+		}
+	}
+	delete(r.trashCategories, id)
+	return nil
+}
+
+// categoryReferenced 判断分类是否仍被记录（含回收站）或子分类（含回收站）引用
+func (r *MemoryRepository) categoryReferenced(id int64) error {
+	for _, records := range []map[int64]*model.Record{r.records, r.trashRecords} {
+		for _, rec := range records {
+			if rec.CategoryID == id {
+				return apperrors.ErrCategoryInUse
+			}
+		}
+	}
+	for _, categories := range []map[int64]*model.Category{r.categories, r.trashCategories} {
+		for _, c := range categories {
+			if c.ParentID == id {
+				return apperrors.ErrCategoryHasChildren
+			}
+		}
+	}
+	return nil
+}
+
+// PurgeRecord 彻底删除回收站中的记录
+func (r *MemoryRepository) PurgeRecord(id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.trashRecords[id]; !ok {
+		return apperrors.ErrRecordNotFound
+	}
+	ch := r.beginChange(model.ActionPurgeRecord)
+	if err := ch.track(model.EntityRecord, id); err != nil {
+		return err
+	}
+	delete(r.trashRecords, id)
+	delete(r.recordTags, id)
+	return ch.commit()
+}
+
+// PurgeCategory 彻底删除回收站中的分类
+func (r *MemoryRepository) PurgeCategory(id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.trashCategories[id]; !ok {
+		return apperrors.ErrCategoryNotFound
+	}
+	if err := r.categoryReferenced(id); err != nil {
+		return err
+	}
+	ch := r.beginChange(model.ActionPurgeCategory)
+	if err := r.purgeCategory(ch, id); err != nil {
+		return err
+	}
+	return ch.commit()
+}
+
+// PurgeTrash 彻底删除 before 及之前移入回收站的记录和分类
+func (r *MemoryRepository) PurgeTrash(before time.Time) (model.PurgeResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var result model.PurgeResult
+	ch := r.beginChange(model.ActionPurgeTrash)
+	for id, rec := range r.trashRecords {
+		if !rec.DeletedAt.After(before) {
+			if err := ch.track(model.EntityRecord, id); err != nil {
+				return result, err
+			}
+			delete(r.trashRecords, id)
+			delete(r.recordTags, id)
+			result.Records++
+		}
+	}
+
+	// 子分类删除后其上级分类才能删除，直到没有可删除的分类为止
+	for {
+		var ids []int64
+		for id, c := range r.trashCategories {
+			if !c.DeletedAt.After(before) && r.categoryReferenced(id) == nil {
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) == 0 {
+			break
+		}
+		for _, id := range ids {
+			if err := r.purgeCategory(ch, id); err != nil {
+				return result, err
+			}
+		}
+		result.Categories += len(ids)
+	}
+	return result, ch.commit()
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	apperrors "dog-view/internal/errors"
+)
+
+// migration 一次带版本号的数据库结构变更
+type migration struct {
+	version int
+	name    string
+	up      func(tx *sql.Tx) error
+}
+
+// MigrationError 迁移失败时返回，携带失败的版本和迁移前备份位置
+type MigrationError struct {
+	Version    int
+	Name       string
+	BackupPath string
+	Err        error
+}
+
+func (e *MigrationError) Error() string {
+	msg := fmt.Sprintf("数据库迁移失败 (v%d %s): %v", e.Version, e.Name, e.Err)
+	if e.BackupPath != "" {
+		msg += fmt.Sprintf("，迁移前备份位于 %s", e.BackupPath)
+	}
+	return msg
+}
+
+func (e *MigrationError) Unwrap() error {
+	return e.Err
+}
+
+func (e *MigrationError) Is(target error) bool {
+	return target == apperrors.ErrMigrationFailed
+}
+
+// SchemaVersion 获取当前数据库结构版本
+func (r *SQLiteRepository) SchemaVersion() (int, error) {
+	var version int
+	err := r.db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_version").Scan(&version)
+	return version, err
+}
+
+// migrate 按顺序执行尚未应用的迁移，每个迁移在独立事务中执行
+func (r *SQLiteRepository) migrate() error {
+	_, err := r.db.Exec(`
+	CREATE TABLE IF NOT EXISTS schema_version (
+		version     INTEGER PRIMARY KEY,
+		name        TEXT NOT NULL,
+		applied_at  DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`)
+	if err != nil {
+		return err
+	}
+
+	current, err := r.SchemaVersion()
+	if err != nil {
+		return err
+	}
+
+	var pending []migration
+	for _, m := range migrations {
+		if m.version > current {
+			pending = append(pending, m)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	// 已有数据的数据库在迁移前先做一份备份
+	backupPath := ""
+	hasData, err := r.hasUserTables()
+	if err != nil {
+		return err
+	}
+	if hasData {
+		target := pending[len(pending)-1].version
+		backupPath, err = r.backupBeforeMigrate(target)
+		if err != nil {
+			return fmt.Errorf("迁移前备份失败: %w", err)
+		}
+	}
+
+	for _, m := range pending {
+		if err := r.applyMigration(m); err != nil {
+			return &MigrationError{
+				Version:    m.version,
+				Name:       m.name,
+				BackupPath: backupPath,
+				Err:        err,
+			}
+		}
+	}
+
+	return nil
+}
+
+// applyMigration 在事务中执行单个迁移并记录版本
+func (r *SQLiteRepository) applyMigration(m migration) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := m.up(tx); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("INSERT INTO schema_version (version, name) VALUES (?, ?)", m.version, m.name)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// hasUserTables 判断数据库中是否已有业务表（包括引入迁移之前创建的旧库）
+func (r *SQLiteRepository) hasUserTables() (bool, error) {
+	var count int
+	err := r.db.QueryRow(`
+		SELECT COUNT(*) FROM sqlite_master
+		WHERE type = 'table' AND name NOT IN ('schema_version', 'sqlite_sequence')
+	`).Scan(&count)
+	return count > 0, err
+}
+
+// backupBeforeMigrate 将数据库文件复制到同目录下的备份文件
+func (r *SQLiteRepository) backupBeforeMigrate(targetVersion int) (string, error) {
+	if r.path == "" {
+		return "", nil
+	}
+
+	backupPath := fmt.Sprintf("%s.pre-v%d-%s.bak", r.path, targetVersion, time.Now().Format("20060102-150405"))
+	if err := copyFile(r.path, backupPath); err != nil {
+		return "", err
+	}
+	return backupPath, nil
+}
+
+// MigrationBackups 返回数据库文件迁移前留下的全部备份文件
+func MigrationBackups(path string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := filepath.Base(path) + ".pre-v"
+	var backups []string
+	for _, e := range entries {
+		if name := e.Name(); !e.IsDir() && strings.HasPrefix(name, prefix) && strings.HasSuffix(name, ".bak") {
+			backups = append(backups, filepath.Join(filepath.Dir(path), name))
+		}
+	}
+	return backups, nil
+}
+
+// copyFile 复制文件并同步到磁盘
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
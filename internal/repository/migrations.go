@@ -0,0 +1,354 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// execSQL 将一段 SQL 包装为迁移函数
+func execSQL(stmt string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		_, err := tx.Exec(stmt)
+		return err
+	}
+}
+
+// migrations 按版本号升序排列，已发布的迁移不可修改，只能追加
+var migrations = []migration{
+	{
+		version: 1,
+		name:    "创建分类与记录表",
+		up: execSQL(`
+		CREATE TABLE IF NOT EXISTS categories (
+			id          INTEGER PRIMARY KEY AUTOINCREMENT,
+			name        TEXT NOT NULL UNIQUE,
+			icon        TEXT,
+			type        TEXT NOT NULL,
+			sort_order  INTEGER DEFAULT 0,
+			created_at  DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS records (
+			id          INTEGER PRIMARY KEY AUTOINCREMENT,
+			amount      DECIMAL(10,2) NOT NULL,
+			type        TEXT NOT NULL,
+			category_id INTEGER NOT NULL,
+			note        TEXT,
+			date        DATE NOT NULL,
+			created_at  DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (category_id) REFERENCES categories(id)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_records_date ON records(date);
+		CREATE INDEX IF NOT EXISTS idx_records_category ON records(category_id);
+		`),
+	},
+	{
+		version: 2,
+		name:    "金额改为最小货币单位整数并增加币种",
+		up:      migrateAmountToMinorUnits,
+	},
+	{
+		version: 3,
+		name:    "创建设置表",
+		up: execSQL(`
+		CREATE TABLE IF NOT EXISTS settings (
+			key         TEXT PRIMARY KEY,
+			value       TEXT NOT NULL,
+			updated_at  DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+		`),
+	},
+	{
+		version: 4,
+		name:    "创建账户表并为记录关联账户",
+		up: execSQL(`
+		CREATE TABLE IF NOT EXISTS accounts (
+			id              INTEGER PRIMARY KEY AUTOINCREMENT,
+			name            TEXT NOT NULL UNIQUE,
+			type            TEXT NOT NULL,
+			icon            TEXT,
+			opening_balance INTEGER NOT NULL DEFAULT 0,
+			currency        TEXT NOT NULL DEFAULT 'CNY',
+			sort_order      INTEGER DEFAULT 0,
+			created_at      DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+
+		ALTER TABLE records ADD COLUMN account_id INTEGER NOT NULL DEFAULT 0;
+		ALTER TABLE records ADD COLUMN to_account_id INTEGER NOT NULL DEFAULT 0;
+
+		CREATE INDEX IF NOT EXISTS idx_records_account ON records(account_id);
+		CREATE INDEX IF NOT EXISTS idx_records_to_account ON records(to_account_id);
+		`),
+	},
+	{
+		version: 5,
+		name:    "创建汇率表",
+		up: execSQL(`
+		CREATE TABLE IF NOT EXISTS exchange_rates (
+			id            INTEGER PRIMARY KEY AUTOINCREMENT,
+			from_currency TEXT NOT NULL,
+			to_currency   TEXT NOT NULL,
+			date          TEXT NOT NULL,
+			rate          TEXT NOT NULL,
+			created_at    DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (from_currency, to_currency, date)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_records_currency ON records(currency);
+		`),
+	},
+	{
+		version: 6,
+		name:    "创建预算表",
+		up: execSQL(`
+		CREATE TABLE IF NOT EXISTS budgets (
+			id          INTEGER PRIMARY KEY AUTOINCREMENT,
+			month       TEXT NOT NULL,
+			category_id INTEGER NOT NULL DEFAULT 0,
+			amount      INTEGER NOT NULL,
+			currency    TEXT NOT NULL DEFAULT 'CNY',
+			recurring   INTEGER NOT NULL DEFAULT 0,
+			created_at  DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (month, category_id)
+		);
+		`),
+	},
+	{
+		version: 7,
+		name:    "创建周期记账规则表",
+		up: execSQL(`
+		CREATE TABLE IF NOT EXISTS recurring_rules (
+			id            INTEGER PRIMARY KEY AUTOINCREMENT,
+			name          TEXT NOT NULL,
+			frequency     TEXT NOT NULL,
+			interval      INTEGER NOT NULL DEFAULT 1,
+			day_of_month  INTEGER NOT NULL DEFAULT 0,
+			start_date    TEXT NOT NULL,
+			end_date      TEXT NOT NULL DEFAULT '',
+			max_count     INTEGER NOT NULL DEFAULT 0,
+			skip_weekends INTEGER NOT NULL DEFAULT 0,
+			amount        INTEGER NOT NULL,
+			currency      TEXT NOT NULL DEFAULT 'CNY',
+			type          TEXT NOT NULL,
+			category_id   INTEGER NOT NULL,
+			account_id    INTEGER NOT NULL DEFAULT 0,
+			note          TEXT,
+			active        INTEGER NOT NULL DEFAULT 1,
+			created_at    DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+
+		-- (rule_id, date) 唯一，保证同一次发生不会重复入账
+		CREATE TABLE IF NOT EXISTS recurring_occurrences (
+			id        INTEGER PRIMARY KEY AUTOINCREMENT,
+			rule_id   INTEGER NOT NULL,
+			date      TEXT NOT NULL,
+			status    TEXT NOT NULL,
+			record_id INTEGER NOT NULL DEFAULT 0,
+			amount    INTEGER,
+			currency  TEXT,
+			note      TEXT,
+			UNIQUE (rule_id, date)
+		);
+		`),
+	},
+	{
+		version: 8,
+		name:    "创建标签表",
+		up: execSQL(`
+		CREATE TABLE IF NOT EXISTS tags (
+			id          INTEGER PRIMARY KEY AUTOINCREMENT,
+			name        TEXT NOT NULL UNIQUE,
+			color       TEXT NOT NULL DEFAULT '',
+			created_at  DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS record_tags (
+			record_id   INTEGER NOT NULL,
+			tag_id      INTEGER NOT NULL,
+			PRIMARY KEY (record_id, tag_id)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_record_tags_tag ON record_tags(tag_id);
+		`),
+	},
+	{
+		version: 9,
+		name:    "分类增加上级分类",
+		up: execSQL(`
+		ALTER TABLE categories ADD COLUMN parent_id INTEGER NOT NULL DEFAULT 0;
+		CREATE INDEX IF NOT EXISTS idx_categories_parent ON categories(parent_id);
+		`),
+	},
+	{
+		version: 10,
+		name:    "分类增加归档标记",
+		up: execSQL(`
+		ALTER TABLE categories ADD COLUMN archived INTEGER NOT NULL DEFAULT 0;
+		`),
+	},
+	{
+		version: 11,
+		name:    "记录与分类支持回收站",
+		up:      migrateSoftDelete,
+	},
+	{
+		version: 12,
+		name:    "创建变更日志表",
+		up: execSQL(`
+		CREATE TABLE IF NOT EXISTS change_sets (
+			id          INTEGER PRIMARY KEY AUTOINCREMENT,
+			action      TEXT NOT NULL,
+			target_id   INTEGER NOT NULL DEFAULT 0,
+			created_at  DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS changes (
+			id          INTEGER PRIMARY KEY AUTOINCREMENT,
+			set_id      INTEGER NOT NULL,
+			entity      TEXT NOT NULL,
+			entity_id   INTEGER NOT NULL,
+			before      TEXT,
+			after       TEXT
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_changes_set ON changes(set_id);
+		CREATE INDEX IF NOT EXISTS idx_changes_entity ON changes(entity, entity_id);
+
+		-- 变更日志只能追加
+		CREATE TRIGGER IF NOT EXISTS change_sets_no_update BEFORE UPDATE ON change_sets
+		BEGIN SELECT RAISE(ABORT, 'change log is append-only'); END;
+		CREATE TRIGGER IF NOT EXISTS change_sets_no_delete BEFORE DELETE ON change_sets
+		BEGIN SELECT RAISE(ABORT, 'change log is append-only'); END;
+		CREATE TRIGGER IF NOT EXISTS changes_no_update BEFORE UPDATE ON changes
+		BEGIN SELECT RAISE(ABORT, 'change log is append-only'); END;
+		CREATE TRIGGER IF NOT EXISTS changes_no_delete BEFORE DELETE ON changes
+		BEGIN SELECT RAISE(ABORT, 'change log is append-only'); END;
+		`),
+	},
+	{
+		version: 13,
+		name:    "创建导入批次表",
+		up: execSQL(`
+		CREATE TABLE IF NOT EXISTS import_batches (
+			id          INTEGER PRIMARY KEY AUTOINCREMENT,
+			source      TEXT NOT NULL DEFAULT '',
+			records     INTEGER NOT NULL DEFAULT 0,
+			categories  INTEGER NOT NULL DEFAULT 0,
+			tags        INTEGER NOT NULL DEFAULT 0,
+			created_at  DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+
+		ALTER TABLE records ADD COLUMN import_batch INTEGER NOT NULL DEFAULT 0;
+		ALTER TABLE categories ADD COLUMN import_batch INTEGER NOT NULL DEFAULT 0;
+		CREATE INDEX IF NOT EXISTS idx_records_import_batch ON records(import_batch) WHERE import_batch != 0;
+		CREATE INDEX IF NOT EXISTS idx_categories_import_batch ON categories(import_batch) WHERE import_batch != 0;
+
+		-- 重建视图以包含新增的列
+		DROP VIEW IF EXISTS active_records;
+		DROP VIEW IF EXISTS active_categories;
+		CREATE VIEW active_records AS SELECT * FROM records WHERE deleted_at IS NULL;
+		CREATE VIEW active_categories AS SELECT * FROM categories WHERE deleted_at IS NULL;
+		`),
+	},
+	{
+		version: 14,
+		name:    "创建 CSV 列映射方案表",
+		up: execSQL(`
+		CREATE TABLE IF NOT EXISTS csv_profiles (
+			id          INTEGER PRIMARY KEY AUTOINCREMENT,
+			name        TEXT NOT NULL UNIQUE,
+			mapping     TEXT NOT NULL, -- model.CSVMapping 的 JSON
+			created_at  DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+		`),
+	},
+}
+
+// migrateAmountToMinorUnits 将 DECIMAL(REAL) 金额转换为以分为单位的整数
+// SQLite 不支持修改列类型，因此重建 records 表并校验转换前后的行数与合计；
+// date 列同时改为 TEXT，避免驱动把 DATE 列解析为 time.Time 后以 RFC3339 格式返回
+func migrateAmountToMinorUnits(tx *sql.Tx) error {
+	var beforeCount int
+	var beforeTotal int64
+	err := tx.QueryRow("SELECT COUNT(*), COALESCE(SUM(CAST(ROUND(amount * 100) AS INTEGER)), 0) FROM records").
+		Scan(&beforeCount, &beforeTotal)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+	CREATE TABLE records_new (
+		id          INTEGER PRIMARY KEY AUTOINCREMENT,
+		amount      INTEGER NOT NULL,
+		currency    TEXT NOT NULL DEFAULT 'CNY',
+		type        TEXT NOT NULL,
+		category_id INTEGER NOT NULL,
+		note        TEXT,
+		date        TEXT NOT NULL,
+		created_at  DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (category_id) REFERENCES categories(id)
+	);
+
+	INSERT INTO records_new (id, amount, currency, type, category_id, note, date, created_at)
+	SELECT id, CAST(ROUND(amount * 100) AS INTEGER), 'CNY', type, category_id, note, date, created_at
+	FROM records;
+
+	DROP TABLE records;
+	ALTER TABLE records_new RENAME TO records;
+
+	CREATE INDEX IF NOT EXISTS idx_records_date ON records(date);
+	CREATE INDEX IF NOT EXISTS idx_records_category ON records(category_id);
+	`)
+	if err != nil {
+		return err
+	}
+
+	var afterCount int
+	var afterTotal int64
+	err = tx.QueryRow("SELECT COUNT(*), COALESCE(SUM(amount), 0) FROM records").Scan(&afterCount, &afterTotal)
+	if err != nil {
+		return err
+	}
+	if afterCount != beforeCount || afterTotal != beforeTotal {
+		return fmt.Errorf("金额转换校验失败: 迁移前 %d 条/%d 分，迁移后 %d 条/%d 分",
+			beforeCount, beforeTotal, afterCount, afterTotal)
+	}
+
+	return nil
+}
+
+// migrateSoftDelete 为记录和分类增加删除时间。分类名称的唯一约束改为只约束未删除的分类，
+// 以便回收站中的分类不占用名称；SQLite 不能删除列约束，因此重建 categories 表。
+// 读取统一经过 active_records / active_categories 视图，避免遗漏删除条件
+func migrateSoftDelete(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+	CREATE TABLE categories_new (
+		id          INTEGER PRIMARY KEY AUTOINCREMENT,
+		name        TEXT NOT NULL,
+		icon        TEXT,
+		type        TEXT NOT NULL,
+		sort_order  INTEGER DEFAULT 0,
+		created_at  DATETIME DEFAULT CURRENT_TIMESTAMP,
+		parent_id   INTEGER NOT NULL DEFAULT 0,
+		archived    INTEGER NOT NULL DEFAULT 0,
+		deleted_at  DATETIME
+	);
+
+	INSERT INTO categories_new (id, name, icon, type, sort_order, created_at, parent_id, archived)
+	SELECT id, name, icon, type, sort_order, created_at, parent_id, archived FROM categories;
+
+	DROP TABLE categories;
+	ALTER TABLE categories_new RENAME TO categories;
+
+	CREATE INDEX IF NOT EXISTS idx_categories_parent ON categories(parent_id);
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_name ON categories(name) WHERE deleted_at IS NULL;
+
+	ALTER TABLE records ADD COLUMN deleted_at DATETIME;
+	CREATE INDEX IF NOT EXISTS idx_records_deleted ON records(deleted_at);
+
+	CREATE VIEW IF NOT EXISTS active_records AS SELECT * FROM records WHERE deleted_at IS NULL;
+	CREATE VIEW IF NOT EXISTS active_categories AS SELECT * FROM categories WHERE deleted_at IS NULL;
+	`)
+	return err
+}
@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"time"
+
+	"dog-view/internal/model"
+)
+
+// CategoryRepository 分类存取，回收站中的分类除 MergeCategories 外均视为不存在
+type CategoryRepository interface {
+	// ListCategories 按树的先序返回分类：一级分类按排序，其后紧跟各自的子分类；
+	// recordType 为空时返回全部，包括已归档的分类
+	ListCategories(recordType string) ([]model.Category, error)
+	// GetCategoryByName 不存在时返回 ErrCategoryNotFound
+	GetCategoryByName(name string) (*model.Category, error)
+	// CreateCategory 名称重复时返回 ErrDuplicateCategory，成功后回填 ID
+	CreateCategory(c *model.Category) error
+	// UpdateCategory 更新名称和图标
+	UpdateCategory(c *model.Category) error
+	// SetCategoryArchived 设置归档标记，不存在时返回 ErrCategoryNotFound
+	SetCategoryArchived(id int64, archived bool) error
+	// MoveCategory 修改上级分类和排序值，层级与类型的校验由服务层负责
+	MoveCategory(id, parentID int64, sortOrder int) error
+	// DeleteCategory 将分类移入回收站，仍有记录引用时返回 ErrCategoryInUse，
+	// 仍有子分类时返回 ErrCategoryHasChildren（回收站中的记录和子分类不算）
+	DeleteCategory(id int64) error
+	// MergeCategories 在一个事务中将 sourceIDs 的记录、周期规则、预算和子分类转到 targetID 下，
+	// 再删除源分类，返回转移的记录数；目标分类当月已有预算时丢弃源分类的预算，
+	// 目标分类原本是某个源分类的子分类时提升为一级分类。回收站中引用源分类的记录和子分类
+	// 同样转移，源分类直接删除而不进入回收站。类型与层级的校验由服务层负责
+	MergeCategories(sourceIDs []int64, targetID int64) (int, error)
+	// UpdateCategoryOrder 按 ids 顺序重写同一上级分类下的排序值（从 1 开始），
+	// ids 中有不属于 parentID 的分类时返回 ErrInvalidParent
+	UpdateCategoryOrder(parentID int64, ids []int64) error
+}
+
+// RecordRepository 记录存取，查询和统计均不含回收站中的记录
+type RecordRepository interface {
+	// CreateRecord 同时关联 rec.Tags 中的标签（按 ID），成功后回填 ID
+	CreateRecord(rec *model.Record) error
+	// UpdateRecord 更新金额、分类、账户、备注和日期，并将标签替换为 rec.Tags（按 ID），二者记入同一变更集
+	UpdateRecord(rec *model.Record) error
+	// DeleteRecord 将记录移入回收站，保留标签关联，不存在时返回 ErrRecordNotFound
+	DeleteRecord(id int64) error
+	// DeleteRecords 将多条记录移入回收站，记为一个变更集，返回移入的条数；
+	// 不存在或已在回收站中的 ID 跳过
+	DeleteRecords(ids []int64) (int, error)
+	// GetRecordByID 不存在时返回 ErrRecordNotFound
+	GetRecordByID(id int64) (*model.Record, error)
+	// ListRecordsBetween 返回 [startDate, endDate) 内的记录，按日期倒序
+	ListRecordsBetween(startDate, endDate string) ([]model.Record, error)
+	// GetRecentRecords 返回最近 limit 条记录
+	GetRecentRecords(limit int) ([]model.Record, error)
+	// GetAllRecords 返回全部记录，按日期倒序
+	GetAllRecords() ([]model.Record, error)
+	// ListRecords 按筛选条件返回记录，排序同 ListRecordsBetween
+	ListRecords(filter model.RecordFilter) ([]model.Record, error)
+	// QueryRecords 按 q.SortBy、q.Desc 排序（排序值相同时按 ID），返回 q.Cursor 之后的
+	// 至多 q.Limit 条记录，Limit 不大于 0 时不限；SortBy 由服务层校验
+	QueryRecords(q model.RecordQuery) ([]model.Record, error)
+	// GetRecordTotals 返回筛选结果的记录数（含转账），以及按日期、类型和币种分组的收支合计
+	GetRecordTotals(filter model.RecordFilter) ([]model.StatTotal, int, error)
+	// SearchRecords 搜索备注同时包含全部 terms 的记录，按相关度（或日期倒序）返回至多 limit 条
+	SearchRecords(terms []string, limit int) ([]model.SearchResult, error)
+}
+
+// TagRepository 标签存取
+type TagRepository interface {
+	// ListTags 按名称返回全部标签
+	ListTags() ([]model.Tag, error)
+	// CreateTag 名称重复时返回 ErrDuplicateTag，成功后回填 ID
+	CreateTag(tag *model.Tag) error
+	UpdateTag(tag *model.Tag) error
+	// DeleteTag 同时解除该标签与所有记录的关联
+	DeleteTag(id int64) error
+	// SetRecordTags 将记录的标签替换为 tagIDs
+	SetRecordTags(recordID int64, tagIDs []int64) error
+	// GetTagTotals 返回按日期、类型、标签和币种分组的收支合计，不含转账
+	GetTagTotals(startDate, endDate string) ([]model.StatTotal, error)
+}
+
+// AccountRepository 资金账户存取
+type AccountRepository interface {
+	// ListAccounts 按排序返回全部账户
+	ListAccounts() ([]model.Account, error)
+	// GetAccountByID 不存在时返回 ErrAccountNotFound
+	GetAccountByID(id int64) (*model.Account, error)
+	// CreateAccount 名称重复时返回 ErrDuplicateAccount，成功后回填 ID
+	CreateAccount(a *model.Account) error
+	// UpdateAccount 仍有记录（含转入和回收站中的记录）引用时不能修改币种，返回 ErrCurrencyMismatch
+	UpdateAccount(a *model.Account) error
+	// DeleteAccount 仍有记录（含转入和回收站中的记录）引用时返回 ErrAccountInUse
+	DeleteAccount(id int64) error
+	// GetAccountBalances 返回各账户截至 endDate（不含）的余额，Date 字段由调用方填写
+	GetAccountBalances(endDate string) ([]model.AccountBalance, error)
+}
+
+// StatsRepository 统计查询，日期区间均为左闭右开
+type StatsRepository interface {
+	// GetStatTotals 返回按日期、类型、分类和币种分组的收支合计，不含转账，
+	// 币种换算由服务层按记录日期的汇率完成
+	GetStatTotals(startDate, endDate string) ([]model.StatTotal, error)
+}
+
+// RateRepository 汇率存取
+type RateRepository interface {
+	// ListExchangeRates 按币种对升序、日期倒序返回全部汇率
+	ListExchangeRates() ([]model.ExchangeRate, error)
+	// SaveExchangeRate 同一币种对同一天已有汇率时覆盖，成功后回填 ID
+	SaveExchangeRate(rate *model.ExchangeRate) error
+	// SaveExchangeRates 在一个事务中逐条保存汇率，任一失败时全部回滚，成功后回填各条的 ID
+	SaveExchangeRates(rates []model.ExchangeRate) error
+	// DeleteExchangeRate 不存在时返回 ErrExchangeRateNotFound
+	DeleteExchangeRate(id int64) error
+	// FindExchangeRate 返回 date 当天或之前最近一天的汇率，不存在时返回 ErrExchangeRateNotFound
+	FindExchangeRate(from, to, date string) (*model.ExchangeRate, error)
+}
+
+// BudgetRepository 预算存取
+type BudgetRepository interface {
+	// ListBudgets 返回 month 及之前各月设置的预算，按月份、分类升序；month 为空时返回全部
+	ListBudgets(month string) ([]model.Budget, error)
+	// SaveBudget 同一月份同一分类已有预算时覆盖，成功后回填 ID
+	SaveBudget(b *model.Budget) error
+	// DeleteBudget 不存在时返回 ErrBudgetNotFound
+	DeleteBudget(id int64) error
+}
+
+// RecurringRepository 周期记账规则及其发生记录存取
+type RecurringRepository interface {
+	ListRecurringRules() ([]model.RecurringRule, error)
+	// GetRecurringRule 不存在时返回 ErrRuleNotFound
+	GetRecurringRule(id int64) (*model.RecurringRule, error)
+	// CreateRecurringRule 成功后回填 ID
+	CreateRecurringRule(rule *model.RecurringRule) error
+	UpdateRecurringRule(rule *model.RecurringRule) error
+	// DeleteRecurringRule 同时删除发生记录，已生成的记账记录保留
+	DeleteRecurringRule(id int64) error
+	// ListOccurrences 按日期升序返回规则已保存的发生记录
+	ListOccurrences(ruleID int64) ([]model.Occurrence, error)
+	// SaveOccurrence 保存跳过或修改，同一次发生已入账时返回 ErrOccurrencePosted
+	SaveOccurrence(o *model.Occurrence) error
+	// PostOccurrence 在同一事务中创建记录并将发生标记为已入账；
+	// 该次发生已入账或已跳过时不做任何操作并返回 false
+	PostOccurrence(o *model.Occurrence, rec *model.Record) (bool, error)
+}
+
+// TrashRepository 回收站存取
+type TrashRepository interface {
+	// ListDeletedRecords 按删除时间倒序返回回收站中的记录
+	ListDeletedRecords() ([]model.Record, error)
+	// ListDeletedCategories 按删除时间倒序返回回收站中的分类
+	ListDeletedCategories() ([]model.Category, error)
+	// RestoreRecord 恢复记录，其分类（及上级分类）也在回收站中时一并恢复；
+	// 记录不在回收站中时返回 ErrRecordNotFound，分类与现有分类重名时返回 ErrDuplicateCategory
+	RestoreRecord(id int64) error
+	// RestoreCategory 恢复分类，其上级分类也在回收站中时一并恢复；
+	// 分类不在回收站中时返回 ErrCategoryNotFound，与现有分类重名时返回 ErrDuplicateCategory
+	RestoreCategory(id int64) error
+	// PurgeRecord 彻底删除回收站中的记录及其标签关联，不在回收站中时返回 ErrRecordNotFound
+	PurgeRecord(id int64) error
+	// PurgeCategory 彻底删除回收站中的分类及其预算，不在回收站中时返回 ErrCategoryNotFound；
+	// 仍有记录引用时返回 ErrCategoryInUse，仍有子分类时返回 ErrCategoryHasChildren（均含回收站）
+	PurgeCategory(id int64) error
+	// PurgeTrash 彻底删除 before 及之前移入回收站的记录，以及此后不再被引用的分类
+	PurgeTrash(before time.Time) (model.PurgeResult, error)
+}
+
+// HistoryRepository 变更日志。记录、分类、标签、账户、预算、周期规则及其单次发生的每次修改
+// （包括进出回收站和彻底删除）都在同一事务中追加一个变更集，保存每个受影响实体修改前后的快照；
+// 没有实际变化的操作不产生变更集。设置、汇率和 CSV 列映射方案不记入变更日志
+type HistoryRepository interface {
+	// ListChanges 按先后顺序返回实体的全部变更
+	ListChanges(entity string, entityID int64) ([]model.Change, error)
+	// ListChangeSets 按先后顺序返回 ID 大于 afterID 的变更集，不含具体变更
+	ListChangeSets(afterID int64) ([]model.ChangeSet, error)
+	// LatestChangeSetID 返回最近一个变更集的 ID，没有时返回 0
+	LatestChangeSetID() (int64, error)
+	// RevertChangeSet 在一个事务中将变更集涉及的实体恢复为修改前的状态，并记为 ActionUndo 变更集；
+	// 变更集不存在时返回 ErrChangeSetNotFound，任一实体已被其他操作修改时返回 ErrUndoConflict
+	RevertChangeSet(id int64) error
+	// ReapplyChangeSet 与 RevertChangeSet 相反，将实体改回修改后的状态，记为 ActionRedo 变更集
+	ReapplyChangeSet(id int64) error
+}
+
+// ImportRepository 导入批次存取
+type ImportRepository interface {
+	// CreateImportBatch 在一个事务中创建导入批次及其分类、标签和记录，任一失败时全部回滚，
+	// 整批记为一个 ActionImport 变更集；分类和记录带上批次 ID。分类按先序排列，
+	// 分类的 ParentID、记录的 CategoryID 和记录标签的 ID 为负数 -n 时指向本批新建的第 n 个分类或标签。
+	// 成功后回填批次和各实体的 ID；分类或标签重名时返回 ErrDuplicateCategory / ErrDuplicateTag
+	CreateImportBatch(batch *model.ImportBatch, categories []model.Category, tags []model.Tag, records []model.Record) error
+	// ListImportBatches 按创建时间倒序返回全部导入批次
+	ListImportBatches() ([]model.ImportBatch, error)
+	// DeleteImportBatch 在一个事务中将批次导入的记录和分类移入回收站，记为 ActionUndoImport 变更集；
+	// 仍被批次外的记录或子分类使用的分类保留。批次不存在时返回 ErrImportBatchNotFound
+	DeleteImportBatch(id int64) (model.ImportUndoResult, error)
+}
+
+// CSVProfileRepository 通用 CSV 列映射方案存取
+type CSVProfileRepository interface {
+	// ListCSVProfiles 按名称返回全部方案
+	ListCSVProfiles() ([]model.CSVProfile, error)
+	// GetCSVProfile 不存在时返回 ErrProfileNotFound
+	GetCSVProfile(id int64) (*model.CSVProfile, error)
+	// CreateCSVProfile 名称重复时返回 ErrDuplicateProfile，成功后回填 ID
+	CreateCSVProfile(p *model.CSVProfile) error
+	// UpdateCSVProfile 更新名称和列映射，不存在时返回 ErrProfileNotFound
+	UpdateCSVProfile(p *model.CSVProfile) error
+	// DeleteCSVProfile 不存在时返回 ErrProfileNotFound
+	DeleteCSVProfile(id int64) error
+}
+
+// SettingsRepository 键值设置存取。设置是偏好而非账目，且包括 PIN 等安全设置，不记入变更日志
+type SettingsRepository interface {
+	// GetSetting 不存在时返回空字符串
+	GetSetting(key string) (string, error)
+	SetSetting(key, value string) error
+}
+
+// Repository 服务层依赖的完整存储接口
+type Repository interface {
+	CategoryRepository
+	RecordRepository
+	TagRepository
+	AccountRepository
+	StatsRepository
+	RateRepository
+	BudgetRepository
+	RecurringRepository
+	TrashRepository
+	HistoryRepository
+	ImportRepository
+	CSVProfileRepository
+	SettingsRepository
+	Close() error
+}
+
+var (
+	_ Repository = (*SQLiteRepository)(nil)
+	_ Repository = (*MemoryRepository)(nil)
+)
@@ -0,0 +1,2089 @@
+// Package repotest 提供 repository.Repository 的一致性测试套件，
+// 所有存储实现都应通过同一套用例，以保证服务层在不同后端上的行为一致。
+//
+// 用法（在实现所在包的测试文件中）：
+//
+//	func TestConformance(t *testing.T) {
+//		repotest.Run(t, func(t *testing.T) repository.Repository {
+//			return repository.NewMemoryRepository()
+//		})
+//	}
+package repotest
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	apperrors "dog-view/internal/errors"
+	"dog-view/internal/model"
+	"dog-view/internal/repository"
+)
+
+// Factory 为每个用例创建一个全新的空仓库
+type Factory func(t *testing.T) repository.Repository
+
+// Run 执行全部一致性用例
+func Run(t *testing.T, newRepo Factory) {
+	cases := []struct {
+		name string
+		fn   func(t *testing.T, repo repository.Repository)
+	}{
+		{"CategoryCRUD", testCategoryCRUD},
+		{"CategoryOrder", testCategoryOrder},
+		{"CategoryInUse", testCategoryInUse},
+		{"CategoryTree", testCategoryTree},
+		{"CategoryMerge", testCategoryMerge},
+		{"CategoryArchive", testCategoryArchive},
+		{"RecordCRUD", testRecordCRUD},
+		{"RecordRanges", testRecordRanges},
+		{"Tags", testTags},
+		{"TagFilters", testTagFilters},
+		{"RecordQuery", testRecordQuery},
+		{"RecordPaging", testRecordPaging},
+		{"Search", testSearch},
+		{"Trash", testTrash},
+		{"DeleteRecords", testDeleteRecords},
+		{"History", testHistory},
+		{"HistoryCoverage", testHistoryCoverage},
+		{"ImportBatch", testImportBatch},
+		{"Stats", testStats},
+		{"ExchangeRates", testExchangeRates},
+		{"Budgets", testBudgets},
+		{"RecurringRules", testRecurringRules},
+		{"Occurrences", testOccurrences},
+		{"Settings", testSettings},
+		{"CSVProfiles", testCSVProfiles},
+		{"Accounts", testAccounts},
+		{"AccountBalances", testAccountBalances},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			repo := newRepo(t)
+			defer repo.Close()
+			c.fn(t, repo)
+		})
+	}
+}
+
+// ============ 辅助函数 ============
+
+func mustCreateCategory(t *testing.T, repo repository.Repository, name, recordType string) *model.Category {
+	t.Helper()
+	c := &model.Category{Name: name, Icon: "🍚", Type: recordType}
+	if err := repo.CreateCategory(c); err != nil {
+		t.Fatalf("CreateCategory(%q): %v", name, err)
+	}
+	if c.ID == 0 {
+		t.Fatalf("CreateCategory(%q) 未回填 ID", name)
+	}
+	return c
+}
+
+func mustCreateRecord(t *testing.T, repo repository.Repository, categoryID int64, recordType string, minor int64, date string) *model.Record {
+	t.Helper()
+	rec := &model.Record{
+		Amount:     model.NewMoney(minor, model.DefaultCurrency),
+		Type:       recordType,
+		CategoryID: categoryID,
+		Note:       "note",
+		Date:       date,
+	}
+	if err := repo.CreateRecord(rec); err != nil {
+		t.Fatalf("CreateRecord: %v", err)
+	}
+	if rec.ID == 0 {
+		t.Fatal("CreateRecord 未回填 ID")
+	}
+	return rec
+}
+
+func mustCreateAccount(t *testing.T, repo repository.Repository, name string, opening int64) *model.Account {
+	t.Helper()
+	a := &model.Account{
+		Name:           name,
+		Type:           model.AccountBank,
+		OpeningBalance: model.NewMoney(opening, model.DefaultCurrency),
+	}
+	if err := repo.CreateAccount(a); err != nil {
+		t.Fatalf("CreateAccount(%q): %v", name, err)
+	}
+	if a.ID == 0 {
+		t.Fatalf("CreateAccount(%q) 未回填 ID", name)
+	}
+	return a
+}
+
+func expectErr(t *testing.T, got, want error) {
+	t.Helper()
+	if !errors.Is(got, want) {
+		t.Fatalf("期望错误 %v，实际 %v", want, got)
+	}
+}
+
+func recordIDs(records []model.Record) []int64 {
+	ids := make([]int64, 0, len(records))
+	for _, r := range records {
+		ids = append(ids, r.ID)
+	}
+	return ids
+}
+
+func equalIDs(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// ============ 用例 ============
+
+func testCategoryCRUD(t *testing.T, repo repository.Repository) {
+	food := mustCreateCategory(t, repo, "餐饮", model.TypeExpense)
+	mustCreateCategory(t, repo, "工资", model.TypeIncome)
+
+	expectErr(t, repo.CreateCategory(&model.Category{Name: "餐饮", Type: model.TypeExpense}), apperrors.ErrDuplicateCategory)
+
+	got, err := repo.GetCategoryByName("餐饮")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != food.ID || got.Type != model.TypeExpense || got.Icon != "🍚" {
+		t.Fatalf("GetCategoryByName 返回 %+v", got)
+	}
+	_, err = repo.GetCategoryByName("不存在")
+	expectErr(t, err, apperrors.ErrCategoryNotFound)
+
+	expenses, err := repo.ListCategories(model.TypeExpense)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(expenses) != 1 || expenses[0].Name != "餐饮" {
+		t.Fatalf("ListCategories(expense) 返回 %+v", expenses)
+	}
+	all, _ := repo.ListCategories("")
+	if len(all) != 2 {
+		t.Fatalf("ListCategories(\"\") 返回 %d 条，期望 2", len(all))
+	}
+
+	if err := repo.UpdateCategory(&model.Category{ID: food.ID, Name: "吃饭", Icon: "🍜"}); err != nil {
+		t.Fatal(err)
+	}
+	got, err = repo.GetCategoryByName("吃饭")
+	if err != nil || got.Icon != "🍜" {
+		t.Fatalf("UpdateCategory 未生效: %+v %v", got, err)
+	}
+	expectErr(t, repo.UpdateCategory(&model.Category{ID: food.ID, Name: "工资"}), apperrors.ErrDuplicateCategory)
+	expectErr(t, repo.UpdateCategory(&model.Category{ID: 9999, Name: "x"}), apperrors.ErrCategoryNotFound)
+
+	if err := repo.DeleteCategory(food.ID); err != nil {
+		t.Fatal(err)
+	}
+	expectErr(t, repo.DeleteCategory(food.ID), apperrors.ErrCategoryNotFound)
+}
+
+func testCategoryOrder(t *testing.T, repo repository.Repository) {
+	a := mustCreateCategory(t, repo, "A", model.TypeExpense)
+	b := mustCreateCategory(t, repo, "B", model.TypeExpense)
+	c := mustCreateCategory(t, repo, "C", model.TypeExpense)
+
+	if err := repo.UpdateCategoryOrder(0, []int64{c.ID, a.ID, b.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := repo.ListCategories(model.TypeExpense)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names string
+	for i, cat := range list {
+		names += cat.Name
+		if cat.SortOrder != i+1 {
+			t.Fatalf("%s 的排序值为 %d，期望 %d", cat.Name, cat.SortOrder, i+1)
+		}
+	}
+	if names != "CAB" {
+		t.Fatalf("排序结果 %s，期望 CAB", names)
+	}
+}
+
+func testCategoryTree(t *testing.T, repo repository.Repository) {
+	food := mustCreateCategory(t, repo, "餐饮", model.TypeExpense)
+	fun := mustCreateCategory(t, repo, "娱乐", model.TypeExpense)
+	children := make([]*model.Category, 0, 3)
+	for i, name := range []string{"早餐", "午餐", "咖啡"} {
+		c := &model.Category{Name: name, Type: model.TypeExpense, ParentID: food.ID, SortOrder: i + 1}
+		if err := repo.CreateCategory(c); err != nil {
+			t.Fatal(err)
+		}
+		children = append(children, c)
+	}
+
+	order := func() string {
+		t.Helper()
+		list, err := repo.ListCategories(model.TypeExpense)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names string
+		for _, c := range list {
+			names += c.Name + ","
+		}
+		return names
+	}
+	if got := order(); got != "餐饮,早餐,午餐,咖啡,娱乐," {
+		t.Fatalf("先序结果 %s", got)
+	}
+
+	got, err := repo.GetCategoryByName("咖啡")
+	if err != nil || got.ParentID != food.ID {
+		t.Fatalf("GetCategoryByName 返回 %+v %v", got, err)
+	}
+
+	// 只能在同一上级分类内排序
+	expectErr(t, repo.UpdateCategoryOrder(food.ID, []int64{children[2].ID, fun.ID}), apperrors.ErrInvalidParent)
+	if err := repo.UpdateCategoryOrder(food.ID, []int64{children[2].ID, children[0].ID, children[1].ID}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.UpdateCategoryOrder(0, []int64{fun.ID, food.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if got := order(); got != "娱乐,餐饮,咖啡,早餐,午餐," {
+		t.Fatalf("排序后先序结果 %s", got)
+	}
+
+	if err := repo.MoveCategory(children[2].ID, fun.ID, 1); err != nil {
+		t.Fatal(err)
+	}
+	expectErr(t, repo.MoveCategory(9999, 0, 1), apperrors.ErrCategoryNotFound)
+	if got := order(); got != "娱乐,咖啡,餐饮,早餐,午餐," {
+		t.Fatalf("移动后先序结果 %s", got)
+	}
+
+	expectErr(t, repo.DeleteCategory(food.ID), apperrors.ErrCategoryHasChildren)
+	for _, c := range children {
+		if err := repo.DeleteCategory(c.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := repo.DeleteCategory(food.ID); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testCategoryMerge(t *testing.T, repo repository.Repository) {
+	food := mustCreateCategory(t, repo, "餐饮", model.TypeExpense)
+	dup1 := mustCreateCategory(t, repo, "吃饭", model.TypeExpense)
+	dup2 := mustCreateCategory(t, repo, "饭钱", model.TypeExpense)
+	coffee := &model.Category{Name: "咖啡", Type: model.TypeExpense, ParentID: dup1.ID, SortOrder: 1}
+	if err := repo.CreateCategory(coffee); err != nil {
+		t.Fatal(err)
+	}
+
+	mustCreateRecord(t, repo, food.ID, model.TypeExpense, 100, "2024-01-01")
+	mustCreateRecord(t, repo, dup1.ID, model.TypeExpense, 200, "2024-01-02")
+	mustCreateRecord(t, repo, dup1.ID, model.TypeExpense, 300, "2024-01-03")
+	mustCreateRecord(t, repo, dup2.ID, model.TypeExpense, 400, "2024-01-04")
+	rule := mustCreateRule(t, repo, dup2.ID)
+
+	for _, b := range []model.Budget{
+		{Month: "2024-01", CategoryID: food.ID, Amount: model.NewMoney(1000, model.DefaultCurrency)},
+		{Month: "2024-01", CategoryID: dup1.ID, Amount: model.NewMoney(2000, model.DefaultCurrency)},
+		{Month: "2024-02", CategoryID: dup1.ID, Amount: model.NewMoney(3000, model.DefaultCurrency)},
+		{Month: "2024-02", CategoryID: dup2.ID, Amount: model.NewMoney(4000, model.DefaultCurrency)},
+	} {
+		if err := repo.SaveBudget(&b); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 任一源分类不存在时整体回滚
+	_, err := repo.MergeCategories([]int64{dup1.ID, 9999}, food.ID)
+	expectErr(t, err, apperrors.ErrCategoryNotFound)
+	if _, err := repo.GetCategoryByName("吃饭"); err != nil {
+		t.Fatalf("回滚后源分类应保留: %v", err)
+	}
+	_, err = repo.MergeCategories([]int64{dup1.ID}, 9999)
+	expectErr(t, err, apperrors.ErrCategoryNotFound)
+
+	moved, err := repo.MergeCategories([]int64{dup1.ID, dup2.ID}, food.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moved != 3 {
+		t.Fatalf("转移了 %d 条记录，期望 3", moved)
+	}
+
+	records, err := repo.GetAllRecords()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rec := range records {
+		if rec.CategoryID != food.ID {
+			t.Fatalf("记录 %d 仍属于分类 %d", rec.ID, rec.CategoryID)
+		}
+	}
+
+	got, err := repo.GetRecurringRule(rule.ID)
+	if err != nil || got.CategoryID != food.ID {
+		t.Fatalf("周期规则未转移: %+v %v", got, err)
+	}
+
+	budgets, err := repo.ListBudgets("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(budgets) != 2 || budgets[0].Amount.Minor != 1000 || budgets[1].Amount.Minor != 3000 {
+		t.Fatalf("预算合并结果 %+v", budgets)
+	}
+	for _, b := range budgets {
+		if b.CategoryID != food.ID {
+			t.Fatalf("预算 %+v 未转移", b)
+		}
+	}
+
+	child, err := repo.GetCategoryByName("咖啡")
+	if err != nil || child.ParentID != food.ID {
+		t.Fatalf("子分类未转移: %+v %v", child, err)
+	}
+	_, err = repo.GetCategoryByName("饭钱")
+	expectErr(t, err, apperrors.ErrCategoryNotFound)
+
+	// 目标分类挂在源分类下时提升为一级分类
+	if _, err := repo.MergeCategories([]int64{food.ID}, coffee.ID); err != nil {
+		t.Fatal(err)
+	}
+	child, err = repo.GetCategoryByName("咖啡")
+	if err != nil || child.ParentID != 0 {
+		t.Fatalf("目标分类未提升为一级分类: %+v %v", child, err)
+	}
+}
+
+func testCategoryArchive(t *testing.T, repo repository.Repository) {
+	gym := mustCreateCategory(t, repo, "健身", model.TypeExpense)
+	mustCreateRecord(t, repo, gym.ID, model.TypeExpense, 500, "2024-01-01")
+
+	if err := repo.SetCategoryArchived(gym.ID, true); err != nil {
+		t.Fatal(err)
+	}
+	expectErr(t, repo.SetCategoryArchived(9999, true), apperrors.ErrCategoryNotFound)
+
+	// 归档的分类仍会返回，由服务层决定是否展示
+	list, err := repo.ListCategories(model.TypeExpense)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || !list[0].Archived {
+		t.Fatalf("ListCategories 返回 %+v", list)
+	}
+	got, err := repo.GetCategoryByName("健身")
+	if err != nil || !got.Archived {
+		t.Fatalf("GetCategoryByName 返回 %+v %v", got, err)
+	}
+	records, err := repo.GetAllRecords()
+	if err != nil || len(records) != 1 || records[0].Category == nil {
+		t.Fatalf("归档分类的记录 %+v %v", records, err)
+	}
+
+	if err := repo.SetCategoryArchived(gym.ID, false); err != nil {
+		t.Fatal(err)
+	}
+	got, _ = repo.GetCategoryByName("健身")
+	if got.Archived {
+		t.Fatal("取消归档未生效")
+	}
+
+	// 导入时可直接创建归档分类
+	old := &model.Category{Name: "旧会员", Type: model.TypeExpense, Archived: true}
+	if err := repo.CreateCategory(old); err != nil {
+		t.Fatal(err)
+	}
+	got, _ = repo.GetCategoryByName("旧会员")
+	if !got.Archived {
+		t.Fatal("CreateCategory 未保存归档标记")
+	}
+}
+
+func testCategoryInUse(t *testing.T, repo repository.Repository) {
+	c := mustCreateCategory(t, repo, "交通", model.TypeExpense)
+	rec := mustCreateRecord(t, repo, c.ID, model.TypeExpense, 300, "2024-03-01")
+
+	expectErr(t, repo.DeleteCategory(c.ID), apperrors.ErrCategoryInUse)
+
+	if err := repo.DeleteRecord(rec.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.DeleteCategory(c.ID); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testRecordCRUD(t *testing.T, repo repository.Repository) {
+	food := mustCreateCategory(t, repo, "餐饮", model.TypeExpense)
+	fun := mustCreateCategory(t, repo, "娱乐", model.TypeExpense)
+	rec := mustCreateRecord(t, repo, food.ID, model.TypeExpense, 1234, "2024-01-15")
+
+	got, err := repo.GetRecordByID(rec.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Amount != model.NewMoney(1234, model.DefaultCurrency) || got.Date != "2024-01-15" || got.Note != "note" {
+		t.Fatalf("GetRecordByID 返回 %+v", got)
+	}
+	if got.Category == nil || got.Category.ID != food.ID || got.Category.Name != "餐饮" {
+		t.Fatalf("记录未关联分类: %+v", got.Category)
+	}
+	if got.CreatedAt.IsZero() {
+		t.Fatal("CreatedAt 未设置")
+	}
+
+	rec.Amount = model.NewMoney(99, "USD")
+	rec.CategoryID = fun.ID
+	rec.Note = "电影"
+	rec.Date = "2024-01-16"
+	if err := repo.UpdateRecord(rec); err != nil {
+		t.Fatal(err)
+	}
+	got, _ = repo.GetRecordByID(rec.ID)
+	if got.Amount != model.NewMoney(99, "USD") || got.CategoryID != fun.ID || got.Note != "电影" || got.Date != "2024-01-16" {
+		t.Fatalf("UpdateRecord 未生效: %+v", got)
+	}
+
+	if err := repo.DeleteRecord(rec.ID); err != nil {
+		t.Fatal(err)
+	}
+	_, err = repo.GetRecordByID(rec.ID)
+	expectErr(t, err, apperrors.ErrRecordNotFound)
+	expectErr(t, repo.DeleteRecord(rec.ID), apperrors.ErrRecordNotFound)
+	expectErr(t, repo.UpdateRecord(rec), apperrors.ErrRecordNotFound)
+}
+
+func testRecordRanges(t *testing.T, repo repository.Repository) {
+	c := mustCreateCategory(t, repo, "餐饮", model.TypeExpense)
+	jan1 := mustCreateRecord(t, repo, c.ID, model.TypeExpense, 100, "2024-01-01")
+	jan31a := mustCreateRecord(t, repo, c.ID, model.TypeExpense, 200, "2024-01-31")
+	jan31b := mustCreateRecord(t, repo, c.ID, model.TypeExpense, 300, "2024-01-31")
+	feb1 := mustCreateRecord(t, repo, c.ID, model.TypeExpense, 400, "2024-02-01")
+
+	jan, err := repo.ListRecordsBetween("2024-01-01", "2024-02-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{jan31b.ID, jan31a.ID, jan1.ID}; !equalIDs(recordIDs(jan), want) {
+		t.Fatalf("ListRecordsBetween 返回 %v，期望 %v（右开区间，日期倒序）", recordIDs(jan), want)
+	}
+
+	recent, err := repo.GetRecentRecords(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{feb1.ID, jan31b.ID}; !equalIDs(recordIDs(recent), want) {
+		t.Fatalf("GetRecentRecords 返回 %v，期望 %v", recordIDs(recent), want)
+	}
+
+	all, err := repo.GetAllRecords()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 4 {
+		t.Fatalf("GetAllRecords 返回 %d 条，期望 4", len(all))
+	}
+}
+
+func mustCreateTag(t *testing.T, repo repository.Repository, name string) model.Tag {
+	t.Helper()
+	tag := model.Tag{Name: name, Color: "#f00"}
+	if err := repo.CreateTag(&tag); err != nil {
+		t.Fatalf("CreateTag(%q): %v", name, err)
+	}
+	if tag.ID == 0 {
+		t.Fatalf("CreateTag(%q) 未回填 ID", name)
+	}
+	return tag
+}
+
+func tagNames(tags []model.Tag) []string {
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	return names
+}
+
+func testTags(t *testing.T, repo repository.Repository) {
+	food := mustCreateCategory(t, repo, "餐饮", model.TypeExpense)
+	travel := mustCreateTag(t, repo, "travel")
+	kid := mustCreateTag(t, repo, "kid")
+
+	expectErr(t, repo.CreateTag(&model.Tag{Name: "kid"}), apperrors.ErrDuplicateTag)
+	kid.Name = "travel"
+	expectErr(t, repo.UpdateTag(&kid), apperrors.ErrDuplicateTag)
+	kid.Name = "child"
+	if err := repo.UpdateTag(&kid); err != nil {
+		t.Fatal(err)
+	}
+
+	tags, err := repo.ListTags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := tagNames(tags); len(names) != 2 || names[0] != "child" || names[1] != "travel" {
+		t.Fatalf("ListTags 返回 %v，期望按名称排序", names)
+	}
+
+	rec := &model.Record{
+		Amount:     model.NewMoney(100, model.DefaultCurrency),
+		Type:       model.TypeExpense,
+		CategoryID: food.ID,
+		Tags:       []model.Tag{travel, kid},
+		Date:       "2024-01-01",
+	}
+	if err := repo.CreateRecord(rec); err != nil {
+		t.Fatal(err)
+	}
+	got, err := repo.GetRecordByID(rec.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := tagNames(got.Tags); len(names) != 2 || names[0] != "child" || names[1] != "travel" {
+		t.Fatalf("记录标签为 %v", names)
+	}
+
+	// UpdateRecord 将标签替换为 rec.Tags，与其他字段记入同一变更集
+	start := latestSet(t, repo)
+	got.Note = "changed"
+	got.Tags = []model.Tag{kid}
+	if err := repo.UpdateRecord(got); err != nil {
+		t.Fatal(err)
+	}
+	updated, err := repo.GetRecordByID(rec.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := tagNames(updated.Tags); updated.Note != "changed" || len(names) != 1 || names[0] != "child" {
+		t.Fatalf("UpdateRecord 后记录为 %+v，标签为 %v", updated, names)
+	}
+	if sets, err := repo.ListChangeSets(start); err != nil || len(sets) != 1 {
+		t.Fatalf("UpdateRecord 产生了 %d 个变更集: %v", len(sets), err)
+	}
+
+	if err := repo.SetRecordTags(rec.ID, []int64{travel.ID}); err != nil {
+		t.Fatal(err)
+	}
+	all, err := repo.GetAllRecords()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := tagNames(all[0].Tags); len(names) != 1 || names[0] != "travel" {
+		t.Fatalf("SetRecordTags 后标签为 %v", names)
+	}
+	expectErr(t, repo.SetRecordTags(rec.ID+100, []int64{travel.ID}), apperrors.ErrRecordNotFound)
+
+	// 删除标签后记录保留，只是不再带有该标签
+	if err := repo.DeleteTag(travel.ID); err != nil {
+		t.Fatal(err)
+	}
+	expectErr(t, repo.DeleteTag(travel.ID), apperrors.ErrTagNotFound)
+	expectErr(t, repo.UpdateTag(&travel), apperrors.ErrTagNotFound)
+	got, err = repo.GetRecordByID(rec.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Tags) != 0 {
+		t.Fatalf("删除标签后记录标签为 %v", tagNames(got.Tags))
+	}
+}
+
+func testRecordQuery(t *testing.T, repo repository.Repository) {
+	food := mustCreateCategory(t, repo, "餐饮", model.TypeExpense)
+	fun := mustCreateCategory(t, repo, "娱乐", model.TypeExpense)
+	salary := mustCreateCategory(t, repo, "工资", model.TypeIncome)
+
+	create := func(categoryID int64, recordType string, minor int64, currency, note, date string) int64 {
+		t.Helper()
+		rec := &model.Record{
+			Amount:     model.NewMoney(minor, currency),
+			Type:       recordType,
+			CategoryID: categoryID,
+			Note:       note,
+			Date:       date,
+		}
+		if err := repo.CreateRecord(rec); err != nil {
+			t.Fatal(err)
+		}
+		return rec.ID
+	}
+
+	lunch := create(food.ID, model.TypeExpense, 3000, "CNY", "Lunch with team", "2024-01-02")
+	movie := create(fun.ID, model.TypeExpense, 8000, "CNY", "电影 100%好看", "2024-01-03")
+	sushi := create(food.ID, model.TypeExpense, 150000, "JPY", "sushi_bar", "2024-01-04")
+	pay := create(salary.ID, model.TypeIncome, 1000000, "CNY", "", "2024-01-10")
+	transfer := create(0, model.TypeTransfer, 5000, "CNY", "", "2024-01-11")
+
+	cases := []struct {
+		name   string
+		filter model.RecordFilter
+		want   []int64
+	}{
+		{"不限", model.RecordFilter{}, []int64{transfer, pay, sushi, movie, lunch}},
+		{"类型", model.RecordFilter{Types: []string{model.TypeIncome, model.TypeTransfer}}, []int64{transfer, pay}},
+		{"分类", model.RecordFilter{CategoryIDs: []int64{food.ID}}, []int64{sushi, lunch}},
+		{"金额区间", model.RecordFilter{MinAmount: 3000, MaxAmount: 8000}, []int64{transfer, movie, lunch}},
+		{"备注不区分大小写", model.RecordFilter{Note: "LUNCH"}, []int64{lunch}},
+		{"备注中的百分号", model.RecordFilter{Note: "0%好"}, []int64{movie}},
+		{"备注中的下划线", model.RecordFilter{Note: "i_b"}, []int64{sushi}},
+		{"下划线不作通配符", model.RecordFilter{Note: "h_w"}, []int64{}},
+		{"组合条件", model.RecordFilter{StartDate: "2024-01-03", EndDate: "2024-01-10", Types: []string{model.TypeExpense}}, []int64{sushi, movie}},
+	}
+	for _, c := range cases {
+		records, err := repo.ListRecords(c.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := recordIDs(records); !equalIDs(got, c.want) {
+			t.Fatalf("%s: ListRecords 返回 %v，期望 %v", c.name, got, c.want)
+		}
+
+		_, count, err := repo.GetRecordTotals(c.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != len(c.want) {
+			t.Fatalf("%s: GetRecordTotals 记录数 %d，期望 %d", c.name, count, len(c.want))
+		}
+	}
+
+	// 合计只含收支，按币种分组
+	totals, _, err := repo.GetRecordTotals(model.RecordFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(totals) != 4 {
+		t.Fatalf("GetRecordTotals 返回 %+v", totals)
+	}
+	for _, total := range totals {
+		if total.Date == "2024-01-04" && (total.Amount.Currency != "JPY" || total.Amount.Minor != 150000) {
+			t.Fatalf("GetRecordTotals 返回 %+v", totals)
+		}
+	}
+}
+
+func testRecordPaging(t *testing.T, repo repository.Repository) {
+	food := mustCreateCategory(t, repo, "餐饮", model.TypeExpense)
+	// 日期和金额都有重复，验证按 ID 决胜的稳定翻页
+	a := mustCreateRecord(t, repo, food.ID, model.TypeExpense, 300, "2024-01-01")
+	b := mustCreateRecord(t, repo, food.ID, model.TypeExpense, 100, "2024-01-02")
+	c := mustCreateRecord(t, repo, food.ID, model.TypeExpense, 300, "2024-01-02")
+	d := mustCreateRecord(t, repo, food.ID, model.TypeExpense, 200, "2024-01-02")
+	e := mustCreateRecord(t, repo, food.ID, model.TypeExpense, 100, "2024-01-03")
+
+	cases := []struct {
+		name   string
+		sortBy string
+		desc   bool
+		want   []int64
+	}{
+		{"日期升序", model.SortByDate, false, []int64{a.ID, b.ID, c.ID, d.ID, e.ID}},
+		{"日期倒序", model.SortByDate, true, []int64{e.ID, d.ID, c.ID, b.ID, a.ID}},
+		{"金额升序", model.SortByAmount, false, []int64{b.ID, e.ID, d.ID, a.ID, c.ID}},
+		{"金额倒序", model.SortByAmount, true, []int64{c.ID, a.ID, d.ID, e.ID, b.ID}},
+	}
+	for _, tc := range cases {
+		var got []int64
+		q := model.RecordQuery{SortBy: tc.sortBy, Desc: tc.desc, Limit: 2}
+		for page := 0; page < 5; page++ {
+			records, err := repo.QueryRecords(q)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, recordIDs(records)...)
+			if len(records) < q.Limit {
+				break
+			}
+			q.Cursor = model.CursorOf(records[len(records)-1]).Encode()
+		}
+		if !equalIDs(got, tc.want) {
+			t.Fatalf("%s: 翻页结果 %v，期望 %v", tc.name, got, tc.want)
+		}
+	}
+
+	_, err := repo.QueryRecords(model.RecordQuery{Cursor: "not a cursor"})
+	if err == nil {
+		t.Fatal("无效游标应返回错误")
+	}
+}
+
+func testSearch(t *testing.T, repo repository.Repository) {
+	health := mustCreateCategory(t, repo, "医疗", model.TypeExpense)
+
+	create := func(note, date string) *model.Record {
+		t.Helper()
+		rec := &model.Record{
+			Amount:     model.NewMoney(100, model.DefaultCurrency),
+			Type:       model.TypeExpense,
+			CategoryID: health.ID,
+			Note:       note,
+			Date:       date,
+		}
+		if err := repo.CreateRecord(rec); err != nil {
+			t.Fatal(err)
+		}
+		return rec
+	}
+
+	dentist := create("看牙医 补牙两颗 dentist bill", "2023-05-01")
+	checkup := create("年度体检 Dentist 预约", "2024-02-01")
+	create("买药", "2024-03-01")
+
+	ids := func(results []model.SearchResult) map[int64]string {
+		out := make(map[int64]string, len(results))
+		for _, r := range results {
+			out[r.Record.ID] = r.Snippet
+		}
+		return out
+	}
+	hl := func(s string) string { return model.HighlightStart + s + model.HighlightEnd }
+
+	results, err := repo.SearchRecords([]string{"dentist"}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := ids(results)
+	if len(got) != 2 || !strings.Contains(got[dentist.ID], hl("dentist")) || !strings.Contains(got[checkup.ID], hl("Dentist")) {
+		t.Fatalf("搜索 dentist 返回 %q", got)
+	}
+	if results[0].Record.Category == nil || results[0].Record.Category.Name != "医疗" {
+		t.Fatalf("搜索结果缺少分类: %+v", results[0].Record)
+	}
+
+	// 中文短词与多个词同时匹配
+	results, err = repo.SearchRecords([]string{"牙医"}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(results); len(got) != 1 || !strings.Contains(got[dentist.ID], hl("牙医")) {
+		t.Fatalf("搜索 牙医 返回 %q", got)
+	}
+	results, err = repo.SearchRecords([]string{"补牙两颗", "bill"}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(results); len(got) != 1 || got[dentist.ID] == "" {
+		t.Fatalf("搜索 补牙两颗 bill 返回 %q", got)
+	}
+
+	results, err = repo.SearchRecords([]string{"dentist"}, 1)
+	if err != nil || len(results) != 1 {
+		t.Fatalf("limit 未生效: %d %v", len(results), err)
+	}
+
+	// 修改和删除记录后索引保持同步
+	dentist.Note = "洗牙"
+	if err := repo.UpdateRecord(dentist); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.DeleteRecord(checkup.ID); err != nil {
+		t.Fatal(err)
+	}
+	results, err = repo.SearchRecords([]string{"dentist"}, 10)
+	if err != nil || len(results) != 0 {
+		t.Fatalf("修改和删除后仍能搜到: %+v %v", results, err)
+	}
+	results, err = repo.SearchRecords([]string{"洗牙"}, 10)
+	if err != nil || len(results) != 1 {
+		t.Fatalf("修改后搜不到新备注: %+v %v", results, err)
+	}
+}
+
+func testTagFilters(t *testing.T, repo repository.Repository) {
+	food := mustCreateCategory(t, repo, "餐饮", model.TypeExpense)
+	travel := mustCreateTag(t, repo, "travel")
+	reimb := mustCreateTag(t, repo, "reimbursable")
+
+	create := func(minor int64, date string, tags ...model.Tag) *model.Record {
+		t.Helper()
+		rec := &model.Record{
+			Amount:     model.NewMoney(minor, model.DefaultCurrency),
+			Type:       model.TypeExpense,
+			CategoryID: food.ID,
+			Tags:       tags,
+			Date:       date,
+		}
+		if err := repo.CreateRecord(rec); err != nil {
+			t.Fatal(err)
+		}
+		return rec
+	}
+
+	both := create(100, "2024-01-03", travel, reimb)
+	onlyTravel := create(200, "2024-01-02", travel)
+	create(400, "2024-01-01")
+	create(800, "2024-02-01", travel)
+
+	cases := []struct {
+		name   string
+		filter model.RecordFilter
+		want   []int64
+	}{
+		{"任一标签", model.RecordFilter{StartDate: "2024-01-01", EndDate: "2024-02-01", TagIDs: []int64{travel.ID, reimb.ID}}, []int64{both.ID, onlyTravel.ID}},
+		{"全部标签", model.RecordFilter{StartDate: "2024-01-01", EndDate: "2024-02-01", TagIDs: []int64{travel.ID, reimb.ID}, MatchAllTags: true}, []int64{both.ID}},
+		{"重复的标签", model.RecordFilter{TagIDs: []int64{reimb.ID, reimb.ID}, MatchAllTags: true}, []int64{both.ID}},
+	}
+	for _, c := range cases {
+		records, err := repo.ListRecords(c.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := recordIDs(records); !equalIDs(got, c.want) {
+			t.Fatalf("%s: ListRecords 返回 %v，期望 %v", c.name, got, c.want)
+		}
+	}
+
+	all, err := repo.ListRecords(model.RecordFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 4 {
+		t.Fatalf("无筛选条件返回 %d 条，期望 4", len(all))
+	}
+
+	totals, err := repo.GetTagTotals("2024-01-01", "2024-02-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sums := make(map[int64]int64)
+	for _, total := range totals {
+		sums[total.TagID] += total.Amount.Minor
+	}
+	if len(sums) != 2 || sums[travel.ID] != 300 || sums[reimb.ID] != 100 {
+		t.Fatalf("GetTagTotals 返回 %+v", totals)
+	}
+}
+
+func testTrash(t *testing.T, repo repository.Repository) {
+	food := mustCreateCategory(t, repo, "餐饮", model.TypeExpense)
+	snack := &model.Category{Name: "零食", Type: model.TypeExpense, ParentID: food.ID}
+	if err := repo.CreateCategory(snack); err != nil {
+		t.Fatal(err)
+	}
+	tag := mustCreateTag(t, repo, "出差")
+	kept := mustCreateRecord(t, repo, food.ID, model.TypeExpense, 100, "2024-05-01")
+	rec := &model.Record{
+		Amount:     model.NewMoney(250, model.DefaultCurrency),
+		Type:       model.TypeExpense,
+		CategoryID: snack.ID,
+		Note:       "薯片",
+		Date:       "2024-05-02",
+		Tags:       []model.Tag{tag},
+	}
+	if err := repo.CreateRecord(rec); err != nil {
+		t.Fatal(err)
+	}
+
+	// 移入回收站后从查询、统计和搜索中消失
+	if err := repo.DeleteRecord(rec.ID); err != nil {
+		t.Fatal(err)
+	}
+	_, err := repo.GetRecordByID(rec.ID)
+	expectErr(t, err, apperrors.ErrRecordNotFound)
+	expectErr(t, repo.DeleteRecord(rec.ID), apperrors.ErrRecordNotFound)
+	expectErr(t, repo.UpdateRecord(rec), apperrors.ErrRecordNotFound)
+	expectErr(t, repo.SetRecordTags(rec.ID, nil), apperrors.ErrRecordNotFound)
+
+	all, err := repo.GetAllRecords()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equalIDs(recordIDs(all), []int64{kept.ID}) {
+		t.Fatalf("GetAllRecords 仍包含回收站中的记录: %v", recordIDs(all))
+	}
+	totals, err := repo.GetStatTotals("2024-05-01", "2024-06-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(totals) != 1 || totals[0].CategoryID != food.ID {
+		t.Fatalf("GetStatTotals 返回 %+v", totals)
+	}
+	tagTotals, err := repo.GetTagTotals("2024-05-01", "2024-06-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tagTotals) != 0 {
+		t.Fatalf("GetTagTotals 返回 %+v", tagTotals)
+	}
+	_, count, err := repo.GetRecordTotals(model.RecordFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("GetRecordTotals 记录数为 %d", count)
+	}
+	found, err := repo.SearchRecords([]string{"薯片"}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 0 {
+		t.Fatalf("SearchRecords 返回回收站中的记录: %+v", found)
+	}
+
+	// 子分类的记录都在回收站中时可以删除，之后重名分类可以再次创建
+	if err := repo.DeleteCategory(snack.ID); err != nil {
+		t.Fatal(err)
+	}
+	expectErr(t, repo.DeleteCategory(food.ID), apperrors.ErrCategoryInUse)
+	if _, err := repo.GetCategoryByName("零食"); !errors.Is(err, apperrors.ErrCategoryNotFound) {
+		t.Fatalf("GetCategoryByName 返回回收站中的分类: %v", err)
+	}
+	categories, err := repo.ListCategories("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range categories {
+		if c.ID == snack.ID {
+			t.Fatal("ListCategories 仍包含回收站中的分类")
+		}
+	}
+
+	trashed, err := repo.ListDeletedRecords()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trashed) != 1 || trashed[0].ID != rec.ID || trashed[0].DeletedAt == nil {
+		t.Fatalf("ListDeletedRecords 返回 %+v", trashed)
+	}
+	if trashed[0].Category == nil || trashed[0].Category.Name != "零食" || len(trashed[0].Tags) != 1 {
+		t.Fatalf("回收站中的记录未关联分类和标签: %+v", trashed[0])
+	}
+	deletedCategories, err := repo.ListDeletedCategories()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deletedCategories) != 1 || deletedCategories[0].ID != snack.ID || deletedCategories[0].DeletedAt == nil {
+		t.Fatalf("ListDeletedCategories 返回 %+v", deletedCategories)
+	}
+
+	// 彻底删除前需先处理引用它的记录
+	expectErr(t, repo.PurgeCategory(snack.ID), apperrors.ErrCategoryInUse)
+	expectErr(t, repo.PurgeCategory(food.ID), apperrors.ErrCategoryNotFound)
+	expectErr(t, repo.RestoreCategory(food.ID), apperrors.ErrCategoryNotFound)
+	expectErr(t, repo.RestoreRecord(kept.ID), apperrors.ErrRecordNotFound)
+	expectErr(t, repo.PurgeRecord(kept.ID), apperrors.ErrRecordNotFound)
+
+	// 恢复记录时分类一并恢复，标签关联保留
+	if err := repo.RestoreRecord(rec.ID); err != nil {
+		t.Fatal(err)
+	}
+	got, err := repo.GetRecordByID(rec.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.DeletedAt != nil || len(got.Tags) != 1 || got.Tags[0].ID != tag.ID {
+		t.Fatalf("恢复后的记录为 %+v", got)
+	}
+	if c, err := repo.GetCategoryByName("零食"); err != nil || c.ParentID != food.ID {
+		t.Fatalf("恢复记录后分类为 %+v, %v", c, err)
+	}
+
+	// 与现有分类重名时不能恢复
+	if err := repo.DeleteRecord(rec.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.DeleteCategory(snack.ID); err != nil {
+		t.Fatal(err)
+	}
+	mustCreateCategory(t, repo, "零食", model.TypeExpense)
+	expectErr(t, repo.RestoreCategory(snack.ID), apperrors.ErrDuplicateCategory)
+	expectErr(t, repo.RestoreRecord(rec.ID), apperrors.ErrDuplicateCategory)
+
+	// 截止时间之前删除的内容不受影响
+	result, err := repo.PurgeTrash(time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != (model.PurgeResult{}) {
+		t.Fatalf("PurgeTrash 删除了未过期的内容: %+v", result)
+	}
+
+	// 记录先于分类删除，分类随后不再被引用
+	result, err = repo.PurgeTrash(time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != (model.PurgeResult{Records: 1, Categories: 1}) {
+		t.Fatalf("PurgeTrash 返回 %+v", result)
+	}
+	trashed, _ = repo.ListDeletedRecords()
+	deletedCategories, _ = repo.ListDeletedCategories()
+	if len(trashed) != 0 || len(deletedCategories) != 0 {
+		t.Fatalf("清空后回收站仍有 %d 条记录、%d 个分类", len(trashed), len(deletedCategories))
+	}
+	expectErr(t, repo.RestoreRecord(rec.ID), apperrors.ErrRecordNotFound)
+
+	// 单独彻底删除
+	if err := repo.DeleteRecord(kept.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.PurgeRecord(kept.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.DeleteCategory(food.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.PurgeCategory(food.ID); err != nil {
+		t.Fatal(err)
+	}
+	expectErr(t, repo.PurgeCategory(food.ID), apperrors.ErrCategoryNotFound)
+}
+
+func testDeleteRecords(t *testing.T, repo repository.Repository) {
+	food := mustCreateCategory(t, repo, "餐饮", model.TypeExpense)
+	a := mustCreateRecord(t, repo, food.ID, model.TypeExpense, 100, "2024-05-01")
+	b := mustCreateRecord(t, repo, food.ID, model.TypeExpense, 100, "2024-05-01")
+	c := mustCreateRecord(t, repo, food.ID, model.TypeExpense, 100, "2024-05-01")
+	if err := repo.DeleteRecord(c.ID); err != nil {
+		t.Fatal(err)
+	}
+	start := latestSet(t, repo)
+
+	// 没有可删除的记录时不产生变更集
+	n, err := repo.DeleteRecords([]int64{c.ID, 9999})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 || latestSet(t, repo) != start {
+		t.Fatalf("DeleteRecords 删除了 %d 条，变更集 %d", n, latestSet(t, repo))
+	}
+
+	// 已在回收站和不存在的 ID 跳过，重复的 ID 只计一次
+	n, err = repo.DeleteRecords([]int64{a.ID, b.ID, b.ID, c.ID, 9999})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Fatalf("DeleteRecords 删除了 %d 条", n)
+	}
+	if all, _ := repo.GetAllRecords(); len(all) != 0 {
+		t.Fatalf("删除后仍有记录 %v", recordIDs(all))
+	}
+	sets, err := repo.ListChangeSets(start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sets) != 1 || sets[0].Action != model.ActionDeleteRecord {
+		t.Fatalf("DeleteRecords 产生的变更集 %+v", sets)
+	}
+
+	// 整体撤销
+	if err := repo.RevertChangeSet(sets[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	all, err := repo.GetAllRecords()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equalIDs(recordIDs(all), []int64{b.ID, a.ID}) {
+		t.Fatalf("撤销后的记录 %v", recordIDs(all))
+	}
+}
+
+// latestSet 返回最近一个变更集的 ID
+func latestSet(t *testing.T, repo repository.Repository) int64 {
+	t.Helper()
+	id, err := repo.LatestChangeSetID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func testHistory(t *testing.T, repo repository.Repository) {
+	start := latestSet(t, repo)
+	food := mustCreateCategory(t, repo, "餐饮", model.TypeExpense)
+	tag := mustCreateTag(t, repo, "出差")
+	rec := &model.Record{
+		Amount:     model.NewMoney(100, model.DefaultCurrency),
+		Type:       model.TypeExpense,
+		CategoryID: food.ID,
+		Note:       "午饭",
+		Date:       "2024-01-01",
+		Tags:       []model.Tag{tag},
+	}
+	if err := repo.CreateRecord(rec); err != nil {
+		t.Fatal(err)
+	}
+	createSet := latestSet(t, repo)
+
+	rec.Amount = model.NewMoney(300, model.DefaultCurrency)
+	rec.Note = "晚饭"
+	if err := repo.UpdateRecord(rec); err != nil {
+		t.Fatal(err)
+	}
+	updateSet := latestSet(t, repo)
+
+	// 没有实际变化的操作不产生变更集
+	if err := repo.UpdateRecord(rec); err != nil {
+		t.Fatal(err)
+	}
+	if got := latestSet(t, repo); got != updateSet {
+		t.Fatalf("未修改任何字段仍产生了变更集 %d", got)
+	}
+
+	sets, err := repo.ListChangeSets(start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actions []string
+	for _, set := range sets {
+		actions = append(actions, set.Action)
+	}
+	want := []string{model.ActionCreateCategory, model.ActionCreateTag, model.ActionCreateRecord, model.ActionUpdateRecord}
+	if strings.Join(actions, ",") != strings.Join(want, ",") {
+		t.Fatalf("ListChangeSets 返回 %v，期望 %v", actions, want)
+	}
+
+	changes, err := repo.ListChanges(model.EntityRecord, rec.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 || changes[0].Before != nil || changes[0].After == nil ||
+		changes[1].SetID != updateSet || changes[1].Before == nil || changes[1].After == nil {
+		t.Fatalf("ListChanges 返回 %+v", changes)
+	}
+
+	amountOf := func(id int64) int64 {
+		t.Helper()
+		got, err := repo.GetRecordByID(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return got.Amount.Minor
+	}
+
+	// 撤销和重做修改
+	if err := repo.RevertChangeSet(updateSet); err != nil {
+		t.Fatal(err)
+	}
+	if got := amountOf(rec.ID); got != 100 {
+		t.Fatalf("撤销后金额为 %d，期望 100", got)
+	}
+	expectErr(t, repo.RevertChangeSet(updateSet), apperrors.ErrUndoConflict)
+	if err := repo.ReapplyChangeSet(updateSet); err != nil {
+		t.Fatal(err)
+	}
+	if got := amountOf(rec.ID); got != 300 {
+		t.Fatalf("重做后金额为 %d，期望 300", got)
+	}
+	sets, err = repo.ListChangeSets(updateSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sets) != 2 || sets[0].Action != model.ActionUndo || sets[0].TargetID != updateSet ||
+		sets[1].Action != model.ActionRedo || sets[1].TargetID != updateSet {
+		t.Fatalf("撤销和重做的变更集为 %+v", sets)
+	}
+
+	// 之后的修改使撤销失败，且不改变任何数据
+	rec.Amount = model.NewMoney(500, model.DefaultCurrency)
+	if err := repo.UpdateRecord(rec); err != nil {
+		t.Fatal(err)
+	}
+	before := latestSet(t, repo)
+	expectErr(t, repo.RevertChangeSet(updateSet), apperrors.ErrUndoConflict)
+	if got := amountOf(rec.ID); got != 500 {
+		t.Fatalf("撤销失败后金额为 %d，期望 500", got)
+	}
+	if got := latestSet(t, repo); got != before {
+		t.Fatalf("撤销失败仍产生了变更集 %d", got)
+	}
+	expectErr(t, repo.RevertChangeSet(before+100), apperrors.ErrChangeSetNotFound)
+
+	// 删除到回收站后撤销，记录回到列表中
+	if err := repo.DeleteRecord(rec.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.RevertChangeSet(latestSet(t, repo)); err != nil {
+		t.Fatal(err)
+	}
+	if got := amountOf(rec.ID); got != 500 {
+		t.Fatalf("撤销删除后金额为 %d，期望 500", got)
+	}
+
+	// 撤销删除标签后，标签及其与记录的关联一并恢复
+	if err := repo.DeleteTag(tag.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.RevertChangeSet(latestSet(t, repo)); err != nil {
+		t.Fatal(err)
+	}
+	got, err := repo.GetRecordByID(rec.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := tagNames(got.Tags); len(names) != 1 || names[0] != "出差" {
+		t.Fatalf("撤销删除标签后记录的标签为 %v", names)
+	}
+
+	// 撤销合并后，源分类和其中的记录复原
+	dup := mustCreateCategory(t, repo, "吃饭", model.TypeExpense)
+	moved := mustCreateRecord(t, repo, dup.ID, model.TypeExpense, 200, "2024-01-02")
+	if _, err := repo.MergeCategories([]int64{dup.ID}, food.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.RevertChangeSet(latestSet(t, repo)); err != nil {
+		t.Fatal(err)
+	}
+	got, err = repo.GetRecordByID(moved.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.CategoryID != dup.ID || got.Category == nil || got.Category.Name != "吃饭" {
+		t.Fatalf("撤销合并后记录的分类为 %d", got.CategoryID)
+	}
+	// 分类名称被占用时撤销失败，不改变任何数据
+	if err := repo.DeleteRecord(moved.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.DeleteCategory(dup.ID); err != nil {
+		t.Fatal(err)
+	}
+	deleteSet := latestSet(t, repo)
+	mustCreateCategory(t, repo, "吃饭", model.TypeExpense)
+	expectErr(t, repo.RevertChangeSet(deleteSet), apperrors.ErrDuplicateCategory)
+	deleted, err := repo.ListDeletedCategories()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deleted) != 1 || deleted[0].ID != dup.ID {
+		t.Fatalf("撤销失败后回收站中的分类为 %+v", deleted)
+	}
+
+	// 撤销新建后记录彻底消失，重做后带着标签复原；之后被修改过的记录不能撤销新建
+	expectErr(t, repo.RevertChangeSet(createSet), apperrors.ErrUndoConflict)
+	fresh := &model.Record{
+		Amount:     model.NewMoney(800, model.DefaultCurrency),
+		Type:       model.TypeExpense,
+		CategoryID: food.ID,
+		Date:       "2024-01-03",
+		Tags:       []model.Tag{tag},
+	}
+	if err := repo.CreateRecord(fresh); err != nil {
+		t.Fatal(err)
+	}
+	freshSet := latestSet(t, repo)
+	if err := repo.RevertChangeSet(freshSet); err != nil {
+		t.Fatal(err)
+	}
+	_, err = repo.GetRecordByID(fresh.ID)
+	expectErr(t, err, apperrors.ErrRecordNotFound)
+	if err := repo.ReapplyChangeSet(freshSet); err != nil {
+		t.Fatal(err)
+	}
+	got, err = repo.GetRecordByID(fresh.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Amount.Minor != 800 || len(got.Tags) != 1 || got.Tags[0].ID != tag.ID {
+		t.Fatalf("重做新建后记录为 %+v", got)
+	}
+
+	// 同时修改字段和标签时，撤销一次即全部还原
+	got.Note = "夜宵"
+	got.Tags = nil
+	if err := repo.UpdateRecord(got); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.RevertChangeSet(latestSet(t, repo)); err != nil {
+		t.Fatal(err)
+	}
+	got, err = repo.GetRecordByID(fresh.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Note != "" || len(got.Tags) != 1 || got.Tags[0].ID != tag.ID {
+		t.Fatalf("撤销修改后记录为 %+v", got)
+	}
+}
+
+// 账户、预算和周期规则的修改同样记入变更日志，设置不记入
+func testHistoryCoverage(t *testing.T, repo repository.Repository) {
+	food := mustCreateCategory(t, repo, "餐饮", model.TypeExpense)
+	start := latestSet(t, repo)
+
+	// 账户：修改后撤销恢复原状，撤销新建后账户消失
+	card := mustCreateAccount(t, repo, "银行卡", 1000)
+	createAccount := latestSet(t, repo)
+	card.Name = "工资卡"
+	if err := repo.UpdateAccount(card); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.RevertChangeSet(latestSet(t, repo)); err != nil {
+		t.Fatal(err)
+	}
+	got, err := repo.GetAccountByID(card.ID)
+	if err != nil || got.Name != "银行卡" {
+		t.Fatalf("撤销修改后账户为 %+v, %v", got, err)
+	}
+	// 账户被记录引用后不能撤销新建
+	rec := mustCreateRecord(t, repo, food.ID, model.TypeExpense, 100, "2024-01-01")
+	rec.AccountID = card.ID
+	if err := repo.UpdateRecord(rec); err != nil {
+		t.Fatal(err)
+	}
+	expectErr(t, repo.RevertChangeSet(createAccount), apperrors.ErrUndoConflict)
+	if err := repo.DeleteRecord(rec.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.PurgeRecord(rec.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.DeleteAccount(card.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.RevertChangeSet(latestSet(t, repo)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.GetAccountByID(card.ID); err != nil {
+		t.Fatalf("撤销删除后账户未恢复: %v", err)
+	}
+
+	// 预算：覆盖保存后撤销恢复原金额
+	b := &model.Budget{Month: "2024-01", CategoryID: food.ID, Amount: model.NewMoney(1000, model.DefaultCurrency)}
+	if err := repo.SaveBudget(b); err != nil {
+		t.Fatal(err)
+	}
+	b.Amount = model.NewMoney(2000, model.DefaultCurrency)
+	if err := repo.SaveBudget(b); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.RevertChangeSet(latestSet(t, repo)); err != nil {
+		t.Fatal(err)
+	}
+	budgets, err := repo.ListBudgets("")
+	if err != nil || len(budgets) != 1 || budgets[0].Amount.Minor != 1000 {
+		t.Fatalf("撤销覆盖后预算为 %+v, %v", budgets, err)
+	}
+	if err := repo.DeleteBudget(b.ID); err != nil {
+		t.Fatal(err)
+	}
+	deleteBudget := latestSet(t, repo)
+	// 同一月份同一分类已重新设置预算时不能撤销删除
+	again := &model.Budget{Month: "2024-01", CategoryID: food.ID, Amount: model.NewMoney(3000, model.DefaultCurrency)}
+	if err := repo.SaveBudget(again); err != nil {
+		t.Fatal(err)
+	}
+	expectErr(t, repo.RevertChangeSet(deleteBudget), apperrors.ErrUndoConflict)
+
+	// 周期规则：撤销删除时规则和已保存的发生一起恢复
+	rule := mustCreateRule(t, repo, food.ID)
+	skip := &model.Occurrence{RuleID: rule.ID, Date: "2024-01-31", Status: model.OccurrenceSkipped}
+	if err := repo.SaveOccurrence(skip); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.DeleteRecurringRule(rule.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.RevertChangeSet(latestSet(t, repo)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.GetRecurringRule(rule.ID); err != nil {
+		t.Fatalf("撤销删除后规则未恢复: %v", err)
+	}
+	occurrences, err := repo.ListOccurrences(rule.ID)
+	if err != nil || len(occurrences) != 1 || occurrences[0].Status != model.OccurrenceSkipped {
+		t.Fatalf("撤销删除后发生记录为 %+v, %v", occurrences, err)
+	}
+	// 撤销跳过后该次发生恢复为未处理
+	saveSet := func() int64 {
+		sets, err := repo.ListChangeSets(start)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := len(sets) - 1; i >= 0; i-- {
+			if sets[i].Action == model.ActionSaveOccurrence {
+				return sets[i].ID
+			}
+		}
+		t.Fatal("保存发生未产生变更集")
+		return 0
+	}()
+	if err := repo.RevertChangeSet(saveSet); err != nil {
+		t.Fatal(err)
+	}
+	occurrences, err = repo.ListOccurrences(rule.ID)
+	if err != nil || len(occurrences) != 0 {
+		t.Fatalf("撤销跳过后发生记录为 %+v, %v", occurrences, err)
+	}
+
+	// 设置不记入变更日志
+	before := latestSet(t, repo)
+	if err := repo.SetSetting("base_currency", "USD"); err != nil {
+		t.Fatal(err)
+	}
+	if got := latestSet(t, repo); got != before {
+		t.Fatalf("修改设置产生了变更集 %d", got)
+	}
+}
+
+func testImportBatch(t *testing.T, repo repository.Repository) {
+	food := mustCreateCategory(t, repo, "餐饮", model.TypeExpense)
+	trip := mustCreateTag(t, repo, "出差")
+	start := latestSet(t, repo)
+
+	// 分类重名时整批回滚
+	batch := &model.ImportBatch{Source: "bad.csv"}
+	err := repo.CreateImportBatch(batch,
+		[]model.Category{{Name: "交通", Type: model.TypeExpense}, {Name: "餐饮", Type: model.TypeExpense}},
+		nil,
+		[]model.Record{{Amount: model.NewMoney(100, ""), Type: model.TypeExpense, CategoryID: -1, Date: "2024-01-01"}},
+	)
+	expectErr(t, err, apperrors.ErrDuplicateCategory)
+	if got := latestSet(t, repo); got != start {
+		t.Fatalf("导入失败仍产生了变更集 %d", got)
+	}
+	if records, _ := repo.GetAllRecords(); len(records) != 0 {
+		t.Fatalf("导入失败仍创建了 %d 条记录", len(records))
+	}
+	if _, err := repo.GetCategoryByName("交通"); err != apperrors.ErrCategoryNotFound {
+		t.Fatalf("导入失败仍创建了分类: %v", err)
+	}
+
+	batch = &model.ImportBatch{Source: "bank.csv"}
+	categories := []model.Category{
+		{Name: "交通", Icon: "🚗", Type: model.TypeExpense},
+		{Name: "地铁", Type: model.TypeExpense, ParentID: -1},
+	}
+	tags := []model.Tag{{Name: "通勤", Color: "#123456"}}
+	records := []model.Record{
+		{Amount: model.NewMoney(300, ""), Type: model.TypeExpense, CategoryID: -2, Date: "2024-01-02",
+			Tags: []model.Tag{{ID: -1}, trip}},
+		{Amount: model.NewMoney(500, ""), Type: model.TypeExpense, CategoryID: food.ID, Date: "2024-01-03"},
+	}
+	if err := repo.CreateImportBatch(batch, categories, tags, records); err != nil {
+		t.Fatal(err)
+	}
+	if batch.ID == 0 || batch.Records != 2 || batch.Categories != 2 || batch.Tags != 1 {
+		t.Fatalf("CreateImportBatch 回填 %+v", batch)
+	}
+	if categories[1].ParentID != categories[0].ID || records[0].CategoryID != categories[1].ID ||
+		records[0].Tags[0].ID != tags[0].ID {
+		t.Fatalf("占位 ID 未转换: %+v %+v", categories, records[0])
+	}
+
+	got, err := repo.GetRecordByID(records[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ImportBatch != batch.ID || strings.Join(tagNames(got.Tags), ",") != "出差,通勤" {
+		t.Fatalf("导入的记录 %+v", got)
+	}
+	subway, err := repo.GetCategoryByName("地铁")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subway.ImportBatch != batch.ID || subway.ParentID != categories[0].ID {
+		t.Fatalf("导入的分类 %+v", subway)
+	}
+
+	sets, err := repo.ListChangeSets(start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sets) != 1 || sets[0].Action != model.ActionImport || sets[0].TargetID != batch.ID {
+		t.Fatalf("整批导入应为一个变更集，得到 %+v", sets)
+	}
+
+	batches, err := repo.ListImportBatches()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(batches) != 1 || batches[0].ID != batch.ID || batches[0].Source != "bank.csv" || batches[0].Records != 2 {
+		t.Fatalf("ListImportBatches 返回 %+v", batches)
+	}
+
+	// 导入后手动记在“交通”下的记录不受撤销影响，“交通”因此保留
+	manual := mustCreateRecord(t, repo, categories[0].ID, model.TypeExpense, 200, "2024-01-04")
+
+	_, err = repo.DeleteImportBatch(batch.ID + 100)
+	expectErr(t, err, apperrors.ErrImportBatchNotFound)
+
+	result, err := repo.DeleteImportBatch(batch.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Records != 2 || result.Categories != 1 {
+		t.Fatalf("DeleteImportBatch 返回 %+v", result)
+	}
+	remaining, err := repo.GetAllRecords()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equalIDs(recordIDs(remaining), []int64{manual.ID}) {
+		t.Fatalf("撤销导入后剩余记录 %v", recordIDs(remaining))
+	}
+	if _, err := repo.GetCategoryByName("地铁"); err != apperrors.ErrCategoryNotFound {
+		t.Fatalf("导入的分类未移入回收站: %v", err)
+	}
+	trashed, err := repo.ListDeletedRecords()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trashed) != 2 {
+		t.Fatalf("回收站中有 %d 条记录，期望 2", len(trashed))
+	}
+
+	// 撤销导入本身也可以撤销
+	undoSet := latestSet(t, repo)
+	if err := repo.RevertChangeSet(undoSet); err != nil {
+		t.Fatal(err)
+	}
+	if remaining, _ := repo.GetAllRecords(); len(remaining) != 3 {
+		t.Fatalf("恢复后有 %d 条记录，期望 3", len(remaining))
+	}
+	if got, err := repo.GetCategoryByName("地铁"); err != nil || got.ImportBatch != batch.ID {
+		t.Fatalf("恢复后的分类 %+v, %v", got, err)
+	}
+}
+
+func testStats(t *testing.T, repo repository.Repository) {
+	food := mustCreateCategory(t, repo, "餐饮", model.TypeExpense)
+	salary := mustCreateCategory(t, repo, "工资", model.TypeIncome)
+
+	// 0.1 + 0.2 在浮点下不等于 0.3，整数分必须精确
+	mustCreateRecord(t, repo, food.ID, model.TypeExpense, 10, "2024-01-05")
+	mustCreateRecord(t, repo, food.ID, model.TypeExpense, 20, "2024-01-05")
+	mustCreateRecord(t, repo, salary.ID, model.TypeIncome, 1000000, "2024-01-10")
+	mustCreateRecord(t, repo, food.ID, model.TypeExpense, 999, "2024-02-01")
+	yen := &model.Record{
+		Amount:     model.NewMoney(1500, "JPY"),
+		Type:       model.TypeExpense,
+		CategoryID: food.ID,
+		Date:       "2024-01-05",
+	}
+	if err := repo.CreateRecord(yen); err != nil {
+		t.Fatal(err)
+	}
+
+	totals, err := repo.GetStatTotals("2024-01-01", "2024-02-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []model.StatTotal{
+		{Date: "2024-01-05", Type: model.TypeExpense, CategoryID: food.ID, Amount: model.NewMoney(30, "CNY")},
+		{Date: "2024-01-05", Type: model.TypeExpense, CategoryID: food.ID, Amount: model.NewMoney(1500, "JPY")},
+		{Date: "2024-01-10", Type: model.TypeIncome, CategoryID: salary.ID, Amount: model.NewMoney(1000000, "CNY")},
+	}
+	if len(totals) != len(want) {
+		t.Fatalf("GetStatTotals 返回 %+v，期望 %+v", totals, want)
+	}
+	for i := range want {
+		if totals[i] != want[i] {
+			t.Fatalf("第 %d 项为 %+v，期望 %+v", i, totals[i], want[i])
+		}
+	}
+
+	empty, err := repo.GetStatTotals("2023-01-01", "2023-02-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(empty) != 0 {
+		t.Fatalf("无数据区间返回 %+v", empty)
+	}
+}
+
+func testExchangeRates(t *testing.T, repo repository.Repository) {
+	save := func(from, to, date, rate string) *model.ExchangeRate {
+		t.Helper()
+		r := &model.ExchangeRate{From: from, To: to, Date: date, Rate: rate}
+		if err := repo.SaveExchangeRate(r); err != nil {
+			t.Fatal(err)
+		}
+		if r.ID == 0 {
+			t.Fatal("SaveExchangeRate 未回填 ID")
+		}
+		return r
+	}
+
+	jan := save("JPY", "CNY", "2024-01-01", "0.05")
+	save("JPY", "CNY", "2024-02-01", "0.048")
+	usd := save("USD", "CNY", "2024-01-01", "7.1")
+
+	// 同一币种对同一天再次保存时覆盖原汇率
+	again := save("JPY", "CNY", "2024-01-01", "0.049")
+	if again.ID != jan.ID {
+		t.Fatalf("覆盖汇率时 ID 为 %d，期望 %d", again.ID, jan.ID)
+	}
+
+	cases := []struct {
+		date, want string
+	}{
+		{"2024-01-01", "0.049"},
+		{"2024-01-31", "0.049"},
+		{"2024-02-01", "0.048"},
+		{"2025-06-30", "0.048"},
+	}
+	for _, c := range cases {
+		r, err := repo.FindExchangeRate("JPY", "CNY", c.date)
+		if err != nil {
+			t.Fatalf("FindExchangeRate(%s): %v", c.date, err)
+		}
+		if r.Rate != c.want {
+			t.Fatalf("FindExchangeRate(%s) = %s，期望 %s", c.date, r.Rate, c.want)
+		}
+	}
+
+	_, err := repo.FindExchangeRate("JPY", "CNY", "2023-12-31")
+	expectErr(t, err, apperrors.ErrExchangeRateNotFound)
+	_, err = repo.FindExchangeRate("CNY", "JPY", "2024-01-31")
+	expectErr(t, err, apperrors.ErrExchangeRateNotFound)
+
+	rates, err := repo.ListExchangeRates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rates) != 3 || rates[0].Date != "2024-02-01" || rates[1].Date != "2024-01-01" || rates[2].ID != usd.ID {
+		t.Fatalf("ListExchangeRates 返回 %+v", rates)
+	}
+
+	if err := repo.DeleteExchangeRate(usd.ID); err != nil {
+		t.Fatal(err)
+	}
+	expectErr(t, repo.DeleteExchangeRate(usd.ID), apperrors.ErrExchangeRateNotFound)
+
+	// 批量保存时逐条回填 ID，已有的汇率同样覆盖
+	batch := []model.ExchangeRate{
+		{From: "JPY", To: "CNY", Date: "2024-01-01", Rate: "0.047"},
+		{From: "EUR", To: "CNY", Date: "2024-01-01", Rate: "7.8"},
+	}
+	if err := repo.SaveExchangeRates(batch); err != nil {
+		t.Fatal(err)
+	}
+	if batch[0].ID != jan.ID || batch[1].ID == 0 {
+		t.Fatalf("SaveExchangeRates 回填 ID 为 %d, %d，期望 %d 与新 ID", batch[0].ID, batch[1].ID, jan.ID)
+	}
+	r, err := repo.FindExchangeRate("JPY", "CNY", "2024-01-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Rate != "0.047" {
+		t.Fatalf("批量覆盖后汇率为 %s，期望 0.047", r.Rate)
+	}
+	if _, err := repo.FindExchangeRate("EUR", "CNY", "2024-01-01"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testBudgets(t *testing.T, repo repository.Repository) {
+	food := mustCreateCategory(t, repo, "餐饮", model.TypeExpense)
+
+	save := func(month string, categoryID, minor int64, recurring bool) *model.Budget {
+		t.Helper()
+		b := &model.Budget{
+			Month:      month,
+			CategoryID: categoryID,
+			Amount:     model.NewMoney(minor, model.DefaultCurrency),
+			Recurring:  recurring,
+		}
+		if err := repo.SaveBudget(b); err != nil {
+			t.Fatal(err)
+		}
+		if b.ID == 0 {
+			t.Fatal("SaveBudget 未回填 ID")
+		}
+		return b
+	}
+
+	overall := save("2024-01", 0, 500000, true)
+	save("2024-01", food.ID, 100000, false)
+	later := save("2024-03", food.ID, 120000, true)
+
+	// 同一月份同一分类再次保存时覆盖
+	again := save("2024-01", 0, 600000, false)
+	if again.ID != overall.ID {
+		t.Fatalf("覆盖预算时 ID 为 %d，期望 %d", again.ID, overall.ID)
+	}
+
+	budgets, err := repo.ListBudgets("2024-02")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(budgets) != 2 || budgets[0].CategoryID != 0 || budgets[1].CategoryID != food.ID {
+		t.Fatalf("ListBudgets(2024-02) 返回 %+v", budgets)
+	}
+	if budgets[0].Amount.Minor != 600000 || budgets[0].Recurring {
+		t.Fatalf("覆盖后的总预算为 %+v", budgets[0])
+	}
+
+	all, err := repo.ListBudgets("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 || all[2].ID != later.ID || !all[2].Recurring {
+		t.Fatalf("ListBudgets() 返回 %+v", all)
+	}
+
+	if err := repo.DeleteBudget(later.ID); err != nil {
+		t.Fatal(err)
+	}
+	expectErr(t, repo.DeleteBudget(later.ID), apperrors.ErrBudgetNotFound)
+}
+
+func mustCreateRule(t *testing.T, repo repository.Repository, categoryID int64) *model.RecurringRule {
+	t.Helper()
+	rule := &model.RecurringRule{
+		Name:       "房租",
+		Frequency:  model.FrequencyMonthly,
+		Interval:   1,
+		DayOfMonth: 31,
+		StartDate:  "2024-01-31",
+		Amount:     model.NewMoney(300000, model.DefaultCurrency),
+		Type:       model.TypeExpense,
+		CategoryID: categoryID,
+		Note:       "rent",
+		Active:     true,
+	}
+	if err := repo.CreateRecurringRule(rule); err != nil {
+		t.Fatal(err)
+	}
+	if rule.ID == 0 {
+		t.Fatal("CreateRecurringRule 未回填 ID")
+	}
+	return rule
+}
+
+func testRecurringRules(t *testing.T, repo repository.Repository) {
+	home := mustCreateCategory(t, repo, "住房", model.TypeExpense)
+	rule := mustCreateRule(t, repo, home.ID)
+
+	got, err := repo.GetRecurringRule(rule.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "房租" || got.DayOfMonth != 31 || got.Amount.Minor != 300000 || !got.Active || got.EndDate != "" {
+		t.Fatalf("GetRecurringRule 返回 %+v", got)
+	}
+
+	rule.Active = false
+	rule.SkipWeekends = true
+	rule.EndDate = "2024-12-31"
+	if err := repo.UpdateRecurringRule(rule); err != nil {
+		t.Fatal(err)
+	}
+	rules, err := repo.ListRecurringRules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 1 || rules[0].Active || !rules[0].SkipWeekends || rules[0].EndDate != "2024-12-31" {
+		t.Fatalf("ListRecurringRules 返回 %+v", rules)
+	}
+
+	if err := repo.DeleteRecurringRule(rule.ID); err != nil {
+		t.Fatal(err)
+	}
+	_, err = repo.GetRecurringRule(rule.ID)
+	expectErr(t, err, apperrors.ErrRuleNotFound)
+	expectErr(t, repo.UpdateRecurringRule(rule), apperrors.ErrRuleNotFound)
+	expectErr(t, repo.DeleteRecurringRule(rule.ID), apperrors.ErrRuleNotFound)
+}
+
+func testOccurrences(t *testing.T, repo repository.Repository) {
+	home := mustCreateCategory(t, repo, "住房", model.TypeExpense)
+	rule := mustCreateRule(t, repo, home.ID)
+
+	newRecord := func(date string) *model.Record {
+		return &model.Record{
+			Amount:     rule.Amount,
+			Type:       rule.Type,
+			CategoryID: rule.CategoryID,
+			Date:       date,
+		}
+	}
+
+	// 同一次发生只能入账一次
+	jan := &model.Occurrence{RuleID: rule.ID, Date: "2024-01-31"}
+	ok, err := repo.PostOccurrence(jan, newRecord("2024-01-31"))
+	if err != nil || !ok {
+		t.Fatalf("PostOccurrence = %v, %v", ok, err)
+	}
+	if jan.Status != model.OccurrencePosted || jan.RecordID == 0 {
+		t.Fatalf("入账后的发生为 %+v", jan)
+	}
+	ok, err = repo.PostOccurrence(&model.Occurrence{RuleID: rule.ID, Date: "2024-01-31"}, newRecord("2024-01-31"))
+	if err != nil || ok {
+		t.Fatalf("重复 PostOccurrence = %v, %v", ok, err)
+	}
+	expectErr(t, repo.SaveOccurrence(&model.Occurrence{
+		RuleID: rule.ID, Date: "2024-01-31", Status: model.OccurrenceSkipped,
+	}), apperrors.ErrOccurrencePosted)
+
+	// 跳过的发生不会入账
+	if err := repo.SaveOccurrence(&model.Occurrence{
+		RuleID: rule.ID, Date: "2024-02-29", Status: model.OccurrenceSkipped,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	ok, err = repo.PostOccurrence(&model.Occurrence{RuleID: rule.ID, Date: "2024-02-29"}, newRecord("2024-02-29"))
+	if err != nil || ok {
+		t.Fatalf("已跳过的 PostOccurrence = %v, %v", ok, err)
+	}
+
+	// 修改过的发生入账后保留修改内容
+	modified := model.NewMoney(310000, model.DefaultCurrency)
+	march := &model.Occurrence{RuleID: rule.ID, Date: "2024-03-31", Status: model.OccurrenceModified, Amount: &modified, Note: "涨租"}
+	if err := repo.SaveOccurrence(march); err != nil {
+		t.Fatal(err)
+	}
+	rec := newRecord("2024-03-31")
+	rec.Amount = modified
+	ok, err = repo.PostOccurrence(march, rec)
+	if err != nil || !ok {
+		t.Fatalf("PostOccurrence(modified) = %v, %v", ok, err)
+	}
+
+	occurrences, err := repo.ListOccurrences(rule.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(occurrences) != 3 {
+		t.Fatalf("ListOccurrences 返回 %+v", occurrences)
+	}
+	if occurrences[1].Status != model.OccurrenceSkipped {
+		t.Fatalf("2 月的发生为 %+v", occurrences[1])
+	}
+	last := occurrences[2]
+	if last.Status != model.OccurrencePosted || last.RecordID != rec.ID || last.Amount == nil || last.Amount.Minor != 310000 || last.Note != "涨租" {
+		t.Fatalf("3 月的发生为 %+v", last)
+	}
+
+	records, err := repo.GetAllRecords()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("生成了 %d 条记录，期望 2", len(records))
+	}
+
+	// 删除规则后生成的记录保留
+	if err := repo.DeleteRecurringRule(rule.ID); err != nil {
+		t.Fatal(err)
+	}
+	occurrences, err = repo.ListOccurrences(rule.ID)
+	if err != nil || len(occurrences) != 0 {
+		t.Fatalf("删除规则后 ListOccurrences 返回 %+v, %v", occurrences, err)
+	}
+	if _, err := repo.GetRecordByID(rec.ID); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testSettings(t *testing.T, repo repository.Repository) {
+	v, err := repo.GetSetting("missing")
+	if err != nil || v != "" {
+		t.Fatalf("GetSetting(missing) = %q, %v", v, err)
+	}
+
+	if err := repo.SetSetting("k", "1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.SetSetting("k", "2"); err != nil {
+		t.Fatal(err)
+	}
+	v, err = repo.GetSetting("k")
+	if err != nil || v != "2" {
+		t.Fatalf("GetSetting(k) = %q, %v", v, err)
+	}
+}
+
+func testCSVProfiles(t *testing.T, repo repository.Repository) {
+	icbc := &model.CSVProfile{Name: "工商银行", Mapping: model.CSVMapping{
+		Encoding:       model.EncodingGBK,
+		HasHeader:      true,
+		Date:           model.CSVColumn{Header: "交易日期"},
+		Amount:         model.CSVColumn{Index: 3},
+		Notes:          []model.CSVColumn{{Header: "摘要"}, {Header: "对方户名"}},
+		DateLayout:     "YYYYMMDD",
+		SignConvention: model.SignNegativeExpense,
+	}}
+	if err := repo.CreateCSVProfile(icbc); err != nil {
+		t.Fatal(err)
+	}
+	cmb := &model.CSVProfile{Name: "招商银行", Mapping: model.CSVMapping{SignConvention: model.SignSplitColumns}}
+	if err := repo.CreateCSVProfile(cmb); err != nil {
+		t.Fatal(err)
+	}
+	if icbc.ID == 0 || cmb.ID == 0 || icbc.ID == cmb.ID {
+		t.Fatalf("CreateCSVProfile 回填 ID %d, %d", icbc.ID, cmb.ID)
+	}
+	expectErr(t, repo.CreateCSVProfile(&model.CSVProfile{Name: "工商银行"}), apperrors.ErrDuplicateProfile)
+
+	// 修改调用方的映射不影响已保存的方案
+	icbc.Mapping.Notes[0].Header = "用途"
+	got, err := repo.GetCSVProfile(icbc.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "工商银行" || got.Mapping.Encoding != model.EncodingGBK || len(got.Mapping.Notes) != 2 ||
+		got.Mapping.Notes[0].Header != "摘要" || got.Mapping.Amount.Index != 3 {
+		t.Fatalf("GetCSVProfile 返回 %+v", got)
+	}
+
+	cmb.Name = "工商银行"
+	expectErr(t, repo.UpdateCSVProfile(cmb), apperrors.ErrDuplicateProfile)
+	cmb.Name = "招行信用卡"
+	cmb.Mapping.SignConvention = model.SignNegativeIncome
+	if err := repo.UpdateCSVProfile(cmb); err != nil {
+		t.Fatal(err)
+	}
+	expectErr(t, repo.UpdateCSVProfile(&model.CSVProfile{ID: 9999, Name: "x"}), apperrors.ErrProfileNotFound)
+
+	profiles, err := repo.ListCSVProfiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profiles) != 2 || profiles[0].ID != icbc.ID || profiles[1].Name != "招行信用卡" ||
+		profiles[1].Mapping.SignConvention != model.SignNegativeIncome {
+		t.Fatalf("ListCSVProfiles 返回 %+v", profiles)
+	}
+
+	if err := repo.DeleteCSVProfile(icbc.ID); err != nil {
+		t.Fatal(err)
+	}
+	expectErr(t, repo.DeleteCSVProfile(icbc.ID), apperrors.ErrProfileNotFound)
+	_, err = repo.GetCSVProfile(icbc.ID)
+	expectErr(t, err, apperrors.ErrProfileNotFound)
+}
+
+func testAccounts(t *testing.T, repo repository.Repository) {
+	cash := mustCreateAccount(t, repo, "现金", 100)
+	bank := mustCreateAccount(t, repo, "银行卡", 0)
+
+	expectErr(t, repo.CreateAccount(&model.Account{Name: "现金", Type: model.AccountCash}), apperrors.ErrDuplicateAccount)
+
+	bank.Name = "现金"
+	expectErr(t, repo.UpdateAccount(bank), apperrors.ErrDuplicateAccount)
+	bank.Name = "工资卡"
+	bank.OpeningBalance = model.NewMoney(500, model.DefaultCurrency)
+	if err := repo.UpdateAccount(bank); err != nil {
+		t.Fatal(err)
+	}
+	got, err := repo.GetAccountByID(bank.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "工资卡" || got.OpeningBalance.Minor != 500 {
+		t.Fatalf("UpdateAccount 后为 %+v", got)
+	}
+
+	accounts, err := repo.ListAccounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts) != 2 || accounts[0].ID != cash.ID || accounts[1].ID != bank.ID {
+		t.Fatalf("ListAccounts 返回 %+v", accounts)
+	}
+
+	// 被转账的转入方引用的账户同样不能删除
+	transfer := &model.Record{
+		Amount:      model.NewMoney(10, model.DefaultCurrency),
+		Type:        model.TypeTransfer,
+		AccountID:   cash.ID,
+		ToAccountID: bank.ID,
+		Date:        "2024-01-01",
+	}
+	if err := repo.CreateRecord(transfer); err != nil {
+		t.Fatal(err)
+	}
+	expectErr(t, repo.DeleteAccount(cash.ID), apperrors.ErrAccountInUse)
+	expectErr(t, repo.DeleteAccount(bank.ID), apperrors.ErrAccountInUse)
+
+	// 回收站中的记录仍可恢复，彻底删除后账户才能删除
+	if err := repo.DeleteRecord(transfer.ID); err != nil {
+		t.Fatal(err)
+	}
+	expectErr(t, repo.DeleteAccount(cash.ID), apperrors.ErrAccountInUse)
+	// 有记录引用的账户不能修改币种
+	bank.OpeningBalance = model.NewMoney(500, "USD")
+	expectErr(t, repo.UpdateAccount(bank), apperrors.ErrCurrencyMismatch)
+	if err := repo.PurgeRecord(transfer.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.UpdateAccount(bank); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.DeleteAccount(cash.ID); err != nil {
+		t.Fatal(err)
+	}
+	expectErr(t, repo.DeleteAccount(cash.ID), apperrors.ErrAccountNotFound)
+	_, err = repo.GetAccountByID(cash.ID)
+	expectErr(t, err, apperrors.ErrAccountNotFound)
+	expectErr(t, repo.UpdateAccount(&model.Account{ID: cash.ID, Name: "x"}), apperrors.ErrAccountNotFound)
+}
+
+func testAccountBalances(t *testing.T, repo repository.Repository) {
+	food := mustCreateCategory(t, repo, "餐饮", model.TypeExpense)
+	salary := mustCreateCategory(t, repo, "工资", model.TypeIncome)
+	cash := mustCreateAccount(t, repo, "现金", 1000)
+	bank := mustCreateAccount(t, repo, "银行卡", 0)
+
+	create := func(rec *model.Record) {
+		t.Helper()
+		if err := repo.CreateRecord(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	money := func(minor int64) model.Money { return model.NewMoney(minor, model.DefaultCurrency) }
+
+	create(&model.Record{Amount: money(300), Type: model.TypeExpense, CategoryID: food.ID, AccountID: cash.ID, Date: "2024-01-02"})
+	create(&model.Record{Amount: money(5000), Type: model.TypeIncome, CategoryID: salary.ID, AccountID: bank.ID, Date: "2024-01-05"})
+	create(&model.Record{Amount: money(2000), Type: model.TypeTransfer, AccountID: bank.ID, ToAccountID: cash.ID, Date: "2024-01-10"})
+	// 未关联账户的记录不影响任何账户余额
+	create(&model.Record{Amount: money(77), Type: model.TypeExpense, CategoryID: food.ID, Date: "2024-01-03"})
+
+	balanceOf := func(endDate string) map[int64]int64 {
+		t.Helper()
+		balances, err := repo.GetAccountBalances(endDate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := make(map[int64]int64)
+		for _, b := range balances {
+			m[b.Account.ID] = b.Balance.Minor
+		}
+		return m
+	}
+
+	if b := balanceOf("2024-01-10"); b[cash.ID] != 700 || b[bank.ID] != 5000 {
+		t.Fatalf("转账前余额 %v，期望现金 700、银行卡 5000", b)
+	}
+	if b := balanceOf("2024-01-11"); b[cash.ID] != 2700 || b[bank.ID] != 3000 {
+		t.Fatalf("转账后余额 %v，期望现金 2700、银行卡 3000", b)
+	}
+
+	// 转账不是收支，不计入统计
+	totals, err := repo.GetStatTotals("2024-01-01", "2024-02-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, total := range totals {
+		if total.Type == model.TypeTransfer {
+			t.Fatalf("GetStatTotals 包含转账 %+v", total)
+		}
+	}
+	if len(totals) != 3 {
+		t.Fatalf("GetStatTotals 返回 %+v", totals)
+	}
+
+	rec, err := repo.GetAllRecords()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec[0].Type != model.TypeTransfer || rec[0].Category != nil || rec[0].ToAccountID != cash.ID {
+		t.Fatalf("转账记录读取为 %+v", rec[0])
+	}
+}
@@ -2,23 +2,37 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
+	"strings"
 	"time"
 
+	apperrors "dog-view/internal/errors"
 	"dog-view/internal/model"
 
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
 )
 
+// DefaultDBFile 默认账本的数据库文件名
+const DefaultDBFile = "data.db"
+
+// timestampLayout 与 CURRENT_TIMESTAMP 写入的文本格式一致（UTC），
+// 由 Go 写入的时间统一使用该格式，保证按文本比较时间的结果正确
+const timestampLayout = "2006-01-02 15:04:05"
+
 type SQLiteRepository struct {
-	db *sql.DB
+	db    *sql.DB
+	path  string
+	fts   bool       // 当前构建是否包含 FTS5，不包含时搜索退回 LIKE
+	vault *vaultFile // 加密账本的磁盘文件，未加密时为 nil
 }
 
-// getDBPath 获取数据库文件路径
-func getDBPath() (string, error) {
+// DataDir 获取应用数据目录，不存在时自动创建
+func DataDir() (string, error) {
 	var baseDir string
 	switch runtime.GOOS {
 	case "darwin":
@@ -35,7 +49,16 @@ func getDBPath() (string, error) {
 		return "", err
 	}
 
-	return filepath.Join(baseDir, "data.db"), nil
+	return baseDir, nil
+}
+
+// getDBPath 获取默认数据库文件路径
+func getDBPath() (string, error) {
+	baseDir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(baseDir, DefaultDBFile), nil
 }
 
 // NewSQLiteRepository 创建 SQLite 仓库
@@ -44,49 +67,34 @@ func NewSQLiteRepository() (*SQLiteRepository, error) {
 	if err != nil {
 		return nil, fmt.Errorf("获取数据库路径失败: %w", err)
 	}
+	return OpenSQLiteRepository(dbPath)
+}
 
+// OpenSQLiteRepository 打开指定路径的数据库并执行迁移
+func OpenSQLiteRepository(dbPath string) (*SQLiteRepository, error) {
 	db, err := sql.Open("sqlite3", dbPath)
 	if err != nil {
 		return nil, fmt.Errorf("打开数据库失败: %w", err)
 	}
+	// 绑定方法会被并发调用，多个连接同时写库时 SQLite 直接返回 database is locked，
+	// 因此与加密账本一样只用一个连接，串行化全部读写
+	db.SetMaxOpenConns(1)
 
-	repo := &SQLiteRepository{db: db}
+	repo := &SQLiteRepository{db: db, path: dbPath}
 	if err := repo.InitSchema(); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("初始化数据库表失败: %w", err)
 	}
 
 	return repo, nil
 }
 
-// InitSchema 初始化数据库表
+// InitSchema 执行数据库迁移并初始化默认数据
 func (r *SQLiteRepository) InitSchema() error {
-	schema := `
-	CREATE TABLE IF NOT EXISTS categories (
-		id          INTEGER PRIMARY KEY AUTOINCREMENT,
-		name        TEXT NOT NULL UNIQUE,
-		icon        TEXT,
-		type        TEXT NOT NULL,
-		sort_order  INTEGER DEFAULT 0,
-		created_at  DATETIME DEFAULT CURRENT_TIMESTAMP
-	);
-
-	CREATE TABLE IF NOT EXISTS records (
-		id          INTEGER PRIMARY KEY AUTOINCREMENT,
-		amount      DECIMAL(10,2) NOT NULL,
-		type        TEXT NOT NULL,
-		category_id INTEGER NOT NULL,
-		note        TEXT,
-		date        DATE NOT NULL,
-		created_at  DATETIME DEFAULT CURRENT_TIMESTAMP,
-		FOREIGN KEY (category_id) REFERENCES categories(id)
-	);
-
-	CREATE INDEX IF NOT EXISTS idx_records_date ON records(date);
-	CREATE INDEX IF NOT EXISTS idx_records_category ON records(category_id);
-	`
-
-	_, err := r.db.Exec(schema)
-	if err != nil {
+	if err := r.migrate(); err != nil {
+		return err
+	}
+	if err := r.initSearchIndex(); err != nil {
 		return err
 	}
 
@@ -131,24 +139,42 @@ func (r *SQLiteRepository) initDefaultCategories() error {
 	return nil
 }
 
-// Close 关闭数据库连接
+// Path 数据库文件路径
+func (r *SQLiteRepository) Path() string {
+	return r.path
+}
+
+// Close 关闭数据库连接，加密账本在关闭前写回磁盘
 func (r *SQLiteRepository) Close() error {
-	return r.db.Close()
+	var err error
+	if r.vault != nil {
+		err = r.closeVault()
+	}
+	if cerr := r.db.Close(); err == nil {
+		err = cerr
+	}
+	return err
 }
 
 // ============ Category 操作 ============
 
-// ListCategories 获取分类列表
-func (r *SQLiteRepository) ListCategories(recordType string) ([]model.Category, error) {
-	query := "SELECT id, name, icon, type, sort_order, created_at FROM categories"
-	args := []interface{}{}
+// categoryColumns 分类查询的公共字段，与 scanCategory 对应
+const categoryColumns = "SELECT id, name, icon, type, parent_id, sort_order, archived, import_batch, created_at, deleted_at"
 
-	if recordType != "" {
-		query += " WHERE type = ?"
-		args = append(args, recordType)
-	}
-	query += " ORDER BY sort_order ASC"
+func scanCategory(row rowScanner) (model.Category, error) {
+	var c model.Category
+	var icon sql.NullString
+	var deletedAt sql.NullTime
+	err := row.Scan(&c.ID, &c.Name, &icon, &c.Type, &c.ParentID, &c.SortOrder, &c.Archived, &c.ImportBatch, &c.CreatedAt, &deletedAt)
+	c.Icon = icon.String
+	if deletedAt.Valid {
+		c.DeletedAt = &deletedAt.Time
+	}
+	return c, err
+}
 
+// queryCategories 执行查询并扫描分类列表
+func (r *SQLiteRepository) queryCategories(query string, args ...interface{}) ([]model.Category, error) {
 	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
@@ -157,150 +183,371 @@ func (r *SQLiteRepository) ListCategories(recordType string) ([]model.Category,
 
 	var categories []model.Category
 	for rows.Next() {
-		var c model.Category
-		err := rows.Scan(&c.ID, &c.Name, &c.Icon, &c.Type, &c.SortOrder, &c.CreatedAt)
+		c, err := scanCategory(rows)
 		if err != nil {
 			return nil, err
 		}
 		categories = append(categories, c)
 	}
+	return categories, rows.Err()
+}
+
+// ListCategories 获取分类列表，按树的先序排列
+func (r *SQLiteRepository) ListCategories(recordType string) ([]model.Category, error) {
+	query := categoryColumns + " FROM active_categories"
+	args := []interface{}{}
+
+	if recordType != "" {
+		query += " WHERE type = ?"
+		args = append(args, recordType)
+	}
+	query += " ORDER BY sort_order ASC, id ASC"
+
+	categories, err := r.queryCategories(query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return treeOrder(categories), nil
+}
+
+// treeOrder 将已按排序值排好的分类整理为先序：每个一级分类后紧跟其子分类，
+// 上级分类不在列表中的子分类按一级分类处理
+func treeOrder(categories []model.Category) []model.Category {
+	present := make(map[int64]bool, len(categories))
+	for _, c := range categories {
+		present[c.ID] = true
+	}
+
+	children := make(map[int64][]model.Category)
+	var roots []model.Category
+	for _, c := range categories {
+		if c.ParentID != 0 && present[c.ParentID] {
+			children[c.ParentID] = append(children[c.ParentID], c)
+		} else {
+			roots = append(roots, c)
+		}
+	}
+
+	ordered := make([]model.Category, 0, len(categories))
+	for _, c := range roots {
+		ordered = append(ordered, c)
+		ordered = append(ordered, children[c.ID]...)
+	}
+	return ordered
+}
 
-	return categories, nil
+// isUniqueViolation 判断是否为唯一约束冲突
+func isUniqueViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique
+}
+
+// requireAffected 更新或删除没有命中任何行时返回 notFound
+func requireAffected(result sql.Result, notFound error) error {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return notFound
+	}
+	return nil
 }
 
 // CreateCategory 创建分类
 func (r *SQLiteRepository) CreateCategory(c *model.Category) error {
-	result, err := r.db.Exec(
-		"INSERT INTO categories (name, icon, type, sort_order) VALUES (?, ?, ?, ?)",
-		c.Name, c.Icon, c.Type, c.SortOrder,
-	)
+	ch, err := r.beginChange(model.ActionCreateCategory)
 	if err != nil {
 		return err
 	}
+	defer ch.rollback()
 
-	id, err := result.LastInsertId()
+	id, err := insertCategory(ch.tx, c)
 	if err != nil {
 		return err
 	}
+	ch.created(model.EntityCategory, id)
+	if err := ch.commit(); err != nil {
+		return err
+	}
 	c.ID = id
 	return nil
 }
 
+// insertCategory 插入分类并返回 ID，名称重复时返回 ErrDuplicateCategory
+func insertCategory(db execer, c *model.Category) (int64, error) {
+	result, err := db.Exec(
+		"INSERT INTO categories (name, icon, type, parent_id, sort_order, archived, import_batch) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		c.Name, c.Icon, c.Type, c.ParentID, c.SortOrder, c.Archived, c.ImportBatch,
+	)
+	if isUniqueViolation(err) {
+		return 0, apperrors.ErrDuplicateCategory
+	}
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// updateCategory 在事务中更新一个未删除的分类并记录变更
+func (r *SQLiteRepository) updateCategory(action string, id int64, query string, args ...interface{}) error {
+	ch, err := r.beginChange(action)
+	if err != nil {
+		return err
+	}
+	defer ch.rollback()
+
+	if err := ch.track(model.EntityCategory, id); err != nil {
+		return err
+	}
+	result, err := ch.tx.Exec(query, args...)
+	if isUniqueViolation(err) {
+		return apperrors.ErrDuplicateCategory
+	}
+	if err != nil {
+		return err
+	}
+	if err := requireAffected(result, apperrors.ErrCategoryNotFound); err != nil {
+		return err
+	}
+	return ch.commit()
+}
+
 // UpdateCategory 更新分类
 func (r *SQLiteRepository) UpdateCategory(c *model.Category) error {
-	_, err := r.db.Exec(
-		"UPDATE categories SET name = ?, icon = ? WHERE id = ?",
+	return r.updateCategory(model.ActionUpdateCategory, c.ID,
+		"UPDATE categories SET name = ?, icon = ? WHERE id = ? AND deleted_at IS NULL",
 		c.Name, c.Icon, c.ID,
 	)
-	return err
 }
 
-// DeleteCategory 删除分类
+// SetCategoryArchived 归档或取消归档分类
+func (r *SQLiteRepository) SetCategoryArchived(id int64, archived bool) error {
+	return r.updateCategory(model.ActionArchiveCategory, id,
+		"UPDATE categories SET archived = ? WHERE id = ? AND deleted_at IS NULL", archived, id,
+	)
+}
+
+// MoveCategory 修改分类的上级分类和排序值
+func (r *SQLiteRepository) MoveCategory(id, parentID int64, sortOrder int) error {
+	return r.updateCategory(model.ActionMoveCategory, id,
+		"UPDATE categories SET parent_id = ?, sort_order = ? WHERE id = ? AND deleted_at IS NULL",
+		parentID, sortOrder, id,
+	)
+}
+
+// DeleteCategory 将分类移入回收站
 func (r *SQLiteRepository) DeleteCategory(id int64) error {
-	// 检查是否有记录使用此分类
+	ch, err := r.beginChange(model.ActionDeleteCategory)
+	if err != nil {
+		return err
+	}
+	defer ch.rollback()
+
+	// 检查是否有记录使用此分类，回收站中的记录不算；与删除在同一事务中，避免检查后新增的记录引用已删除的分类
 	var count int
-	err := r.db.QueryRow("SELECT COUNT(*) FROM records WHERE category_id = ?", id).Scan(&count)
+	err = ch.tx.QueryRow("SELECT COUNT(*) FROM active_records WHERE category_id = ?", id).Scan(&count)
 	if err != nil {
 		return err
 	}
 	if count > 0 {
-		return fmt.Errorf("分类正在使用中，无法删除")
+		return apperrors.ErrCategoryInUse
 	}
 
-	_, err = r.db.Exec("DELETE FROM categories WHERE id = ?", id)
-	return err
-}
+	err = ch.tx.QueryRow("SELECT COUNT(*) FROM active_categories WHERE parent_id = ?", id).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return apperrors.ErrCategoryHasChildren
+	}
 
-// UpdateCategoryOrder 更新分类排序
-func (r *SQLiteRepository) UpdateCategoryOrder(ids []int64) error {
-	tx, err := r.db.Begin()
+	if err := ch.track(model.EntityCategory, id); err != nil {
+		return err
+	}
+	result, err := ch.tx.Exec("UPDATE categories SET deleted_at = CURRENT_TIMESTAMP WHERE id = ? AND deleted_at IS NULL", id)
 	if err != nil {
 		return err
 	}
-	defer tx.Rollback()
+	if err := requireAffected(result, apperrors.ErrCategoryNotFound); err != nil {
+		return err
+	}
+	return ch.commit()
+}
 
-	for i, id := range ids {
-		_, err := tx.Exec("UPDATE categories SET sort_order = ? WHERE id = ?", i+1, id)
-		if err != nil {
-			return err
+// MergeCategories 合并分类，所有改动在同一事务中完成
+func (r *SQLiteRepository) MergeCategories(sourceIDs []int64, targetID int64) (int, error) {
+	ch, err := r.beginChange(model.ActionMergeCategories)
+	if err != nil {
+		return 0, err
+	}
+	defer ch.rollback()
+	tx := ch.tx
+
+	var exists int
+	err = tx.QueryRow("SELECT COUNT(*) FROM active_categories WHERE id = ?", targetID).Scan(&exists)
+	if err != nil {
+		return 0, err
+	}
+	if exists == 0 {
+		return 0, apperrors.ErrCategoryNotFound
+	}
+
+	// 受影响的记录与分类（包括回收站中的）、预算和周期规则都记入变更日志
+	if err := ch.track(model.EntityCategory, targetID); err != nil {
+		return 0, err
+	}
+	for _, id := range sourceIDs {
+		if err := ch.track(model.EntityCategory, id); err != nil {
+			return 0, err
+		}
+		if err := ch.trackQuery(model.EntityCategory, "SELECT id FROM categories WHERE parent_id = ?", id); err != nil {
+			return 0, err
+		}
+		if err := ch.trackQuery(model.EntityRecord, "SELECT id FROM records WHERE category_id = ?", id); err != nil {
+			return 0, err
+		}
+		if err := ch.trackQuery(model.EntityBudget, "SELECT id FROM budgets WHERE category_id = ?", id); err != nil {
+			return 0, err
+		}
+		if err := ch.trackQuery(model.EntityRule, "SELECT id FROM recurring_rules WHERE category_id = ?", id); err != nil {
+			return 0, err
 		}
 	}
 
-	return tx.Commit()
-}
+	moved := 0
+	for _, id := range sourceIDs {
+		result, err := tx.Exec("UPDATE records SET category_id = ? WHERE category_id = ?", targetID, id)
+		if err != nil {
+			return 0, err
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		moved += int(n)
+
+		// 目标分类当月已有预算的行被 OR IGNORE 跳过，随后删除
+		for _, stmt := range []string{
+			"UPDATE recurring_rules SET category_id = ? WHERE category_id = ?",
+			"UPDATE OR IGNORE budgets SET category_id = ? WHERE category_id = ?",
+			"UPDATE categories SET parent_id = ? WHERE parent_id = ?",
+		} {
+			if _, err := tx.Exec(stmt, targetID, id); err != nil {
+				return 0, err
+			}
+		}
+		if _, err := tx.Exec("DELETE FROM budgets WHERE category_id = ?", id); err != nil {
+			return 0, err
+		}
 
-// ============ Record 操作 ============
+		result, err = tx.Exec("DELETE FROM categories WHERE id = ? AND deleted_at IS NULL", id)
+		if err != nil {
+			return 0, err
+		}
+		if err := requireAffected(result, apperrors.ErrCategoryNotFound); err != nil {
+			return 0, err
+		}
+	}
 
-// CreateRecord 创建记录
-func (r *SQLiteRepository) CreateRecord(rec *model.Record) error {
-	result, err := r.db.Exec(
-		"INSERT INTO records (amount, type, category_id, note, date) VALUES (?, ?, ?, ?, ?)",
-		rec.Amount, rec.Type, rec.CategoryID, rec.Note, rec.Date,
-	)
+	// 目标分类原本挂在源分类下时，上一步会把它的上级改成自己
+	_, err = tx.Exec("UPDATE categories SET parent_id = 0 WHERE id = ? AND parent_id = ?", targetID, targetID)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	id, err := result.LastInsertId()
+	return moved, ch.commit()
+}
+
+// UpdateCategoryOrder 更新同一上级分类下的分类排序
+func (r *SQLiteRepository) UpdateCategoryOrder(parentID int64, ids []int64) error {
+	ch, err := r.beginChange(model.ActionReorderCategories)
 	if err != nil {
 		return err
 	}
-	rec.ID = id
-	return nil
-}
+	defer ch.rollback()
 
-// UpdateRecord 更新记录
-func (r *SQLiteRepository) UpdateRecord(rec *model.Record) error {
-	_, err := r.db.Exec(
-		"UPDATE records SET amount = ?, category_id = ?, note = ?, date = ? WHERE id = ?",
-		rec.Amount, rec.CategoryID, rec.Note, rec.Date, rec.ID,
-	)
-	return err
-}
+	for i, id := range ids {
+		if err := ch.track(model.EntityCategory, id); err != nil {
+			return err
+		}
+		result, err := ch.tx.Exec(
+			"UPDATE categories SET sort_order = ? WHERE id = ? AND parent_id = ? AND deleted_at IS NULL",
+			i+1, id, parentID,
+		)
+		if err != nil {
+			return err
+		}
+		if err := requireAffected(result, apperrors.ErrInvalidParent); err != nil {
+			return err
+		}
+	}
 
-// DeleteRecord 删除记录
-func (r *SQLiteRepository) DeleteRecord(id int64) error {
-	_, err := r.db.Exec("DELETE FROM records WHERE id = ?", id)
-	return err
+	return ch.commit()
 }
 
-// GetRecordByID 根据 ID 获取记录
-func (r *SQLiteRepository) GetRecordByID(id int64) (*model.Record, error) {
-	row := r.db.QueryRow(`
-		SELECT r.id, r.amount, r.type, r.category_id, r.note, r.date, r.created_at,
-		       c.id, c.name, c.icon, c.type
-		FROM records r
-		LEFT JOIN categories c ON r.category_id = c.id
-		WHERE r.id = ?
-	`, id)
+// ============ Record 操作 ============
 
+// recordColumns 记录查询的公共字段，与 scanRecord 对应
+const recordColumns = `
+	SELECT r.id, r.amount, r.currency, r.type, r.category_id, r.account_id, r.to_account_id,
+	       r.note, r.date, r.import_batch, r.created_at, r.deleted_at,
+	       c.id, c.name, c.icon, c.type,
+	       (SELECT GROUP_CONCAT(rt.tag_id) FROM record_tags rt WHERE rt.record_id = r.id)`
+
+// recordSelect 未删除记录的查询；分类关联原表，回收站中的分类也能带出名称
+const recordSelect = recordColumns + `
+	FROM active_records r
+	LEFT JOIN categories c ON r.category_id = c.id`
+
+// rowScanner 兼容 *sql.Row 与 *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanRecord 扫描一行记录及其分类，转账等没有分类的记录 Category 为 nil；
+// 标签只扫描出 ID，名称由 fillTags 补全
+func scanRecord(row rowScanner) (model.Record, error) {
 	var rec model.Record
-	var cat model.Category
+	var catID sql.NullInt64
+	var catName, catIcon, catType, tagIDs sql.NullString
+	var deletedAt sql.NullTime
 	err := row.Scan(
-		&rec.ID, &rec.Amount, &rec.Type, &rec.CategoryID, &rec.Note, &rec.Date, &rec.CreatedAt,
-		&cat.ID, &cat.Name, &cat.Icon, &cat.Type,
+		&rec.ID, &rec.Amount.Minor, &rec.Amount.Currency, &rec.Type, &rec.CategoryID, &rec.AccountID, &rec.ToAccountID,
+		&rec.Note, &rec.Date, &rec.ImportBatch, &rec.CreatedAt, &deletedAt,
+		&catID, &catName, &catIcon, &catType, &tagIDs,
 	)
 	if err != nil {
-		return nil, err
+		return rec, err
+	}
+	if deletedAt.Valid {
+		rec.DeletedAt = &deletedAt.Time
+	}
+	rec.Tags = []model.Tag{}
+	if tagIDs.Valid {
+		for _, s := range strings.Split(tagIDs.String, ",") {
+			id, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				return rec, err
+			}
+			rec.Tags = append(rec.Tags, model.Tag{ID: id})
+		}
 	}
-
-	rec.Category = &cat
-	return &rec, nil
+	if catID.Valid {
+		rec.Category = &model.Category{
+			ID:   catID.Int64,
+			Name: catName.String,
+			Icon: catIcon.String,
+			Type: catType.String,
+		}
+	}
+	return rec, nil
 }
 
-// ListRecordsByMonth 获取月度记录
-func (r *SQLiteRepository) ListRecordsByMonth(year, month int) ([]model.Record, error) {
-	startDate := fmt.Sprintf("%04d-%02d-01", year, month)
-	endDate := fmt.Sprintf("%04d-%02d-31", year, month)
-
-	rows, err := r.db.Query(`
-		SELECT r.id, r.amount, r.type, r.category_id, r.note, r.date, r.created_at,
-		       c.id, c.name, c.icon, c.type
-		FROM records r
-		LEFT JOIN categories c ON r.category_id = c.id
-		WHERE r.date >= ? AND r.date <= ?
-		ORDER BY r.date DESC, r.created_at DESC
-	`, startDate, endDate)
+// queryRecords 执行查询并扫描记录列表
+func (r *SQLiteRepository) queryRecords(query string, args ...interface{}) ([]model.Record, error) {
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -308,202 +555,255 @@ func (r *SQLiteRepository) ListRecordsByMonth(year, month int) ([]model.Record,
 
 	var records []model.Record
 	for rows.Next() {
-		var rec model.Record
-		var cat model.Category
-		err := rows.Scan(
-			&rec.ID, &rec.Amount, &rec.Type, &rec.CategoryID, &rec.Note, &rec.Date, &rec.CreatedAt,
-			&cat.ID, &cat.Name, &cat.Icon, &cat.Type,
-		)
+		rec, err := scanRecord(rows)
 		if err != nil {
 			return nil, err
 		}
-		rec.Category = &cat
 		records = append(records, rec)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
-	return records, nil
+	return records, r.fillTags(records)
 }
 
-// ============ 统计查询 ============
-
-// GetMonthSummary 获取月度汇总
-func (r *SQLiteRepository) GetMonthSummary(year, month int) (*model.MonthSummary, error) {
-	startDate := fmt.Sprintf("%04d-%02d-01", year, month)
-	endDate := fmt.Sprintf("%04d-%02d-31", year, month)
-
-	var summary model.MonthSummary
-
-	// 计算收入
-	err := r.db.QueryRow(`
-		SELECT COALESCE(SUM(amount), 0) FROM records
-		WHERE type = 'income' AND date >= ? AND date <= ?
-	`, startDate, endDate).Scan(&summary.TotalIncome)
+// CreateRecord 创建记录
+func (r *SQLiteRepository) CreateRecord(rec *model.Record) error {
+	ch, err := r.beginChange(model.ActionCreateRecord)
 	if err != nil {
-		return nil, err
+		return err
 	}
+	defer ch.rollback()
 
-	// 计算支出
-	err = r.db.QueryRow(`
-		SELECT COALESCE(SUM(amount), 0) FROM records
-		WHERE type = 'expense' AND date >= ? AND date <= ?
-	`, startDate, endDate).Scan(&summary.TotalExpense)
-	if err != nil {
-		return nil, err
+	if err := insertRecord(ch.tx, rec); err != nil {
+		return err
 	}
-
-	summary.Balance = summary.TotalIncome - summary.TotalExpense
-	return &summary, nil
+	ch.created(model.EntityRecord, rec.ID)
+	return ch.commit()
 }
 
-// GetCategoryStats 获取分类统计
-func (r *SQLiteRepository) GetCategoryStats(year, month int, recordType string) ([]model.CategoryStat, error) {
-	startDate := fmt.Sprintf("%04d-%02d-01", year, month)
-	endDate := fmt.Sprintf("%04d-%02d-31", year, month)
+// execer 兼容 *sql.DB 与 *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
 
-	// 先获取总金额
-	var total float64
-	err := r.db.QueryRow(`
-		SELECT COALESCE(SUM(amount), 0) FROM records
-		WHERE type = ? AND date >= ? AND date <= ?
-	`, recordType, startDate, endDate).Scan(&total)
+// insertRecord 插入记录及其标签关联并回填 ID，需在事务中调用
+func insertRecord(db execer, rec *model.Record) error {
+	result, err := db.Exec(
+		`INSERT INTO records (amount, currency, type, category_id, account_id, to_account_id, note, date, import_batch)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		rec.Amount.Minor, rec.Amount.Currency, rec.Type, rec.CategoryID, rec.AccountID, rec.ToAccountID, rec.Note, rec.Date,
+		rec.ImportBatch,
+	)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	rows, err := r.db.Query(`
-		SELECT c.id, c.name, c.icon, COALESCE(SUM(r.amount), 0) as amount
-		FROM categories c
-		LEFT JOIN records r ON c.id = r.category_id
-			AND r.date >= ? AND r.date <= ?
-		WHERE c.type = ?
-		GROUP BY c.id
-		HAVING amount > 0
-		ORDER BY amount DESC
-	`, startDate, endDate, recordType)
+	id, err := result.LastInsertId()
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer rows.Close()
+	rec.ID = id
 
-	var stats []model.CategoryStat
-	for rows.Next() {
-		var s model.CategoryStat
-		err := rows.Scan(&s.CategoryID, &s.CategoryName, &s.CategoryIcon, &s.Amount)
+	for _, tag := range rec.Tags {
+		_, err := db.Exec("INSERT OR IGNORE INTO record_tags (record_id, tag_id) VALUES (?, ?)", rec.ID, tag.ID)
 		if err != nil {
-			return nil, err
-		}
-		if total > 0 {
-			s.Percentage = (s.Amount / total) * 100
+			return err
 		}
-		stats = append(stats, s)
 	}
-
-	return stats, nil
+	return nil
 }
 
-// GetMonthlyTrends 获取年度月趋势
-func (r *SQLiteRepository) GetMonthlyTrends(year int) ([]model.MonthTrend, error) {
-	trends := make([]model.MonthTrend, 12)
+// updateRecord 在事务中更新一条未删除的记录并记录变更
+func (r *SQLiteRepository) updateRecord(action string, id int64, query string, args ...interface{}) error {
+	ch, err := r.beginChange(action)
+	if err != nil {
+		return err
+	}
+	defer ch.rollback()
 
-	for i := 1; i <= 12; i++ {
-		month := fmt.Sprintf("%04d-%02d", year, i)
-		trends[i-1] = model.MonthTrend{Month: month}
+	if err := ch.track(model.EntityRecord, id); err != nil {
+		return err
+	}
+	result, err := ch.tx.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	if err := requireAffected(result, apperrors.ErrRecordNotFound); err != nil {
+		return err
+	}
+	return ch.commit()
+}
 
-		startDate := fmt.Sprintf("%04d-%02d-01", year, i)
-		endDate := fmt.Sprintf("%04d-%02d-31", year, i)
+// UpdateRecord 更新记录及其标签
+func (r *SQLiteRepository) UpdateRecord(rec *model.Record) error {
+	ch, err := r.beginChange(model.ActionUpdateRecord)
+	if err != nil {
+		return err
+	}
+	defer ch.rollback()
 
-		// 收入
-		r.db.QueryRow(`
-			SELECT COALESCE(SUM(amount), 0) FROM records
-			WHERE type = 'income' AND date >= ? AND date <= ?
-		`, startDate, endDate).Scan(&trends[i-1].Income)
+	if err := ch.track(model.EntityRecord, rec.ID); err != nil {
+		return err
+	}
+	result, err := ch.tx.Exec(
+		`UPDATE records SET amount = ?, currency = ?, category_id = ?, account_id = ?, to_account_id = ?, note = ?, date = ?
+		WHERE id = ? AND deleted_at IS NULL`,
+		rec.Amount.Minor, rec.Amount.Currency, rec.CategoryID, rec.AccountID, rec.ToAccountID, rec.Note, rec.Date, rec.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if err := requireAffected(result, apperrors.ErrRecordNotFound); err != nil {
+		return err
+	}
 
-		// 支出
-		r.db.QueryRow(`
-			SELECT COALESCE(SUM(amount), 0) FROM records
-			WHERE type = 'expense' AND date >= ? AND date <= ?
-		`, startDate, endDate).Scan(&trends[i-1].Expense)
+	tagIDs := make([]int64, len(rec.Tags))
+	for i, tag := range rec.Tags {
+		tagIDs[i] = tag.ID
+	}
+	if err := replaceRecordTags(ch.tx, rec.ID, tagIDs); err != nil {
+		return err
 	}
+	return ch.commit()
+}
 
-	return trends, nil
+// DeleteRecord 将记录移入回收站，标签关联保留以便恢复
+func (r *SQLiteRepository) DeleteRecord(id int64) error {
+	return r.updateRecord(model.ActionDeleteRecord, id,
+		"UPDATE records SET deleted_at = CURRENT_TIMESTAMP WHERE id = ? AND deleted_at IS NULL", id,
+	)
 }
 
-// GetRecentRecords 获取最近 N 条记录
-func (r *SQLiteRepository) GetRecentRecords(limit int) ([]model.Record, error) {
-	rows, err := r.db.Query(`
-		SELECT r.id, r.amount, r.type, r.category_id, r.note, r.date, r.created_at,
-		       c.id, c.name, c.icon, c.type
-		FROM records r
-		LEFT JOIN categories c ON r.category_id = c.id
-		ORDER BY r.date DESC, r.created_at DESC
-		LIMIT ?
-	`, limit)
+// DeleteRecords 将多条记录移入回收站
+func (r *SQLiteRepository) DeleteRecords(ids []int64) (int, error) {
+	ch, err := r.beginChange(model.ActionDeleteRecord)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
-	defer rows.Close()
+	defer ch.rollback()
 
-	var records []model.Record
-	for rows.Next() {
-		var rec model.Record
-		var cat model.Category
-		err := rows.Scan(
-			&rec.ID, &rec.Amount, &rec.Type, &rec.CategoryID, &rec.Note, &rec.Date, &rec.CreatedAt,
-			&cat.ID, &cat.Name, &cat.Icon, &cat.Type,
+	count := 0
+	for _, id := range ids {
+		if err := ch.track(model.EntityRecord, id); err != nil {
+			return 0, err
+		}
+		result, err := ch.tx.Exec(
+			"UPDATE records SET deleted_at = CURRENT_TIMESTAMP WHERE id = ? AND deleted_at IS NULL", id,
 		)
 		if err != nil {
-			return nil, err
+			return 0, err
 		}
-		rec.Category = &cat
-		records = append(records, rec)
+		n, err := result.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		count += int(n)
 	}
+	return count, ch.commit()
+}
 
-	return records, nil
+// GetRecordByID 根据 ID 获取记录
+func (r *SQLiteRepository) GetRecordByID(id int64) (*model.Record, error) {
+	rec, err := scanRecord(r.db.QueryRow(recordSelect+" WHERE r.id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, apperrors.ErrRecordNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	records := []model.Record{rec}
+	if err := r.fillTags(records); err != nil {
+		return nil, err
+	}
+	return &records[0], nil
 }
 
-// GetAllRecords 获取所有记录（用于导出）
-func (r *SQLiteRepository) GetAllRecords() ([]model.Record, error) {
+// ListRecordsBetween 获取日期区间 [startDate, endDate) 内的记录
+func (r *SQLiteRepository) ListRecordsBetween(startDate, endDate string) ([]model.Record, error) {
+	return r.queryRecords(recordSelect+`
+		WHERE r.date >= ? AND r.date < ?
+		ORDER BY r.date DESC, r.created_at DESC, r.id DESC
+	`, startDate, endDate)
+}
+
+// ============ 统计查询 ============
+
+// GetStatTotals 获取日期区间 [startDate, endDate) 内按日期、类型、分类和币种分组的收支合计
+func (r *SQLiteRepository) GetStatTotals(startDate, endDate string) ([]model.StatTotal, error) {
 	rows, err := r.db.Query(`
-		SELECT r.id, r.amount, r.type, r.category_id, r.note, r.date, r.created_at,
-		       c.id, c.name, c.icon, c.type
-		FROM records r
-		LEFT JOIN categories c ON r.category_id = c.id
-		ORDER BY r.date DESC
-	`)
+		SELECT date, type, category_id, currency, SUM(amount)
+		FROM active_records
+		WHERE type IN ('income', 'expense') AND date >= ? AND date < ?
+		GROUP BY date, type, category_id, currency
+		ORDER BY date ASC, type ASC, category_id ASC, currency ASC
+	`, startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var records []model.Record
+	var totals []model.StatTotal
 	for rows.Next() {
-		var rec model.Record
-		var cat model.Category
-		err := rows.Scan(
-			&rec.ID, &rec.Amount, &rec.Type, &rec.CategoryID, &rec.Note, &rec.Date, &rec.CreatedAt,
-			&cat.ID, &cat.Name, &cat.Icon, &cat.Type,
-		)
-		if err != nil {
+		var t model.StatTotal
+		if err := rows.Scan(&t.Date, &t.Type, &t.CategoryID, &t.Amount.Currency, &t.Amount.Minor); err != nil {
 			return nil, err
 		}
-		rec.Category = &cat
-		records = append(records, rec)
+		totals = append(totals, t)
 	}
 
-	return records, nil
+	return totals, rows.Err()
+}
+
+// GetRecentRecords 获取最近 N 条记录
+func (r *SQLiteRepository) GetRecentRecords(limit int) ([]model.Record, error) {
+	return r.queryRecords(recordSelect+`
+		ORDER BY r.date DESC, r.created_at DESC, r.id DESC
+		LIMIT ?
+	`, limit)
+}
+
+// GetAllRecords 获取所有记录（用于导出）
+func (r *SQLiteRepository) GetAllRecords() ([]model.Record, error) {
+	return r.queryRecords(recordSelect + `
+		ORDER BY r.date DESC, r.id DESC
+	`)
 }
 
 // GetCategoryByName 根据名称获取分类
 func (r *SQLiteRepository) GetCategoryByName(name string) (*model.Category, error) {
-	row := r.db.QueryRow("SELECT id, name, icon, type, sort_order, created_at FROM categories WHERE name = ?", name)
-
-	var c model.Category
-	err := row.Scan(&c.ID, &c.Name, &c.Icon, &c.Type, &c.SortOrder, &c.CreatedAt)
+	c, err := scanCategory(r.db.QueryRow(categoryColumns+" FROM active_categories WHERE name = ?", name))
+	if err == sql.ErrNoRows {
+		return nil, apperrors.ErrCategoryNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
 	return &c, nil
 }
 
+// ============ 设置 ============
+
+// GetSetting 读取设置项，不存在时返回空字符串
+func (r *SQLiteRepository) GetSetting(key string) (string, error) {
+	var value string
+	err := r.db.QueryRow("SELECT value FROM settings WHERE key = ?", key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return value, err
+}
+
+// SetSetting 写入设置项
+func (r *SQLiteRepository) SetSetting(key, value string) error {
+	_, err := r.db.Exec(`
+		INSERT INTO settings (key, value, updated_at) VALUES (?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at
+	`, key, value)
+	return err
+}
+
 // Ensure time package is used
 var _ = time.Now
@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"database/sql"
+
+	apperrors "dog-view/internal/errors"
+	"dog-view/internal/model"
+)
+
+// ============ Account 操作 ============
+
+const accountSelect = `
+	SELECT id, name, type, icon, opening_balance, currency, sort_order, created_at
+	FROM accounts`
+
+func scanAccount(row rowScanner) (model.Account, error) {
+	var a model.Account
+	var icon sql.NullString
+	err := row.Scan(
+		&a.ID, &a.Name, &a.Type, &icon, &a.OpeningBalance.Minor, &a.OpeningBalance.Currency,
+		&a.SortOrder, &a.CreatedAt,
+	)
+	a.Icon = icon.String
+	return a, err
+}
+
+// ListAccounts 获取账户列表
+func (r *SQLiteRepository) ListAccounts() ([]model.Account, error) {
+	rows, err := r.db.Query(accountSelect + " ORDER BY sort_order ASC, id ASC")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var accounts []model.Account
+	for rows.Next() {
+		a, err := scanAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, a)
+	}
+
+	return accounts, rows.Err()
+}
+
+// GetAccountByID 根据 ID 获取账户
+func (r *SQLiteRepository) GetAccountByID(id int64) (*model.Account, error) {
+	a, err := scanAccount(r.db.QueryRow(accountSelect+" WHERE id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, apperrors.ErrAccountNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// CreateAccount 创建账户
+func (r *SQLiteRepository) CreateAccount(a *model.Account) error {
+	ch, err := r.beginChange(model.ActionCreateAccount)
+	if err != nil {
+		return err
+	}
+	defer ch.rollback()
+
+	result, err := ch.tx.Exec(
+		`INSERT INTO accounts (name, type, icon, opening_balance, currency, sort_order)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		a.Name, a.Type, a.Icon, a.OpeningBalance.Minor, a.OpeningBalance.Currency, a.SortOrder,
+	)
+	if isUniqueViolation(err) {
+		return apperrors.ErrDuplicateAccount
+	}
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	ch.created(model.EntityAccount, id)
+	if err := ch.commit(); err != nil {
+		return err
+	}
+	a.ID = id
+	return nil
+}
+
+// UpdateAccount 更新账户名称、类型、图标和期初余额，仍有记录引用时不能修改币种
+func (r *SQLiteRepository) UpdateAccount(a *model.Account) error {
+	ch, err := r.beginChange(model.ActionUpdateAccount)
+	if err != nil {
+		return err
+	}
+	defer ch.rollback()
+
+	var currency string
+	err = ch.tx.QueryRow("SELECT currency FROM accounts WHERE id = ?", a.ID).Scan(&currency)
+	if err == sql.ErrNoRows {
+		return apperrors.ErrAccountNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if currency != a.OpeningBalance.Currency {
+		var count int
+		err := ch.tx.QueryRow(
+			"SELECT COUNT(*) FROM records WHERE account_id = ? OR to_account_id = ?", a.ID, a.ID,
+		).Scan(&count)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return apperrors.ErrCurrencyMismatch
+		}
+	}
+
+	if err := ch.track(model.EntityAccount, a.ID); err != nil {
+		return err
+	}
+	result, err := ch.tx.Exec(
+		`UPDATE accounts SET name = ?, type = ?, icon = ?, opening_balance = ?, currency = ?
+		WHERE id = ?`,
+		a.Name, a.Type, a.Icon, a.OpeningBalance.Minor, a.OpeningBalance.Currency, a.ID,
+	)
+	if isUniqueViolation(err) {
+		return apperrors.ErrDuplicateAccount
+	}
+	if err != nil {
+		return err
+	}
+	if err := requireAffected(result, apperrors.ErrAccountNotFound); err != nil {
+		return err
+	}
+	return ch.commit()
+}
+
+// DeleteAccount 删除账户，仍有记录（包括回收站中的记录）引用时拒绝删除
+func (r *SQLiteRepository) DeleteAccount(id int64) error {
+	ch, err := r.beginChange(model.ActionDeleteAccount)
+	if err != nil {
+		return err
+	}
+	defer ch.rollback()
+
+	var count int
+	err = ch.tx.QueryRow(
+		"SELECT COUNT(*) FROM records WHERE account_id = ? OR to_account_id = ?", id, id,
+	).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return apperrors.ErrAccountInUse
+	}
+
+	if err := ch.track(model.EntityAccount, id); err != nil {
+		return err
+	}
+	result, err := ch.tx.Exec("DELETE FROM accounts WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	if err := requireAffected(result, apperrors.ErrAccountNotFound); err != nil {
+		return err
+	}
+	return ch.commit()
+}
+
+// GetAccountBalances 计算所有账户在 endDate 之前（不含）的余额：
+// 期初余额 + 收入 - 支出 - 转出 + 转入
+func (r *SQLiteRepository) GetAccountBalances(endDate string) ([]model.AccountBalance, error) {
+	accounts, err := r.ListAccounts()
+	if err != nil {
+		return nil, err
+	}
+
+	flows := make(map[int64]int64)
+	rows, err := r.db.Query(`
+		SELECT account_id, SUM(CASE WHEN type = 'income' THEN amount ELSE -amount END)
+		FROM active_records
+		WHERE account_id != 0 AND date < ?
+		GROUP BY account_id
+		UNION ALL
+		SELECT to_account_id, SUM(amount)
+		FROM active_records
+		WHERE type = 'transfer' AND to_account_id != 0 AND date < ?
+		GROUP BY to_account_id
+	`, endDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, amount int64
+		if err := rows.Scan(&id, &amount); err != nil {
+			return nil, err
+		}
+		flows[id] += amount
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	balances := make([]model.AccountBalance, 0, len(accounts))
+	for _, a := range accounts {
+		balances = append(balances, model.AccountBalance{
+			Account: a,
+			Balance: model.NewMoney(a.OpeningBalance.Minor+flows[a.ID], a.OpeningBalance.Currency),
+		})
+	}
+	return balances, nil
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+
+	apperrors "dog-view/internal/errors"
+	"dog-view/internal/vault"
+)
+
+// ============ 备份 ============
+
+// BackupTo 将数据库的一致快照写入 path（VACUUM INTO），不阻塞其他连接的读取；
+// path 已存在时返回错误，写入失败时删除不完整的文件。加密账本的备份用同一密码加密
+func (r *SQLiteRepository) BackupTo(path string) error {
+	if r.vault != nil {
+		return r.backupEncrypted(path)
+	}
+	if _, err := r.db.Exec("VACUUM INTO ?", path); err != nil {
+		os.Remove(path)
+		return fmt.Errorf("备份数据库失败: %w", err)
+	}
+	return nil
+}
+
+// CheckDatabase 检查 path 处的数据库能否作为账本打开：结构版本不高于当前支持的版本且完整性检查通过，
+// 用于恢复备份前的校验。只读取不迁移，不修改文件也不留下迁移前备份；加密的数据库用 passphrase 解密
+func CheckDatabase(path, passphrase string) error {
+	db, err := openReadOnly(path, passphrase)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var version int
+	if err := db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_version").Scan(&version); err != nil {
+		return fmt.Errorf("不是有效的账本数据库: %w", err)
+	}
+	if latest := migrations[len(migrations)-1].version; version > latest {
+		return fmt.Errorf("数据库版本 v%d 高于当前支持的 v%d，请升级应用后再恢复", version, latest)
+	}
+
+	var result string
+	if err := db.QueryRow("PRAGMA integrity_check").Scan(&result); err != nil {
+		return fmt.Errorf("检查数据库完整性失败: %w", err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("数据库已损坏: %s", result)
+	}
+	return nil
+}
+
+// openReadOnly 只读打开数据库。加密的数据库解密到内存中，修改不会写回文件
+func openReadOnly(path, passphrase string) (*sql.DB, error) {
+	encrypted, err := vault.IsEncrypted(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开数据库失败: %w", err)
+	}
+	if encrypted {
+		if passphrase == "" {
+			return nil, apperrors.ErrLedgerLocked
+		}
+		image, _, err := vault.ReadFile(path, passphrase)
+		if err != nil {
+			return nil, err
+		}
+		db, err := openMemoryDB(image, func() {})
+		if err != nil {
+			return nil, fmt.Errorf("打开数据库失败: %w", err)
+		}
+		return db, nil
+	}
+
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, fmt.Errorf("打开数据库失败: %w", err)
+	}
+	// query_only 按连接生效，只保留一个连接
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec("PRAGMA query_only = ON"); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("打开数据库失败: %w", err)
+	}
+	return db, nil
+}
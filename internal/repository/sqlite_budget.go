@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"database/sql"
+
+	apperrors "dog-view/internal/errors"
+	"dog-view/internal/model"
+)
+
+// ============ 预算 ============
+
+const budgetSelect = `SELECT id, month, category_id, amount, currency, recurring, created_at FROM budgets`
+
+func scanBudget(row rowScanner) (model.Budget, error) {
+	var b model.Budget
+	err := row.Scan(&b.ID, &b.Month, &b.CategoryID, &b.Amount.Minor, &b.Amount.Currency, &b.Recurring, &b.CreatedAt)
+	return b, err
+}
+
+// ListBudgets 获取 month 及之前各月设置的预算
+func (r *SQLiteRepository) ListBudgets(month string) ([]model.Budget, error) {
+	query := budgetSelect
+	var args []interface{}
+	if month != "" {
+		query += " WHERE month <= ?"
+		args = append(args, month)
+	}
+	query += " ORDER BY month ASC, category_id ASC"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var budgets []model.Budget
+	for rows.Next() {
+		b, err := scanBudget(rows)
+		if err != nil {
+			return nil, err
+		}
+		budgets = append(budgets, b)
+	}
+
+	return budgets, rows.Err()
+}
+
+// SaveBudget 保存预算，同一月份同一分类已有预算时覆盖
+func (r *SQLiteRepository) SaveBudget(b *model.Budget) error {
+	ch, err := r.beginChange(model.ActionSetBudget)
+	if err != nil {
+		return err
+	}
+	defer ch.rollback()
+
+	var existing int64
+	err = ch.tx.QueryRow(
+		"SELECT id FROM budgets WHERE month = ? AND category_id = ?", b.Month, b.CategoryID,
+	).Scan(&existing)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if existing != 0 {
+		if err := ch.track(model.EntityBudget, existing); err != nil {
+			return err
+		}
+	}
+
+	_, err = ch.tx.Exec(`
+		INSERT INTO budgets (month, category_id, amount, currency, recurring) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(month, category_id) DO UPDATE SET
+			amount = excluded.amount, currency = excluded.currency, recurring = excluded.recurring
+	`, b.Month, b.CategoryID, b.Amount.Minor, b.Amount.Currency, b.Recurring)
+	if err != nil {
+		return err
+	}
+
+	var id int64
+	err = ch.tx.QueryRow(
+		"SELECT id FROM budgets WHERE month = ? AND category_id = ?", b.Month, b.CategoryID,
+	).Scan(&id)
+	if err != nil {
+		return err
+	}
+	if existing == 0 {
+		ch.created(model.EntityBudget, id)
+	}
+	if err := ch.commit(); err != nil {
+		return err
+	}
+	b.ID = id
+	return nil
+}
+
+// DeleteBudget 删除预算
+func (r *SQLiteRepository) DeleteBudget(id int64) error {
+	ch, err := r.beginChange(model.ActionDeleteBudget)
+	if err != nil {
+		return err
+	}
+	defer ch.rollback()
+
+	if err := ch.track(model.EntityBudget, id); err != nil {
+		return err
+	}
+	result, err := ch.tx.Exec("DELETE FROM budgets WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	if err := requireAffected(result, apperrors.ErrBudgetNotFound); err != nil {
+		return err
+	}
+	return ch.commit()
+}
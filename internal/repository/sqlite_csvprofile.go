@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	apperrors "dog-view/internal/errors"
+	"dog-view/internal/model"
+)
+
+// ============ CSV 列映射方案 ============
+
+const csvProfileSelect = `SELECT id, name, mapping, created_at FROM csv_profiles`
+
+func scanCSVProfile(row rowScanner) (model.CSVProfile, error) {
+	var p model.CSVProfile
+	var mapping string
+	if err := row.Scan(&p.ID, &p.Name, &mapping, &p.CreatedAt); err != nil {
+		return p, err
+	}
+	err := json.Unmarshal([]byte(mapping), &p.Mapping)
+	return p, err
+}
+
+// ListCSVProfiles 获取全部列映射方案
+func (r *SQLiteRepository) ListCSVProfiles() ([]model.CSVProfile, error) {
+	rows, err := r.db.Query(csvProfileSelect + " ORDER BY name ASC")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var profiles []model.CSVProfile
+	for rows.Next() {
+		p, err := scanCSVProfile(rows)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, p)
+	}
+
+	return profiles, rows.Err()
+}
+
+// GetCSVProfile 根据 ID 获取列映射方案
+func (r *SQLiteRepository) GetCSVProfile(id int64) (*model.CSVProfile, error) {
+	p, err := scanCSVProfile(r.db.QueryRow(csvProfileSelect+" WHERE id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, apperrors.ErrProfileNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// CreateCSVProfile 创建列映射方案
+func (r *SQLiteRepository) CreateCSVProfile(p *model.CSVProfile) error {
+	mapping, err := json.Marshal(p.Mapping)
+	if err != nil {
+		return err
+	}
+	result, err := r.db.Exec("INSERT INTO csv_profiles (name, mapping) VALUES (?, ?)", p.Name, string(mapping))
+	if isUniqueViolation(err) {
+		return apperrors.ErrDuplicateProfile
+	}
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	p.ID = id
+	return nil
+}
+
+// UpdateCSVProfile 更新列映射方案的名称和映射
+func (r *SQLiteRepository) UpdateCSVProfile(p *model.CSVProfile) error {
+	mapping, err := json.Marshal(p.Mapping)
+	if err != nil {
+		return err
+	}
+	result, err := r.db.Exec("UPDATE csv_profiles SET name = ?, mapping = ? WHERE id = ?", p.Name, string(mapping), p.ID)
+	if isUniqueViolation(err) {
+		return apperrors.ErrDuplicateProfile
+	}
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, apperrors.ErrProfileNotFound)
+}
+
+// DeleteCSVProfile 删除列映射方案
+func (r *SQLiteRepository) DeleteCSVProfile(id int64) error {
+	result, err := r.db.Exec("DELETE FROM csv_profiles WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, apperrors.ErrProfileNotFound)
+}
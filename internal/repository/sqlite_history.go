@@ -0,0 +1,537 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	apperrors "dog-view/internal/errors"
+	"dog-view/internal/model"
+)
+
+// ============ 变更日志 ============
+
+// changeRecorder 在一个事务中记录操作涉及的实体修改前的快照；
+// commit 时读取修改后的快照，与修改前相同的实体不写入，没有任何变化时不产生变更集
+type changeRecorder struct {
+	tx       *sql.Tx
+	action   string
+	targetID int64
+	changes  []model.Change
+	tracked  map[entityKey]bool
+}
+
+// beginChange 开启事务并创建变更记录器，调用方需 defer ch.rollback()
+func (r *SQLiteRepository) beginChange(action string) (*changeRecorder, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return &changeRecorder{tx: tx, action: action, tracked: make(map[entityKey]bool)}, nil
+}
+
+// rollback 未提交时回滚事务，提交后调用无副作用
+func (ch *changeRecorder) rollback() {
+	ch.tx.Rollback()
+}
+
+// track 在修改前记录实体的快照，同一实体只记录第一次
+func (ch *changeRecorder) track(entity string, id int64) error {
+	key := entityKey{entity, id}
+	if ch.tracked[key] {
+		return nil
+	}
+	before, err := snapshotTx(ch.tx, entity, id)
+	if err != nil {
+		return err
+	}
+	ch.tracked[key] = true
+	ch.changes = append(ch.changes, model.Change{Entity: entity, EntityID: id, Before: before})
+	return nil
+}
+
+// trackQuery 记录查询返回的每个实体
+func (ch *changeRecorder) trackQuery(entity, query string, args ...interface{}) error {
+	ids, err := queryIDs(ch.tx, query, args...)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := ch.track(entity, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// created 记录新建的实体，其修改前的快照为空
+func (ch *changeRecorder) created(entity string, id int64) {
+	ch.tracked[entityKey{entity, id}] = true
+	ch.changes = append(ch.changes, model.Change{Entity: entity, EntityID: id})
+}
+
+// commit 写入变更集并提交事务
+func (ch *changeRecorder) commit() error {
+	var changes []model.Change
+	for _, c := range ch.changes {
+		after, err := snapshotTx(ch.tx, c.Entity, c.EntityID)
+		if err != nil {
+			return err
+		}
+		same, err := sameSnapshot(c.Entity, c.Before, after)
+		if err != nil {
+			return err
+		}
+		if !same {
+			c.After = after
+			changes = append(changes, c)
+		}
+	}
+
+	if len(changes) > 0 {
+		result, err := ch.tx.Exec(
+			"INSERT INTO change_sets (action, target_id) VALUES (?, ?)", ch.action, ch.targetID,
+		)
+		if err != nil {
+			return err
+		}
+		setID, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		for _, c := range changes {
+			_, err := ch.tx.Exec(
+				"INSERT INTO changes (set_id, entity, entity_id, before, after) VALUES (?, ?, ?, ?, ?)",
+				setID, c.Entity, c.EntityID, nullJSON(c.Before), nullJSON(c.After),
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return ch.tx.Commit()
+}
+
+// nullJSON 空快照写入 NULL
+func nullJSON(data json.RawMessage) interface{} {
+	if data == nil {
+		return nil
+	}
+	return string(data)
+}
+
+// sqlTime 按 timestampLayout 格式化时间，nil 写入 NULL
+func sqlTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.UTC().Format(timestampLayout)
+}
+
+// snapshotTx 读取实体当前的快照（包括回收站中的），不存在时返回 nil
+func snapshotTx(tx *sql.Tx, entity string, id int64) (json.RawMessage, error) {
+	var v interface{}
+	var err error
+	switch entity {
+	case model.EntityRecord:
+		var rec model.Record
+		rec, err = scanRecord(tx.QueryRow(
+			recordColumns+" FROM records r LEFT JOIN categories c ON r.category_id = c.id WHERE r.id = ?", id,
+		))
+		if err == nil {
+			rec.Tags, err = recordTagsTx(tx, id)
+		}
+		v = rec
+	case model.EntityCategory:
+		v, err = scanCategory(tx.QueryRow(categoryColumns+" FROM categories WHERE id = ?", id))
+	case model.EntityAccount:
+		v, err = scanAccount(tx.QueryRow(accountSelect+" WHERE id = ?", id))
+	case model.EntityBudget:
+		v, err = scanBudget(tx.QueryRow(budgetSelect+" WHERE id = ?", id))
+	case model.EntityRule:
+		v, err = scanRule(tx.QueryRow(ruleSelect+" WHERE id = ?", id))
+	case model.EntityOccurrence:
+		v, err = scanOccurrence(tx.QueryRow(occurrenceSelect+" WHERE id = ?", id))
+	default:
+		var t model.Tag
+		err = tx.QueryRow("SELECT id, name, color, created_at FROM tags WHERE id = ?", id).
+			Scan(&t.ID, &t.Name, &t.Color, &t.CreatedAt)
+		v = t
+	}
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	return marshalSnapshot(v, err == nil)
+}
+
+// recordTagsTx 读取记录的标签，按名称排序
+func recordTagsTx(tx *sql.Tx, recordID int64) ([]model.Tag, error) {
+	rows, err := tx.Query(`
+		SELECT t.id, t.name, t.color, t.created_at
+		FROM record_tags rt JOIN tags t ON t.id = rt.tag_id
+		WHERE rt.record_id = ?
+	`, recordID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []model.Tag{}
+	for rows.Next() {
+		var t model.Tag
+		if err := rows.Scan(&t.ID, &t.Name, &t.Color, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		tags = append(tags, t)
+	}
+	sortTags(tags)
+	return tags, rows.Err()
+}
+
+// putTx 将实体写成快照中的状态，快照为 nil 时彻底删除实体
+func putTx(tx *sql.Tx, entity string, id int64, data json.RawMessage) error {
+	switch entity {
+	case model.EntityRecord:
+		return putRecordTx(tx, id, data)
+	case model.EntityCategory:
+		return putCategoryTx(tx, id, data)
+	case model.EntityAccount:
+		return putAccountTx(tx, id, data)
+	case model.EntityBudget:
+		return putBudgetTx(tx, id, data)
+	case model.EntityRule:
+		return putRuleTx(tx, id, data)
+	case model.EntityOccurrence:
+		return putOccurrenceTx(tx, id, data)
+	default:
+		return putTagTx(tx, id, data)
+	}
+}
+
+func putRecordTx(tx *sql.Tx, id int64, data json.RawMessage) error {
+	if _, err := tx.Exec("DELETE FROM record_tags WHERE record_id = ?", id); err != nil {
+		return err
+	}
+	if data == nil {
+		_, err := tx.Exec("DELETE FROM records WHERE id = ?", id)
+		return err
+	}
+
+	var rec model.Record
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return err
+	}
+	_, err := tx.Exec(`
+		INSERT INTO records (id, amount, currency, type, category_id, account_id, to_account_id, note, date,
+			import_batch, created_at, deleted_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			amount = excluded.amount, currency = excluded.currency, type = excluded.type,
+			category_id = excluded.category_id, account_id = excluded.account_id,
+			to_account_id = excluded.to_account_id, note = excluded.note, date = excluded.date,
+			import_batch = excluded.import_batch, created_at = excluded.created_at, deleted_at = excluded.deleted_at
+	`, id, rec.Amount.Minor, rec.Amount.Currency, rec.Type, rec.CategoryID, rec.AccountID, rec.ToAccountID,
+		rec.Note, rec.Date, rec.ImportBatch, sqlTime(&rec.CreatedAt), sqlTime(rec.DeletedAt))
+	if err != nil {
+		return err
+	}
+
+	// 快照之后被彻底删除的标签不再关联
+	for _, t := range rec.Tags {
+		_, err := tx.Exec("INSERT INTO record_tags (record_id, tag_id) SELECT ?, id FROM tags WHERE id = ?", id, t.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func putCategoryTx(tx *sql.Tx, id int64, data json.RawMessage) error {
+	if data == nil {
+		_, err := tx.Exec("DELETE FROM categories WHERE id = ?", id)
+		return err
+	}
+
+	var c model.Category
+	if err := json.Unmarshal(data, &c); err != nil {
+		return err
+	}
+	_, err := tx.Exec(`
+		INSERT INTO categories (id, name, icon, type, parent_id, sort_order, archived, import_batch, created_at, deleted_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			name = excluded.name, icon = excluded.icon, type = excluded.type, parent_id = excluded.parent_id,
+			sort_order = excluded.sort_order, archived = excluded.archived, import_batch = excluded.import_batch,
+			created_at = excluded.created_at, deleted_at = excluded.deleted_at
+	`, id, c.Name, c.Icon, c.Type, c.ParentID, c.SortOrder, c.Archived, c.ImportBatch,
+		sqlTime(&c.CreatedAt), sqlTime(c.DeletedAt))
+	if isUniqueViolation(err) {
+		return apperrors.ErrDuplicateCategory
+	}
+	return err
+}
+
+func putTagTx(tx *sql.Tx, id int64, data json.RawMessage) error {
+	if data == nil {
+		if _, err := tx.Exec("DELETE FROM record_tags WHERE tag_id = ?", id); err != nil {
+			return err
+		}
+		_, err := tx.Exec("DELETE FROM tags WHERE id = ?", id)
+		return err
+	}
+
+	var t model.Tag
+	if err := json.Unmarshal(data, &t); err != nil {
+		return err
+	}
+	_, err := tx.Exec(`
+		INSERT INTO tags (id, name, color, created_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET name = excluded.name, color = excluded.color, created_at = excluded.created_at
+	`, id, t.Name, t.Color, sqlTime(&t.CreatedAt))
+	if isUniqueViolation(err) {
+		return apperrors.ErrDuplicateTag
+	}
+	return err
+}
+
+// putAccountTx 仍有记录引用的账户不能删除，名称被占用时不能写回
+func putAccountTx(tx *sql.Tx, id int64, data json.RawMessage) error {
+	if data == nil {
+		var count int
+		err := tx.QueryRow(
+			"SELECT COUNT(*) FROM records WHERE account_id = ? OR to_account_id = ?", id, id,
+		).Scan(&count)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return apperrors.ErrUndoConflict
+		}
+		_, err = tx.Exec("DELETE FROM accounts WHERE id = ?", id)
+		return err
+	}
+
+	var a model.Account
+	if err := json.Unmarshal(data, &a); err != nil {
+		return err
+	}
+	_, err := tx.Exec(`
+		INSERT INTO accounts (id, name, type, icon, opening_balance, currency, sort_order, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			name = excluded.name, type = excluded.type, icon = excluded.icon,
+			opening_balance = excluded.opening_balance, currency = excluded.currency,
+			sort_order = excluded.sort_order, created_at = excluded.created_at
+	`, id, a.Name, a.Type, a.Icon, a.OpeningBalance.Minor, a.OpeningBalance.Currency, a.SortOrder,
+		sqlTime(&a.CreatedAt))
+	if isUniqueViolation(err) {
+		return apperrors.ErrDuplicateAccount
+	}
+	return err
+}
+
+// putBudgetTx 同一月份同一分类已有其他预算时不能写回
+func putBudgetTx(tx *sql.Tx, id int64, data json.RawMessage) error {
+	if data == nil {
+		_, err := tx.Exec("DELETE FROM budgets WHERE id = ?", id)
+		return err
+	}
+
+	var b model.Budget
+	if err := json.Unmarshal(data, &b); err != nil {
+		return err
+	}
+	_, err := tx.Exec(`
+		INSERT INTO budgets (id, month, category_id, amount, currency, recurring, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			month = excluded.month, category_id = excluded.category_id, amount = excluded.amount,
+			currency = excluded.currency, recurring = excluded.recurring, created_at = excluded.created_at
+	`, id, b.Month, b.CategoryID, b.Amount.Minor, b.Amount.Currency, b.Recurring, sqlTime(&b.CreatedAt))
+	if isUniqueViolation(err) {
+		return apperrors.ErrUndoConflict
+	}
+	return err
+}
+
+func putRuleTx(tx *sql.Tx, id int64, data json.RawMessage) error {
+	if data == nil {
+		_, err := tx.Exec("DELETE FROM recurring_rules WHERE id = ?", id)
+		return err
+	}
+
+	var rule model.RecurringRule
+	if err := json.Unmarshal(data, &rule); err != nil {
+		return err
+	}
+	_, err := tx.Exec(`
+		INSERT INTO recurring_rules (id, name, frequency, interval, day_of_month, start_date, end_date, max_count,
+			skip_weekends, amount, currency, type, category_id, account_id, note, active, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			name = excluded.name, frequency = excluded.frequency, interval = excluded.interval,
+			day_of_month = excluded.day_of_month, start_date = excluded.start_date, end_date = excluded.end_date,
+			max_count = excluded.max_count, skip_weekends = excluded.skip_weekends, amount = excluded.amount,
+			currency = excluded.currency, type = excluded.type, category_id = excluded.category_id,
+			account_id = excluded.account_id, note = excluded.note, active = excluded.active,
+			created_at = excluded.created_at
+	`, id, rule.Name, rule.Frequency, rule.Interval, rule.DayOfMonth, rule.StartDate, rule.EndDate, rule.MaxCount,
+		rule.SkipWeekends, rule.Amount.Minor, rule.Amount.Currency, rule.Type, rule.CategoryID, rule.AccountID,
+		rule.Note, rule.Active, sqlTime(&rule.CreatedAt))
+	return err
+}
+
+// putOccurrenceTx 同一次发生已有其他记录（如已自动入账）时不能写回
+func putOccurrenceTx(tx *sql.Tx, id int64, data json.RawMessage) error {
+	if data == nil {
+		_, err := tx.Exec("DELETE FROM recurring_occurrences WHERE id = ?", id)
+		return err
+	}
+
+	var o model.Occurrence
+	if err := json.Unmarshal(data, &o); err != nil {
+		return err
+	}
+	amount, currency := occurrenceAmount(&o)
+	_, err := tx.Exec(`
+		INSERT INTO recurring_occurrences (id, rule_id, date, status, record_id, amount, currency, note)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			rule_id = excluded.rule_id, date = excluded.date, status = excluded.status,
+			record_id = excluded.record_id, amount = excluded.amount, currency = excluded.currency,
+			note = excluded.note
+	`, id, o.RuleID, o.Date, o.Status, o.RecordID, amount, currency, o.Note)
+	if isUniqueViolation(err) {
+		return apperrors.ErrUndoConflict
+	}
+	return err
+}
+
+// querier 兼容 *sql.DB 与 *sql.Tx
+type querier interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// queryChanges 查询变更并附带所属变更集的操作和时间，按先后顺序排列
+func queryChanges(q querier, where string, args ...interface{}) ([]model.Change, error) {
+	rows, err := q.Query(`
+		SELECT ch.id, ch.set_id, s.action, ch.entity, ch.entity_id, ch.before, ch.after, s.created_at
+		FROM changes ch JOIN change_sets s ON s.id = ch.set_id
+		`+where+`
+		ORDER BY ch.id ASC
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []model.Change{}
+	for rows.Next() {
+		var c model.Change
+		var before, after sql.NullString
+		err := rows.Scan(&c.ID, &c.SetID, &c.Action, &c.Entity, &c.EntityID, &before, &after, &c.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if before.Valid {
+			c.Before = json.RawMessage(before.String)
+		}
+		if after.Valid {
+			c.After = json.RawMessage(after.String)
+		}
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
+
+// ListChanges 获取实体的全部变更，按先后顺序排列
+func (r *SQLiteRepository) ListChanges(entity string, entityID int64) ([]model.Change, error) {
+	return queryChanges(r.db, "WHERE ch.entity = ? AND ch.entity_id = ?", entity, entityID)
+}
+
+// ListChangeSets 获取 ID 大于 afterID 的变更集，不含具体变更
+func (r *SQLiteRepository) ListChangeSets(afterID int64) ([]model.ChangeSet, error) {
+	rows, err := r.db.Query(
+		"SELECT id, action, target_id, created_at FROM change_sets WHERE id > ? ORDER BY id ASC", afterID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sets := []model.ChangeSet{}
+	for rows.Next() {
+		var s model.ChangeSet
+		if err := rows.Scan(&s.ID, &s.Action, &s.TargetID, &s.CreatedAt); err != nil {
+			return nil, err
+		}
+		sets = append(sets, s)
+	}
+	return sets, rows.Err()
+}
+
+// LatestChangeSetID 获取最近一个变更集的 ID，没有变更时返回 0
+func (r *SQLiteRepository) LatestChangeSetID() (int64, error) {
+	var id int64
+	err := r.db.QueryRow("SELECT COALESCE(MAX(id), 0) FROM change_sets").Scan(&id)
+	return id, err
+}
+
+// RevertChangeSet 撤销变更集
+func (r *SQLiteRepository) RevertChangeSet(id int64) error {
+	return r.replayChangeSet(id, true)
+}
+
+// ReapplyChangeSet 重做变更集
+func (r *SQLiteRepository) ReapplyChangeSet(id int64) error {
+	return r.replayChangeSet(id, false)
+}
+
+// replayChangeSet 在一个事务中将变更集涉及的实体写回修改前（撤销）或修改后（重做）的状态，
+// 任一实体的当前状态与期望不符时整体放弃
+func (r *SQLiteRepository) replayChangeSet(id int64, undo bool) error {
+	action := model.ActionRedo
+	if undo {
+		action = model.ActionUndo
+	}
+	ch, err := r.beginChange(action)
+	if err != nil {
+		return err
+	}
+	defer ch.rollback()
+	ch.targetID = id
+
+	changes, err := queryChanges(ch.tx, "WHERE ch.set_id = ?", id)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		return apperrors.ErrChangeSetNotFound
+	}
+
+	steps := replaySteps(changes, undo)
+	for _, s := range steps {
+		current, err := snapshotTx(ch.tx, s.Entity, s.EntityID)
+		if err != nil {
+			return err
+		}
+		same, err := sameSnapshot(s.Entity, current, s.Before)
+		if err != nil {
+			return err
+		}
+		if !same {
+			return apperrors.ErrUndoConflict
+		}
+		if err := ch.track(s.Entity, s.EntityID); err != nil {
+			return err
+		}
+	}
+	for _, s := range steps {
+		if err := putTx(ch.tx, s.Entity, s.EntityID, s.After); err != nil {
+			return err
+		}
+	}
+
+	return ch.commit()
+}
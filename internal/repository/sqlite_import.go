@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"database/sql"
+
+	apperrors "dog-view/internal/errors"
+	"dog-view/internal/model"
+)
+
+// ============ 导入批次 ============
+
+// importRef 将占位 ID 转换为实际 ID：负数 -n 指向本批新建的第 n 个实体，其他值原样返回
+func importRef(id int64, created []int64) (int64, error) {
+	if id >= 0 {
+		return id, nil
+	}
+	if -id > int64(len(created)) {
+		return 0, apperrors.ErrImportFailed
+	}
+	return created[-id-1], nil
+}
+
+// CreateImportBatch 在一个事务中创建导入批次及其分类、标签和记录
+func (r *SQLiteRepository) CreateImportBatch(batch *model.ImportBatch, categories []model.Category, tags []model.Tag, records []model.Record) error {
+	ch, err := r.beginChange(model.ActionImport)
+	if err != nil {
+		return err
+	}
+	defer ch.rollback()
+	tx := ch.tx
+
+	result, err := tx.Exec(
+		"INSERT INTO import_batches (source, records, categories, tags) VALUES (?, ?, ?, ?)",
+		batch.Source, len(records), len(categories), len(tags),
+	)
+	if err != nil {
+		return err
+	}
+	batchID, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	ch.targetID = batchID
+
+	categoryIDs := make([]int64, 0, len(categories))
+	for i := range categories {
+		c := &categories[i]
+		if c.ParentID, err = importRef(c.ParentID, categoryIDs); err != nil {
+			return err
+		}
+		c.ImportBatch = batchID
+		if c.ID, err = insertCategory(tx, c); err != nil {
+			return err
+		}
+		ch.created(model.EntityCategory, c.ID)
+		categoryIDs = append(categoryIDs, c.ID)
+	}
+
+	tagIDs := make([]int64, 0, len(tags))
+	for i := range tags {
+		t := &tags[i]
+		if t.ID, err = insertTag(tx, t); err != nil {
+			return err
+		}
+		ch.created(model.EntityTag, t.ID)
+		tagIDs = append(tagIDs, t.ID)
+	}
+
+	for i := range records {
+		rec := &records[i]
+		if rec.CategoryID, err = importRef(rec.CategoryID, categoryIDs); err != nil {
+			return err
+		}
+		for j := range rec.Tags {
+			if rec.Tags[j].ID, err = importRef(rec.Tags[j].ID, tagIDs); err != nil {
+				return err
+			}
+		}
+		rec.ImportBatch = batchID
+		if err := insertRecord(tx, rec); err != nil {
+			return err
+		}
+		ch.created(model.EntityRecord, rec.ID)
+	}
+
+	err = tx.QueryRow("SELECT created_at FROM import_batches WHERE id = ?", batchID).Scan(&batch.CreatedAt)
+	if err != nil {
+		return err
+	}
+	if err := ch.commit(); err != nil {
+		return err
+	}
+	batch.ID = batchID
+	batch.Records = len(records)
+	batch.Categories = len(categories)
+	batch.Tags = len(tags)
+	return nil
+}
+
+// ListImportBatches 获取全部导入批次，最新的在前
+func (r *SQLiteRepository) ListImportBatches() ([]model.ImportBatch, error) {
+	rows, err := r.db.Query(
+		"SELECT id, source, records, categories, tags, created_at FROM import_batches ORDER BY id DESC",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	batches := []model.ImportBatch{}
+	for rows.Next() {
+		var b model.ImportBatch
+		if err := rows.Scan(&b.ID, &b.Source, &b.Records, &b.Categories, &b.Tags, &b.CreatedAt); err != nil {
+			return nil, err
+		}
+		batches = append(batches, b)
+	}
+	return batches, rows.Err()
+}
+
+// DeleteImportBatch 将导入批次的记录和分类移入回收站
+func (r *SQLiteRepository) DeleteImportBatch(id int64) (model.ImportUndoResult, error) {
+	var result model.ImportUndoResult
+
+	ch, err := r.beginChange(model.ActionUndoImport)
+	if err != nil {
+		return result, err
+	}
+	defer ch.rollback()
+	tx := ch.tx
+	ch.targetID = id
+
+	var exists int
+	if err := tx.QueryRow("SELECT 1 FROM import_batches WHERE id = ?", id).Scan(&exists); err != nil {
+		if err == sql.ErrNoRows {
+			return result, apperrors.ErrImportBatchNotFound
+		}
+		return result, err
+	}
+
+	err = ch.trackQuery(model.EntityRecord, "SELECT id FROM active_records WHERE import_batch = ?", id)
+	if err != nil {
+		return result, err
+	}
+	res, err := tx.Exec(
+		"UPDATE records SET deleted_at = CURRENT_TIMESTAMP WHERE import_batch = ? AND deleted_at IS NULL", id,
+	)
+	if err != nil {
+		return result, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return result, err
+	}
+	result.Records = int(n)
+
+	// 先删除子分类，上级分类在下一轮才不再有子分类
+	for {
+		ids, err := queryIDs(tx, `
+			SELECT id FROM active_categories c
+			WHERE import_batch = ?
+			  AND NOT EXISTS (SELECT 1 FROM active_records WHERE category_id = c.id)
+			  AND NOT EXISTS (SELECT 1 FROM active_categories WHERE parent_id = c.id)
+		`, id)
+		if err != nil {
+			return result, err
+		}
+		if len(ids) == 0 {
+			break
+		}
+		for _, cid := range ids {
+			if err := ch.track(model.EntityCategory, cid); err != nil {
+				return result, err
+			}
+			_, err := tx.Exec("UPDATE categories SET deleted_at = CURRENT_TIMESTAMP WHERE id = ?", cid)
+			if err != nil {
+				return result, err
+			}
+		}
+		result.Categories += len(ids)
+	}
+
+	return result, ch.commit()
+}
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"strings"
+
+	"dog-view/internal/model"
+)
+
+// ============ 记录查询 ============
+
+// placeholders 生成 n 个以逗号分隔的占位符
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
+
+// likeEscaper 转义 LIKE 模式中的通配符，配合 ESCAPE '\'
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// filterWhere 将筛选条件转换为以 AND 连接的条件及其参数，记录表别名为 r
+func filterWhere(filter model.RecordFilter) ([]string, []interface{}) {
+	var where []string
+	var args []interface{}
+
+	if filter.StartDate != "" {
+		where = append(where, "r.date >= ?")
+		args = append(args, filter.StartDate)
+	}
+	if filter.EndDate != "" {
+		where = append(where, "r.date < ?")
+		args = append(args, filter.EndDate)
+	}
+	if len(filter.Types) > 0 {
+		where = append(where, "r.type IN ("+placeholders(len(filter.Types))+")")
+		for _, t := range filter.Types {
+			args = append(args, t)
+		}
+	}
+	if len(filter.CategoryIDs) > 0 {
+		where = append(where, "r.category_id IN ("+placeholders(len(filter.CategoryIDs))+")")
+		for _, id := range filter.CategoryIDs {
+			args = append(args, id)
+		}
+	}
+	if len(filter.TagIDs) > 0 {
+		in := placeholders(len(filter.TagIDs))
+		for _, id := range filter.TagIDs {
+			args = append(args, id)
+		}
+		if filter.MatchAllTags {
+			where = append(where, `(SELECT COUNT(DISTINCT tag_id) FROM record_tags
+				WHERE record_id = r.id AND tag_id IN (`+in+`)) = ?`)
+			args = append(args, len(uniqueIDs(filter.TagIDs)))
+		} else {
+			where = append(where, "r.id IN (SELECT record_id FROM record_tags WHERE tag_id IN ("+in+"))")
+		}
+	}
+	if filter.MinAmount != 0 {
+		where = append(where, "r.amount >= ?")
+		args = append(args, filter.MinAmount)
+	}
+	if filter.MaxAmount != 0 {
+		where = append(where, "r.amount <= ?")
+		args = append(args, filter.MaxAmount)
+	}
+	if filter.Note != "" {
+		where = append(where, `r.note LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+likeEscaper.Replace(filter.Note)+"%")
+	}
+
+	return where, args
+}
+
+// ListRecords 按筛选条件获取记录
+func (r *SQLiteRepository) ListRecords(filter model.RecordFilter) ([]model.Record, error) {
+	where, args := filterWhere(filter)
+
+	query := recordSelect
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY r.date DESC, r.created_at DESC, r.id DESC"
+
+	return r.queryRecords(query, args...)
+}
+
+// QueryRecords 按筛选条件、排序和游标获取一页记录
+func (r *SQLiteRepository) QueryRecords(q model.RecordQuery) ([]model.Record, error) {
+	where, args := filterWhere(q.Filter)
+
+	column := "r.date"
+	if q.SortBy == model.SortByAmount {
+		column = "r.amount"
+	}
+	op, dir := ">", "ASC"
+	if q.Desc {
+		op, dir = "<", "DESC"
+	}
+
+	if q.Cursor != "" {
+		cursor, err := model.ParseRecordCursor(q.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		var value interface{} = cursor.Date
+		if q.SortBy == model.SortByAmount {
+			value = cursor.Amount
+		}
+		where = append(where, "("+column+" "+op+" ? OR ("+column+" = ? AND r.id "+op+" ?))")
+		args = append(args, value, value, cursor.ID)
+	}
+
+	query := recordSelect
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY " + column + " " + dir + ", r.id " + dir
+	if q.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, q.Limit)
+	}
+
+	return r.queryRecords(query, args...)
+}
+
+// GetRecordTotals 获取筛选结果的记录数，以及按日期、类型和币种分组的收支合计
+func (r *SQLiteRepository) GetRecordTotals(filter model.RecordFilter) ([]model.StatTotal, int, error) {
+	where, args := filterWhere(filter)
+	clause := ""
+	if len(where) > 0 {
+		clause = " WHERE " + strings.Join(where, " AND ")
+	}
+
+	var count int
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM active_records r"+clause, args...).Scan(&count); err != nil {
+		return nil, 0, err
+	}
+
+	where = append(where, "r.type IN ('income', 'expense')")
+	rows, err := r.db.Query(`
+		SELECT r.date, r.type, r.currency, SUM(r.amount)
+		FROM active_records r
+		WHERE `+strings.Join(where, " AND ")+`
+		GROUP BY r.date, r.type, r.currency
+		ORDER BY r.date ASC, r.type ASC, r.currency ASC
+	`, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var totals []model.StatTotal
+	for rows.Next() {
+		var t model.StatTotal
+		if err := rows.Scan(&t.Date, &t.Type, &t.Amount.Currency, &t.Amount.Minor); err != nil {
+			return nil, 0, err
+		}
+		totals = append(totals, t)
+	}
+
+	return totals, count, rows.Err()
+}
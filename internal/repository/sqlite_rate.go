@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"database/sql"
+
+	apperrors "dog-view/internal/errors"
+	"dog-view/internal/model"
+)
+
+// ============ 汇率 ============
+
+const rateSelect = `
+	SELECT id, from_currency, to_currency, date, rate, created_at
+	FROM exchange_rates`
+
+func scanRate(row rowScanner) (model.ExchangeRate, error) {
+	var rate model.ExchangeRate
+	err := row.Scan(&rate.ID, &rate.From, &rate.To, &rate.Date, &rate.Rate, &rate.CreatedAt)
+	return rate, err
+}
+
+// ListExchangeRates 获取全部汇率
+func (r *SQLiteRepository) ListExchangeRates() ([]model.ExchangeRate, error) {
+	rows, err := r.db.Query(rateSelect + " ORDER BY from_currency ASC, to_currency ASC, date DESC")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rates []model.ExchangeRate
+	for rows.Next() {
+		rate, err := scanRate(rows)
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+
+	return rates, rows.Err()
+}
+
+// SaveExchangeRate 保存汇率，同一币种对同一天已有汇率时覆盖
+func (r *SQLiteRepository) SaveExchangeRate(rate *model.ExchangeRate) error {
+	return saveExchangeRate(r.db, rate)
+}
+
+// SaveExchangeRates 在一个事务中保存多条汇率
+func (r *SQLiteRepository) SaveExchangeRates(rates []model.ExchangeRate) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for i := range rates {
+		if err := saveExchangeRate(tx, &rates[i]); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// rateExecer 兼容 *sql.DB 与 *sql.Tx
+type rateExecer interface {
+	execer
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// saveExchangeRate 插入或覆盖汇率并回填 ID
+func saveExchangeRate(db rateExecer, rate *model.ExchangeRate) error {
+	_, err := db.Exec(`
+		INSERT INTO exchange_rates (from_currency, to_currency, date, rate) VALUES (?, ?, ?, ?)
+		ON CONFLICT(from_currency, to_currency, date) DO UPDATE SET rate = excluded.rate
+	`, rate.From, rate.To, rate.Date, rate.Rate)
+	if err != nil {
+		return err
+	}
+
+	// 覆盖已有汇率时 LastInsertId 不可靠，按唯一键回查
+	return db.QueryRow(
+		"SELECT id FROM exchange_rates WHERE from_currency = ? AND to_currency = ? AND date = ?",
+		rate.From, rate.To, rate.Date,
+	).Scan(&rate.ID)
+}
+
+// DeleteExchangeRate 删除汇率
+func (r *SQLiteRepository) DeleteExchangeRate(id int64) error {
+	result, err := r.db.Exec("DELETE FROM exchange_rates WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, apperrors.ErrExchangeRateNotFound)
+}
+
+// FindExchangeRate 查找 date 当天或之前最近一天的汇率
+func (r *SQLiteRepository) FindExchangeRate(from, to, date string) (*model.ExchangeRate, error) {
+	rate, err := scanRate(r.db.QueryRow(rateSelect+`
+		WHERE from_currency = ? AND to_currency = ? AND date <= ?
+		ORDER BY date DESC
+		LIMIT 1
+	`, from, to, date))
+	if err == sql.ErrNoRows {
+		return nil, apperrors.ErrExchangeRateNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rate, nil
+}
@@ -0,0 +1,285 @@
+package repository
+
+import (
+	"database/sql"
+
+	apperrors "dog-view/internal/errors"
+	"dog-view/internal/model"
+)
+
+// ============ 周期记账 ============
+
+const ruleSelect = `
+	SELECT id, name, frequency, interval, day_of_month, start_date, end_date, max_count, skip_weekends,
+		amount, currency, type, category_id, account_id, note, active, created_at
+	FROM recurring_rules`
+
+func scanRule(row rowScanner) (model.RecurringRule, error) {
+	var rule model.RecurringRule
+	var note sql.NullString
+	err := row.Scan(
+		&rule.ID, &rule.Name, &rule.Frequency, &rule.Interval, &rule.DayOfMonth,
+		&rule.StartDate, &rule.EndDate, &rule.MaxCount, &rule.SkipWeekends,
+		&rule.Amount.Minor, &rule.Amount.Currency, &rule.Type, &rule.CategoryID, &rule.AccountID,
+		&note, &rule.Active, &rule.CreatedAt,
+	)
+	rule.Note = note.String
+	return rule, err
+}
+
+// ListRecurringRules 获取全部周期规则
+func (r *SQLiteRepository) ListRecurringRules() ([]model.RecurringRule, error) {
+	rows, err := r.db.Query(ruleSelect + " ORDER BY id ASC")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []model.RecurringRule
+	for rows.Next() {
+		rule, err := scanRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, rows.Err()
+}
+
+// GetRecurringRule 根据 ID 获取周期规则
+func (r *SQLiteRepository) GetRecurringRule(id int64) (*model.RecurringRule, error) {
+	rule, err := scanRule(r.db.QueryRow(ruleSelect+" WHERE id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, apperrors.ErrRuleNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// CreateRecurringRule 创建周期规则
+func (r *SQLiteRepository) CreateRecurringRule(rule *model.RecurringRule) error {
+	ch, err := r.beginChange(model.ActionCreateRule)
+	if err != nil {
+		return err
+	}
+	defer ch.rollback()
+
+	result, err := ch.tx.Exec(`
+		INSERT INTO recurring_rules (name, frequency, interval, day_of_month, start_date, end_date, max_count,
+			skip_weekends, amount, currency, type, category_id, account_id, note, active)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		rule.Name, rule.Frequency, rule.Interval, rule.DayOfMonth, rule.StartDate, rule.EndDate, rule.MaxCount,
+		rule.SkipWeekends, rule.Amount.Minor, rule.Amount.Currency, rule.Type, rule.CategoryID, rule.AccountID,
+		rule.Note, rule.Active,
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	ch.created(model.EntityRule, id)
+	if err := ch.commit(); err != nil {
+		return err
+	}
+	rule.ID = id
+	return nil
+}
+
+// UpdateRecurringRule 更新周期规则
+func (r *SQLiteRepository) UpdateRecurringRule(rule *model.RecurringRule) error {
+	ch, err := r.beginChange(model.ActionUpdateRule)
+	if err != nil {
+		return err
+	}
+	defer ch.rollback()
+
+	if err := ch.track(model.EntityRule, rule.ID); err != nil {
+		return err
+	}
+	result, err := ch.tx.Exec(`
+		UPDATE recurring_rules SET name = ?, frequency = ?, interval = ?, day_of_month = ?, start_date = ?,
+			end_date = ?, max_count = ?, skip_weekends = ?, amount = ?, currency = ?, type = ?,
+			category_id = ?, account_id = ?, note = ?, active = ?
+		WHERE id = ?`,
+		rule.Name, rule.Frequency, rule.Interval, rule.DayOfMonth, rule.StartDate, rule.EndDate, rule.MaxCount,
+		rule.SkipWeekends, rule.Amount.Minor, rule.Amount.Currency, rule.Type, rule.CategoryID, rule.AccountID,
+		rule.Note, rule.Active, rule.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if err := requireAffected(result, apperrors.ErrRuleNotFound); err != nil {
+		return err
+	}
+	return ch.commit()
+}
+
+// DeleteRecurringRule 删除周期规则及其发生记录，撤销时一并恢复，已入账的发生不会重复入账
+func (r *SQLiteRepository) DeleteRecurringRule(id int64) error {
+	ch, err := r.beginChange(model.ActionDeleteRule)
+	if err != nil {
+		return err
+	}
+	defer ch.rollback()
+	tx := ch.tx
+
+	if err := ch.track(model.EntityRule, id); err != nil {
+		return err
+	}
+	if err := ch.trackQuery(model.EntityOccurrence, "SELECT id FROM recurring_occurrences WHERE rule_id = ?", id); err != nil {
+		return err
+	}
+	result, err := tx.Exec("DELETE FROM recurring_rules WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	if err := requireAffected(result, apperrors.ErrRuleNotFound); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM recurring_occurrences WHERE rule_id = ?", id); err != nil {
+		return err
+	}
+
+	return ch.commit()
+}
+
+const occurrenceSelect = `
+	SELECT id, rule_id, date, status, record_id, amount, currency, note
+	FROM recurring_occurrences`
+
+func scanOccurrence(row rowScanner) (model.Occurrence, error) {
+	var o model.Occurrence
+	var amount sql.NullInt64
+	var currency, note sql.NullString
+	err := row.Scan(&o.ID, &o.RuleID, &o.Date, &o.Status, &o.RecordID, &amount, &currency, &note)
+	if amount.Valid {
+		o.Amount = &model.Money{Minor: amount.Int64, Currency: currency.String}
+	}
+	o.Note = note.String
+	return o, err
+}
+
+// ListOccurrences 获取规则已保存的发生记录
+func (r *SQLiteRepository) ListOccurrences(ruleID int64) ([]model.Occurrence, error) {
+	rows, err := r.db.Query(occurrenceSelect+" WHERE rule_id = ? ORDER BY date ASC", ruleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var occurrences []model.Occurrence
+	for rows.Next() {
+		o, err := scanOccurrence(rows)
+		if err != nil {
+			return nil, err
+		}
+		occurrences = append(occurrences, o)
+	}
+
+	return occurrences, rows.Err()
+}
+
+// occurrenceAmount 拆分单次修改的金额，未修改时写入 NULL
+func occurrenceAmount(o *model.Occurrence) (sql.NullInt64, sql.NullString) {
+	if o.Amount == nil {
+		return sql.NullInt64{}, sql.NullString{}
+	}
+	return sql.NullInt64{Int64: o.Amount.Minor, Valid: true}, sql.NullString{String: o.Amount.Currency, Valid: true}
+}
+
+// SaveOccurrence 保存单次发生的跳过或修改
+func (r *SQLiteRepository) SaveOccurrence(o *model.Occurrence) error {
+	ch, err := r.beginChange(model.ActionSaveOccurrence)
+	if err != nil {
+		return err
+	}
+	defer ch.rollback()
+	tx := ch.tx
+
+	var existing int64
+	var status string
+	err = tx.QueryRow(
+		"SELECT id, status FROM recurring_occurrences WHERE rule_id = ? AND date = ?", o.RuleID, o.Date,
+	).Scan(&existing, &status)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if status == model.OccurrencePosted {
+		return apperrors.ErrOccurrencePosted
+	}
+	if existing != 0 {
+		if err := ch.track(model.EntityOccurrence, existing); err != nil {
+			return err
+		}
+	}
+
+	amount, currency := occurrenceAmount(o)
+	_, err = tx.Exec(`
+		INSERT INTO recurring_occurrences (rule_id, date, status, amount, currency, note) VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(rule_id, date) DO UPDATE SET
+			status = excluded.status, amount = excluded.amount, currency = excluded.currency, note = excluded.note
+	`, o.RuleID, o.Date, o.Status, amount, currency, o.Note)
+	if err != nil {
+		return err
+	}
+	if err := tx.QueryRow(
+		"SELECT id FROM recurring_occurrences WHERE rule_id = ? AND date = ?", o.RuleID, o.Date,
+	).Scan(&o.ID); err != nil {
+		return err
+	}
+	if existing == 0 {
+		ch.created(model.EntityOccurrence, o.ID)
+	}
+
+	return ch.commit()
+}
+
+// PostOccurrence 创建记录并将发生标记为已入账
+func (r *SQLiteRepository) PostOccurrence(o *model.Occurrence, rec *model.Record) (bool, error) {
+	ch, err := r.beginChange(model.ActionPostRecurring)
+	if err != nil {
+		return false, err
+	}
+	defer ch.rollback()
+	tx := ch.tx
+
+	var status string
+	err = tx.QueryRow(
+		"SELECT status FROM recurring_occurrences WHERE rule_id = ? AND date = ?", o.RuleID, o.Date,
+	).Scan(&status)
+	if err != nil && err != sql.ErrNoRows {
+		return false, err
+	}
+	if status == model.OccurrencePosted || status == model.OccurrenceSkipped {
+		return false, nil
+	}
+
+	if err := insertRecord(tx, rec); err != nil {
+		return false, err
+	}
+	// 发生的状态不记入变更日志：撤销自动入账只删除记录，该次发生不会再次入账
+	ch.created(model.EntityRecord, rec.ID)
+
+	amount, currency := occurrenceAmount(o)
+	_, err = tx.Exec(`
+		INSERT INTO recurring_occurrences (rule_id, date, status, record_id, amount, currency, note)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(rule_id, date) DO UPDATE SET status = excluded.status, record_id = excluded.record_id
+	`, o.RuleID, o.Date, model.OccurrencePosted, rec.ID, amount, currency, o.Note)
+	if err != nil {
+		return false, err
+	}
+
+	if err := ch.commit(); err != nil {
+		return false, err
+	}
+	o.Status = model.OccurrencePosted
+	o.RecordID = rec.ID
+	return true, nil
+}
@@ -0,0 +1,254 @@
+package repository
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"dog-view/internal/model"
+)
+
+// ============ 备注搜索 ============
+
+// trigram 分词器按 3 个字符切分，更短的词无法走全文索引
+const minTrigramTerm = 3
+
+// 摘要最多保留的字符数（trigram 分词下约等于 FTS5 的词数），以及首个命中位置之前保留的字符数
+const (
+	snippetWidth   = 32
+	snippetContext = 10
+)
+
+// initSearchIndex 建立备注的 FTS5 全文索引并用触发器与 records 保持同步。
+// 索引不放在迁移中：FTS5 需要 sqlite_fts5 构建标签，不含 FTS5 的构建打开同一数据库时
+// 只移除触发器（否则写入记录会失败），搜索退回 LIKE；再次以含 FTS5 的构建打开时重建索引
+func (r *SQLiteRepository) initSearchIndex() error {
+	var enabled bool
+	if err := r.db.QueryRow("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&enabled); err != nil {
+		return err
+	}
+	if !enabled {
+		r.fts = false
+		_, err := r.db.Exec(`
+		DROP TRIGGER IF EXISTS records_fts_ai;
+		DROP TRIGGER IF EXISTS records_fts_ad;
+		DROP TRIGGER IF EXISTS records_fts_au;
+		`)
+		return err
+	}
+
+	var triggers int
+	err := r.db.QueryRow(
+		"SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name LIKE 'records_fts_%'",
+	).Scan(&triggers)
+	if err != nil {
+		return err
+	}
+	if triggers == 3 {
+		r.fts = true
+		return nil
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+	CREATE VIRTUAL TABLE IF NOT EXISTS records_fts USING fts5(
+		note, content = 'records', content_rowid = 'id', tokenize = 'trigram'
+	);
+
+	DROP TRIGGER IF EXISTS records_fts_ai;
+	DROP TRIGGER IF EXISTS records_fts_ad;
+	DROP TRIGGER IF EXISTS records_fts_au;
+
+	CREATE TRIGGER records_fts_ai AFTER INSERT ON records BEGIN
+		INSERT INTO records_fts (rowid, note) VALUES (new.id, new.note);
+	END;
+	CREATE TRIGGER records_fts_ad AFTER DELETE ON records BEGIN
+		INSERT INTO records_fts (records_fts, rowid, note) VALUES ('delete', old.id, old.note);
+	END;
+	CREATE TRIGGER records_fts_au AFTER UPDATE OF note ON records BEGIN
+		INSERT INTO records_fts (records_fts, rowid, note) VALUES ('delete', old.id, old.note);
+		INSERT INTO records_fts (rowid, note) VALUES (new.id, new.note);
+	END;
+
+	INSERT INTO records_fts (records_fts) VALUES ('rebuild');
+	`)
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	r.fts = true
+	return nil
+}
+
+// SearchRecords 搜索备注，词都不短于 3 个字符时走全文索引按相关度排序，否则按日期倒序
+func (r *SQLiteRepository) SearchRecords(terms []string, limit int) ([]model.SearchResult, error) {
+	if len(terms) == 0 {
+		return []model.SearchResult{}, nil
+	}
+
+	useIndex := r.fts
+	for _, term := range terms {
+		if utf8.RuneCountInString(term) < minTrigramTerm {
+			useIndex = false
+		}
+	}
+	if useIndex {
+		return r.searchIndex(terms, limit)
+	}
+	return r.searchLike(terms, limit)
+}
+
+// searchIndex 通过 FTS5 检索，结果按 bm25 相关度排序
+func (r *SQLiteRepository) searchIndex(terms []string, limit int) ([]model.SearchResult, error) {
+	// 每个词作为短语加引号，避免被解析为 FTS5 查询语法；多个短语之间为 AND
+	phrases := make([]string, len(terms))
+	for i, term := range terms {
+		phrases[i] = `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+	}
+
+	rows, err := r.db.Query(`
+		SELECT rowid, snippet(records_fts, 0, ?, ?, '…', ?)
+		FROM records_fts
+		WHERE records_fts MATCH ? AND rowid IN (SELECT id FROM active_records)
+		ORDER BY rank
+		LIMIT ?
+	`, model.HighlightStart, model.HighlightEnd, snippetWidth, strings.Join(phrases, " "), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	snippets := make(map[int64]string)
+	for rows.Next() {
+		var id int64
+		var snippet string
+		if err := rows.Scan(&id, &snippet); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+		snippets[id] = snippet
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return []model.SearchResult{}, nil
+	}
+
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	records, err := r.queryRecords(recordSelect+" WHERE r.id IN ("+placeholders(len(ids))+")", args...)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]model.Record, len(records))
+	for _, rec := range records {
+		byID[rec.ID] = rec
+	}
+
+	results := make([]model.SearchResult, 0, len(ids))
+	for _, id := range ids {
+		if rec, ok := byID[id]; ok {
+			results = append(results, model.SearchResult{Record: rec, Snippet: snippets[id]})
+		}
+	}
+	return results, nil
+}
+
+// searchLike 用 LIKE 逐条匹配，摘要在 Go 中生成
+func (r *SQLiteRepository) searchLike(terms []string, limit int) ([]model.SearchResult, error) {
+	where := make([]string, len(terms))
+	args := make([]interface{}, 0, len(terms)+1)
+	for i, term := range terms {
+		where[i] = `r.note LIKE ? ESCAPE '\'`
+		args = append(args, "%"+likeEscaper.Replace(term)+"%")
+	}
+	args = append(args, limit)
+
+	records, err := r.queryRecords(
+		recordSelect+" WHERE "+strings.Join(where, " AND ")+" ORDER BY r.date DESC, r.created_at DESC, r.id DESC LIMIT ?",
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]model.SearchResult, 0, len(records))
+	for _, rec := range records {
+		results = append(results, model.SearchResult{Record: rec, Snippet: snippet(rec.Note, terms)})
+	}
+	return results, nil
+}
+
+// lowerRunes 逐字符转小写，保证与原文按下标一一对应
+func lowerRunes(s string) []rune {
+	runes := []rune(s)
+	for i, c := range runes {
+		runes[i] = unicode.ToLower(c)
+	}
+	return runes
+}
+
+// snippet 截取首个命中位置附近的片段，并用高亮标记包围所有命中的文本
+func snippet(note string, terms []string) string {
+	text := []rune(note)
+	lower := lowerRunes(note)
+	marked := make([]bool, len(text))
+	first := -1
+
+	for _, term := range terms {
+		t := lowerRunes(term)
+		if len(t) == 0 {
+			continue
+		}
+		for i := 0; i+len(t) <= len(lower); i++ {
+			if string(lower[i:i+len(t)]) != string(t) {
+				continue
+			}
+			for j := i; j < i+len(t); j++ {
+				marked[j] = true
+			}
+			if first < 0 || i < first {
+				first = i
+			}
+		}
+	}
+
+	start := 0
+	if first > snippetContext {
+		start = first - snippetContext
+	}
+	end := start + snippetWidth
+	if end > len(text) {
+		end = len(text)
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	for i := start; i < end; i++ {
+		if marked[i] && (i == start || !marked[i-1]) {
+			b.WriteString(model.HighlightStart)
+		}
+		b.WriteRune(text[i])
+		if marked[i] && (i == end-1 || !marked[i+1]) {
+			b.WriteString(model.HighlightEnd)
+		}
+	}
+	if end < len(text) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
@@ -0,0 +1,236 @@
+package repository
+
+import (
+	"sort"
+
+	apperrors "dog-view/internal/errors"
+	"dog-view/internal/model"
+)
+
+// ============ 标签 ============
+
+// ListTags 获取全部标签
+func (r *SQLiteRepository) ListTags() ([]model.Tag, error) {
+	rows, err := r.db.Query("SELECT id, name, color, created_at FROM tags ORDER BY name ASC, id ASC")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []model.Tag
+	for rows.Next() {
+		var t model.Tag
+		if err := rows.Scan(&t.ID, &t.Name, &t.Color, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		tags = append(tags, t)
+	}
+
+	return tags, rows.Err()
+}
+
+// fillTags 按 ID 补全记录的标签名称和颜色，并按名称排序
+func (r *SQLiteRepository) fillTags(records []model.Record) error {
+	tagged := false
+	for _, rec := range records {
+		if len(rec.Tags) > 0 {
+			tagged = true
+			break
+		}
+	}
+	if !tagged {
+		return nil
+	}
+
+	tags, err := r.ListTags()
+	if err != nil {
+		return err
+	}
+	byID := make(map[int64]model.Tag, len(tags))
+	for _, t := range tags {
+		byID[t.ID] = t
+	}
+
+	for i := range records {
+		for j, t := range records[i].Tags {
+			records[i].Tags[j] = byID[t.ID]
+		}
+		sortTags(records[i].Tags)
+	}
+	return nil
+}
+
+// sortTags 标签按名称排序，与 ListTags 一致
+func sortTags(tags []model.Tag) {
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Name != tags[j].Name {
+			return tags[i].Name < tags[j].Name
+		}
+		return tags[i].ID < tags[j].ID
+	})
+}
+
+// CreateTag 创建标签
+func (r *SQLiteRepository) CreateTag(tag *model.Tag) error {
+	ch, err := r.beginChange(model.ActionCreateTag)
+	if err != nil {
+		return err
+	}
+	defer ch.rollback()
+
+	id, err := insertTag(ch.tx, tag)
+	if err != nil {
+		return err
+	}
+	ch.created(model.EntityTag, id)
+	if err := ch.commit(); err != nil {
+		return err
+	}
+	tag.ID = id
+	return nil
+}
+
+// insertTag 插入标签并返回 ID，名称重复时返回 ErrDuplicateTag
+func insertTag(db execer, tag *model.Tag) (int64, error) {
+	result, err := db.Exec("INSERT INTO tags (name, color) VALUES (?, ?)", tag.Name, tag.Color)
+	if isUniqueViolation(err) {
+		return 0, apperrors.ErrDuplicateTag
+	}
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// UpdateTag 更新标签名称和颜色
+func (r *SQLiteRepository) UpdateTag(tag *model.Tag) error {
+	ch, err := r.beginChange(model.ActionUpdateTag)
+	if err != nil {
+		return err
+	}
+	defer ch.rollback()
+
+	if err := ch.track(model.EntityTag, tag.ID); err != nil {
+		return err
+	}
+	result, err := ch.tx.Exec("UPDATE tags SET name = ?, color = ? WHERE id = ?", tag.Name, tag.Color, tag.ID)
+	if isUniqueViolation(err) {
+		return apperrors.ErrDuplicateTag
+	}
+	if err != nil {
+		return err
+	}
+	if err := requireAffected(result, apperrors.ErrTagNotFound); err != nil {
+		return err
+	}
+	return ch.commit()
+}
+
+// DeleteTag 删除标签并解除与记录的关联
+func (r *SQLiteRepository) DeleteTag(id int64) error {
+	ch, err := r.beginChange(model.ActionDeleteTag)
+	if err != nil {
+		return err
+	}
+	defer ch.rollback()
+	tx := ch.tx
+
+	if err := ch.track(model.EntityTag, id); err != nil {
+		return err
+	}
+	if err := ch.trackQuery(model.EntityRecord, "SELECT record_id FROM record_tags WHERE tag_id = ?", id); err != nil {
+		return err
+	}
+	result, err := tx.Exec("DELETE FROM tags WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	if err := requireAffected(result, apperrors.ErrTagNotFound); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM record_tags WHERE tag_id = ?", id); err != nil {
+		return err
+	}
+
+	return ch.commit()
+}
+
+// SetRecordTags 替换记录的标签
+func (r *SQLiteRepository) SetRecordTags(recordID int64, tagIDs []int64) error {
+	ch, err := r.beginChange(model.ActionSetRecordTags)
+	if err != nil {
+		return err
+	}
+	defer ch.rollback()
+	tx := ch.tx
+
+	var exists int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM active_records WHERE id = ?", recordID).Scan(&exists); err != nil {
+		return err
+	}
+	if exists == 0 {
+		return apperrors.ErrRecordNotFound
+	}
+	if err := ch.track(model.EntityRecord, recordID); err != nil {
+		return err
+	}
+
+	if err := replaceRecordTags(tx, recordID, tagIDs); err != nil {
+		return err
+	}
+	return ch.commit()
+}
+
+// replaceRecordTags 将记录的标签关联替换为 tagIDs，需在事务中调用
+func replaceRecordTags(db execer, recordID int64, tagIDs []int64) error {
+	if _, err := db.Exec("DELETE FROM record_tags WHERE record_id = ?", recordID); err != nil {
+		return err
+	}
+	for _, tagID := range tagIDs {
+		_, err := db.Exec("INSERT OR IGNORE INTO record_tags (record_id, tag_id) VALUES (?, ?)", recordID, tagID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// uniqueIDs 去除重复的 ID
+func uniqueIDs(ids []int64) []int64 {
+	seen := make(map[int64]bool, len(ids))
+	var out []int64
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// GetTagTotals 获取日期区间 [startDate, endDate) 内按日期、类型、标签和币种分组的收支合计
+func (r *SQLiteRepository) GetTagTotals(startDate, endDate string) ([]model.StatTotal, error) {
+	rows, err := r.db.Query(`
+		SELECT r.date, r.type, rt.tag_id, r.currency, SUM(r.amount)
+		FROM active_records r
+		JOIN record_tags rt ON rt.record_id = r.id
+		WHERE r.type IN ('income', 'expense') AND r.date >= ? AND r.date < ?
+		GROUP BY r.date, r.type, rt.tag_id, r.currency
+		ORDER BY r.date ASC, r.type ASC, rt.tag_id ASC, r.currency ASC
+	`, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var totals []model.StatTotal
+	for rows.Next() {
+		var t model.StatTotal
+		if err := rows.Scan(&t.Date, &t.Type, &t.TagID, &t.Amount.Currency, &t.Amount.Minor); err != nil {
+			return nil, err
+		}
+		totals = append(totals, t)
+	}
+
+	return totals, rows.Err()
+}
@@ -0,0 +1,267 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	apperrors "dog-view/internal/errors"
+	"dog-view/internal/model"
+)
+
+// ============ 回收站 ============
+
+// ListDeletedRecords 获取回收站中的记录，最近删除的在前
+func (r *SQLiteRepository) ListDeletedRecords() ([]model.Record, error) {
+	return r.queryRecords(recordColumns + `
+		FROM records r
+		LEFT JOIN categories c ON r.category_id = c.id
+		WHERE r.deleted_at IS NOT NULL
+		ORDER BY r.deleted_at DESC, r.id DESC`)
+}
+
+// ListDeletedCategories 获取回收站中的分类，最近删除的在前
+func (r *SQLiteRepository) ListDeletedCategories() ([]model.Category, error) {
+	return r.queryCategories(
+		categoryColumns + " FROM categories WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC, id DESC",
+	)
+}
+
+// restoreCategoryTx 恢复分类及其上级分类（已恢复的不受影响），
+// 与现有分类重名时返回 ErrDuplicateCategory
+func restoreCategoryTx(ch *changeRecorder, id int64) error {
+	tx := ch.tx
+	var parentID int64
+	err := tx.QueryRow("SELECT parent_id FROM categories WHERE id = ?", id).Scan(&parentID)
+	if err == sql.ErrNoRows {
+		return apperrors.ErrCategoryNotFound
+	}
+	if err != nil {
+		return err
+	}
+	for _, cid := range []int64{id, parentID} {
+		if cid == 0 {
+			continue
+		}
+		if err := ch.track(model.EntityCategory, cid); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(
+		"UPDATE categories SET deleted_at = NULL WHERE id IN (?, ?) AND deleted_at IS NOT NULL", id, parentID,
+	)
+	if isUniqueViolation(err) {
+		return apperrors.ErrDuplicateCategory
+	}
+	return err
+}
+
+// RestoreRecord 从回收站恢复记录，其分类也在回收站中时一并恢复
+func (r *SQLiteRepository) RestoreRecord(id int64) error {
+	ch, err := r.beginChange(model.ActionRestoreRecord)
+	if err != nil {
+		return err
+	}
+	defer ch.rollback()
+	tx := ch.tx
+
+	var categoryID int64
+	err = tx.QueryRow(
+		"SELECT category_id FROM records WHERE id = ? AND deleted_at IS NOT NULL", id,
+	).Scan(&categoryID)
+	if err == sql.ErrNoRows {
+		return apperrors.ErrRecordNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if categoryID != 0 {
+		if err := restoreCategoryTx(ch, categoryID); err != nil && err != apperrors.ErrCategoryNotFound {
+			return err
+		}
+	}
+	if err := ch.track(model.EntityRecord, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("UPDATE records SET deleted_at = NULL WHERE id = ?", id); err != nil {
+		return err
+	}
+
+	return ch.commit()
+}
+
+// RestoreCategory 从回收站恢复分类，其上级分类也在回收站中时一并恢复
+func (r *SQLiteRepository) RestoreCategory(id int64) error {
+	ch, err := r.beginChange(model.ActionRestoreCategory)
+	if err != nil {
+		return err
+	}
+	defer ch.rollback()
+	tx := ch.tx
+
+	var count int
+	err = tx.QueryRow("SELECT COUNT(*) FROM categories WHERE id = ? AND deleted_at IS NOT NULL", id).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return apperrors.ErrCategoryNotFound
+	}
+	if err := restoreCategoryTx(ch, id); err != nil {
+		return err
+	}
+
+	return ch.commit()
+}
+
+// purgeRecordsTx 彻底删除满足条件的回收站记录及其标签关联，返回删除的记录数
+func purgeRecordsTx(ch *changeRecorder, where string, args ...interface{}) (int, error) {
+	tx := ch.tx
+	cond := "deleted_at IS NOT NULL AND " + where
+	if err := ch.trackQuery(model.EntityRecord, "SELECT id FROM records WHERE "+cond, args...); err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec("DELETE FROM record_tags WHERE record_id IN (SELECT id FROM records WHERE "+cond+")", args...); err != nil {
+		return 0, err
+	}
+	result, err := tx.Exec("DELETE FROM records WHERE "+cond, args...)
+	if err != nil {
+		return 0, err
+	}
+	n, err := result.RowsAffected()
+	return int(n), err
+}
+
+// purgeCategoryTx 彻底删除分类及其预算
+func purgeCategoryTx(ch *changeRecorder, id int64) error {
+	tx := ch.tx
+	if err := ch.track(model.EntityCategory, id); err != nil {
+		return err
+	}
+	if err := ch.trackQuery(model.EntityBudget, "SELECT id FROM budgets WHERE category_id = ?", id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM budgets WHERE category_id = ?", id); err != nil {
+		return err
+	}
+	_, err := tx.Exec("DELETE FROM categories WHERE id = ?", id)
+	return err
+}
+
+// PurgeRecord 彻底删除回收站中的记录
+func (r *SQLiteRepository) PurgeRecord(id int64) error {
+	ch, err := r.beginChange(model.ActionPurgeRecord)
+	if err != nil {
+		return err
+	}
+	defer ch.rollback()
+
+	n, err := purgeRecordsTx(ch, "id = ?", id)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return apperrors.ErrRecordNotFound
+	}
+
+	return ch.commit()
+}
+
+// PurgeCategory 彻底删除回收站中的分类
+func (r *SQLiteRepository) PurgeCategory(id int64) error {
+	ch, err := r.beginChange(model.ActionPurgeCategory)
+	if err != nil {
+		return err
+	}
+	defer ch.rollback()
+	tx := ch.tx
+
+	var count int
+	err = tx.QueryRow("SELECT COUNT(*) FROM categories WHERE id = ? AND deleted_at IS NOT NULL", id).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return apperrors.ErrCategoryNotFound
+	}
+
+	// 回收站中的记录和子分类同样保留着引用
+	if err := tx.QueryRow("SELECT COUNT(*) FROM records WHERE category_id = ?", id).Scan(&count); err != nil {
+		return err
+	}
+	if count > 0 {
+		return apperrors.ErrCategoryInUse
+	}
+	if err := tx.QueryRow("SELECT COUNT(*) FROM categories WHERE parent_id = ?", id).Scan(&count); err != nil {
+		return err
+	}
+	if count > 0 {
+		return apperrors.ErrCategoryHasChildren
+	}
+
+	if err := purgeCategoryTx(ch, id); err != nil {
+		return err
+	}
+	return ch.commit()
+}
+
+// PurgeTrash 彻底删除 before 及之前移入回收站的记录和分类。
+// 先删除记录，再逐轮删除不再被引用的分类（子分类删除后其上级分类才能删除）
+func (r *SQLiteRepository) PurgeTrash(before time.Time) (model.PurgeResult, error) {
+	var result model.PurgeResult
+	cutoff := before.UTC().Format(timestampLayout)
+
+	ch, err := r.beginChange(model.ActionPurgeTrash)
+	if err != nil {
+		return result, err
+	}
+	defer ch.rollback()
+	tx := ch.tx
+
+	if result.Records, err = purgeRecordsTx(ch, "deleted_at <= ?", cutoff); err != nil {
+		return result, err
+	}
+
+	for {
+		ids, err := queryIDs(tx, `
+			SELECT id FROM categories c
+			WHERE deleted_at IS NOT NULL AND deleted_at <= ?
+			  AND NOT EXISTS (SELECT 1 FROM records WHERE category_id = c.id)
+			  AND NOT EXISTS (SELECT 1 FROM categories WHERE parent_id = c.id)
+		`, cutoff)
+		if err != nil {
+			return result, err
+		}
+		if len(ids) == 0 {
+			break
+		}
+		for _, id := range ids {
+			if err := purgeCategoryTx(ch, id); err != nil {
+				return result, err
+			}
+		}
+		result.Categories += len(ids)
+	}
+
+	return result, ch.commit()
+}
+
+// queryIDs 执行只返回 ID 列的查询
+func queryIDs(tx *sql.Tx, query string, args ...interface{}) ([]int64, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
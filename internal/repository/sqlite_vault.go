@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"sync"
+
+	apperrors "dog-view/internal/errors"
+	"dog-view/internal/vault"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// ============ 加密账本 ============
+
+// vaultFile 加密账本的磁盘文件。解密后的数据只存在于内存数据库中，
+// 每次提交后由后台协程写回磁盘，写入期间的多次提交合并为下一次写入
+type vaultFile struct {
+	key     *vault.Key
+	pending chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+
+	mu     sync.Mutex // 串行化写回
+	closed bool
+}
+
+// OpenRepository 打开账本数据库：加密的账本用 passphrase 解密，未提供密码时返回 ErrLedgerLocked
+func OpenRepository(dbPath, passphrase string) (*SQLiteRepository, error) {
+	encrypted, err := vault.IsEncrypted(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("打开数据库失败: %w", err)
+	}
+	if !encrypted {
+		return OpenSQLiteRepository(dbPath)
+	}
+	if passphrase == "" {
+		return nil, apperrors.ErrLedgerLocked
+	}
+	return OpenEncryptedRepository(dbPath, passphrase)
+}
+
+// OpenEncryptedRepository 解密账本文件到内存数据库并执行迁移，密码错误时返回 ErrWrongPassphrase
+func OpenEncryptedRepository(dbPath, passphrase string) (*SQLiteRepository, error) {
+	image, key, err := vault.ReadFile(dbPath, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &vaultFile{
+		key:     key,
+		pending: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	db, err := openMemoryDB(image, v.notify)
+	if err != nil {
+		return nil, fmt.Errorf("打开数据库失败: %w", err)
+	}
+
+	repo := &SQLiteRepository{db: db, path: dbPath, vault: v}
+	if err := repo.InitSchema(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("初始化数据库表失败: %w", err)
+	}
+	// 迁移和默认数据在后台协程启动前提交，需要在这里写回
+	if err := repo.flush(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	go repo.runFlusher()
+	return repo, nil
+}
+
+// openMemoryDB 将数据库映像载入只有一个连接的内存数据库，onCommit 在每次提交时调用。
+// Deserialize 得到的数据库大小固定，需再用备份 API 复制到可增长的内存数据库中；
+// 连接池保持唯一的连接不被回收，否则内存中的数据会丢失
+func openMemoryDB(image []byte, onCommit func()) (*sql.DB, error) {
+	src, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+	db.SetConnMaxLifetime(0)
+	db.SetConnMaxIdleTime(0)
+
+	ctx := context.Background()
+	srcConn, err := src.Conn(ctx)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	defer srcConn.Close()
+	dstConn, err := db.Conn(ctx)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	defer dstConn.Close()
+
+	err = srcConn.Raw(func(s interface{}) error {
+		srcLite := s.(*sqlite3.SQLiteConn)
+		if err := srcLite.Deserialize(image, "main"); err != nil {
+			return err
+		}
+		return dstConn.Raw(func(d interface{}) error {
+			dstLite := d.(*sqlite3.SQLiteConn)
+			bk, err := dstLite.Backup("main", srcLite, "main")
+			if err != nil {
+				return err
+			}
+			if _, err := bk.Step(-1); err != nil {
+				bk.Close()
+				return err
+			}
+			if err := bk.Finish(); err != nil {
+				return err
+			}
+			dstLite.RegisterCommitHook(func() int {
+				onCommit()
+				return 0
+			})
+			return nil
+		})
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// notify 请求写回，不阻塞提交
+func (v *vaultFile) notify() {
+	select {
+	case v.pending <- struct{}{}:
+	default:
+	}
+}
+
+// runFlusher 每次收到写回请求时写回磁盘，直到仓库关闭
+func (r *SQLiteRepository) runFlusher() {
+	defer close(r.vault.stopped)
+	for {
+		select {
+		case <-r.vault.done:
+			return
+		case <-r.vault.pending:
+			// 写回失败时保留内存中的数据，下次提交或关闭时重试
+			r.flush()
+		}
+	}
+}
+
+// flush 将内存数据库加密写回账本文件
+func (r *SQLiteRepository) flush() error {
+	v := r.vault
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	image, err := r.serialize()
+	if err != nil {
+		return fmt.Errorf("保存加密账本失败: %w", err)
+	}
+	if err := vault.WriteFile(r.path, image, v.key); err != nil {
+		return fmt.Errorf("保存加密账本失败: %w", err)
+	}
+	return nil
+}
+
+// serialize 返回数据库的完整映像
+func (r *SQLiteRepository) serialize() ([]byte, error) {
+	conn, err := r.db.Conn(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var image []byte
+	err = conn.Raw(func(c interface{}) error {
+		var err error
+		image, err = c.(*sqlite3.SQLiteConn).Serialize("main")
+		return err
+	})
+	return image, err
+}
+
+// closeVault 停止后台写回并最后写回一次，重复调用时不做任何操作
+func (r *SQLiteRepository) closeVault() error {
+	v := r.vault
+	v.mu.Lock()
+	if v.closed {
+		v.mu.Unlock()
+		return nil
+	}
+	v.closed = true
+	v.mu.Unlock()
+
+	close(v.done)
+	<-v.stopped
+	return r.flush()
+}
+
+// Encrypted 账本文件是否加密
+func (r *SQLiteRepository) Encrypted() bool {
+	return r.vault != nil
+}
+
+// backupEncrypted 将加密账本的映像用同一密钥加密写入 path，path 已存在时返回错误
+func (r *SQLiteRepository) backupEncrypted(path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("备份数据库失败: %s 已存在", path)
+	}
+	image, err := r.serialize()
+	if err != nil {
+		return fmt.Errorf("备份数据库失败: %w", err)
+	}
+	if err := vault.WriteFile(path, image, r.vault.key); err != nil {
+		return fmt.Errorf("备份数据库失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"strings"
+
+	apperrors "dog-view/internal/errors"
+	"dog-view/internal/model"
+	"dog-view/internal/repository"
+)
+
+type AccountService struct {
+	repo repository.Repository
+}
+
+func NewAccountService(repo repository.Repository) *AccountService {
+	return &AccountService{repo: repo}
+}
+
+// normalizeCurrency 补全并校验账户币种，账户币种决定其记录可使用的币种
+func normalizeCurrency(m model.Money) (model.Money, error) {
+	m = model.NewMoney(m.Minor, strings.ToUpper(strings.TrimSpace(m.Currency)))
+	if !model.ValidCurrency(m.Currency) {
+		return m, apperrors.ErrInvalidCurrency
+	}
+	return m, nil
+}
+
+func (s *AccountService) List() ([]model.Account, error) {
+	return s.repo.ListAccounts()
+}
+
+func (s *AccountService) Create(name, accountType, icon string, openingBalance model.Money) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return apperrors.ErrInvalidAccountName
+	}
+	if !model.ValidAccountType(accountType) {
+		return apperrors.ErrInvalidAccountType
+	}
+
+	openingBalance, err := normalizeCurrency(openingBalance)
+	if err != nil {
+		return err
+	}
+
+	maxOrder := 0
+	accounts, err := s.repo.ListAccounts()
+	if err != nil {
+		return err
+	}
+	for _, a := range accounts {
+		if a.SortOrder > maxOrder {
+			maxOrder = a.SortOrder
+		}
+	}
+
+	return s.repo.CreateAccount(&model.Account{
+		Name:           name,
+		Type:           accountType,
+		Icon:           icon,
+		OpeningBalance: openingBalance,
+		SortOrder:      maxOrder + 1,
+	})
+}
+
+// Update 修改账户。已有记录（含回收站中的记录）的账户不能修改币种，返回 ErrCurrencyMismatch
+func (s *AccountService) Update(id int64, name, accountType, icon string, openingBalance model.Money) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return apperrors.ErrInvalidAccountName
+	}
+	if !model.ValidAccountType(accountType) {
+		return apperrors.ErrInvalidAccountType
+	}
+	openingBalance, err := normalizeCurrency(openingBalance)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.UpdateAccount(&model.Account{
+		ID:             id,
+		Name:           name,
+		Type:           accountType,
+		Icon:           icon,
+		OpeningBalance: openingBalance,
+	})
+}
+
+func (s *AccountService) Delete(id int64) error {
+	return s.repo.DeleteAccount(id)
+}
+
+// Balances 返回所有账户截至 date（含当天）的余额
+func (s *AccountService) Balances(date string) ([]model.AccountBalance, error) {
+	end, err := nextDay(date)
+	if err != nil {
+		return nil, err
+	}
+
+	balances, err := s.repo.GetAccountBalances(end)
+	if err != nil {
+		return nil, err
+	}
+	for i := range balances {
+		balances[i].Date = date
+	}
+	return balances, nil
+}
+
+// Balance 返回单个账户截至 date（含当天）的余额
+func (s *AccountService) Balance(accountID int64, date string) (*model.AccountBalance, error) {
+	balances, err := s.Balances(date)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range balances {
+		if b.Account.ID == accountID {
+			return &b, nil
+		}
+	}
+	return nil, apperrors.ErrAccountNotFound
+}
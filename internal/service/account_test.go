@@ -0,0 +1,73 @@
+package service
+
+import (
+	"testing"
+
+	apperrors "dog-view/internal/errors"
+	"dog-view/internal/model"
+	"dog-view/internal/repository"
+)
+
+func TestAccountCurrencyLockedByRecords(t *testing.T) {
+	repo := repository.NewMemoryRepository()
+	settings := NewSettingsService(repo)
+	records := NewRecordService(repo, settings, NewRateService(repo, settings))
+	accounts := NewAccountService(repo)
+
+	if err := accounts.Create("现金", model.AccountCash, "", model.NewMoney(0, "CNY")); err != nil {
+		t.Fatal(err)
+	}
+	list, err := accounts.List()
+	if err != nil || len(list) != 1 {
+		t.Fatalf("List() = %v, %v", list, err)
+	}
+	cash := list[0]
+	// 没有记录时可以修改币种
+	if err := accounts.Update(cash.ID, "现金", model.AccountCash, "", model.NewMoney(0, "USD")); err != nil {
+		t.Fatal(err)
+	}
+
+	food := &model.Category{Name: "餐饮", Type: model.TypeExpense}
+	if err := repo.CreateCategory(food); err != nil {
+		t.Fatal(err)
+	}
+	if err := records.Create(model.NewMoney(100, "USD"), model.TypeExpense, food.ID, cash.ID, "", "2024-01-01", nil); err != nil {
+		t.Fatal(err)
+	}
+	err = accounts.Update(cash.ID, "钱包", model.AccountCash, "", model.NewMoney(0, "CNY"))
+	if err != apperrors.ErrCurrencyMismatch {
+		t.Fatalf("Update() = %v，期望 ErrCurrencyMismatch", err)
+	}
+	// 不修改币种时其他字段照常更新
+	if err := accounts.Update(cash.ID, "钱包", model.AccountCash, "", model.NewMoney(500, "usd")); err != nil {
+		t.Fatal(err)
+	}
+	got, err := repo.GetAccountByID(cash.ID)
+	if err != nil || got.Name != "钱包" || got.OpeningBalance != model.NewMoney(500, "USD") {
+		t.Fatalf("更新后账户为 %+v, %v", got, err)
+	}
+}
+
+func TestAccountCreateValidatesType(t *testing.T) {
+	repo := repository.NewMemoryRepository()
+	accounts := NewAccountService(repo)
+
+	for _, accountType := range []string{"", "savings"} {
+		if err := accounts.Create("现金", accountType, "", model.NewMoney(0, "CNY")); err != apperrors.ErrInvalidAccountType {
+			t.Fatalf("Create(type=%q) = %v，期望 ErrInvalidAccountType", accountType, err)
+		}
+	}
+	if err := accounts.Create("现金", model.AccountCash, "", model.NewMoney(0, "CNY")); err != nil {
+		t.Fatal(err)
+	}
+	if err := accounts.Create("信用卡", model.AccountCredit, "", model.NewMoney(0, "CNY")); err != nil {
+		t.Fatal(err)
+	}
+	list, err := accounts.List()
+	if err != nil || len(list) != 2 || list[1].SortOrder != list[0].SortOrder+1 {
+		t.Fatalf("List() = %+v, %v", list, err)
+	}
+	if err := accounts.Update(list[0].ID, "现金", "savings", "", list[0].OpeningBalance); err != apperrors.ErrInvalidAccountType {
+		t.Fatalf("Update() = %v，期望 ErrInvalidAccountType", err)
+	}
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"dog-view/internal/backup"
+	"dog-view/internal/model"
+	"dog-view/internal/vault"
+)
+
+// BackupSource 能将自身数据写成一致快照的仓库
+type BackupSource interface {
+	BackupTo(path string) error
+}
+
+type BackupService struct {
+	source   BackupSource
+	store    *backup.Store
+	settings *SettingsService
+}
+
+func NewBackupService(source BackupSource, store *backup.Store, settings *SettingsService) *BackupService {
+	return &BackupService{source: source, store: store, settings: settings}
+}
+
+// List 返回当前账本的全部备份，最新的在前
+func (s *BackupService) List() ([]model.Backup, error) {
+	return s.store.List()
+}
+
+// Create 立即备份，之后按保留策略删除多余的备份
+func (s *BackupService) Create(now time.Time) (*model.Backup, error) {
+	path, err := s.store.NewPath(now)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.source.BackupTo(path); err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := s.settings.BackupPolicy()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.store.Rotate(policy); err != nil {
+		return nil, err
+	}
+	return &model.Backup{Name: filepath.Base(path), Size: info.Size(), CreatedAt: now.Truncate(time.Second)}, nil
+}
+
+// AutoBackup 自动备份：启动时总是备份，之后距最近一次备份超过设定间隔时才备份；
+// 间隔为 0 时不自动备份。没有备份时返回 nil
+func (s *BackupService) AutoBackup(now time.Time, startup bool) (*model.Backup, error) {
+	policy, err := s.settings.BackupPolicy()
+	if err != nil || policy.IntervalHours == 0 {
+		return nil, err
+	}
+	if !startup {
+		backups, err := s.store.List()
+		if err != nil {
+			return nil, err
+		}
+		interval := time.Duration(policy.IntervalHours) * time.Hour
+		if len(backups) > 0 && now.Sub(backups[0].CreatedAt) < interval {
+			return nil, nil
+		}
+	}
+	return s.Create(now)
+}
+
+// CopyTo 将备份复制到 dst，供恢复时替换账本文件
+func (s *BackupService) CopyTo(name, dst string) error {
+	return s.store.CopyTo(name, dst)
+}
+
+// Rewrite 将全部备份改写为用 key 加密（key 为 nil 时解密），已加密的备份用 passphrase 解密。
+// 某份备份失败时继续处理其余备份，返回第一个错误
+func (s *BackupService) Rewrite(passphrase string, key *vault.Key) error {
+	backups, err := s.store.List()
+	if err != nil {
+		return err
+	}
+
+	var firstErr error
+	for _, b := range backups {
+		path, err := s.store.Path(b.Name)
+		if err == nil {
+			err = vault.Rewrite(path, passphrase, key)
+		}
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("%s: %w", b.Name, err)
+		}
+	}
+	return firstErr
+}
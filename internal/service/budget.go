@@ -0,0 +1,297 @@
+package service
+
+import (
+	"strings"
+	"time"
+
+	apperrors "dog-view/internal/errors"
+	"dog-view/internal/model"
+	"dog-view/internal/repository"
+)
+
+// 预算提醒阈值（使用率百分比）
+const (
+	BudgetWarningThreshold  = 80
+	BudgetExceededThreshold = 100
+)
+
+type BudgetService struct {
+	repo     repository.Repository
+	settings *SettingsService
+	records  *RecordService
+}
+
+func NewBudgetService(repo repository.Repository, settings *SettingsService, records *RecordService) *BudgetService {
+	return &BudgetService{repo: repo, settings: settings, records: records}
+}
+
+// Effective 返回某个记账周期生效的预算：当月设置的预算优先，
+// 否则沿用之前最近一次标记为循环的预算
+func (s *BudgetService) Effective(year, month int) ([]model.Budget, error) {
+	period, err := s.records.Period(year, month)
+	if err != nil {
+		return nil, err
+	}
+	return s.effective(period.Label())
+}
+
+func (s *BudgetService) effective(label string) ([]model.Budget, error) {
+	budgets, err := s.repo.ListBudgets(label)
+	if err != nil {
+		return nil, err
+	}
+
+	// 预算按月份升序返回，后出现的覆盖先出现的
+	byCategory := make(map[int64]model.Budget)
+	var order []int64
+	for _, b := range budgets {
+		if b.Month != label && !b.Recurring {
+			continue
+		}
+		if _, ok := byCategory[b.CategoryID]; !ok {
+			order = append(order, b.CategoryID)
+		}
+		byCategory[b.CategoryID] = b
+	}
+
+	effective := make([]model.Budget, 0, len(order))
+	for _, id := range order {
+		effective = append(effective, byCategory[id])
+	}
+	return effective, nil
+}
+
+// Set 设置某个记账周期的预算，categoryID 为 0 表示总预算
+func (s *BudgetService) Set(year, month int, categoryID int64, amount model.Money, recurring bool) error {
+	if amount.Minor <= 0 {
+		return apperrors.ErrInvalidAmount
+	}
+	amount.Currency = strings.ToUpper(strings.TrimSpace(amount.Currency))
+	if amount.Currency == "" {
+		base, err := s.settings.BaseCurrency()
+		if err != nil {
+			return err
+		}
+		amount.Currency = base
+	}
+	if !model.ValidCurrency(amount.Currency) {
+		return apperrors.ErrInvalidCurrency
+	}
+
+	if categoryID != 0 {
+		categories, err := s.repo.ListCategories(model.TypeExpense)
+		if err != nil {
+			return err
+		}
+		found := false
+		for _, c := range categories {
+			if c.ID == categoryID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return apperrors.ErrCategoryNotFound
+		}
+	}
+
+	period, err := s.records.Period(year, month)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.SaveBudget(&model.Budget{
+		Month:      period.Label(),
+		CategoryID: categoryID,
+		Amount:     amount,
+		Recurring:  recurring,
+	})
+}
+
+// Delete 删除在该记账周期设置的预算。沿用之前月份的循环预算不能在此删除，
+// 否则会连同设置它的月份及之后各月一起删除，此时返回 ErrBudgetInherited
+func (s *BudgetService) Delete(year, month int, id int64) error {
+	period, err := s.records.Period(year, month)
+	if err != nil {
+		return err
+	}
+	budgets, err := s.repo.ListBudgets(period.Label())
+	if err != nil {
+		return err
+	}
+	for _, b := range budgets {
+		if b.ID != id {
+			continue
+		}
+		if b.Month != period.Label() {
+			return apperrors.ErrBudgetInherited
+		}
+		return s.repo.DeleteBudget(id)
+	}
+	return apperrors.ErrBudgetNotFound
+}
+
+// Status 获取记账周期的预算执行情况
+func (s *BudgetService) Status(year, month int) (*model.BudgetReport, error) {
+	period, err := s.records.Period(year, month)
+	if err != nil {
+		return nil, err
+	}
+	return s.status(period, time.Now())
+}
+
+// StatusOn 获取某一天所属记账周期的预算执行情况
+func (s *BudgetService) StatusOn(date string) (*model.BudgetReport, error) {
+	day, err := time.Parse(DateLayout, date)
+	if err != nil {
+		return nil, apperrors.ErrInvalidDate
+	}
+	startDay, err := s.settings.MonthStartDay()
+	if err != nil {
+		return nil, err
+	}
+	return s.status(PeriodOf(day, startDay), time.Now())
+}
+
+func (s *BudgetService) status(period Period, today time.Time) (*model.BudgetReport, error) {
+	budgets, err := s.effective(period.Label())
+	if err != nil {
+		return nil, err
+	}
+
+	report := &model.BudgetReport{Month: period.Label(), Categories: []model.BudgetStatus{}}
+	if len(budgets) == 0 {
+		return report, nil
+	}
+
+	totals, base, err := s.records.convertedTotals(period.Start, period.End)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := s.repo.ListCategories(model.TypeExpense)
+	if err != nil {
+		return nil, err
+	}
+
+	// 子分类的支出同时计入其一级分类的预算
+	parents := parentMap(categories)
+	spent := make(map[int64]int64)
+	var totalSpent int64
+	for _, t := range totals {
+		if t.Type == model.TypeExpense {
+			spent[t.CategoryID] += t.Amount.Minor
+			if parent, ok := parents[t.CategoryID]; ok {
+				spent[parent] += t.Amount.Minor
+			}
+			totalSpent += t.Amount.Minor
+		}
+	}
+	categoryByID := make(map[int64]model.Category, len(categories))
+	for _, c := range categories {
+		categoryByID[c.ID] = c
+	}
+
+	// 预算金额以设置时的币种保存，本位币变更后按周期首日汇率换算
+	conv := s.records.rates.newConverter(base)
+	progress := periodProgress(period, today)
+
+	budgetByCategory := make(map[int64]model.Budget, len(budgets))
+	for _, b := range budgets {
+		budgetByCategory[b.CategoryID] = b
+	}
+
+	newStatus := func(b model.Budget, spentMinor int64) (model.BudgetStatus, error) {
+		amount, err := conv.convert(b.Amount, period.Start)
+		if err != nil {
+			return model.BudgetStatus{}, err
+		}
+		st := model.BudgetStatus{
+			BudgetID:   b.ID,
+			CategoryID: b.CategoryID,
+			Budget:     amount,
+			Spent:      model.NewMoney(spentMinor, base),
+		}
+		if b.Month != period.Label() {
+			st.InheritedFrom = b.Month
+		}
+		st.Remaining = st.Budget.Sub(st.Spent)
+		st.Percentage = st.Spent.Percentage(st.Budget)
+		st.Projected = model.NewMoney(int64(float64(spentMinor)/progress+0.5), base)
+		st.ProjectedOverrun = st.Projected.Minor > st.Budget.Minor
+		return st, nil
+	}
+
+	if b, ok := budgetByCategory[0]; ok {
+		overall, err := newStatus(b, totalSpent)
+		if err != nil {
+			return nil, err
+		}
+		overall.CategoryName = "总预算"
+		report.Overall = &overall
+	}
+
+	// 按分类排序输出，已删除分类的预算忽略
+	for _, c := range categories {
+		b, ok := budgetByCategory[c.ID]
+		if !ok {
+			continue
+		}
+		st, err := newStatus(b, spent[c.ID])
+		if err != nil {
+			return nil, err
+		}
+		st.CategoryName = c.Name
+		st.CategoryIcon = c.Icon
+		report.Categories = append(report.Categories, st)
+	}
+
+	return report, nil
+}
+
+// periodProgress 返回 today 时周期已过去的比例（按天计，含当天），
+// 已结束或尚未开始的周期返回 1，此时预测支出即为实际支出
+func periodProgress(period Period, today time.Time) float64 {
+	start, _ := time.Parse(DateLayout, period.Start)
+	end, _ := time.Parse(DateLayout, period.End)
+	day := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	if day.Before(start) || !day.Before(end) {
+		return 1
+	}
+
+	total := end.Sub(start).Hours() / 24
+	elapsed := day.Sub(start).Hours()/24 + 1
+	return elapsed / total
+}
+
+// Alerts 比较新增记录前后的预算执行情况，返回新越过的提醒阈值。
+// 新增记录只影响其分类、一级分类和总预算，因此逐个比较全部预算即可
+func Alerts(before, after *model.BudgetReport) []model.BudgetAlert {
+	if before == nil || after == nil {
+		return nil
+	}
+
+	var pairs [][2]*model.BudgetStatus
+	if before.Overall != nil && after.Overall != nil {
+		pairs = append(pairs, [2]*model.BudgetStatus{before.Overall, after.Overall})
+	}
+	for i := range after.Categories {
+		for j := range before.Categories {
+			if before.Categories[j].CategoryID == after.Categories[i].CategoryID {
+				pairs = append(pairs, [2]*model.BudgetStatus{&before.Categories[j], &after.Categories[i]})
+			}
+		}
+	}
+
+	var alerts []model.BudgetAlert
+	for _, p := range pairs {
+		for _, threshold := range []int{BudgetExceededThreshold, BudgetWarningThreshold} {
+			if p[0].Percentage < float64(threshold) && p[1].Percentage >= float64(threshold) {
+				alerts = append(alerts, model.BudgetAlert{Threshold: threshold, Status: *p[1]})
+				// 一次越过两个阈值时只提醒更高的一个
+				break
+			}
+		}
+	}
+	return alerts
+}
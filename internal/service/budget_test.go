@@ -0,0 +1,141 @@
+package service
+
+import (
+	"testing"
+
+	apperrors "dog-view/internal/errors"
+	"dog-view/internal/model"
+	"dog-view/internal/repository"
+)
+
+func newBudgetTestServices(t *testing.T) (repository.Repository, *RecordService, *BudgetService) {
+	t.Helper()
+	repo := repository.NewMemoryRepository()
+	settings := NewSettingsService(repo)
+	records := NewRecordService(repo, settings, NewRateService(repo, settings))
+	return repo, records, NewBudgetService(repo, settings, records)
+}
+
+func TestBudgetStatusRollsUpChildren(t *testing.T) {
+	repo, records, budgets := newBudgetTestServices(t)
+	food := &model.Category{Name: "餐饮", Type: model.TypeExpense}
+	if err := repo.CreateCategory(food); err != nil {
+		t.Fatal(err)
+	}
+	lunch := &model.Category{Name: "午餐", Type: model.TypeExpense, ParentID: food.ID}
+	if err := repo.CreateCategory(lunch); err != nil {
+		t.Fatal(err)
+	}
+	if err := budgets.Set(2024, 1, food.ID, model.Money{Minor: 10000}, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := budgets.Set(2024, 1, lunch.ID, model.Money{Minor: 5000}, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := records.Create(model.Money{Minor: 3000}, model.TypeExpense, food.ID, 0, "", "2024-01-05", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := records.Create(model.Money{Minor: 4000}, model.TypeExpense, lunch.ID, 0, "", "2024-01-06", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := budgets.Status(2024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spent := make(map[int64]int64)
+	for _, st := range report.Categories {
+		spent[st.CategoryID] = st.Spent.Minor
+	}
+	// 一级分类的预算包含子分类的支出，子分类的预算只包含自身
+	if spent[food.ID] != 7000 || spent[lunch.ID] != 4000 {
+		t.Fatalf("预算支出为 %v，期望 餐饮 7000、午餐 4000", spent)
+	}
+
+	// 子分类的新记录使一级分类预算越过提醒阈值
+	before := report
+	if err := records.Create(model.Money{Minor: 1500}, model.TypeExpense, lunch.ID, 0, "", "2024-01-07", nil); err != nil {
+		t.Fatal(err)
+	}
+	after, err := budgets.Status(2024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	alerts := Alerts(before, after)
+	if len(alerts) != 2 {
+		t.Fatalf("Alerts() = %+v，期望午餐超支和餐饮预警", alerts)
+	}
+	for _, a := range alerts {
+		switch a.Status.CategoryID {
+		case lunch.ID:
+			if a.Threshold != BudgetExceededThreshold {
+				t.Fatalf("午餐提醒阈值为 %d", a.Threshold)
+			}
+		case food.ID:
+			if a.Threshold != BudgetWarningThreshold {
+				t.Fatalf("餐饮提醒阈值为 %d", a.Threshold)
+			}
+		default:
+			t.Fatalf("意外的提醒 %+v", a)
+		}
+	}
+}
+
+func TestBudgetRecurringInheritance(t *testing.T) {
+	_, _, budgets := newBudgetTestServices(t)
+	if err := budgets.Set(2024, 1, 0, model.Money{Minor: 50000}, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := budgets.Set(2024, 3, 0, model.Money{Minor: 60000}, false); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		month     int
+		minor     int64
+		inherited string
+	}{
+		{2, 50000, "2024-01"},
+		{3, 60000, ""},
+		// 非循环预算只在当月生效，之后仍沿用最近的循环预算
+		{4, 50000, "2024-01"},
+	}
+	for _, c := range cases {
+		report, err := budgets.Status(2024, c.month)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.Overall == nil || report.Overall.Budget.Minor != c.minor || report.Overall.InheritedFrom != c.inherited {
+			t.Fatalf("%d 月总预算为 %+v", c.month, report.Overall)
+		}
+	}
+}
+
+// 沿用之前月份的循环预算不能在之后的月份删除，只能在设置它的月份删除
+func TestBudgetDeleteInherited(t *testing.T) {
+	_, _, budgets := newBudgetTestServices(t)
+	if err := budgets.Set(2024, 1, 0, model.Money{Minor: 50000}, true); err != nil {
+		t.Fatal(err)
+	}
+	effective, err := budgets.Effective(2024, 3)
+	if err != nil || len(effective) != 1 {
+		t.Fatalf("Effective = %+v, %v", effective, err)
+	}
+	id := effective[0].ID
+
+	if err := budgets.Delete(2024, 3, id); err != apperrors.ErrBudgetInherited {
+		t.Fatalf("Delete inherited = %v, want ErrBudgetInherited", err)
+	}
+	if effective, err := budgets.Effective(2024, 1); err != nil || len(effective) != 1 {
+		t.Fatalf("budget removed by a rejected delete: %+v, %v", effective, err)
+	}
+	if err := budgets.Delete(2024, 1, 9999); err != apperrors.ErrBudgetNotFound {
+		t.Errorf("Delete missing = %v, want ErrBudgetNotFound", err)
+	}
+	if err := budgets.Delete(2024, 1, id); err != nil {
+		t.Fatal(err)
+	}
+	if effective, err := budgets.Effective(2024, 3); err != nil || len(effective) != 0 {
+		t.Fatalf("Effective after delete = %+v, %v", effective, err)
+	}
+}
@@ -1,36 +1,116 @@
 package service
 
 import (
+	apperrors "dog-view/internal/errors"
 	"dog-view/internal/model"
 	"dog-view/internal/repository"
 )
 
 type CategoryService struct {
-	repo *repository.SQLiteRepository
+	repo repository.Repository
 }
 
-func NewCategoryService(repo *repository.SQLiteRepository) *CategoryService {
+func NewCategoryService(repo repository.Repository) *CategoryService {
 	return &CategoryService{repo: repo}
 }
 
-func (s *CategoryService) List(recordType string) ([]model.Category, error) {
-	return s.repo.ListCategories(recordType)
+// List 返回分类列表，includeArchived 为 false 时去掉已归档的分类
+func (s *CategoryService) List(recordType string, includeArchived bool) ([]model.Category, error) {
+	categories, err := s.repo.ListCategories(recordType)
+	if err != nil {
+		return nil, err
+	}
+	if includeArchived {
+		return categories, nil
+	}
+	return unarchived(categories), nil
+}
+
+// unarchived 去掉已归档的分类，上级分类已归档时其子分类一并隐藏
+func unarchived(categories []model.Category) []model.Category {
+	hidden := make(map[int64]bool)
+	visible := []model.Category{}
+	for _, c := range categories {
+		if c.Archived || hidden[c.ParentID] {
+			hidden[c.ID] = true
+			continue
+		}
+		visible = append(visible, c)
+	}
+	return visible
+}
+
+// Tree 返回分类树，一级分类的 Children 中为其子分类
+func (s *CategoryService) Tree(recordType string, includeArchived bool) ([]model.Category, error) {
+	categories, err := s.List(recordType, includeArchived)
+	if err != nil {
+		return nil, err
+	}
+
+	// ListCategories 已按先序返回，子分类总是紧跟在上级分类之后
+	tree := []model.Category{}
+	index := make(map[int64]int)
+	for _, c := range categories {
+		if i, ok := index[c.ParentID]; ok {
+			tree[i].Children = append(tree[i].Children, c)
+			continue
+		}
+		index[c.ID] = len(tree)
+		tree = append(tree, c)
+	}
+	return tree, nil
 }
 
-func (s *CategoryService) Create(name, icon, recordType string) error {
+// nextSortOrder 返回 parentID 下新分类的排序值
+func nextSortOrder(categories []model.Category, parentID int64, recordType string) int {
 	maxOrder := 0
-	categories, _ := s.repo.ListCategories(recordType)
 	for _, c := range categories {
-		if c.SortOrder > maxOrder {
+		if c.ParentID == parentID && c.Type == recordType && c.SortOrder > maxOrder {
 			maxOrder = c.SortOrder
 		}
 	}
+	return maxOrder + 1
+}
+
+// findCategory 在列表中按 ID 查找分类
+func findCategory(categories []model.Category, id int64) (model.Category, bool) {
+	for _, c := range categories {
+		if c.ID == id {
+			return c, true
+		}
+	}
+	return model.Category{}, false
+}
+
+// validateParent 校验 parentID 可以作为 recordType 类型分类的上级：
+// 必须是同类型的一级分类，分类最多两级
+func validateParent(categories []model.Category, parentID int64, recordType string) error {
+	if parentID == 0 {
+		return nil
+	}
+	parent, ok := findCategory(categories, parentID)
+	if !ok || parent.ParentID != 0 || parent.Type != recordType {
+		return apperrors.ErrInvalidParent
+	}
+	return nil
+}
+
+// Create 创建分类，parentID 为 0 时创建一级分类
+func (s *CategoryService) Create(name, icon, recordType string, parentID int64) error {
+	categories, err := s.repo.ListCategories("")
+	if err != nil {
+		return err
+	}
+	if err := validateParent(categories, parentID, recordType); err != nil {
+		return err
+	}
 
 	category := &model.Category{
 		Name:      name,
 		Icon:      icon,
 		Type:      recordType,
-		SortOrder: maxOrder + 1,
+		ParentID:  parentID,
+		SortOrder: nextSortOrder(categories, parentID, recordType),
 	}
 	return s.repo.CreateCategory(category)
 }
@@ -43,10 +123,97 @@ func (s *CategoryService) Update(id int64, name, icon string) error {
 	})
 }
 
+// Move 将分类移到 parentID 下（0 表示提升为一级分类），排在同级分类末尾；
+// 仍有子分类的一级分类不能再移到其他分类下
+func (s *CategoryService) Move(id, parentID int64) error {
+	categories, err := s.repo.ListCategories("")
+	if err != nil {
+		return err
+	}
+	c, ok := findCategory(categories, id)
+	if !ok {
+		return apperrors.ErrCategoryNotFound
+	}
+	if c.ParentID == parentID {
+		return nil
+	}
+	if parentID != 0 {
+		if parentID == id {
+			return apperrors.ErrInvalidParent
+		}
+		for _, child := range categories {
+			if child.ParentID == id {
+				return apperrors.ErrInvalidParent
+			}
+		}
+	}
+	if err := validateParent(categories, parentID, c.Type); err != nil {
+		return err
+	}
+
+	return s.repo.MoveCategory(id, parentID, nextSortOrder(categories, parentID, c.Type))
+}
+
+// SetArchived 归档或取消归档分类，归档不影响已有记录和历史统计
+func (s *CategoryService) SetArchived(id int64, archived bool) error {
+	return s.repo.SetCategoryArchived(id, archived)
+}
+
+// Delete 将分类移入回收站
 func (s *CategoryService) Delete(id int64) error {
 	return s.repo.DeleteCategory(id)
 }
 
-func (s *CategoryService) Reorder(ids []int64) error {
-	return s.repo.UpdateCategoryOrder(ids)
+// Merge 将 sourceIDs 合并到 targetID，返回转移的记录数。
+// 源分类与目标分类必须同类型；源分类有子分类时，合并后的目标分类须为一级分类，
+// 以免出现三级分类
+func (s *CategoryService) Merge(sourceIDs []int64, targetID int64) (int, error) {
+	categories, err := s.repo.ListCategories("")
+	if err != nil {
+		return 0, err
+	}
+	target, ok := findCategory(categories, targetID)
+	if !ok {
+		return 0, apperrors.ErrCategoryNotFound
+	}
+
+	var ids []int64
+	sources := make(map[int64]bool, len(sourceIDs))
+	for _, id := range sourceIDs {
+		source, ok := findCategory(categories, id)
+		if !ok {
+			return 0, apperrors.ErrCategoryNotFound
+		}
+		if id == targetID || source.Type != target.Type {
+			return 0, apperrors.ErrInvalidMerge
+		}
+		if !sources[id] {
+			sources[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return 0, apperrors.ErrInvalidMerge
+	}
+
+	// 目标分类挂在某个源分类下时，合并后会提升为一级分类
+	if target.ParentID != 0 && !sources[target.ParentID] {
+		for _, c := range categories {
+			if sources[c.ParentID] && c.ID != targetID {
+				return 0, apperrors.ErrInvalidMerge
+			}
+		}
+	}
+
+	return s.repo.MergeCategories(ids, targetID)
+}
+
+// DeleteAndReassign 删除分类并将其记录转到 targetID，返回转移的记录数
+func (s *CategoryService) DeleteAndReassign(id, targetID int64) (int, error) {
+	return s.Merge([]int64{id}, targetID)
+}
+
+// Reorder 按 ids 顺序重排 parentID 下的分类
+func (s *CategoryService) Reorder(parentID int64, ids []int64) error {
+	return s.repo.UpdateCategoryOrder(parentID, ids)
 }
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	apperrors "dog-view/internal/errors"
+	"dog-view/internal/export"
+	"dog-view/internal/model"
+	"dog-view/internal/repository"
+)
+
+// CSVProfileService 管理通用 CSV 的列映射方案，每家银行保存一份，导入时复用
+type CSVProfileService struct {
+	repo repository.Repository
+}
+
+func NewCSVProfileService(repo repository.Repository) *CSVProfileService {
+	return &CSVProfileService{repo: repo}
+}
+
+func (s *CSVProfileService) List() ([]model.CSVProfile, error) {
+	return s.repo.ListCSVProfiles()
+}
+
+func (s *CSVProfileService) Get(id int64) (*model.CSVProfile, error) {
+	return s.repo.GetCSVProfile(id)
+}
+
+// Create 保存新方案，返回回填了 ID 的方案
+func (s *CSVProfileService) Create(name string, mapping model.CSVMapping) (*model.CSVProfile, error) {
+	p, err := newCSVProfile(0, name, mapping)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateCSVProfile(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (s *CSVProfileService) Update(id int64, name string, mapping model.CSVMapping) error {
+	p, err := newCSVProfile(id, name, mapping)
+	if err != nil {
+		return err
+	}
+	return s.repo.UpdateCSVProfile(p)
+}
+
+func (s *CSVProfileService) Delete(id int64) error {
+	return s.repo.DeleteCSVProfile(id)
+}
+
+func newCSVProfile(id int64, name string, mapping model.CSVMapping) (*model.CSVProfile, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, apperrors.ErrInvalidProfileName
+	}
+	if err := normalizeMapping(&mapping); err != nil {
+		return nil, err
+	}
+	return &model.CSVProfile{ID: id, Name: name, Mapping: mapping}, nil
+}
+
+// normalizeMapping 补全默认值并校验列映射：必须指定日期列，以及正负号约定所需的金额、类型列；
+// 按表头名称查找列时文件必须有表头，日期格式必须能表示年、月、日
+func normalizeMapping(m *model.CSVMapping) error {
+	m.Encoding = strings.ToLower(strings.TrimSpace(m.Encoding))
+	switch m.Encoding {
+	case "":
+		m.Encoding = model.EncodingAuto
+	case model.EncodingAuto, model.EncodingUTF8, model.EncodingGBK:
+	default:
+		return apperrors.ErrInvalidCSVMapping
+	}
+	if m.Delimiter != `\t` && utf8.RuneCountInString(m.Delimiter) > 1 || m.SkipRows < 0 {
+		return apperrors.ErrInvalidCSVMapping
+	}
+	switch m.DecimalSeparator {
+	case "":
+		m.DecimalSeparator = "."
+	case ".", ",":
+	default:
+		return apperrors.ErrInvalidCSVMapping
+	}
+
+	if m.SignConvention == "" {
+		m.SignConvention = model.SignNegativeExpense
+	}
+	required := []model.CSVColumn{m.Date}
+	switch m.SignConvention {
+	case model.SignNegativeExpense, model.SignNegativeIncome:
+		required = append(required, m.Amount)
+	case model.SignTypeColumn:
+		required = append(required, m.Amount, m.Type)
+	case model.SignSplitColumns:
+		required = append(required, m.Income, m.Expense)
+	default:
+		return apperrors.ErrInvalidCSVMapping
+	}
+	for _, c := range required {
+		if !c.IsSet() {
+			return apperrors.ErrInvalidCSVMapping
+		}
+	}
+
+	columns := []*model.CSVColumn{&m.Date, &m.Amount, &m.Income, &m.Expense, &m.Type, &m.Category, &m.Currency}
+	for i := range m.Notes {
+		columns = append(columns, &m.Notes[i])
+	}
+	for _, c := range columns {
+		c.Header = strings.TrimSpace(c.Header)
+		if c.Index < 0 || c.Header != "" && !m.HasHeader {
+			return apperrors.ErrInvalidCSVMapping
+		}
+	}
+
+	m.DateLayout = strings.TrimSpace(m.DateLayout)
+	if m.DateLayout != "" && !validDateLayout(m.DateLayout) {
+		return apperrors.ErrInvalidCSVMapping
+	}
+	return nil
+}
+
+// validDateLayout 日期格式按该格式写出的日期能否原样解析回来，且年、月、日都参与了解析
+func validDateLayout(layout string) bool {
+	if !strings.Contains(layout, "YY") || !strings.Contains(layout, "M") || !strings.Contains(layout, "D") {
+		return false
+	}
+	goLayout := export.GoDateLayout(layout)
+	ref := time.Date(2024, 11, 23, 10, 30, 45, 0, time.UTC)
+	parsed, err := time.Parse(goLayout, ref.Format(goLayout))
+	return err == nil && parsed.Year() == ref.Year() && parsed.Month() == ref.Month() && parsed.Day() == ref.Day()
+}
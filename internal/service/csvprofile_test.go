@@ -0,0 +1,137 @@
+package service
+
+import (
+	"testing"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+
+	apperrors "dog-view/internal/errors"
+	"dog-view/internal/model"
+	"dog-view/internal/repository"
+)
+
+func TestCSVProfileBankStatement(t *testing.T) {
+	repo := repository.NewMemoryRepository()
+	svc := NewExportService(repo, NewSettingsService(repo))
+	profiles := NewCSVProfileService(repo)
+
+	statement, err := simplifiedchinese.GBK.NewEncoder().String("中国工商银行账户历史明细\n账号: 6222\n" +
+		"交易日期,摘要,金额,余额,对方户名\n" +
+		"20240102,消费,-1.234,50,某超市\n" +
+		"20240103 10:00:00,工资,\"8.000,00\",100,公司\n" +
+		"\n" +
+		"20240199,错误,-1,0,\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := writeImportFile(t, "icbc.csv", statement)
+
+	// 配置映射前按自动识别的编码预览原始行
+	sample, err := svc.InspectCSV(path, "", "")
+	if err != nil || sample.Encoding != model.EncodingGBK || sample.Rows[2][0] != "交易日期" {
+		t.Fatalf("InspectCSV() = %+v, %v", sample, err)
+	}
+
+	mapping := model.CSVMapping{
+		SkipRows:         2,
+		HasHeader:        true,
+		Date:             model.CSVColumn{Header: "交易日期"},
+		Amount:           model.CSVColumn{Index: 3},
+		Notes:            []model.CSVColumn{{Header: "摘要"}, {Header: "对方户名"}},
+		DateLayout:       "YYYYMMDD",
+		DecimalSeparator: ",",
+	}
+	profile, err := profiles.Create(" 工商银行 ", mapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.Name != "工商银行" || profile.Mapping.Encoding != model.EncodingAuto || profile.Mapping.SignConvention != model.SignNegativeExpense {
+		t.Fatalf("Create() = %+v", profile)
+	}
+	if _, err := profiles.Create("工商银行", mapping); err != apperrors.ErrDuplicateProfile {
+		t.Fatalf("Create(同名) = %v，期望 ErrDuplicateProfile", err)
+	}
+
+	preview, err := svc.PreviewMappedCSV(path, profile.Mapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 空行跳过，日期无效的行校验失败
+	if len(preview.Rows) != 3 || preview.Valid != 2 || preview.Invalid != 1 {
+		t.Fatalf("PreviewMappedCSV() = %+v", preview)
+	}
+	expense, income := preview.Rows[0], preview.Rows[1]
+	if expense.Line != 4 || expense.Type != model.TypeExpense || expense.Amount != "1234" || expense.Date != "2024-01-02" || expense.Note != "消费 · 某超市" {
+		t.Fatalf("支出行为 %+v", expense)
+	}
+	if income.Type != model.TypeIncome || income.Amount != "8000.00" || income.Date != "2024-01-03" {
+		t.Fatalf("收入行为 %+v", income)
+	}
+}
+
+func TestCSVProfileSignConventions(t *testing.T) {
+	repo := repository.NewMemoryRepository()
+	svc := NewExportService(repo, NewSettingsService(repo))
+
+	// 收入和支出分列
+	split := writeImportFile(t, "split.csv", "\ufeffdate;in;out;memo\n2024/1/5;;12,50;咖啡\n2024/1/6;100;;红包\n")
+	preview, err := svc.PreviewMappedCSV(split, model.CSVMapping{
+		Delimiter: ";", HasHeader: true, DecimalSeparator: ",", DateLayout: "YYYY/M/D",
+		Date: model.CSVColumn{Index: 1}, Income: model.CSVColumn{Header: "in"}, Expense: model.CSVColumn{Header: "out"},
+		Notes: []model.CSVColumn{{Index: 4}}, SignConvention: model.SignSplitColumns,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preview.Valid != 2 || preview.Rows[0].Type != model.TypeExpense || preview.Rows[0].Amount != "12.50" ||
+		preview.Rows[1].Type != model.TypeIncome || preview.Rows[1].Date != "2024-01-06" {
+		t.Fatalf("分列预览为 %+v", preview.Rows)
+	}
+
+	// 借贷类型列，括号表示负数，无法识别的类型校验失败
+	typed := writeImportFile(t, "typed.csv", "d,t,a\n2024-01-07,借,5\n2024-01-08,贷,(3)\n2024-01-09,?,1\n")
+	preview, err = svc.PreviewMappedCSV(typed, model.CSVMapping{
+		HasHeader: true, Date: model.CSVColumn{Index: 1}, Type: model.CSVColumn{Index: 2}, Amount: model.CSVColumn{Index: 3},
+		SignConvention: model.SignTypeColumn, IncomeLabels: []string{"贷"}, ExpenseLabels: []string{"借"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preview.Valid != 2 || preview.Rows[1].Amount != "3" || preview.Rows[1].Type != model.TypeIncome {
+		t.Fatalf("类型列预览为 %+v", preview.Rows)
+	}
+
+	// 信用卡账单中正数为支出
+	preview, err = svc.PreviewMappedCSV(typed, model.CSVMapping{
+		HasHeader: true, Date: model.CSVColumn{Index: 1}, Amount: model.CSVColumn{Index: 3}, SignConvention: model.SignNegativeIncome,
+	})
+	if err != nil || preview.Rows[0].Type != model.TypeExpense || preview.Rows[1].Type != model.TypeIncome {
+		t.Fatalf("信用卡预览为 %+v, %v", preview, err)
+	}
+
+	if _, err := svc.PreviewMappedCSV(typed, model.CSVMapping{HasHeader: true, Date: model.CSVColumn{Header: "nope"}, Amount: model.CSVColumn{Index: 1}}); err == nil {
+		t.Fatal("PreviewMappedCSV() 表头中不存在的列应返回错误")
+	}
+}
+
+func TestCSVProfileRejectsInvalidMapping(t *testing.T) {
+	profiles := NewCSVProfileService(repository.NewMemoryRepository())
+	date, amount := model.CSVColumn{Index: 1}, model.CSVColumn{Index: 2}
+	bad := map[string]model.CSVMapping{
+		"缺少日期列":     {Amount: amount},
+		"缺少金额列":     {Date: date},
+		"无表头时按名称取列": {Date: model.CSVColumn{Header: "x"}, Amount: amount},
+		"日期格式缺少年月日": {Date: date, Amount: amount, DateLayout: "HH:mm"},
+		"不支持的编码":    {Date: date, Amount: amount, Encoding: "big5"},
+		"分列缺少收支列":   {Date: date, Amount: amount, SignConvention: model.SignSplitColumns},
+		"分隔符多于一个字符": {Date: date, Amount: amount, Delimiter: ";;"},
+	}
+	for name, m := range bad {
+		if _, err := profiles.Create("x", m); err != apperrors.ErrInvalidCSVMapping {
+			t.Errorf("%s: Create() = %v，期望 ErrInvalidCSVMapping", name, err)
+		}
+	}
+	if _, err := profiles.Create(" ", model.CSVMapping{Date: date, Amount: amount}); err != apperrors.ErrInvalidProfileName {
+		t.Errorf("Create(空名称) = %v，期望 ErrInvalidProfileName", err)
+	}
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"dog-view/internal/model"
+	"dog-view/internal/repository"
+)
+
+// normalizeNote 规范化备注用于比较：全角字符转为半角，忽略大小写，连续空白视为一个空格
+func normalizeNote(note string) string {
+	folded := strings.Map(func(r rune) rune {
+		switch {
+		case r == '　':
+			r = ' '
+		case r >= '！' && r <= '～':
+			r -= 0xfee0
+		}
+		return unicode.ToLower(r)
+	}, note)
+	return strings.Join(strings.Fields(folded), " ")
+}
+
+// fingerprint 记录的指纹：日期、类型、分类、金额和规范化后的备注都相同的记录视为疑似重复。
+// 转账没有分类，改用转出和转入账户区分
+func fingerprint(rec model.Record) string {
+	category := fmt.Sprint(rec.CategoryID)
+	if rec.Type == model.TypeTransfer {
+		category = fmt.Sprintf("%d>%d", rec.AccountID, rec.ToAccountID)
+	}
+	return strings.Join([]string{
+		rec.Date,
+		rec.Type,
+		category,
+		fmt.Sprint(rec.Amount.Minor),
+		rec.Amount.Currency,
+		normalizeNote(rec.Note),
+	}, "|")
+}
+
+// DuplicateService 查找和清理疑似重复的记录
+type DuplicateService struct {
+	repo repository.Repository
+}
+
+func NewDuplicateService(repo repository.Repository) *DuplicateService {
+	return &DuplicateService{repo: repo}
+}
+
+// Find 扫描整个账本，返回疑似重复的记录组，按日期倒序排列
+func (s *DuplicateService) Find() ([]model.DuplicateGroup, error) {
+	records, err := s.repo.GetAllRecords()
+	if err != nil {
+		return nil, err
+	}
+
+	byPrint := make(map[string][]model.Record)
+	var order []string
+	for _, rec := range records {
+		fp := fingerprint(rec)
+		if _, ok := byPrint[fp]; !ok {
+			order = append(order, fp)
+		}
+		byPrint[fp] = append(byPrint[fp], rec)
+	}
+
+	groups := []model.DuplicateGroup{}
+	for _, fp := range order {
+		group := byPrint[fp]
+		if len(group) < 2 {
+			continue
+		}
+		sortByEntry(group)
+		groups = append(groups, model.DuplicateGroup{Fingerprint: fp, Records: group})
+	}
+	return groups, nil
+}
+
+// Similar 返回与待录入的记录指纹相同的已有记录，用于录入前提醒
+func (s *DuplicateService) Similar(rec model.Record) ([]model.Record, error) {
+	if err := validateDate(rec.Date); err != nil {
+		return nil, err
+	}
+	end, err := nextDay(rec.Date)
+	if err != nil {
+		return nil, err
+	}
+	records, err := s.repo.ListRecordsBetween(rec.Date, end)
+	if err != nil {
+		return nil, err
+	}
+
+	fp := fingerprint(rec)
+	similar := []model.Record{}
+	for _, r := range records {
+		if fingerprint(r) == fp {
+			similar = append(similar, r)
+		}
+	}
+	sortByEntry(similar)
+	return similar, nil
+}
+
+// Resolve 将选中的重复记录一并移入回收站，可以整体撤销，返回移入的条数
+func (s *DuplicateService) Resolve(ids []int64) (int, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	return s.repo.DeleteRecords(ids)
+}
+
+// sortByEntry 按录入时间（相同时按 ID）排序
+func sortByEntry(records []model.Record) {
+	sort.Slice(records, func(i, j int) bool {
+		a, b := records[i], records[j]
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	})
+}
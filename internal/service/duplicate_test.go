@@ -0,0 +1,82 @@
+package service
+
+import (
+	"testing"
+
+	"dog-view/internal/model"
+	"dog-view/internal/repository"
+)
+
+func TestDuplicateImportAndResolve(t *testing.T) {
+	repo := repository.NewMemoryRepository()
+	food := &model.Category{Name: "餐饮", Icon: "🍜", Type: model.TypeExpense}
+	if err := repo.CreateCategory(food); err != nil {
+		t.Fatal(err)
+	}
+	rec := &model.Record{Amount: model.NewMoney(1250, model.DefaultCurrency), Type: model.TypeExpense, CategoryID: food.ID, Note: "ＬＵＮＣＨ  box", Date: "2024-01-02"}
+	if err := repo.CreateRecord(rec); err != nil {
+		t.Fatal(err)
+	}
+	dup := NewDuplicateService(repo)
+
+	// 备注忽略全角、大小写和多余空白
+	similar, err := dup.Similar(model.Record{Amount: model.NewMoney(1250, model.DefaultCurrency), Type: model.TypeExpense, CategoryID: food.ID, Note: "lunch box", Date: "2024-01-02"})
+	if err != nil || len(similar) != 1 || similar[0].ID != rec.ID {
+		t.Fatalf("Similar() = %+v, %v", similar, err)
+	}
+
+	svc := NewExportService(repo, NewSettingsService(repo))
+	path := writeImportFile(t, "in.csv", "date,type,category,amount,note,currency,tags\n"+
+		"2024-01-02,expense,餐饮,12.50,lunch box,,新标签\n"+
+		"2024-01-02,expense,餐饮,12.50,lunch box,,\n"+
+		"2024-01-03,expense,餐饮,1,,,\n")
+	preview, err := svc.PreviewCSV(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 已有一条相同记录，文件中只有第一行视为重复；默认跳过的行不引入新标签
+	if preview.Duplicates != 1 || !preview.Rows[0].Duplicate || preview.Rows[1].Duplicate || len(preview.NewTags) != 0 {
+		t.Fatalf("PreviewCSV() = %+v", preview)
+	}
+
+	plan := *preview
+	plan.DuplicatePolicy = "bogus"
+	if _, err := svc.CommitImport(&plan); err == nil {
+		t.Fatal("CommitImport() 无效的重复处理方式应返回错误")
+	}
+	plan.DuplicatePolicy = model.DuplicateFlag
+	result, err := svc.CommitImport(&plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Imported != 3 || result.Duplicates != 1 || result.TagsCreated != 2 {
+		t.Fatalf("CommitImport() = %+v", result)
+	}
+
+	groups, err := dup.Find()
+	if err != nil || len(groups) != 1 || len(groups[0].Records) != 3 {
+		t.Fatalf("Find() = %+v, %v", groups, err)
+	}
+	if groups[0].Records[0].ID != rec.ID {
+		t.Fatalf("重复组的第一条应为最早的记录，实际为 %+v", groups[0].Records[0])
+	}
+	flagged := 0
+	for _, r := range groups[0].Records {
+		for _, tag := range r.Tags {
+			if tag.Name == model.DuplicateTag {
+				flagged++
+			}
+		}
+	}
+	if flagged != 1 {
+		t.Fatalf("打上重复标签的记录有 %d 条，期望 1 条", flagged)
+	}
+
+	n, err := dup.Resolve([]int64{groups[0].Records[1].ID, groups[0].Records[2].ID})
+	if err != nil || n != 2 {
+		t.Fatalf("Resolve() = %d, %v", n, err)
+	}
+	if groups, err := dup.Find(); err != nil || len(groups) != 0 {
+		t.Fatalf("处理后 Find() = %+v, %v", groups, err)
+	}
+}
@@ -1,17 +1,23 @@
 package service
 
 import (
+	"path/filepath"
+
 	"dog-view/internal/export"
 	"dog-view/internal/model"
 	"dog-view/internal/repository"
 )
 
+// csvSampleRows 配置列映射时预览的行数
+const csvSampleRows = 20
+
 type ExportService struct {
-	repo *repository.SQLiteRepository
+	repo     repository.Repository
+	settings *SettingsService
 }
 
-func NewExportService(repo *repository.SQLiteRepository) *ExportService {
-	return &ExportService{repo: repo}
+func NewExportService(repo repository.Repository, settings *SettingsService) *ExportService {
+	return &ExportService{repo: repo, settings: settings}
 }
 
 func (s *ExportService) ExportToCSV(filePath string) error {
@@ -33,91 +39,229 @@ func (s *ExportService) ExportToJSON(filePath string) error {
 		return err
 	}
 
-	return export.ExportJSON(records, categories, filePath)
+	tags, err := s.repo.ListTags()
+	if err != nil {
+		return err
+	}
+
+	return export.ExportJSON(records, categories, tags, filePath)
 }
 
-func (s *ExportService) ImportFromCSV(filePath string) (int, error) {
+// ============ 导入 ============
+
+// PreviewCSV 试运行 CSV 导入：逐行校验并汇总将要新建的分类和标签，不修改账本
+func (s *ExportService) PreviewCSV(filePath string) (*model.ImportPreview, error) {
 	csvRecords, err := export.ImportCSV(filePath)
 	if err != nil {
-		return 0, err
-	}
-
-	count := 0
-	for _, csvRec := range csvRecords {
-		// 查找或创建分类
-		category, err := s.repo.GetCategoryByName(csvRec.Category)
-		if err != nil {
-			// 分类不存在，创建新分类
-			category = &model.Category{
-				Name: csvRec.Category,
-				Icon: "📦",
-				Type: csvRec.Type,
-			}
-			if err := s.repo.CreateCategory(category); err != nil {
-				continue
-			}
-		}
+		return nil, err
+	}
+	return s.previewRecords(filePath, csvRecords)
+}
 
-		// 创建记录
-		record := &model.Record{
-			Date:       csvRec.Date,
-			Type:       csvRec.Type,
-			CategoryID: category.ID,
-			Amount:     csvRec.Amount,
-			Note:       csvRec.Note,
-		}
-		if err := s.repo.CreateRecord(record); err != nil {
-			continue
-		}
-		count++
+// PreviewBill 试运行支付宝或微信支付账单的导入，自动识别账单格式；
+// 未成功、已全额退款或不计收支的交易不导入，计入 Ignored
+func (s *ExportService) PreviewBill(filePath string) (*model.ImportPreview, error) {
+	bill, err := export.ImportBill(filePath)
+	if err != nil {
+		return nil, err
+	}
+	preview, err := s.previewRecords(filePath, bill.Records)
+	if err != nil {
+		return nil, err
 	}
+	preview.Ignored = bill.Ignored
+	return preview, nil
+}
 
-	return count, nil
+// PreviewMappedCSV 按列映射试运行银行流水等通用 CSV 的导入
+func (s *ExportService) PreviewMappedCSV(filePath string, mapping model.CSVMapping) (*model.ImportPreview, error) {
+	if err := normalizeMapping(&mapping); err != nil {
+		return nil, err
+	}
+	csvRecords, err := export.ImportMappedCSV(filePath, mapping)
+	if err != nil {
+		return nil, err
+	}
+	return s.previewRecords(filePath, csvRecords)
 }
 
-func (s *ExportService) ImportFromJSON(filePath string) (int, error) {
+// InspectCSV 读取 CSV 的前几行供配置列映射，encoding 为空时自动识别
+func (s *ExportService) InspectCSV(filePath, encoding, delimiter string) (*model.CSVSample, error) {
+	encoding, rows, err := export.PeekCSV(filePath, encoding, delimiter, csvSampleRows)
+	if err != nil {
+		return nil, err
+	}
+	return &model.CSVSample{Source: filepath.Base(filePath), Encoding: encoding, Rows: rows}, nil
+}
+
+// previewRecords 逐行校验按 CSV 列读取的记录
+func (s *ExportService) previewRecords(filePath string, records []export.CSVRecord) (*model.ImportPreview, error) {
+	p, err := newImportPlanner(s.repo, s.settings)
+	if err != nil {
+		return nil, err
+	}
+	p.preview.Source = filepath.Base(filePath)
+	for _, r := range records {
+		p.add(model.ImportRow{
+			Line:     r.Line,
+			Date:     r.Date,
+			Type:     r.Type,
+			Category: r.Category,
+			Amount:   r.Amount,
+			Currency: r.Currency,
+			Note:     r.Note,
+			Tags:     r.Tags,
+		})
+	}
+	return &p.preview, nil
+}
+
+// PreviewJSON 试运行 JSON 导入，文件中的分类（含层级和图标）和标签（含颜色）即使没有记录使用也会导入
+func (s *ExportService) PreviewJSON(filePath string) (*model.ImportPreview, error) {
 	data, err := export.ImportJSON(filePath)
 	if err != nil {
-		return 0, err
+		return nil, err
+	}
+
+	p, err := newImportPlanner(s.repo, s.settings)
+	if err != nil {
+		return nil, err
 	}
 
-	// 先导入分类
-	categoryMap := make(map[string]int64)
+	// 上级分类在导出文件中排在子分类之前
+	categories := make([]model.ImportCategory, 0, len(data.Categories))
 	for _, c := range data.Categories {
-		existing, err := s.repo.GetCategoryByName(c.Name)
-		if err == nil {
-			categoryMap[c.Name] = existing.ID
-		} else {
-			newCat := &model.Category{
-				Name: c.Name,
-				Icon: c.Icon,
-				Type: c.Type,
-			}
-			if err := s.repo.CreateCategory(newCat); err == nil {
-				categoryMap[c.Name] = newCat.ID
-			}
-		}
+		categories = append(categories, model.ImportCategory{
+			Name:     c.Name,
+			Icon:     c.Icon,
+			Type:     c.Type,
+			Parent:   c.Parent,
+			Archived: c.Archived,
+		})
+	}
+	p.declareCategories(categories)
+
+	tags := make([]model.ImportTag, 0, len(data.Tags))
+	for _, t := range data.Tags {
+		tags = append(tags, model.ImportTag{Name: t.Name, Color: t.Color})
+	}
+	p.declareTags(tags)
+
+	p.preview.Source = filepath.Base(filePath)
+	for i, r := range data.Records {
+		p.add(model.ImportRow{
+			Line:     i + 1,
+			Date:     r.Date,
+			Type:     r.Type,
+			Category: r.Category,
+			Amount:   r.Amount.String(),
+			Currency: r.Currency,
+			Note:     r.Note,
+			Tags:     r.Tags,
+		})
+	}
+	return &p.preview, nil
+}
+
+// CommitImport 按预览得到的计划在一个事务中导入，任一记录写入失败时整批回滚。
+// 账本可能在预览之后发生变化，提交前按当前状态重新校验，校验未通过的行跳过并计入 Skipped；
+// 疑似重复的行按 plan.DuplicatePolicy 跳过、照常导入或打上 DuplicateTag 标签导入
+func (s *ExportService) CommitImport(plan *model.ImportPreview) (*model.ImportResult, error) {
+	p, err := newImportPlanner(s.repo, s.settings)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.plan(plan); err != nil {
+		return nil, err
+	}
+	policy := p.preview.DuplicatePolicy
+	result := &model.ImportResult{Skipped: p.preview.Invalid, Duplicates: p.preview.Duplicates}
+
+	// 本次新建的分类和标签以负数占位 ID 引用，由仓库在事务中转换为实际 ID
+	categoryIDs := make(map[string]int64, len(p.existing)+len(p.preview.NewCategories))
+	for name, c := range p.existing {
+		categoryIDs[name] = c.ID
+	}
+	categories := make([]model.Category, 0, len(p.preview.NewCategories))
+	for i, c := range p.preview.NewCategories {
+		categories = append(categories, model.Category{
+			Name:     c.Name,
+			Icon:     c.Icon,
+			Type:     c.Type,
+			ParentID: categoryIDs[c.Parent],
+			Archived: c.Archived,
+		})
+		categoryIDs[c.Name] = -int64(i + 1)
 	}
 
-	// 导入记录
-	count := 0
-	for _, r := range data.Records {
-		categoryID, ok := categoryMap[r.Category]
-		if !ok {
+	tagIDs := make(map[string]int64, len(p.tags)+len(p.preview.NewTags))
+	for name, id := range p.tags {
+		tagIDs[name] = id
+	}
+	tags := make([]model.Tag, 0, len(p.preview.NewTags)+1)
+	for _, t := range p.preview.NewTags {
+		tags = append(tags, model.Tag{Name: t.Name, Color: t.Color})
+		tagIDs[t.Name] = -int64(len(tags))
+	}
+	if _, ok := tagIDs[model.DuplicateTag]; !ok && policy == model.DuplicateFlag && result.Duplicates > 0 {
+		tags = append(tags, model.Tag{Name: model.DuplicateTag})
+		tagIDs[model.DuplicateTag] = -int64(len(tags))
+	}
+
+	records := make([]model.Record, 0, p.preview.Valid)
+	for i, row := range p.preview.Rows {
+		if len(row.Errors) > 0 || row.Duplicate && policy == model.DuplicateSkip {
 			continue
 		}
-
-		record := &model.Record{
-			Date:       r.Date,
-			Type:       r.Type,
-			CategoryID: categoryID,
-			Amount:     r.Amount,
-			Note:       r.Note,
+		names := row.Tags
+		if row.Duplicate && policy == model.DuplicateFlag {
+			names = append(names[:len(names):len(names)], model.DuplicateTag)
 		}
-		if err := s.repo.CreateRecord(record); err == nil {
-			count++
+		records = append(records, model.Record{
+			Date:       row.Date,
+			Type:       row.Type,
+			CategoryID: categoryIDs[row.Category],
+			Amount:     p.amounts[i],
+			Note:       row.Note,
+			Tags:       rowTags(names, tagIDs),
+		})
+	}
+	if len(records) == 0 && len(categories) == 0 && len(tags) == 0 {
+		return result, nil
+	}
+
+	batch := &model.ImportBatch{Source: plan.Source}
+	if err := s.repo.CreateImportBatch(batch, categories, tags, records); err != nil {
+		return nil, err
+	}
+	result.BatchID = batch.ID
+	result.Imported = batch.Records
+	result.CategoriesCreated = batch.Categories
+	result.TagsCreated = batch.Tags
+	return result, nil
+}
+
+// rowTags 将标签名称转换为标签 ID，跳过无效和重复的名称
+func rowTags(names []string, ids map[string]int64) []model.Tag {
+	var tags []model.Tag
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name, err := normalizeTagName(name)
+		if err != nil || seen[name] {
+			continue
 		}
+		seen[name] = true
+		tags = append(tags, model.Tag{ID: ids[name], Name: name})
 	}
+	return tags
+}
+
+// ImportBatches 返回全部导入批次，最新的在前
+func (s *ExportService) ImportBatches() ([]model.ImportBatch, error) {
+	return s.repo.ListImportBatches()
+}
 
-	return count, nil
+// UndoImport 将一次导入的记录和分类整批移入回收站，导入时新建的标签保留
+func (s *ExportService) UndoImport(batchID int64) (model.ImportUndoResult, error) {
+	return s.repo.DeleteImportBatch(batchID)
 }
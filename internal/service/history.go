@@ -0,0 +1,144 @@
+package service
+
+import (
+	"encoding/json"
+	"sync"
+
+	apperrors "dog-view/internal/errors"
+	"dog-view/internal/model"
+	"dog-view/internal/repository"
+)
+
+// undoLimit 撤销栈的最大深度，超出时丢弃最早的操作
+const undoLimit = 100
+
+// HistoryService 会话级的撤销和重做。撤销栈只包含打开账本之后产生的变更集，
+// 每次撤销或重做前从变更日志中补齐新产生的变更集。不记入变更日志的设置修改不会出现在撤销栈中
+type HistoryService struct {
+	repo repository.Repository
+
+	mu       sync.Mutex
+	lastSeen int64
+	undo     []int64
+	redo     []int64
+}
+
+func NewHistoryService(repo repository.Repository) (*HistoryService, error) {
+	lastSeen, err := repo.LatestChangeSetID()
+	if err != nil {
+		return nil, err
+	}
+	return &HistoryService{repo: repo, lastSeen: lastSeen}, nil
+}
+
+// undoable 判断操作能否撤销：彻底删除无法恢复，自动入账不是用户操作，
+// 撤销和重做本身通过两个栈互相转换
+func undoable(action string) bool {
+	switch action {
+	case model.ActionPurgeRecord, model.ActionPurgeCategory, model.ActionPurgeTrash,
+		model.ActionPostRecurring, model.ActionUndo, model.ActionRedo:
+		return false
+	}
+	return true
+}
+
+// sync 将新产生的可撤销变更集压入撤销栈，有新操作时清空重做栈。调用方需持有锁
+func (s *HistoryService) sync() error {
+	sets, err := s.repo.ListChangeSets(s.lastSeen)
+	if err != nil {
+		return err
+	}
+	for _, set := range sets {
+		s.lastSeen = set.ID
+		if !undoable(set.Action) {
+			continue
+		}
+		s.undo = append(s.undo, set.ID)
+		s.redo = nil
+	}
+	if len(s.undo) > undoLimit {
+		s.undo = s.undo[len(s.undo)-undoLimit:]
+	}
+	return nil
+}
+
+// replay 从 from 栈顶取出变更集并回放，成功后压入 to 栈。
+// 数据已被修改时丢弃该变更集，其他错误时保留以便重试
+func (s *HistoryService) replay(from, to *[]int64, empty error, apply func(id int64) error) (*model.ChangeSet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.sync(); err != nil {
+		return nil, err
+	}
+	if len(*from) == 0 {
+		return nil, empty
+	}
+	id := (*from)[len(*from)-1]
+	if err := apply(id); err != nil {
+		if err == apperrors.ErrUndoConflict {
+			*from = (*from)[:len(*from)-1]
+		}
+		return nil, err
+	}
+	*from = (*from)[:len(*from)-1]
+	*to = append(*to, id)
+
+	// 回放本身产生的变更集不进入撤销栈
+	if err := s.sync(); err != nil {
+		return nil, err
+	}
+	sets, err := s.repo.ListChangeSets(id - 1)
+	if err != nil {
+		return nil, err
+	}
+	for _, set := range sets {
+		if set.ID == id {
+			return &set, nil
+		}
+	}
+	return nil, apperrors.ErrChangeSetNotFound
+}
+
+// Undo 撤销最近一次操作，返回被撤销的变更集
+func (s *HistoryService) Undo() (*model.ChangeSet, error) {
+	return s.replay(&s.undo, &s.redo, apperrors.ErrNothingToUndo, s.repo.RevertChangeSet)
+}
+
+// Redo 重做最近一次撤销的操作，返回被重做的变更集
+func (s *HistoryService) Redo() (*model.ChangeSet, error) {
+	return s.replay(&s.redo, &s.undo, apperrors.ErrNothingToRedo, s.repo.ReapplyChangeSet)
+}
+
+// RecordHistory 返回记录的全部变更，按先后顺序排列
+func (s *HistoryService) RecordHistory(id int64) ([]model.RecordChange, error) {
+	changes, err := s.repo.ListChanges(model.EntityRecord, id)
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]model.RecordChange, 0, len(changes))
+	for _, c := range changes {
+		rc := model.RecordChange{SetID: c.SetID, Action: c.Action, CreatedAt: c.CreatedAt}
+		if rc.Before, err = decodeRecord(c.Before); err != nil {
+			return nil, err
+		}
+		if rc.After, err = decodeRecord(c.After); err != nil {
+			return nil, err
+		}
+		history = append(history, rc)
+	}
+	return history, nil
+}
+
+// decodeRecord 解码记录快照，快照为空时返回 nil
+func decodeRecord(data json.RawMessage) (*model.Record, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var rec model.Record
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"testing"
+
+	apperrors "dog-view/internal/errors"
+	"dog-view/internal/model"
+	"dog-view/internal/repository"
+)
+
+func TestHistoryUndoRedo(t *testing.T) {
+	repo := repository.NewMemoryRepository()
+	food := &model.Category{Name: "餐饮", Type: model.TypeExpense}
+	if err := repo.CreateCategory(food); err != nil {
+		t.Fatal(err)
+	}
+	history, err := NewHistoryService(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 服务创建之前的变更不能撤销
+	if _, err := history.Undo(); err != apperrors.ErrNothingToUndo {
+		t.Fatalf("Undo() = %v，期望 ErrNothingToUndo", err)
+	}
+
+	rec := &model.Record{Amount: model.NewMoney(100, model.DefaultCurrency), Type: model.TypeExpense, CategoryID: food.ID, Date: "2024-01-01"}
+	if err := repo.CreateRecord(rec); err != nil {
+		t.Fatal(err)
+	}
+	rec.Amount = model.NewMoney(200, model.DefaultCurrency)
+	if err := repo.UpdateRecord(rec); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{model.ActionUpdateRecord, model.ActionCreateRecord} {
+		set, err := history.Undo()
+		if err != nil || set.Action != want {
+			t.Fatalf("Undo() = %+v, %v，期望 %s", set, err, want)
+		}
+	}
+	if _, err := history.Undo(); err != apperrors.ErrNothingToUndo {
+		t.Fatalf("Undo() = %v，期望 ErrNothingToUndo", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := history.Redo(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := repo.GetRecordByID(rec.ID)
+	if err != nil || got.Amount.Minor != 200 {
+		t.Fatalf("重做后记录为 %+v, %v", got, err)
+	}
+
+	// 新的修改清空重做栈
+	if _, err := history.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.CreateTag(&model.Tag{Name: "出差"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := history.Redo(); err != apperrors.ErrNothingToRedo {
+		t.Fatalf("Redo() = %v，期望 ErrNothingToRedo", err)
+	}
+}
+
+func TestRecordUpdateUndoesTagsTogether(t *testing.T) {
+	repo := repository.NewMemoryRepository()
+	settings := NewSettingsService(repo)
+	records := NewRecordService(repo, settings, NewRateService(repo, settings))
+	food := &model.Category{Name: "餐饮", Type: model.TypeExpense}
+	if err := repo.CreateCategory(food); err != nil {
+		t.Fatal(err)
+	}
+	trip := &model.Tag{Name: "出差"}
+	if err := repo.CreateTag(trip); err != nil {
+		t.Fatal(err)
+	}
+	if err := records.Create(model.Money{Minor: 100}, model.TypeExpense, food.ID, 0, "午饭", "2024-01-01", []int64{trip.ID}); err != nil {
+		t.Fatal(err)
+	}
+	all, err := repo.GetAllRecords()
+	if err != nil || len(all) != 1 {
+		t.Fatalf("GetAllRecords() = %v, %v", all, err)
+	}
+	id := all[0].ID
+
+	history, err := NewHistoryService(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := records.Update(id, model.Money{Minor: 300}, food.ID, 0, "晚饭", "2024-01-01", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// 修改字段和清除标签是同一次操作，撤销一次即全部还原
+	set, err := history.Undo()
+	if err != nil || set.Action != model.ActionUpdateRecord {
+		t.Fatalf("Undo() = %+v, %v", set, err)
+	}
+	got, err := repo.GetRecordByID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Amount.Minor != 100 || got.Note != "午饭" || len(got.Tags) != 1 || got.Tags[0].ID != trip.ID {
+		t.Fatalf("撤销后记录为 %+v", got)
+	}
+	if _, err := history.Undo(); err != apperrors.ErrNothingToUndo {
+		t.Fatalf("Undo() = %v，期望 ErrNothingToUndo", err)
+	}
+}
@@ -0,0 +1,260 @@
+package service
+
+import (
+	"strings"
+	"time"
+
+	apperrors "dog-view/internal/errors"
+	"dog-view/internal/model"
+	"dog-view/internal/repository"
+)
+
+// defaultImportIcon 导入时自动创建的分类使用的图标
+const defaultImportIcon = "📦"
+
+// importPlanner 按账本当前的分类和标签逐行校验导入数据，汇总需要新建的分类和标签。
+// 预览和提交使用同一套校验，提交时账本可能已经变化，需要重新规划
+type importPlanner struct {
+	existing map[string]model.Category // 已有分类，按名称
+	tags     map[string]int64          // 已有标签，按名称
+	prints   map[string]int            // 已有记录的指纹及条数，每匹配一行消耗一条
+	today    string
+	base     string // 本位币，未填写币种的行使用
+
+	preview model.ImportPreview
+	newCats map[string]model.ImportCategory
+	newTags map[string]bool
+	amounts []model.Money // 与 preview.Rows 一一对应，仅校验通过的行有值
+}
+
+func newImportPlanner(repo repository.Repository, settings *SettingsService) (*importPlanner, error) {
+	base, err := settings.BaseCurrency()
+	if err != nil {
+		return nil, err
+	}
+	categories, err := repo.ListCategories("")
+	if err != nil {
+		return nil, err
+	}
+	tags, err := repo.ListTags()
+	if err != nil {
+		return nil, err
+	}
+	records, err := repo.GetAllRecords()
+	if err != nil {
+		return nil, err
+	}
+
+	p := &importPlanner{
+		existing: make(map[string]model.Category, len(categories)),
+		tags:     make(map[string]int64, len(tags)),
+		prints:   make(map[string]int, len(records)),
+		today:    time.Now().Format(DateLayout),
+		base:     base,
+		newCats:  make(map[string]model.ImportCategory),
+		newTags:  make(map[string]bool),
+		preview: model.ImportPreview{
+			Rows:          []model.ImportRow{},
+			NewCategories: []model.ImportCategory{},
+			NewTags:       []model.ImportTag{},
+
+			DuplicatePolicy: model.DuplicateSkip,
+		},
+	}
+	for _, c := range categories {
+		p.existing[c.Name] = c
+	}
+	for _, t := range tags {
+		p.tags[t.Name] = t.ID
+	}
+	for _, rec := range records {
+		p.prints[fingerprint(rec)]++
+	}
+	return p, nil
+}
+
+// category 按名称查找已有或将要创建的分类，返回类型和是否为一级分类
+func (p *importPlanner) category(name string) (typ string, topLevel, found bool) {
+	if c, ok := p.existing[name]; ok {
+		return c.Type, c.ParentID == 0, true
+	}
+	if c, ok := p.newCats[name]; ok {
+		return c.Type, c.Parent == "", true
+	}
+	return "", false, false
+}
+
+// declareCategories 登记文件中定义的分类（JSON 导出文件），已存在或无效的分类跳过。
+// 上级分类不存在、类型不同或本身是子分类时按一级分类创建
+func (p *importPlanner) declareCategories(categories []model.ImportCategory) {
+	for _, c := range categories {
+		c.Name = strings.TrimSpace(c.Name)
+		if c.Name == "" || c.Type != model.TypeIncome && c.Type != model.TypeExpense {
+			continue
+		}
+		if _, _, found := p.category(c.Name); found {
+			continue
+		}
+		if c.Parent != "" {
+			typ, topLevel, found := p.category(c.Parent)
+			if !found || typ != c.Type || !topLevel {
+				c.Parent = ""
+			}
+		}
+		if c.Icon == "" {
+			c.Icon = defaultImportIcon
+		}
+		c.FromFile = true
+		p.newCats[c.Name] = c
+		p.preview.NewCategories = append(p.preview.NewCategories, c)
+	}
+}
+
+// declareTags 登记文件中定义的标签，保留颜色
+func (p *importPlanner) declareTags(tags []model.ImportTag) {
+	for _, t := range tags {
+		name, err := normalizeTagName(t.Name)
+		if err != nil {
+			continue
+		}
+		if _, ok := p.tags[name]; ok || p.newTags[name] {
+			continue
+		}
+		p.newTags[name] = true
+		p.preview.NewTags = append(p.preview.NewTags, model.ImportTag{Name: name, Color: t.Color, FromFile: true})
+	}
+}
+
+// add 校验一行并加入预览。只有校验通过的行才会引入自动创建的分类和标签
+func (p *importPlanner) add(row model.ImportRow) {
+	row.Errors = []model.ImportIssue{}
+	row.Warnings = []model.ImportIssue{}
+	fail := func(field string, err error) {
+		row.Errors = append(row.Errors, model.ImportIssue{Field: field, Message: err.Error()})
+	}
+	warn := func(field, message string) {
+		row.Warnings = append(row.Warnings, model.ImportIssue{Field: field, Message: message})
+	}
+
+	if err := validateDate(row.Date); err != nil {
+		fail("date", err)
+	} else if row.Date > p.today {
+		warn("date", "日期晚于今天")
+	}
+
+	validType := false
+	switch row.Type {
+	case model.TypeIncome, model.TypeExpense:
+		validType = true
+	case model.TypeTransfer:
+		fail("type", apperrors.ErrTransferImport)
+	default:
+		fail("type", apperrors.ErrInvalidRecordType)
+	}
+
+	// 与手动记账一样，未填写币种时使用本位币
+	row.Currency = strings.ToUpper(strings.TrimSpace(row.Currency))
+	if row.Currency == "" {
+		row.Currency = p.base
+	}
+	amount, err := model.ParseMoney(row.Amount, row.Currency)
+	switch {
+	case !model.ValidCurrency(row.Currency):
+		fail("currency", apperrors.ErrInvalidCurrency)
+	case err != nil || amount.Minor <= 0:
+		fail("amount", apperrors.ErrInvalidAmount)
+	}
+
+	row.Category = strings.TrimSpace(row.Category)
+	var newCategory *model.ImportCategory
+	if row.Category == "" {
+		fail("category", apperrors.ErrCategoryRequired)
+	} else if typ, _, found := p.category(row.Category); found {
+		if validType && typ != row.Type {
+			fail("category", apperrors.ErrCategoryTypeMismatch)
+		}
+		if c, ok := p.existing[row.Category]; ok && c.Archived {
+			warn("category", "分类已归档")
+		}
+	} else if validType {
+		newCategory = &model.ImportCategory{Name: row.Category, Icon: defaultImportIcon, Type: row.Type}
+	}
+
+	// 文件中有多行相同时，只有与已有记录条数相同的行视为重复
+	if c, ok := p.existing[row.Category]; ok && len(row.Errors) == 0 {
+		fp := fingerprint(model.Record{Date: row.Date, Type: row.Type, CategoryID: c.ID, Amount: amount, Note: row.Note})
+		if p.prints[fp] > 0 {
+			p.prints[fp]--
+			row.Duplicate = true
+			warn("", "可能与已有记录重复")
+		}
+	}
+
+	var newTags []string
+	for _, name := range row.Tags {
+		name, err := normalizeTagName(name)
+		if err != nil {
+			warn("tags", "标签名称无效，已忽略")
+			continue
+		}
+		if _, ok := p.tags[name]; !ok && !p.newTags[name] {
+			newTags = append(newTags, name)
+		}
+	}
+
+	if len(row.Errors) > 0 {
+		p.preview.Invalid++
+		p.amounts = append(p.amounts, model.Money{})
+	} else {
+		p.preview.Valid++
+		p.amounts = append(p.amounts, amount)
+		if row.Duplicate {
+			p.preview.Duplicates++
+			if p.preview.DuplicatePolicy == model.DuplicateSkip {
+				newTags = nil // 跳过的行不引入新标签
+			}
+		}
+		if newCategory != nil {
+			p.newCats[newCategory.Name] = *newCategory
+			p.preview.NewCategories = append(p.preview.NewCategories, *newCategory)
+		}
+		for _, name := range newTags {
+			if !p.newTags[name] {
+				p.newTags[name] = true
+				p.preview.NewTags = append(p.preview.NewTags, model.ImportTag{Name: name})
+			}
+		}
+	}
+	p.preview.Rows = append(p.preview.Rows, row)
+}
+
+// plan 按计划重新规划：文件中定义的分类和标签原样登记，其余由各行重新推导，
+// 因此用户在预览后删除的行不会再引入新分类或标签
+func (p *importPlanner) plan(plan *model.ImportPreview) error {
+	switch plan.DuplicatePolicy {
+	case model.DuplicateSkip, model.DuplicateKeep, model.DuplicateFlag:
+		p.preview.DuplicatePolicy = plan.DuplicatePolicy
+	case "":
+	default:
+		return apperrors.ErrInvalidDupPolicy
+	}
+
+	var categories []model.ImportCategory
+	for _, c := range plan.NewCategories {
+		if c.FromFile {
+			categories = append(categories, c)
+		}
+	}
+	var tags []model.ImportTag
+	for _, t := range plan.NewTags {
+		if t.FromFile {
+			tags = append(tags, t)
+		}
+	}
+	p.declareCategories(categories)
+	p.declareTags(tags)
+	for _, row := range plan.Rows {
+		p.add(row)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"dog-view/internal/model"
+	"dog-view/internal/repository"
+)
+
+func writeImportFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestImportPreviewAndCommit(t *testing.T) {
+	repo := repository.NewMemoryRepository()
+	food := &model.Category{Name: "餐饮", Icon: "🍜", Type: model.TypeExpense}
+	if err := repo.CreateCategory(food); err != nil {
+		t.Fatal(err)
+	}
+	svc := NewExportService(repo, NewSettingsService(repo))
+
+	path := writeImportFile(t, "in.csv", "\ufeffdate,type,category,amount,note,currency,tags\n"+
+		"2024-01-02,expense,餐饮,12.50,午饭,CNY,工作\n"+
+		"2024-13-02,expense,餐饮,12.50\n"+
+		"2024-01-03,income,餐饮,100\n"+
+		"2024-01-04,expense,交通,3,,usd,通勤|工作\n"+
+		"2024-01-05,expense,购物,-3\n")
+	preview, err := svc.PreviewCSV(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 日期无效、分类类型不符和金额为负的行校验失败，只有通过校验的行引入新分类和标签
+	if preview.Valid != 2 || preview.Invalid != 3 {
+		t.Fatalf("Valid = %d, Invalid = %d", preview.Valid, preview.Invalid)
+	}
+	if len(preview.NewCategories) != 1 || preview.NewCategories[0].Name != "交通" {
+		t.Fatalf("NewCategories = %+v", preview.NewCategories)
+	}
+	if len(preview.NewTags) != 2 {
+		t.Fatalf("NewTags = %+v", preview.NewTags)
+	}
+	if all, _ := repo.GetAllRecords(); len(all) != 0 {
+		t.Fatal("预览写入了记录")
+	}
+
+	result, err := svc.CommitImport(preview)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.BatchID == 0 || result.Imported != 2 || result.Skipped != 3 || result.CategoriesCreated != 1 || result.TagsCreated != 2 {
+		t.Fatalf("CommitImport() = %+v", result)
+	}
+	all, err := repo.GetAllRecords()
+	if err != nil || len(all) != 2 {
+		t.Fatalf("GetAllRecords() = %v, %v", all, err)
+	}
+	for _, rec := range all {
+		if rec.Date == "2024-01-04" && (rec.Amount != model.NewMoney(300, "USD") || len(rec.Tags) != 2) {
+			t.Fatalf("导入的记录为 %+v", rec)
+		}
+	}
+}
+
+func TestImportUsesBaseCurrency(t *testing.T) {
+	repo := repository.NewMemoryRepository()
+	settings := NewSettingsService(repo)
+	if err := settings.SetBaseCurrency("JPY"); err != nil {
+		t.Fatal(err)
+	}
+	svc := NewExportService(repo, settings)
+
+	path := writeImportFile(t, "in.csv", "date,type,category,amount\n2024-01-02,expense,餐饮,1500\n")
+	preview, err := svc.PreviewCSV(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preview.Valid != 1 || preview.Rows[0].Currency != "JPY" {
+		t.Fatalf("预览行为 %+v", preview.Rows)
+	}
+	if _, err := svc.CommitImport(preview); err != nil {
+		t.Fatal(err)
+	}
+	all, err := repo.GetAllRecords()
+	if err != nil || len(all) != 1 || all[0].Amount != model.NewMoney(1500, "JPY") {
+		t.Fatalf("GetAllRecords() = %+v, %v", all, err)
+	}
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	apperrors "dog-view/internal/errors"
+	"dog-view/internal/model"
+	"dog-view/internal/repository"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// 应用锁设置项键名
+const (
+	SettingLockPINHash     = "lock_pin_hash"
+	SettingLockIdleMinutes = "lock_idle_minutes"
+	SettingLockOnMinimize  = "lock_on_minimize"
+)
+
+// PIN 长度限制与自动锁定时间的默认值、上限
+const (
+	MinPINLength           = 4
+	MaxPINLength           = 64
+	DefaultLockIdleMinutes = 5
+	MaxLockIdleMinutes     = 1440
+)
+
+// PIN 哈希的 Argon2id 参数，编码在哈希字符串中，调整后已保存的哈希仍可校验
+const (
+	pinHashTime    = 2
+	pinHashMemory  = 32 * 1024
+	pinHashThreads = 2
+	pinHashSize    = 32
+	pinSaltSize    = 16
+)
+
+type LockService struct {
+	repo repository.Repository
+}
+
+func NewLockService(repo repository.Repository) *LockService {
+	return &LockService{repo: repo}
+}
+
+// Settings 返回应用锁设置，未设置的项使用默认值
+func (s *LockService) Settings() (model.LockSettings, error) {
+	hash, err := s.repo.GetSetting(SettingLockPINHash)
+	if err != nil {
+		return model.LockSettings{}, err
+	}
+	idle, err := s.repo.GetSetting(SettingLockIdleMinutes)
+	if err != nil {
+		return model.LockSettings{}, err
+	}
+	onMinimize, err := s.repo.GetSetting(SettingLockOnMinimize)
+	if err != nil {
+		return model.LockSettings{}, err
+	}
+
+	settings := model.LockSettings{
+		Enabled:        hash != "",
+		IdleMinutes:    DefaultLockIdleMinutes,
+		LockOnMinimize: onMinimize == "1",
+	}
+	if n, err := strconv.Atoi(idle); err == nil && n >= 0 && n <= MaxLockIdleMinutes {
+		settings.IdleMinutes = n
+	}
+	return settings, nil
+}
+
+// Verify 校验 PIN，未设置 PIN 时返回 ErrNoPIN
+func (s *LockService) Verify(pin string) (bool, error) {
+	hash, err := s.repo.GetSetting(SettingLockPINHash)
+	if err != nil {
+		return false, err
+	}
+	if hash == "" {
+		return false, apperrors.ErrNoPIN
+	}
+	return verifyPIN(hash, pin), nil
+}
+
+// SetPIN 设置或修改 PIN，已设置 PIN 时需提供当前 PIN
+func (s *LockService) SetPIN(currentPIN, newPIN string) error {
+	if n := utf8.RuneCountInString(newPIN); n < MinPINLength || n > MaxPINLength {
+		return apperrors.ErrInvalidPIN
+	}
+	if err := s.checkCurrent(currentPIN); err != nil {
+		return err
+	}
+	hash, err := hashPIN(newPIN)
+	if err != nil {
+		return err
+	}
+	return s.repo.SetSetting(SettingLockPINHash, hash)
+}
+
+// RemovePIN 清除 PIN，关闭应用锁
+func (s *LockService) RemovePIN(currentPIN string) error {
+	ok, err := s.Verify(currentPIN)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return apperrors.ErrWrongPIN
+	}
+	return s.repo.SetSetting(SettingLockPINHash, "")
+}
+
+// checkCurrent 已设置 PIN 时校验当前 PIN
+func (s *LockService) checkCurrent(pin string) error {
+	ok, err := s.Verify(pin)
+	if err == apperrors.ErrNoPIN {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return apperrors.ErrWrongPIN
+	}
+	return nil
+}
+
+// SetOptions 设置空闲自动锁定时间（0 表示不自动锁定）和最小化时是否锁定
+func (s *LockService) SetOptions(idleMinutes int, lockOnMinimize bool) error {
+	if idleMinutes < 0 || idleMinutes > MaxLockIdleMinutes {
+		return apperrors.ErrInvalidIdleMinutes
+	}
+	if err := s.repo.SetSetting(SettingLockIdleMinutes, strconv.Itoa(idleMinutes)); err != nil {
+		return err
+	}
+	onMinimize := "0"
+	if lockOnMinimize {
+		onMinimize = "1"
+	}
+	return s.repo.SetSetting(SettingLockOnMinimize, onMinimize)
+}
+
+// hashPIN 生成加盐哈希，格式为 argon2id$time$memory$threads$salt$hash（base64）
+func hashPIN(pin string) (string, error) {
+	salt := make([]byte, pinSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	sum := argon2.IDKey([]byte(pin), salt, pinHashTime, pinHashMemory, pinHashThreads, pinHashSize)
+	enc := base64.RawStdEncoding
+	return fmt.Sprintf("argon2id$%d$%d$%d$%s$%s",
+		pinHashTime, pinHashMemory, pinHashThreads, enc.EncodeToString(salt), enc.EncodeToString(sum)), nil
+}
+
+// verifyPIN 按哈希中记录的参数重新计算并比较，哈希格式错误时视为不匹配
+func verifyPIN(encoded, pin string) bool {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[0] != "argon2id" {
+		return false
+	}
+	t, err1 := strconv.ParseUint(parts[1], 10, 32)
+	m, err2 := strconv.ParseUint(parts[2], 10, 32)
+	p, err3 := strconv.ParseUint(parts[3], 10, 8)
+	salt, err4 := base64.RawStdEncoding.DecodeString(parts[4])
+	want, err5 := base64.RawStdEncoding.DecodeString(parts[5])
+	for _, err := range []error{err1, err2, err3, err4, err5} {
+		if err != nil {
+			return false
+		}
+	}
+	got := argon2.IDKey([]byte(pin), salt, uint32(t), uint32(m), uint8(p), uint32(len(want)))
+	return subtle.ConstantTimeCompare(got, want) == 1
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	apperrors "dog-view/internal/errors"
+)
+
+// DateLayout 记录日期格式
+const DateLayout = "2006-01-02"
+
+// validateDate 校验日期为 YYYY-MM-DD 格式
+func validateDate(date string) error {
+	if _, err := time.Parse(DateLayout, date); err != nil {
+		return apperrors.ErrInvalidDate
+	}
+	return nil
+}
+
+// nextDay 返回下一天，用于把“截至某天（含）”转换为右开区间的终点
+func nextDay(date string) (string, error) {
+	t, err := time.Parse(DateLayout, date)
+	if err != nil {
+		return "", apperrors.ErrInvalidDate
+	}
+	return t.AddDate(0, 0, 1).Format(DateLayout), nil
+}
+
+// Period 记账周期，日期区间为左闭右开 [Start, End)
+// 周期以起始所在的自然月命名，例如起始日为 10 时，2024 年 1 月表示 2024-01-10 至 2024-02-09
+type Period struct {
+	Year  int    `json:"year"`
+	Month int    `json:"month"`
+	Start string `json:"start"` // 包含
+	End   string `json:"end"`   // 不包含
+}
+
+// NewPeriod 根据年月和每月起始日构造记账周期
+func NewPeriod(year, month, startDay int) Period {
+	start := time.Date(year, time.Month(month), startDay, 0, 0, 0, 0, time.UTC)
+	end := time.Date(year, time.Month(month)+1, startDay, 0, 0, 0, 0, time.UTC)
+	return Period{
+		Year:  start.Year(),
+		Month: int(start.Month()),
+		Start: start.Format(DateLayout),
+		End:   end.Format(DateLayout),
+	}
+}
+
+// PeriodOf 返回某一天所属的记账周期
+func PeriodOf(date time.Time, startDay int) Period {
+	year, month := date.Year(), int(date.Month())
+	if date.Day() < startDay {
+		month--
+	}
+	return NewPeriod(year, month, startDay)
+}
+
+// Label 周期名称，如 "2024-01"
+func (p Period) Label() string {
+	return fmt.Sprintf("%04d-%02d", p.Year, p.Month)
+}
+
+// Contains 判断日期（YYYY-MM-DD）是否落在周期内
+func (p Period) Contains(date string) bool {
+	return date >= p.Start && date < p.End
+}
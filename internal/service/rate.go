@@ -0,0 +1,193 @@
+package service
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	apperrors "dog-view/internal/errors"
+	"dog-view/internal/export"
+	"dog-view/internal/model"
+	"dog-view/internal/repository"
+)
+
+// RateService 维护本地汇率表，并按记录日期把金额换算为本位币
+type RateService struct {
+	repo     repository.Repository
+	settings *SettingsService
+}
+
+func NewRateService(repo repository.Repository, settings *SettingsService) *RateService {
+	return &RateService{repo: repo, settings: settings}
+}
+
+// parseRate 解析正的十进制汇率，如 "7.1", "0.0482"
+func parseRate(s string) (*big.Rat, error) {
+	s = strings.TrimSpace(s)
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	if (intPart != "" && !isDigits(intPart)) || (fracPart != "" && !isDigits(fracPart)) || intPart+fracPart == "" {
+		return nil, apperrors.ErrInvalidExchangeRate
+	}
+
+	rate, ok := new(big.Rat).SetString(s)
+	if !ok || rate.Sign() <= 0 {
+		return nil, apperrors.ErrInvalidExchangeRate
+	}
+	return rate, nil
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// validateRate 校验并规范化汇率
+func validateRate(rate *model.ExchangeRate) error {
+	rate.From = strings.ToUpper(strings.TrimSpace(rate.From))
+	rate.To = strings.ToUpper(strings.TrimSpace(rate.To))
+	if !model.ValidCurrency(rate.From) || !model.ValidCurrency(rate.To) || rate.From == rate.To {
+		return apperrors.ErrInvalidCurrency
+	}
+	if err := validateDate(rate.Date); err != nil {
+		return err
+	}
+	if _, err := parseRate(rate.Rate); err != nil {
+		return err
+	}
+	rate.Rate = strings.TrimSpace(rate.Rate)
+	return nil
+}
+
+func (s *RateService) List() ([]model.ExchangeRate, error) {
+	return s.repo.ListExchangeRates()
+}
+
+// Save 录入某日汇率：1 单位 from 兑换 rate 单位 to
+func (s *RateService) Save(from, to, date, rate string) error {
+	r := &model.ExchangeRate{From: from, To: to, Date: date, Rate: rate}
+	if err := validateRate(r); err != nil {
+		return err
+	}
+	return s.repo.SaveExchangeRate(r)
+}
+
+func (s *RateService) Delete(id int64) error {
+	return s.repo.DeleteExchangeRate(id)
+}
+
+// ImportCSV 从 CSV 导入汇率，任一行无效时整个文件都不导入
+func (s *RateService) ImportCSV(filePath string) (int, error) {
+	rates, err := export.ImportRatesCSV(filePath)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range rates {
+		if err := validateRate(&rates[i]); err != nil {
+			return 0, fmt.Errorf("第 %d 行: %w", i+2, err)
+		}
+	}
+
+	if err := s.repo.SaveExchangeRates(rates); err != nil {
+		return 0, err
+	}
+	return len(rates), nil
+}
+
+// Convert 按 date 当天（或之前最近一天）的汇率把金额换算为 to 币种
+func (s *RateService) Convert(amount model.Money, to, date string) (model.Money, error) {
+	return s.newConverter(to).convert(amount, date)
+}
+
+// converter 一次统计中复用的换算器，缓存已查到的汇率
+type converter struct {
+	repo  repository.Repository
+	to    string
+	cache map[string]*big.Rat
+}
+
+func (s *RateService) newConverter(to string) *converter {
+	return &converter{repo: s.repo, to: to, cache: make(map[string]*big.Rat)}
+}
+
+// rate 查找 from→to 的汇率，没有直接汇率时使用反向汇率的倒数
+func (c *converter) rate(from, date string) (*big.Rat, error) {
+	key := from + "|" + date
+	if rate, ok := c.cache[key]; ok {
+		return rate, nil
+	}
+
+	var rate *big.Rat
+	direct, err := c.repo.FindExchangeRate(from, c.to, date)
+	switch {
+	case err == nil:
+		rate, err = parseRate(direct.Rate)
+	case err == apperrors.ErrExchangeRateNotFound:
+		var inverse *model.ExchangeRate
+		inverse, err = c.repo.FindExchangeRate(c.to, from, date)
+		if err == nil {
+			rate, err = parseRate(inverse.Rate)
+			if err == nil {
+				rate.Inv(rate)
+			}
+		}
+	}
+	if err == apperrors.ErrExchangeRateNotFound {
+		return nil, fmt.Errorf("%w: %s → %s（%s）", apperrors.ErrExchangeRateNotFound, from, c.to, date)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c.cache[key] = rate
+	return rate, nil
+}
+
+// convert 换算金额，结果按目标币种精度四舍五入
+func (c *converter) convert(amount model.Money, date string) (model.Money, error) {
+	if amount.Currency == c.to {
+		return amount, nil
+	}
+
+	rate, err := c.rate(amount.Currency, date)
+	if err != nil {
+		return model.Money{}, err
+	}
+
+	value := new(big.Rat).SetInt64(amount.Minor)
+	value.Mul(value, rate)
+	shift := model.CurrencyExponent(c.to) - model.CurrencyExponent(amount.Currency)
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(shift))), nil))
+	if shift >= 0 {
+		value.Mul(value, scale)
+	} else {
+		value.Quo(value, scale)
+	}
+
+	return model.NewMoney(roundRat(value), c.to), nil
+}
+
+// roundRat 四舍五入到整数（远离零）
+func roundRat(r *big.Rat) int64 {
+	q, m := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	m.Abs(m).Lsh(m, 1)
+	if m.Cmp(r.Denom()) >= 0 {
+		if r.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return q.Int64()
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -1,39 +1,215 @@
 package service
 
 import (
+	"sort"
+	"strings"
+	"time"
+
+	apperrors "dog-view/internal/errors"
 	"dog-view/internal/model"
 	"dog-view/internal/repository"
 )
 
 type RecordService struct {
-	repo *repository.SQLiteRepository
+	repo     repository.Repository
+	settings *SettingsService
+	rates    *RateService
+}
+
+func NewRecordService(repo repository.Repository, settings *SettingsService, rates *RateService) *RecordService {
+	return &RecordService{repo: repo, settings: settings, rates: rates}
+}
+
+// normalizeAmount 校验金额为正数和币种，未指定币种时使用本位币
+func (s *RecordService) normalizeAmount(amount model.Money) (model.Money, error) {
+	if amount.Minor <= 0 {
+		return amount, apperrors.ErrInvalidAmount
+	}
+
+	amount.Currency = strings.ToUpper(strings.TrimSpace(amount.Currency))
+	if amount.Currency == "" {
+		base, err := s.settings.BaseCurrency()
+		if err != nil {
+			return amount, err
+		}
+		amount.Currency = base
+	}
+	if !model.ValidCurrency(amount.Currency) {
+		return amount, apperrors.ErrInvalidCurrency
+	}
+	return amount, nil
+}
+
+// checkAccount 校验账户存在且与记录币种一致，0 表示未指定账户
+func (s *RecordService) checkAccount(accountID int64, currency string) error {
+	if accountID == 0 {
+		return nil
+	}
+	account, err := s.repo.GetAccountByID(accountID)
+	if err != nil {
+		return err
+	}
+	if account.OpeningBalance.Currency != currency {
+		return apperrors.ErrCurrencyMismatch
+	}
+	return nil
 }
 
-func NewRecordService(repo *repository.SQLiteRepository) *RecordService {
-	return &RecordService{repo: repo}
+// checkCategory 校验分类存在（不在回收站中）、未归档且类型与 recordType 一致
+func (s *RecordService) checkCategory(categoryID int64, recordType string) error {
+	categories, err := s.repo.ListCategories("")
+	if err != nil {
+		return err
+	}
+	c, ok := findCategory(categories, categoryID)
+	if !ok {
+		return apperrors.ErrCategoryNotFound
+	}
+	if c.Archived {
+		return apperrors.ErrCategoryArchived
+	}
+	if c.Type != recordType {
+		return apperrors.ErrCategoryTypeMismatch
+	}
+	return nil
 }
 
-func (s *RecordService) Create(amount float64, recordType string, categoryID int64, note, date string) error {
+func (s *RecordService) Create(amount model.Money, recordType string, categoryID, accountID int64, note, date string, tagIDs []int64) error {
+	amount, err := s.normalizeAmount(amount)
+	if err != nil {
+		return err
+	}
+	if recordType != model.TypeIncome && recordType != model.TypeExpense {
+		return apperrors.ErrInvalidRecordType
+	}
+	if err := validateDate(date); err != nil {
+		return err
+	}
+	if err := s.checkAccount(accountID, amount.Currency); err != nil {
+		return err
+	}
+	tags, err := resolveTags(s.repo, tagIDs)
+	if err != nil {
+		return err
+	}
+
 	record := &model.Record{
 		Amount:     amount,
 		Type:       recordType,
 		CategoryID: categoryID,
+		AccountID:  accountID,
+		Tags:       tags,
 		Note:       note,
 		Date:       date,
 	}
 	return s.repo.CreateRecord(record)
 }
 
-func (s *RecordService) Update(id int64, amount float64, categoryID int64, note, date string) error {
+// Update 修改收支记录，标签替换为 tagIDs
+func (s *RecordService) Update(id int64, amount model.Money, categoryID, accountID int64, note, date string, tagIDs []int64) error {
+	amount, err := s.normalizeAmount(amount)
+	if err != nil {
+		return err
+	}
+	if err := validateDate(date); err != nil {
+		return err
+	}
+	if err := s.checkAccount(accountID, amount.Currency); err != nil {
+		return err
+	}
+
+	existing, err := s.repo.GetRecordByID(id)
+	if err != nil {
+		return err
+	}
+	if existing.Type == model.TypeTransfer {
+		return apperrors.ErrInvalidRecordType
+	}
+	tags, err := resolveTags(s.repo, tagIDs)
+	if err != nil {
+		return err
+	}
+
 	return s.repo.UpdateRecord(&model.Record{
 		ID:         id,
 		Amount:     amount,
 		CategoryID: categoryID,
+		AccountID:  accountID,
 		Note:       note,
 		Date:       date,
+		Tags:       tags,
 	})
 }
 
+// validateTransfer 校验转账双方账户存在、不相同且币种与转账金额一致
+func (s *RecordService) validateTransfer(fromAccountID, toAccountID int64, currency string) error {
+	if fromAccountID == 0 || toAccountID == 0 {
+		return apperrors.ErrAccountNotFound
+	}
+	if fromAccountID == toAccountID {
+		return apperrors.ErrInvalidTransfer
+	}
+	if err := s.checkAccount(fromAccountID, currency); err != nil {
+		return err
+	}
+	return s.checkAccount(toAccountID, currency)
+}
+
+// CreateTransfer 在两个账户之间转账，转账记录不计入收支统计
+func (s *RecordService) CreateTransfer(amount model.Money, fromAccountID, toAccountID int64, note, date string) error {
+	amount, err := s.normalizeAmount(amount)
+	if err != nil {
+		return err
+	}
+	if err := validateDate(date); err != nil {
+		return err
+	}
+	if err := s.validateTransfer(fromAccountID, toAccountID, amount.Currency); err != nil {
+		return err
+	}
+
+	return s.repo.CreateRecord(&model.Record{
+		Amount:      amount,
+		Type:        model.TypeTransfer,
+		AccountID:   fromAccountID,
+		ToAccountID: toAccountID,
+		Note:        note,
+		Date:        date,
+	})
+}
+
+// UpdateTransfer 修改转账记录
+func (s *RecordService) UpdateTransfer(id int64, amount model.Money, fromAccountID, toAccountID int64, note, date string) error {
+	amount, err := s.normalizeAmount(amount)
+	if err != nil {
+		return err
+	}
+	if err := validateDate(date); err != nil {
+		return err
+	}
+	if err := s.validateTransfer(fromAccountID, toAccountID, amount.Currency); err != nil {
+		return err
+	}
+
+	existing, err := s.repo.GetRecordByID(id)
+	if err != nil {
+		return err
+	}
+	if existing.Type != model.TypeTransfer {
+		return apperrors.ErrInvalidRecordType
+	}
+
+	return s.repo.UpdateRecord(&model.Record{
+		ID:          id,
+		Amount:      amount,
+		AccountID:   fromAccountID,
+		ToAccountID: toAccountID,
+		Note:        note,
+		Date:        date,
+	})
+}
+
+// Delete 将记录移入回收站
 func (s *RecordService) Delete(id int64) error {
 	return s.repo.DeleteRecord(id)
 }
@@ -42,35 +218,293 @@ func (s *RecordService) GetByID(id int64) (*model.Record, error) {
 	return s.repo.GetRecordByID(id)
 }
 
+// Period 返回指定年月对应的记账周期
+func (s *RecordService) Period(year, month int) (Period, error) {
+	startDay, err := s.settings.MonthStartDay()
+	if err != nil {
+		return Period{}, err
+	}
+	return NewPeriod(year, month, startDay), nil
+}
+
+// CurrentPeriod 返回今天所属的记账周期
+func (s *RecordService) CurrentPeriod() (Period, error) {
+	startDay, err := s.settings.MonthStartDay()
+	if err != nil {
+		return Period{}, err
+	}
+	return PeriodOf(time.Now(), startDay), nil
+}
+
 func (s *RecordService) ListByMonth(year, month int) ([]model.Record, error) {
-	return s.repo.ListRecordsByMonth(year, month)
+	period, err := s.Period(year, month)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListRecordsBetween(period.Start, period.End)
 }
 
+// convertedTotals 读取区间内的收支合计，并按各自日期的汇率换算为本位币
+func (s *RecordService) convertedTotals(startDate, endDate string) ([]model.StatTotal, string, error) {
+	totals, err := s.repo.GetStatTotals(startDate, endDate)
+	if err != nil {
+		return nil, "", err
+	}
+
+	base, err := s.toBase(totals)
+	if err != nil {
+		return nil, "", err
+	}
+	return totals, base, nil
+}
+
+// toBase 将合计金额原地换算为本位币，返回本位币代码
+func (s *RecordService) toBase(totals []model.StatTotal) (string, error) {
+	base, err := s.settings.BaseCurrency()
+	if err != nil {
+		return "", err
+	}
+
+	conv := s.rates.newConverter(base)
+	for i := range totals {
+		totals[i].Amount, err = conv.convert(totals[i].Amount, totals[i].Date)
+		if err != nil {
+			return "", err
+		}
+	}
+	return base, nil
+}
+
+// summarize 汇总收入、支出和结余
+func summarize(totals []model.StatTotal, currency string) *model.MonthSummary {
+	summary := &model.MonthSummary{
+		TotalIncome:  model.NewMoney(0, currency),
+		TotalExpense: model.NewMoney(0, currency),
+	}
+	for _, t := range totals {
+		switch t.Type {
+		case model.TypeIncome:
+			summary.TotalIncome = summary.TotalIncome.Add(t.Amount)
+		case model.TypeExpense:
+			summary.TotalExpense = summary.TotalExpense.Add(t.Amount)
+		}
+	}
+	summary.Balance = summary.TotalIncome.Sub(summary.TotalExpense)
+	return summary
+}
+
+// parentMap 返回子分类到其一级分类的映射，一级分类不在其中
+func parentMap(categories []model.Category) map[int64]int64 {
+	parents := make(map[int64]int64)
+	for _, c := range categories {
+		if c.ParentID != 0 {
+			parents[c.ID] = c.ParentID
+		}
+	}
+	return parents
+}
+
+// categoryStats 按分类汇总指定类型的金额，只返回金额大于 0 的分类，按金额倒序；
+// rollUp 为 true 时子分类的金额计入其一级分类，只返回一级分类，
+// 否则按记录所属的分类返回（直接记在一级分类上的金额单独列出）
+func categoryStats(totals []model.StatTotal, categories []model.Category, recordType, currency string, rollUp bool) []model.CategoryStat {
+	parents := make(map[int64]int64)
+	if rollUp {
+		parents = parentMap(categories)
+	}
+
+	total := model.NewMoney(0, currency)
+	sums := make(map[int64]int64)
+	for _, t := range totals {
+		if t.Type != recordType {
+			continue
+		}
+		total = total.Add(t.Amount)
+		id := t.CategoryID
+		if parent, ok := parents[id]; ok {
+			id = parent
+		}
+		sums[id] += t.Amount.Minor
+	}
+
+	// categories 已按排序值返回，稳定排序保证金额相同时沿用分类顺序
+	var stats []model.CategoryStat
+	for _, c := range categories {
+		if c.Type != recordType || sums[c.ID] <= 0 {
+			continue
+		}
+		s := model.CategoryStat{
+			CategoryID:   c.ID,
+			CategoryName: c.Name,
+			CategoryIcon: c.Icon,
+			ParentID:     c.ParentID,
+			Amount:       model.NewMoney(sums[c.ID], currency),
+		}
+		s.Percentage = s.Amount.Percentage(total)
+		stats = append(stats, s)
+	}
+
+	sort.SliceStable(stats, func(i, j int) bool {
+		return stats[i].Amount.Minor > stats[j].Amount.Minor
+	})
+	return stats
+}
+
+// GetMonthSummary 获取记账周期的收支汇总，金额换算为本位币
 func (s *RecordService) GetMonthSummary(year, month int) (*model.MonthSummary, error) {
-	return s.repo.GetMonthSummary(year, month)
+	period, err := s.Period(year, month)
+	if err != nil {
+		return nil, err
+	}
+
+	totals, base, err := s.convertedTotals(period.Start, period.End)
+	if err != nil {
+		return nil, err
+	}
+	return summarize(totals, base), nil
 }
 
-func (s *RecordService) GetCategoryStats(year, month int) (*model.CategoryStatsResponse, error) {
-	incomeStats, err := s.repo.GetCategoryStats(year, month, model.TypeIncome)
+// GetCategoryStats 获取记账周期的分类统计，金额换算为本位币；
+// rollUp 为 true 时按一级分类汇总，否则按明细分类统计，供饼图下钻使用
+func (s *RecordService) GetCategoryStats(year, month int, rollUp bool) (*model.CategoryStatsResponse, error) {
+	period, err := s.Period(year, month)
 	if err != nil {
 		return nil, err
 	}
 
-	expenseStats, err := s.repo.GetCategoryStats(year, month, model.TypeExpense)
+	totals, base, err := s.convertedTotals(period.Start, period.End)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := s.repo.ListCategories("")
 	if err != nil {
 		return nil, err
 	}
 
 	return &model.CategoryStatsResponse{
-		IncomeStats:  incomeStats,
-		ExpenseStats: expenseStats,
+		IncomeStats:  categoryStats(totals, categories, model.TypeIncome, base, rollUp),
+		ExpenseStats: categoryStats(totals, categories, model.TypeExpense, base, rollUp),
 	}, nil
 }
 
+// GetTrendStats 获取一年中 12 个记账周期的收支趋势，金额换算为本位币
 func (s *RecordService) GetTrendStats(year int) ([]model.MonthTrend, error) {
-	return s.repo.GetMonthlyTrends(year)
+	startDay, err := s.settings.MonthStartDay()
+	if err != nil {
+		return nil, err
+	}
+
+	periods := make([]Period, 12)
+	for i := range periods {
+		periods[i] = NewPeriod(year, i+1, startDay)
+	}
+
+	totals, base, err := s.convertedTotals(periods[0].Start, periods[11].End)
+	if err != nil {
+		return nil, err
+	}
+
+	trends := make([]model.MonthTrend, 12)
+	for i, period := range periods {
+		var inPeriod []model.StatTotal
+		for _, t := range totals {
+			if period.Contains(t.Date) {
+				inPeriod = append(inPeriod, t)
+			}
+		}
+
+		summary := summarize(inPeriod, base)
+		trends[i] = model.MonthTrend{
+			Month:   period.Label(),
+			Income:  summary.TotalIncome,
+			Expense: summary.TotalExpense,
+		}
+	}
+
+	return trends, nil
 }
 
 func (s *RecordService) GetRecentRecords(limit int) ([]model.Record, error) {
 	return s.repo.GetRecentRecords(limit)
 }
+
+// Search 按备注搜索记录，query 按空白拆分为多个词，记录需包含全部词
+func (s *RecordService) Search(query string, limit int) ([]model.SearchResult, error) {
+	terms := strings.Fields(query)
+	if len(terms) == 0 {
+		return []model.SearchResult{}, nil
+	}
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	if limit > MaxPageSize {
+		limit = MaxPageSize
+	}
+	return s.repo.SearchRecords(terms, limit)
+}
+
+// 分页查询每页的默认和最大条数
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 500
+)
+
+// Query 按筛选条件分页查询记录，同时返回整个筛选结果的记录数和本位币收支合计
+func (s *RecordService) Query(q model.RecordQuery) (*model.RecordPage, error) {
+	switch q.SortBy {
+	case "":
+		q.SortBy = model.SortByDate
+	case model.SortByDate, model.SortByAmount:
+	default:
+		return nil, apperrors.ErrInvalidSortField
+	}
+	for _, t := range q.Filter.Types {
+		if t != model.TypeIncome && t != model.TypeExpense && t != model.TypeTransfer {
+			return nil, apperrors.ErrInvalidRecordType
+		}
+	}
+	if q.Filter.MinAmount < 0 || q.Filter.MaxAmount < 0 ||
+		(q.Filter.MaxAmount != 0 && q.Filter.MinAmount > q.Filter.MaxAmount) {
+		return nil, apperrors.ErrInvalidAmount
+	}
+	if q.Cursor != "" {
+		if _, err := model.ParseRecordCursor(q.Cursor); err != nil {
+			return nil, apperrors.ErrInvalidCursor
+		}
+	}
+	if q.Limit <= 0 {
+		q.Limit = DefaultPageSize
+	}
+	if q.Limit > MaxPageSize {
+		q.Limit = MaxPageSize
+	}
+
+	// 多取一条判断是否还有下一页
+	limit := q.Limit
+	q.Limit++
+	records, err := s.repo.QueryRecords(q)
+	if err != nil {
+		return nil, err
+	}
+	page := &model.RecordPage{Records: records}
+	if len(records) > limit {
+		page.Records = records[:limit]
+		page.NextCursor = model.CursorOf(records[limit-1]).Encode()
+	}
+	if page.Records == nil {
+		page.Records = []model.Record{}
+	}
+
+	totals, count, err := s.repo.GetRecordTotals(q.Filter)
+	if err != nil {
+		return nil, err
+	}
+	base, err := s.toBase(totals)
+	if err != nil {
+		return nil, err
+	}
+	page.Total = count
+	page.Summary = *summarize(totals, base)
+	return page, nil
+}
@@ -0,0 +1,325 @@
+package service
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	apperrors "dog-view/internal/errors"
+	"dog-view/internal/model"
+	"dog-view/internal/repository"
+)
+
+type RecurringService struct {
+	repo    repository.Repository
+	records *RecordService
+}
+
+func NewRecurringService(repo repository.Repository, records *RecordService) *RecurringService {
+	return &RecurringService{repo: repo, records: records}
+}
+
+// validateRule 校验并规范化周期规则
+func (s *RecurringService) validateRule(rule *model.RecurringRule) error {
+	rule.Name = strings.TrimSpace(rule.Name)
+	if rule.Name == "" {
+		return apperrors.ErrInvalidRule
+	}
+
+	switch rule.Frequency {
+	case model.FrequencyDaily, model.FrequencyWeekly, model.FrequencyMonthly, model.FrequencyYearly:
+	default:
+		return apperrors.ErrInvalidRule
+	}
+	if rule.Interval == 0 {
+		rule.Interval = 1
+	}
+	if rule.Interval < 0 || rule.DayOfMonth < 0 || rule.DayOfMonth > 31 || rule.MaxCount < 0 {
+		return apperrors.ErrInvalidRule
+	}
+
+	if err := validateDate(rule.StartDate); err != nil {
+		return err
+	}
+	if rule.EndDate != "" {
+		if err := validateDate(rule.EndDate); err != nil {
+			return err
+		}
+		if rule.EndDate < rule.StartDate {
+			return apperrors.ErrInvalidRule
+		}
+	}
+
+	if rule.Type != model.TypeIncome && rule.Type != model.TypeExpense {
+		return apperrors.ErrInvalidRecordType
+	}
+	if err := s.records.checkCategory(rule.CategoryID, rule.Type); err != nil {
+		return err
+	}
+	amount, err := s.records.normalizeAmount(rule.Amount)
+	if err != nil {
+		return err
+	}
+	rule.Amount = amount
+	return s.records.checkAccount(rule.AccountID, amount.Currency)
+}
+
+func (s *RecurringService) List() ([]model.RecurringRule, error) {
+	return s.repo.ListRecurringRules()
+}
+
+// Create 创建周期规则，新规则默认启用
+func (s *RecurringService) Create(rule model.RecurringRule) (*model.RecurringRule, error) {
+	if err := s.validateRule(&rule); err != nil {
+		return nil, err
+	}
+	rule.Active = true
+	if err := s.repo.CreateRecurringRule(&rule); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// Update 修改周期规则，只影响尚未入账的发生
+func (s *RecurringService) Update(rule model.RecurringRule) error {
+	if err := s.validateRule(&rule); err != nil {
+		return err
+	}
+	return s.repo.UpdateRecurringRule(&rule)
+}
+
+// Delete 删除周期规则，已生成的记录保留
+func (s *RecurringService) Delete(id int64) error {
+	return s.repo.DeleteRecurringRule(id)
+}
+
+// nth 返回规则第 n 个周期的日期（未考虑开始日期、结束条件和周末）
+func nth(rule model.RecurringRule, start time.Time, n int) time.Time {
+	step := n * rule.Interval
+	switch rule.Frequency {
+	case model.FrequencyDaily:
+		return start.AddDate(0, 0, step)
+	case model.FrequencyWeekly:
+		return start.AddDate(0, 0, 7*step)
+	}
+
+	day := rule.DayOfMonth
+	if day == 0 {
+		day = start.Day()
+	}
+	year, month := start.Year(), start.Month()
+	if rule.Frequency == model.FrequencyMonthly {
+		month += time.Month(step)
+	} else {
+		year += step
+	}
+
+	// 发生日超过当月天数时取月末，如 31 号的规则在 2 月落在 28 或 29 号
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// scheduleUntil 返回规则在 until（含）之前的全部发生日期
+func scheduleUntil(rule model.RecurringRule, until string) []string {
+	start, err := time.Parse(DateLayout, rule.StartDate)
+	if err != nil {
+		return nil
+	}
+
+	var dates []string
+	for n := 0; ; n++ {
+		date := nth(rule, start, n)
+		d := date.Format(DateLayout)
+		if d > until || (rule.EndDate != "" && d > rule.EndDate) {
+			break
+		}
+		// 按月规则的发生日早于开始日期时，第一个周期不发生
+		if d < rule.StartDate {
+			continue
+		}
+		if rule.SkipWeekends && (date.Weekday() == time.Saturday || date.Weekday() == time.Sunday) {
+			continue
+		}
+		if rule.MaxCount > 0 && len(dates) >= rule.MaxCount {
+			break
+		}
+		dates = append(dates, d)
+	}
+	return dates
+}
+
+// occurrencesByDate 读取规则已保存的发生记录
+func (s *RecurringService) occurrencesByDate(ruleID int64) (map[string]model.Occurrence, error) {
+	occurrences, err := s.repo.ListOccurrences(ruleID)
+	if err != nil {
+		return nil, err
+	}
+	byDate := make(map[string]model.Occurrence, len(occurrences))
+	for _, o := range occurrences {
+		byDate[o.Date] = o
+	}
+	return byDate, nil
+}
+
+// instance 按规则模板和单次修改生成某次发生的内容
+func instance(rule model.RecurringRule, date string, o model.Occurrence) model.UpcomingOccurrence {
+	item := model.UpcomingOccurrence{
+		RuleID:     rule.ID,
+		RuleName:   rule.Name,
+		Date:       date,
+		Status:     model.OccurrencePending,
+		Amount:     rule.Amount,
+		Type:       rule.Type,
+		CategoryID: rule.CategoryID,
+		AccountID:  rule.AccountID,
+		Note:       rule.Note,
+	}
+	if o.Status != "" {
+		item.Status = o.Status
+	}
+	if o.Status == model.OccurrenceModified {
+		if o.Amount != nil {
+			item.Amount = *o.Amount
+		}
+		item.Note = o.Note
+	}
+	return item
+}
+
+// PostDue 为所有启用的规则补记 today（含）之前尚未入账的发生，返回新生成的记录数；
+// 已入账或已跳过的发生不会重复处理，可以安全地反复调用
+func (s *RecurringService) PostDue(today string) (int, error) {
+	rules, err := s.repo.ListRecurringRules()
+	if err != nil {
+		return 0, err
+	}
+
+	posted := 0
+	for _, rule := range rules {
+		if !rule.Active {
+			continue
+		}
+		existing, err := s.occurrencesByDate(rule.ID)
+		if err != nil {
+			return posted, err
+		}
+
+		for _, date := range scheduleUntil(rule, today) {
+			o := existing[date]
+			if o.Status == model.OccurrencePosted || o.Status == model.OccurrenceSkipped {
+				continue
+			}
+
+			item := instance(rule, date, o)
+			o.RuleID, o.Date = rule.ID, date
+			ok, err := s.repo.PostOccurrence(&o, &model.Record{
+				Amount:     item.Amount,
+				Type:       item.Type,
+				CategoryID: item.CategoryID,
+				AccountID:  item.AccountID,
+				Note:       item.Note,
+				Date:       date,
+			})
+			if err != nil {
+				return posted, err
+			}
+			if ok {
+				posted++
+			}
+		}
+	}
+	return posted, nil
+}
+
+// Upcoming 列出 today 起 days 天内（含）尚未入账的发生，包括已跳过的
+func (s *RecurringService) Upcoming(today string, days int) ([]model.UpcomingOccurrence, error) {
+	start, err := time.Parse(DateLayout, today)
+	if err != nil {
+		return nil, apperrors.ErrInvalidDate
+	}
+	until := start.AddDate(0, 0, days).Format(DateLayout)
+
+	rules, err := s.repo.ListRecurringRules()
+	if err != nil {
+		return nil, err
+	}
+
+	upcoming := []model.UpcomingOccurrence{}
+	for _, rule := range rules {
+		if !rule.Active {
+			continue
+		}
+		existing, err := s.occurrencesByDate(rule.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, date := range scheduleUntil(rule, until) {
+			if date < today || existing[date].Status == model.OccurrencePosted {
+				continue
+			}
+			upcoming = append(upcoming, instance(rule, date, existing[date]))
+		}
+	}
+
+	sort.SliceStable(upcoming, func(i, j int) bool {
+		return upcoming[i].Date < upcoming[j].Date
+	})
+	return upcoming, nil
+}
+
+// scheduledRule 获取规则并确认 date 是它的一个发生日
+func (s *RecurringService) scheduledRule(ruleID int64, date string) (*model.RecurringRule, error) {
+	if err := validateDate(date); err != nil {
+		return nil, err
+	}
+	rule, err := s.repo.GetRecurringRule(ruleID)
+	if err != nil {
+		return nil, err
+	}
+
+	dates := scheduleUntil(*rule, date)
+	if len(dates) == 0 || dates[len(dates)-1] != date {
+		return nil, apperrors.ErrNotScheduled
+	}
+	return rule, nil
+}
+
+// Skip 跳过某一次发生，已入账的发生需删除对应记录
+func (s *RecurringService) Skip(ruleID int64, date string) error {
+	if _, err := s.scheduledRule(ruleID, date); err != nil {
+		return err
+	}
+	return s.repo.SaveOccurrence(&model.Occurrence{
+		RuleID: ruleID,
+		Date:   date,
+		Status: model.OccurrenceSkipped,
+	})
+}
+
+// Modify 单独修改某一次发生的金额和备注，不影响规则的其他发生
+func (s *RecurringService) Modify(ruleID int64, date string, amount model.Money, note string) error {
+	rule, err := s.scheduledRule(ruleID, date)
+	if err != nil {
+		return err
+	}
+
+	amount, err = s.records.normalizeAmount(amount)
+	if err != nil {
+		return err
+	}
+	if err := s.records.checkAccount(rule.AccountID, amount.Currency); err != nil {
+		return err
+	}
+
+	return s.repo.SaveOccurrence(&model.Occurrence{
+		RuleID: ruleID,
+		Date:   date,
+		Status: model.OccurrenceModified,
+		Amount: &amount,
+		Note:   note,
+	})
+}
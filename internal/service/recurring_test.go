@@ -0,0 +1,72 @@
+package service
+
+import (
+	"testing"
+
+	apperrors "dog-view/internal/errors"
+	"dog-view/internal/model"
+	"dog-view/internal/repository"
+)
+
+// 周期规则的分类需存在、未归档、不在回收站中且与规则类型一致
+func TestRecurringRuleCategory(t *testing.T) {
+	repo := repository.NewMemoryRepository()
+	settings := NewSettingsService(repo)
+	rules := NewRecurringService(repo, NewRecordService(repo, settings, NewRateService(repo, settings)))
+
+	newCategory := func(name, recordType string) int64 {
+		t.Helper()
+		c := &model.Category{Name: name, Type: recordType}
+		if err := repo.CreateCategory(c); err != nil {
+			t.Fatal(err)
+		}
+		return c.ID
+	}
+	rent := newCategory("房租", model.TypeExpense)
+	salary := newCategory("工资", model.TypeIncome)
+	archived := newCategory("旧分类", model.TypeExpense)
+	if err := repo.SetCategoryArchived(archived, true); err != nil {
+		t.Fatal(err)
+	}
+	deleted := newCategory("已删除", model.TypeExpense)
+	if err := repo.DeleteCategory(deleted); err != nil {
+		t.Fatal(err)
+	}
+
+	rule := func(categoryID int64) model.RecurringRule {
+		return model.RecurringRule{
+			Name:       "房租",
+			Type:       model.TypeExpense,
+			CategoryID: categoryID,
+			Amount:     model.Money{Minor: 300000},
+			Frequency:  model.FrequencyMonthly,
+			StartDate:  "2024-01-01",
+		}
+	}
+
+	cases := []struct {
+		categoryID int64
+		want       error
+	}{
+		{0, apperrors.ErrCategoryNotFound},
+		{9999, apperrors.ErrCategoryNotFound},
+		{deleted, apperrors.ErrCategoryNotFound},
+		{archived, apperrors.ErrCategoryArchived},
+		{salary, apperrors.ErrCategoryTypeMismatch},
+	}
+	for _, c := range cases {
+		if _, err := rules.Create(rule(c.categoryID)); err != c.want {
+			t.Errorf("Create(category %d) = %v, want %v", c.categoryID, err, c.want)
+		}
+	}
+
+	created, err := rules.Create(rule(rent))
+	if err != nil {
+		t.Fatal(err)
+	}
+	update := *created
+	update.CategoryID = salary
+	if err := rules.Update(update); err != apperrors.ErrCategoryTypeMismatch {
+		t.Errorf("Update to an income category = %v, want ErrCategoryTypeMismatch", err)
+	}
+}
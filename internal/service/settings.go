@@ -0,0 +1,177 @@
+package service
+
+import (
+	"strconv"
+	"strings"
+
+	apperrors "dog-view/internal/errors"
+	"dog-view/internal/model"
+	"dog-view/internal/repository"
+)
+
+// 设置项键名
+const (
+	SettingMonthStartDay      = "month_start_day"
+	SettingBaseCurrency       = "base_currency"
+	SettingTrashRetentionDays = "trash_retention_days"
+	SettingBackupInterval     = "backup_interval_hours"
+	SettingBackupKeepDaily    = "backup_keep_daily"
+	SettingBackupKeepWeekly   = "backup_keep_weekly"
+	SettingBackupKeepMonthly  = "backup_keep_monthly"
+)
+
+// MaxMonthStartDay 每月起始日上限，保证每个月都存在该日期
+const MaxMonthStartDay = 28
+
+// 回收站保留天数的默认值与上限，0 表示不自动清理
+const (
+	DefaultTrashRetentionDays = 30
+	MaxTrashRetentionDays     = 3650
+)
+
+// 备份间隔上限（30 天）与各级保留份数上限
+const (
+	MaxBackupIntervalHours = 720
+	MaxBackupKeep          = 100
+)
+
+// DefaultBackupPolicy 每天备份一次，保留最近 7 天、4 周、12 个月
+var DefaultBackupPolicy = model.BackupPolicy{IntervalHours: 24, KeepDaily: 7, KeepWeekly: 4, KeepMonthly: 12}
+
+type SettingsService struct {
+	repo repository.Repository
+}
+
+func NewSettingsService(repo repository.Repository) *SettingsService {
+	return &SettingsService{repo: repo}
+}
+
+// MonthStartDay 记账月的起始日，未设置时为 1 号
+func (s *SettingsService) MonthStartDay() (int, error) {
+	value, err := s.repo.GetSetting(SettingMonthStartDay)
+	if err != nil {
+		return 0, err
+	}
+	if value == "" {
+		return 1, nil
+	}
+
+	day, err := strconv.Atoi(value)
+	if err != nil || day < 1 || day > MaxMonthStartDay {
+		return 1, nil
+	}
+	return day, nil
+}
+
+// SetMonthStartDay 设置记账月的起始日
+func (s *SettingsService) SetMonthStartDay(day int) error {
+	if day < 1 || day > MaxMonthStartDay {
+		return apperrors.ErrInvalidMonthStartDay
+	}
+	return s.repo.SetSetting(SettingMonthStartDay, strconv.Itoa(day))
+}
+
+// BaseCurrency 本位币，统计金额统一换算为该币种，未设置时为默认币种
+func (s *SettingsService) BaseCurrency() (string, error) {
+	value, err := s.repo.GetSetting(SettingBaseCurrency)
+	if err != nil {
+		return "", err
+	}
+	if !model.ValidCurrency(value) {
+		return model.DefaultCurrency, nil
+	}
+	return value, nil
+}
+
+// SetBaseCurrency 设置本位币
+func (s *SettingsService) SetBaseCurrency(currency string) error {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if !model.ValidCurrency(currency) {
+		return apperrors.ErrInvalidCurrency
+	}
+	return s.repo.SetSetting(SettingBaseCurrency, currency)
+}
+
+// TrashRetentionDays 回收站内容的保留天数，超过后自动彻底删除；0 表示不自动清理
+func (s *SettingsService) TrashRetentionDays() (int, error) {
+	value, err := s.repo.GetSetting(SettingTrashRetentionDays)
+	if err != nil {
+		return 0, err
+	}
+	if value == "" {
+		return DefaultTrashRetentionDays, nil
+	}
+
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 0 || days > MaxTrashRetentionDays {
+		return DefaultTrashRetentionDays, nil
+	}
+	return days, nil
+}
+
+// SetTrashRetentionDays 设置回收站保留天数
+func (s *SettingsService) SetTrashRetentionDays(days int) error {
+	if days < 0 || days > MaxTrashRetentionDays {
+		return apperrors.ErrInvalidRetention
+	}
+	return s.repo.SetSetting(SettingTrashRetentionDays, strconv.Itoa(days))
+}
+
+// intSetting 读取整数设置项，未设置或超出 [0, max] 时返回默认值
+func (s *SettingsService) intSetting(key string, max, def int) (int, error) {
+	value, err := s.repo.GetSetting(key)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 || n > max {
+		return def, nil
+	}
+	return n, nil
+}
+
+// BackupPolicy 自动备份的间隔与保留份数，未设置的项使用默认值
+func (s *SettingsService) BackupPolicy() (model.BackupPolicy, error) {
+	var p model.BackupPolicy
+	for _, item := range []struct {
+		key      string
+		max, def int
+		dst      *int
+	}{
+		{SettingBackupInterval, MaxBackupIntervalHours, DefaultBackupPolicy.IntervalHours, &p.IntervalHours},
+		{SettingBackupKeepDaily, MaxBackupKeep, DefaultBackupPolicy.KeepDaily, &p.KeepDaily},
+		{SettingBackupKeepWeekly, MaxBackupKeep, DefaultBackupPolicy.KeepWeekly, &p.KeepWeekly},
+		{SettingBackupKeepMonthly, MaxBackupKeep, DefaultBackupPolicy.KeepMonthly, &p.KeepMonthly},
+	} {
+		n, err := s.intSetting(item.key, item.max, item.def)
+		if err != nil {
+			return model.BackupPolicy{}, err
+		}
+		*item.dst = n
+	}
+	return p, nil
+}
+
+// SetBackupPolicy 设置自动备份的间隔与保留份数
+func (s *SettingsService) SetBackupPolicy(p model.BackupPolicy) error {
+	if p.IntervalHours < 0 || p.IntervalHours > MaxBackupIntervalHours {
+		return apperrors.ErrInvalidBackupPolicy
+	}
+	for _, keep := range []int{p.KeepDaily, p.KeepWeekly, p.KeepMonthly} {
+		if keep < 0 || keep > MaxBackupKeep {
+			return apperrors.ErrInvalidBackupPolicy
+		}
+	}
+
+	for key, value := range map[string]int{
+		SettingBackupInterval:    p.IntervalHours,
+		SettingBackupKeepDaily:   p.KeepDaily,
+		SettingBackupKeepWeekly:  p.KeepWeekly,
+		SettingBackupKeepMonthly: p.KeepMonthly,
+	} {
+		if err := s.repo.SetSetting(key, strconv.Itoa(value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"sort"
+	"strings"
+
+	apperrors "dog-view/internal/errors"
+	"dog-view/internal/export"
+	"dog-view/internal/model"
+	"dog-view/internal/repository"
+)
+
+type TagService struct {
+	repo    repository.Repository
+	records *RecordService
+}
+
+func NewTagService(repo repository.Repository, records *RecordService) *TagService {
+	return &TagService{repo: repo, records: records}
+}
+
+// normalizeTagName 去除首尾空白并校验标签名称，CSV 的标签分隔符不能出现在名称中
+func normalizeTagName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.Contains(name, export.TagSeparator) {
+		return "", apperrors.ErrInvalidTagName
+	}
+	return name, nil
+}
+
+// resolveTags 校验标签 ID 均存在，返回去重后的标签
+func resolveTags(repo repository.Repository, tagIDs []int64) ([]model.Tag, error) {
+	if len(tagIDs) == 0 {
+		return nil, nil
+	}
+
+	all, err := repo.ListTags()
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]model.Tag, len(all))
+	for _, t := range all {
+		byID[t.ID] = t
+	}
+
+	var tags []model.Tag
+	seen := make(map[int64]bool, len(tagIDs))
+	for _, id := range tagIDs {
+		t, ok := byID[id]
+		if !ok {
+			return nil, apperrors.ErrTagNotFound
+		}
+		if !seen[id] {
+			seen[id] = true
+			tags = append(tags, t)
+		}
+	}
+	return tags, nil
+}
+
+// idsOfTags 提取标签 ID
+func idsOfTags(tags []model.Tag) []int64 {
+	ids := make([]int64, 0, len(tags))
+	for _, t := range tags {
+		ids = append(ids, t.ID)
+	}
+	return ids
+}
+
+func (s *TagService) List() ([]model.Tag, error) {
+	return s.repo.ListTags()
+}
+
+func (s *TagService) Create(name, color string) (*model.Tag, error) {
+	name, err := normalizeTagName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	tag := &model.Tag{Name: name, Color: color}
+	if err := s.repo.CreateTag(tag); err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+func (s *TagService) Update(id int64, name, color string) error {
+	name, err := normalizeTagName(name)
+	if err != nil {
+		return err
+	}
+	return s.repo.UpdateTag(&model.Tag{ID: id, Name: name, Color: color})
+}
+
+// Delete 删除标签，带有该标签的记录保留
+func (s *TagService) Delete(id int64) error {
+	return s.repo.DeleteTag(id)
+}
+
+// SetRecordTags 替换记录的标签
+func (s *TagService) SetRecordTags(recordID int64, tagIDs []int64) error {
+	tags, err := resolveTags(s.repo, tagIDs)
+	if err != nil {
+		return err
+	}
+	return s.repo.SetRecordTags(recordID, idsOfTags(tags))
+}
+
+// ListByTags 获取记账周期内带有指定标签的记录，matchAll 为 true 时要求包含全部标签
+func (s *TagService) ListByTags(year, month int, tagIDs []int64, matchAll bool) ([]model.Record, error) {
+	period, err := s.records.Period(year, month)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListRecords(model.RecordFilter{
+		StartDate:    period.Start,
+		EndDate:      period.End,
+		TagIDs:       tagIDs,
+		MatchAllTags: matchAll,
+	})
+}
+
+// GetTagStats 获取记账周期的标签统计，金额换算为本位币；
+// 一条记录有多个标签时计入每个标签，因此各标签占比之和可能超过 100%
+func (s *TagService) GetTagStats(year, month int) (*model.TagStatsResponse, error) {
+	period, err := s.records.Period(year, month)
+	if err != nil {
+		return nil, err
+	}
+
+	totals, err := s.repo.GetTagTotals(period.Start, period.End)
+	if err != nil {
+		return nil, err
+	}
+	base, err := s.records.toBase(totals)
+	if err != nil {
+		return nil, err
+	}
+
+	// 占比以当期该类型的收支总额为分母
+	all, _, err := s.records.convertedTotals(period.Start, period.End)
+	if err != nil {
+		return nil, err
+	}
+	summary := summarize(all, base)
+
+	tags, err := s.repo.ListTags()
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TagStatsResponse{
+		IncomeStats:  tagStats(totals, tags, model.TypeIncome, summary.TotalIncome),
+		ExpenseStats: tagStats(totals, tags, model.TypeExpense, summary.TotalExpense),
+	}, nil
+}
+
+// tagStats 按标签汇总指定类型的金额，只返回金额大于 0 的标签，按金额倒序
+func tagStats(totals []model.StatTotal, tags []model.Tag, recordType string, total model.Money) []model.TagStat {
+	sums := make(map[int64]int64)
+	for _, t := range totals {
+		if t.Type == recordType {
+			sums[t.TagID] += t.Amount.Minor
+		}
+	}
+
+	// tags 已按名称排序，稳定排序保证金额相同时按名称
+	var stats []model.TagStat
+	for _, tag := range tags {
+		if sums[tag.ID] <= 0 {
+			continue
+		}
+		s := model.TagStat{
+			TagID:    tag.ID,
+			TagName:  tag.Name,
+			TagColor: tag.Color,
+			Amount:   model.NewMoney(sums[tag.ID], total.Currency),
+		}
+		s.Percentage = s.Amount.Percentage(total)
+		stats = append(stats, s)
+	}
+
+	sort.SliceStable(stats, func(i, j int) bool {
+		return stats[i].Amount.Minor > stats[j].Amount.Minor
+	})
+	return stats
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"time"
+
+	"dog-view/internal/model"
+	"dog-view/internal/repository"
+)
+
+type TrashService struct {
+	repo     repository.Repository
+	settings *SettingsService
+}
+
+func NewTrashService(repo repository.Repository, settings *SettingsService) *TrashService {
+	return &TrashService{repo: repo, settings: settings}
+}
+
+// List 返回回收站中的记录和分类，最近删除的在前
+func (s *TrashService) List() (*model.Trash, error) {
+	records, err := s.repo.ListDeletedRecords()
+	if err != nil {
+		return nil, err
+	}
+	categories, err := s.repo.ListDeletedCategories()
+	if err != nil {
+		return nil, err
+	}
+	if records == nil {
+		records = []model.Record{}
+	}
+	if categories == nil {
+		categories = []model.Category{}
+	}
+	return &model.Trash{Records: records, Categories: categories}, nil
+}
+
+// RestoreRecord 恢复记录，其分类在回收站中时一并恢复
+func (s *TrashService) RestoreRecord(id int64) error {
+	return s.repo.RestoreRecord(id)
+}
+
+// RestoreCategory 恢复分类，其上级分类在回收站中时一并恢复
+func (s *TrashService) RestoreCategory(id int64) error {
+	return s.repo.RestoreCategory(id)
+}
+
+// PurgeRecord 彻底删除回收站中的记录
+func (s *TrashService) PurgeRecord(id int64) error {
+	return s.repo.PurgeRecord(id)
+}
+
+// PurgeCategory 彻底删除回收站中的分类，回收站中仍有记录或子分类引用时需先处理它们
+func (s *TrashService) PurgeCategory(id int64) error {
+	return s.repo.PurgeCategory(id)
+}
+
+// Empty 清空回收站；仍被未删除的记录引用的分类（如子分类已恢复的上级分类）会保留
+func (s *TrashService) Empty() (model.PurgeResult, error) {
+	return s.repo.PurgeTrash(time.Now())
+}
+
+// PurgeExpired 彻底删除移入回收站超过保留天数的内容，保留天数为 0 时不做任何操作
+func (s *TrashService) PurgeExpired(now time.Time) (model.PurgeResult, error) {
+	days, err := s.settings.TrashRetentionDays()
+	if err != nil || days == 0 {
+		return model.PurgeResult{}, err
+	}
+	return s.repo.PurgeTrash(now.AddDate(0, 0, -days))
+}
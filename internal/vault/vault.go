@@ -0,0 +1,230 @@
+// Package vault 负责账本文件和备份的静态加密。
+//
+// 加密文件格式：魔数 | 版本 | Argon2id 参数（time、memory、threads）| salt | nonce | 密文。
+// 密钥由用户密码经 Argon2id 派生，正文使用 AES-256-GCM 加密，文件头作为附加数据参与认证，
+// 因此篡改参数或密文都会导致解密失败
+package vault
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"unicode/utf8"
+
+	apperrors "dog-view/internal/errors"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	magic   = "DOGVIEW-ENC"
+	version = 1
+
+	saltSize  = 16
+	nonceSize = 12
+	keySize   = 32
+	// headerSize 魔数、版本、time(4)、memory(4)、threads(1)、salt
+	headerSize = len(magic) + 1 + 4 + 4 + 1 + saltSize
+)
+
+// MinPassphraseLength 密码的最少字符数
+const MinPassphraseLength = 8
+
+// 新密钥使用的 Argon2id 参数，单次派生在普通电脑上约需数百毫秒
+var defaultParams = params{time: 3, memory: 64 * 1024, threads: 4}
+
+// sqliteHeader 未加密的 SQLite 数据库文件开头
+var sqliteHeader = []byte("SQLite format 3\x00")
+
+type params struct {
+	time    uint32
+	memory  uint32 // KiB
+	threads uint8
+}
+
+// Key 由密码派生的密钥及其派生参数，同一个 Key 可加密多个文件（每次使用随机 nonce）
+type Key struct {
+	params params
+	salt   []byte
+	aead   cipher.AEAD
+}
+
+// ValidatePassphrase 校验密码强度
+func ValidatePassphrase(passphrase string) error {
+	if utf8.RuneCountInString(passphrase) < MinPassphraseLength {
+		return apperrors.ErrWeakPassphrase
+	}
+	return nil
+}
+
+// NewKey 以随机 salt 从密码派生新密钥
+func NewKey(passphrase string) (*Key, error) {
+	if err := ValidatePassphrase(passphrase); err != nil {
+		return nil, err
+	}
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return deriveKey(passphrase, defaultParams, salt)
+}
+
+func deriveKey(passphrase string, p params, salt []byte) (*Key, error) {
+	raw := argon2.IDKey([]byte(passphrase), salt, p.time, p.memory, p.threads, keySize)
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Key{params: p, salt: salt, aead: aead}, nil
+}
+
+// header 返回该密钥对应的文件头
+func (k *Key) header() []byte {
+	h := make([]byte, 0, headerSize)
+	h = append(h, magic...)
+	h = append(h, version)
+	h = binary.BigEndian.AppendUint32(h, k.params.time)
+	h = binary.BigEndian.AppendUint32(h, k.params.memory)
+	h = append(h, k.params.threads)
+	return append(h, k.salt...)
+}
+
+// Seal 加密数据，返回完整的加密文件内容
+func (k *Key) Seal(plaintext []byte) ([]byte, error) {
+	header := k.header()
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out := append(header, nonce...)
+	return k.aead.Seal(out, nonce, plaintext, header), nil
+}
+
+// parseHeader 解析加密文件头，不是加密文件时返回 false
+func parseHeader(data []byte) (params, []byte, bool) {
+	if len(data) < headerSize || !bytes.HasPrefix(data, []byte(magic)) || data[len(magic)] != version {
+		return params{}, nil, false
+	}
+	rest := data[len(magic)+1:]
+	p := params{
+		time:    binary.BigEndian.Uint32(rest[0:4]),
+		memory:  binary.BigEndian.Uint32(rest[4:8]),
+		threads: rest[8],
+	}
+	return p, rest[9 : 9+saltSize], true
+}
+
+// Open 用密码解密加密文件内容，返回明文和解密所用的密钥，密码错误时返回 ErrWrongPassphrase
+func Open(data []byte, passphrase string) ([]byte, *Key, error) {
+	p, salt, ok := parseHeader(data)
+	if !ok {
+		return nil, nil, apperrors.ErrNotEncrypted
+	}
+	if len(data) < headerSize+nonceSize {
+		return nil, nil, apperrors.ErrWrongPassphrase
+	}
+	key, err := deriveKey(passphrase, p, append([]byte(nil), salt...))
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce := data[headerSize : headerSize+nonceSize]
+	plaintext, err := key.aead.Open(nil, nonce, data[headerSize+nonceSize:], data[:headerSize])
+	if err != nil {
+		return nil, nil, apperrors.ErrWrongPassphrase
+	}
+	return plaintext, key, nil
+}
+
+// IsEncrypted 判断文件是否为加密文件，文件不存在时返回 false
+func IsEncrypted(path string) (bool, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	head := make([]byte, headerSize)
+	if _, err := io.ReadFull(f, head); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return false, nil
+		}
+		return false, err
+	}
+	_, _, ok := parseHeader(head)
+	return ok, nil
+}
+
+// ReadFile 读取并解密文件
+func ReadFile(path, passphrase string) ([]byte, *Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return Open(data, passphrase)
+}
+
+// WriteFile 加密数据后写入 path；key 为 nil 时写入明文。
+// 先写临时文件并同步到磁盘再重命名，写到一半时崩溃不会损坏原文件
+func WriteFile(path string, data []byte, key *Key) error {
+	if key != nil {
+		sealed, err := key.Seal(data)
+		if err != nil {
+			return err
+		}
+		data = sealed
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// Rewrite 将已关闭的数据库文件改写为用 key 加密（key 为 nil 时为明文）。
+// 原文件已加密时用 passphrase 解密，未加密时必须是完整的 SQLite 数据库文件
+func Rewrite(path, passphrase string, key *Key) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if _, _, ok := parseHeader(data); ok {
+		if data, _, err = Open(data, passphrase); err != nil {
+			return err
+		}
+	} else if !bytes.HasPrefix(data, sqliteHeader) {
+		return apperrors.ErrNotDatabase
+	}
+	return WriteFile(path, data, key)
+}